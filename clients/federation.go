@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/gernest/sydent-go/config"
@@ -52,10 +53,10 @@ func SrvResolver() SRVResolveFunc {
 	return func(host string) (*Server, error) {
 		mu.RLock()
 		v, ok := cache[host]
+		mu.RUnlock()
 		if ok {
 			return v, nil
 		}
-		mu.RUnlock()
 		cname, addrs, err := net.LookupSRV(serviceName, serviceProto, host)
 		if err != nil {
 			return nil, err
@@ -184,3 +185,43 @@ func NewFederatedClient() *http.Client {
 
 // Fed is a global federated matrix client. Safe for concurrent use.
 var Fed = NewFederatedClient()
+
+// ValidServerName returns true if name is a valid matrix server name. That is
+// a dns name, an IPv4 address or a bracketed IPv6 address optionally followed
+// by a port.
+func ValidServerName(name string) bool {
+	if name == "" || strings.ContainsAny(name, "/?#@\\ ") {
+		return false
+	}
+	host := name
+	if i := strings.LastIndexByte(name, ':'); i != -1 && !strings.HasSuffix(name, "]") {
+		host = name[:i]
+		port, err := strconv.Atoi(name[i+1:])
+		if err != nil || port <= 0 || port > 65535 {
+			return false
+		}
+	}
+	if strings.HasPrefix(host, "[") {
+		if !strings.HasSuffix(host, "]") {
+			return false
+		}
+		ip := net.ParseIP(host[1 : len(host)-1])
+		return ip != nil && ip.To4() == nil
+	}
+	if len(host) == 0 || len(host) > 255 {
+		return false
+	}
+	for _, label := range strings.Split(host, ".") {
+		if label == "" || len(label) > 63 {
+			return false
+		}
+		for _, c := range label {
+			switch {
+			case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-':
+			default:
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -2,8 +2,12 @@ package clients
 
 import (
 	"fmt"
+	"io/ioutil"
 	"net"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
 )
 
@@ -62,3 +66,79 @@ func TestRouteMatrixURI(t *testing.T) {
 		}
 	}
 }
+
+func TestFederatedTripper(t *testing.T) {
+	var calls int
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch r.URL.Path {
+		case "/flaky":
+			if calls < 3 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			b, _ := ioutil.ReadAll(r.Body)
+			w.Write(b)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{
+		Transport: NewFederatedTripperWith(5, srv.Client().Transport, nil),
+	}
+
+	req, err := http.NewRequest(http.MethodPost, "matrix://"+u.Host+"/flaky", strings.NewReader("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if string(b) != "hello" || calls != 3 {
+		t.Errorf("expected hello after 3 calls got %q after %d", string(b), calls)
+	}
+
+	calls = 0
+	res, err = client.Get("matrix://" + u.Host + "/missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusNotFound || calls != 1 {
+		t.Errorf("expected a single 404 got %d after %d calls", res.StatusCode, calls)
+	}
+}
+
+func TestValidServerName(t *testing.T) {
+	sample := []struct {
+		name  string
+		valid bool
+	}{
+		{"matrix.org", true},
+		{"matrix.org:8448", true},
+		{"127.0.0.1:8448", true},
+		{"[::1]:8448", true},
+		{"[::1]", true},
+		{"", false},
+		{"matrix.org:0", false},
+		{"matrix.org:port", false},
+		{"evil.com/path", false},
+		{"user@evil.com", false},
+		{"bad_host.com", false},
+		{"[::1", false},
+		{"a..b", false},
+	}
+	for _, v := range sample {
+		if got := ValidServerName(v.name); got != v.valid {
+			t.Errorf("%q: expected %v got %v", v.name, v.valid, got)
+		}
+	}
+}
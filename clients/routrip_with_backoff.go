@@ -9,47 +9,96 @@ import (
 
 // FederatedTripper implements http.RoundTripper interface with support for exponential
 // backoff retries. This is used to talk to federated servers
+//
+// Requests are made to matrix://server_name/path urls, the server name is
+// resolved to the actual host and the request is sent over https. Responses
+// with 5xx status codes are retried, other responses are returned as is.
 type FederatedTripper struct {
-	backoff   backoff.BackOff
-	round     http.RoundTripper
-	resolve   SRVResolveFunc
-	wellKnown bool
+	maxRetries uint64
+	round      http.RoundTripper
+	resolve    SRVResolveFunc
+	wellKnown  bool
 }
 
+// NewFederatedTripper returns a FederatedTripper which retries failed requests
+// at most maxRetries times, requests are not retried when maxRetries is 0.
 func NewFederatedTripper(maxRetries uint64) *FederatedTripper {
-	b := backoff.NewExponentialBackOff()
+	return NewFederatedTripperWith(maxRetries, http.DefaultTransport, SrvResolver())
+}
+
+// NewFederatedTripperWith returns a FederatedTripper which sends requests with
+// round and resolves server names with resolve. This allows talking to servers
+// with custom tls settings, like a local homeserver used in tests.
+func NewFederatedTripperWith(maxRetries uint64, round http.RoundTripper, resolve SRVResolveFunc) *FederatedTripper {
 	return &FederatedTripper{
-		backoff: backoff.WithMaxRetries(b, maxRetries),
-		round:   http.DefaultTransport,
-		resolve: SrvResolver(),
+		maxRetries: maxRetries,
+		round:      round,
+		resolve:    resolve,
 	}
 }
 
 func (tr *FederatedTripper) RoundTrip(req *http.Request) (*http.Response, error) {
 	var res *http.Response
-	var ri *RoutingInfo
-	var terr error
+	// backoff.WithMaxRetries retries forever when the maximum is 0.
+	var retry backoff.BackOff = &backoff.StopBackOff{}
+	if tr.maxRetries > 0 {
+		retry = backoff.WithMaxRetries(backoff.NewExponentialBackOff(), tr.maxRetries)
+	}
+	b := backoff.WithContext(retry, req.Context())
+	attempt := 0
 	err := backoff.Retry(func() error {
-		ri, terr = RouteMatrixURI(tr.resolve, req.URL, tr.wellKnown)
-		if terr != nil {
-			return terr
+		ri, err := RouteMatrixURI(tr.resolve, req.URL, tr.wellKnown)
+		if err != nil {
+			return err
 		}
-		if h := req.Header.Get("Host"); h == "" {
-			req.Header.Set("Host", ri.HostHeader)
+		r, err := routedRequest(req, ri, attempt)
+		if err != nil {
+			return backoff.Permanent(err)
 		}
-		req.URL.Host = fmt.Sprintf("%s:%d", ri.TargetHost, ri.TargetPort)
-		res, terr = tr.round.RoundTrip(req)
-		if terr != nil {
-			return terr
+		attempt++
+		res, err = tr.round.RoundTrip(r)
+		if err != nil {
+			return err
 		}
-		if res.StatusCode != http.StatusOK {
+		if res.StatusCode >= http.StatusInternalServerError {
 			res.Body.Close()
 			return fmt.Errorf("matrixid/clients:received not 200 status code :%d", res.StatusCode)
 		}
 		return nil
-	}, tr.backoff)
+	}, b)
 	if err != nil {
 		return nil, err
 	}
 	return res, nil
 }
+
+// routedRequest returns a copy of req which is sent to the host in ri. The
+// original request is not modified.
+func routedRequest(req *http.Request, ri *RoutingInfo, attempt int) (*http.Request, error) {
+	r := new(http.Request)
+	*r = *req
+	u := *req.URL
+	r.URL = &u
+	r.Header = make(http.Header)
+	for k, v := range req.Header {
+		r.Header[k] = v
+	}
+	if r.Host == "" || r.Host == req.URL.Host {
+		r.Host = ri.HostHeader
+	}
+	r.URL.Host = fmt.Sprintf("%s:%d", ri.TargetHost, ri.TargetPort)
+	if r.URL.Scheme == "matrix" || r.URL.Scheme == "" {
+		r.URL.Scheme = "https"
+	}
+	if attempt > 0 && req.Body != nil {
+		if req.GetBody == nil {
+			return nil, fmt.Errorf("matrixid/clients: can not retry request to %s", req.URL)
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		r.Body = body
+	}
+	return r, nil
+}
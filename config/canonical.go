@@ -0,0 +1,32 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/gernest/sydent-go/models"
+)
+
+// Canonical configures the canonical form addresses are stored and looked up
+// in, see models.Canonicalizer.
+type Canonical struct {
+	// EmailProviders are the names of the models.ProviderRules applied to
+	// email addresses, for instance gmail ignores dots and everything after a
+	// + in the local part.
+	EmailProviders []string `hcl:"email_providers"`
+}
+
+// Valid validates c settings.
+func (c Canonical) Valid() *Validation {
+	v := &Validation{Namespace: "canonical"}
+	for _, p := range c.EmailProviders {
+		if _, ok := models.ProviderRules[p]; !ok {
+			v.Set("email_providers", fmt.Sprintf("unknown provider %q, supported are %v", p, models.Providers()))
+		}
+	}
+	return v
+}
+
+// Canonicalizer returns the models.Canonicalizer applying c.
+func (c Canonical) Canonicalizer() (*models.Canonicalizer, error) {
+	return models.NewCanonicalizer(c.EmailProviders...)
+}
@@ -0,0 +1,344 @@
+package config
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/bits"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Captcha verifiers, see Captcha.Verifier.
+const (
+	CaptchaProofOfWork = "pow"
+	CaptchaHTTP        = "http"
+)
+
+// Captcha defaults, see Captcha.
+const (
+	DefaultPowDifficulty = 20
+	DefaultCaptchaWindow = time.Hour
+)
+
+// PowChallengeTTL is how long a proof of work challenge can be solved for.
+const PowChallengeTTL = 10 * time.Minute
+
+// ErrCaptchaInvalid is returned by CaptchaVerifier.Verify when the response
+// does not solve a challenge.
+var ErrCaptchaInvalid = errors.New("captcha: invalid response")
+
+// CaptchaVerifier verifies the responses of clients to a challenge.
+type CaptchaVerifier interface {
+	Validator
+
+	// Challenge returns what clients need to solve a challenge, it is sent to
+	// them with M_CAPTCHA_NEEDED.
+	Challenge() (map[string]interface{}, error)
+
+	// Verify returns nil if response solves a challenge, ip is the address of
+	// the client. ErrCaptchaInvalid is returned when it does not.
+	Verify(ctx context.Context, response, ip string) error
+}
+
+// Captcha configures the challenge clients must solve before requesting tokens
+// or storing invites. It is disabled when Verifier is empty.
+//
+// Clients are challenged once they made more than Threshold requests in
+// Window, or always when Threshold is empty.
+type Captcha struct {
+	// Verifier is pow for the built in hashcash like proof of work, or http for
+	// a recaptcha compatible siteverify endpoint.
+	Verifier string `hcl:"verifier"`
+
+	// Threshold is the number of requests a client ip can make in Window
+	// without solving a challenge.
+	Threshold string `hcl:"threshold"`
+
+	// Window is the period Threshold applies to, defaults to 1h.
+	Window string `hcl:"window"`
+
+	// Difficulty is the number of leading zero bits of the proof of work,
+	// defaults to 20.
+	Difficulty string `hcl:"difficulty"`
+
+	// Secret signs the proof of work challenges, replicas must share it. A
+	// random secret is used when it is empty. For the http verifier it is the
+	// secret sent to URL.
+	Secret string `hcl:"secret"`
+
+	// URL is the siteverify endpoint of the http verifier.
+	URL string `hcl:"url"`
+
+	// SiteKey is sent to clients for the http verifier.
+	SiteKey string `hcl:"site_key"`
+}
+
+// Enabled returns true if clients can be challenged.
+func (c Captcha) Enabled() bool {
+	return c.Verifier != ""
+}
+
+// Valid validates c settings.
+func (c Captcha) Valid() *Validation {
+	v := &Validation{Namespace: "captcha"}
+	if !c.Enabled() {
+		return v
+	}
+	if c.Threshold != "" {
+		n, err := strconv.Atoi(c.Threshold)
+		if err != nil {
+			v.Set("threshold", err.Error())
+		} else if n < 0 {
+			v.Set("threshold", "must not be negative")
+		}
+	}
+	if c.Window != "" {
+		d, err := time.ParseDuration(c.Window)
+		if err != nil {
+			v.Set("window", err.Error())
+		} else if d <= 0 {
+			v.Set("window", "must be positive")
+		}
+	}
+	verifier, err := c.CaptchaVerifier()
+	if err != nil {
+		v.Set("verifier", err.Error())
+	} else {
+		v.add(verifier)
+	}
+	return v
+}
+
+// Limit returns the number of requests allowed without a challenge, 0 means
+// all requests are challenged.
+func (c Captcha) Limit() int {
+	return positive(c.Threshold, 0)
+}
+
+// Every returns the period Limit applies to.
+func (c Captcha) Every() time.Duration {
+	return duration(c.Window, DefaultCaptchaWindow)
+}
+
+// CaptchaVerifier returns the configured verifier, nil is returned when c is
+// disabled.
+func (c Captcha) CaptchaVerifier() (CaptchaVerifier, error) {
+	switch c.Verifier {
+	case "":
+		return nil, nil
+	case CaptchaProofOfWork:
+		difficulty := DefaultPowDifficulty
+		if c.Difficulty != "" {
+			n, err := strconv.Atoi(c.Difficulty)
+			if err != nil {
+				return nil, err
+			}
+			difficulty = n
+		}
+		key := []byte(c.Secret)
+		if len(key) == 0 {
+			key = make([]byte, 32)
+			if _, err := io.ReadFull(rand.Reader, key); err != nil {
+				return nil, err
+			}
+		}
+		return NewPowVerifier(key, difficulty), nil
+	case CaptchaHTTP:
+		return NewHTTPCaptchaVerifier(c.URL, c.Secret, c.SiteKey), nil
+	default:
+		return nil, fmt.Errorf("unknown captcha verifier %q", c.Verifier)
+	}
+}
+
+// PowVerifier is a hashcash like proof of work. Challenges are signed
+// timestamps, clients solve them by finding a nonce such that
+// sha256(challenge + ":" + nonce) starts with Difficulty zero bits. The
+// response is challenge:nonce.
+//
+// Challenges are stateless, they can be solved until PowChallengeTTL passed.
+// Replays must be detected by the caller.
+type PowVerifier struct {
+	key        []byte
+	difficulty int
+
+	// now is replaced in tests.
+	now func() time.Time
+}
+
+// NewPowVerifier returns PowVerifier signing challenges with key.
+func NewPowVerifier(key []byte, difficulty int) *PowVerifier {
+	return &PowVerifier{key: key, difficulty: difficulty, now: time.Now}
+}
+
+func (p *PowVerifier) Valid() *Validation {
+	v := &Validation{Namespace: "pow"}
+	if p.difficulty < 1 || p.difficulty > 32 {
+		v.Set("difficulty", "must be between 1 and 32")
+	}
+	return v
+}
+
+func (p *PowVerifier) Challenge() (map[string]interface{}, error) {
+	nonce := make([]byte, 12)
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	challenge := strconv.FormatInt(p.now().Unix(), 10) + "." +
+		base64.RawURLEncoding.EncodeToString(nonce)
+	challenge += "." + p.sign(challenge)
+	return map[string]interface{}{
+		"type":       CaptchaProofOfWork,
+		"challenge":  challenge,
+		"difficulty": p.difficulty,
+		"algorithm":  "sha256",
+	}, nil
+}
+
+func (p *PowVerifier) Verify(ctx context.Context, response, ip string) error {
+	i := strings.LastIndexByte(response, ':')
+	if i < 0 {
+		return ErrCaptchaInvalid
+	}
+	challenge := response[:i]
+	parts := strings.Split(challenge, ".")
+	if len(parts) != 3 {
+		return ErrCaptchaInvalid
+	}
+	mac := p.sign(parts[0] + "." + parts[1])
+	if !hmac.Equal([]byte(mac), []byte(parts[2])) {
+		return ErrCaptchaInvalid
+	}
+	ts, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return ErrCaptchaInvalid
+	}
+	age := p.now().Sub(time.Unix(ts, 0))
+	if age < -time.Minute || age > PowChallengeTTL {
+		return ErrCaptchaInvalid
+	}
+	if leadingZeros(sha256.Sum256([]byte(response))) < p.difficulty {
+		return ErrCaptchaInvalid
+	}
+	return nil
+}
+
+func (p *PowVerifier) sign(s string) string {
+	h := hmac.New(sha256.New, p.key)
+	h.Write([]byte(s))
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
+
+// SolvePow returns the response solving the proof of work challenge.
+func SolvePow(challenge string, difficulty int) string {
+	for nonce := 0; ; nonce++ {
+		response := challenge + ":" + strconv.Itoa(nonce)
+		if leadingZeros(sha256.Sum256([]byte(response))) >= difficulty {
+			return response
+		}
+	}
+}
+
+func leadingZeros(sum [sha256.Size]byte) int {
+	n := 0
+	for _, b := range sum {
+		if b != 0 {
+			return n + bits.LeadingZeros8(b)
+		}
+		n += 8
+	}
+	return n
+}
+
+// HTTPCaptchaVerifier verifies responses with a recaptcha compatible
+// siteverify endpoint, like the ones of recaptcha, hcaptcha or turnstile. The
+// secret, response and remoteip are posted as a form, the endpoint replies with
+// a json object whose success field is true for valid responses.
+type HTTPCaptchaVerifier struct {
+	URL     string
+	Secret  string
+	SiteKey string
+	Client  HTTPClient
+}
+
+// NewHTTPCaptchaVerifier returns HTTPCaptchaVerifier which gives up on
+// requests after 10 seconds.
+func NewHTTPCaptchaVerifier(endpoint, secret, siteKey string) *HTTPCaptchaVerifier {
+	return &HTTPCaptchaVerifier{
+		URL:     endpoint,
+		Secret:  secret,
+		SiteKey: siteKey,
+		Client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (h *HTTPCaptchaVerifier) Valid() *Validation {
+	v := &Validation{Namespace: "http_captcha"}
+	if h.URL == "" {
+		v.Set("url", missingField)
+	} else if u, err := url.Parse(h.URL); err != nil {
+		v.Set("url", err.Error())
+	} else if u.Scheme != "http" && u.Scheme != "https" {
+		v.Set("url", "must be a http or https url")
+	}
+	if h.Secret == "" {
+		v.Set("secret", missingField)
+	}
+	return v
+}
+
+func (h *HTTPCaptchaVerifier) Challenge() (map[string]interface{}, error) {
+	return map[string]interface{}{
+		"type":     CaptchaHTTP,
+		"site_key": h.SiteKey,
+	}, nil
+}
+
+func (h *HTTPCaptchaVerifier) Verify(ctx context.Context, response, ip string) error {
+	if response == "" {
+		return ErrCaptchaInvalid
+	}
+	form := url.Values{
+		"secret":   {h.Secret},
+		"response": {response},
+	}
+	if ip != "" {
+		form.Set("remoteip", ip)
+	}
+	req, err := http.NewRequest(http.MethodPost, h.URL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", AgentName)
+	res, err := h.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		io.Copy(ioutil.Discard, res.Body)
+		return fmt.Errorf("captcha: verifier responded with %s", res.Status)
+	}
+	var result struct {
+		Success bool `json:"success"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return err
+	}
+	if !result.Success {
+		return ErrCaptchaInvalid
+	}
+	return nil
+}
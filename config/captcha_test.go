@@ -0,0 +1,95 @@
+package config
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPowVerifier(t *testing.T) {
+	ctx := context.Background()
+	p := NewPowVerifier([]byte("secret"), 8)
+	now := time.Unix(1560000000, 0)
+	p.now = func() time.Time { return now }
+	c, err := p.Challenge()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c["type"] != CaptchaProofOfWork || c["difficulty"] != 8 {
+		t.Fatalf("unexpected challenge %v", c)
+	}
+	challenge := c["challenge"].(string)
+	response := SolvePow(challenge, 8)
+	if err := p.Verify(ctx, response, ""); err != nil {
+		t.Fatal(err)
+	}
+	other := NewPowVerifier([]byte("other"), 8)
+	other.now = p.now
+	invalid := []struct {
+		name     string
+		v        *PowVerifier
+		response string
+	}{
+		{"empty", p, ""},
+		{"unsolved", p, challenge + ":x"},
+		{"tampered", p, SolvePow("1560000000.AAAA."+strings.Split(challenge, ".")[2], 8)},
+		{"wrong key", other, response},
+		{"harder", NewPowVerifier([]byte("secret"), 32), response},
+	}
+	for _, v := range invalid {
+		if v.v.now == nil {
+			v.v.now = p.now
+		}
+		if err := v.v.Verify(ctx, v.response, ""); err != ErrCaptchaInvalid {
+			t.Errorf("%s: expected ErrCaptchaInvalid got %v", v.name, err)
+		}
+	}
+	now = now.Add(PowChallengeTTL + time.Second)
+	if err := p.Verify(ctx, response, ""); err != ErrCaptchaInvalid {
+		t.Errorf("expected expired challenge to be invalid got %v", err)
+	}
+}
+
+func TestHTTPCaptchaVerifier(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("secret") != "secret" || r.FormValue("remoteip") != "10.0.0.1" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch r.FormValue("response") {
+		case "good":
+			w.Write([]byte(`{"success":true}`))
+		case "down":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.Write([]byte(`{"success":false,"error-codes":["invalid-input-response"]}`))
+		}
+	}))
+	defer srv.Close()
+	c := Captcha{Verifier: CaptchaHTTP, URL: srv.URL, Secret: "secret", SiteKey: "site"}
+	if v := c.Valid(); !v.IsValid() {
+		t.Fatal(v)
+	}
+	h, err := c.CaptchaVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := h.Verify(ctx, "good", "10.0.0.1"); err != nil {
+		t.Error(err)
+	}
+	for _, response := range []string{"", "bad"} {
+		if err := h.Verify(ctx, response, "10.0.0.1"); err != ErrCaptchaInvalid {
+			t.Errorf("%q: expected ErrCaptchaInvalid got %v", response, err)
+		}
+	}
+	if err := h.Verify(ctx, "down", "10.0.0.1"); err == nil || err == ErrCaptchaInvalid {
+		t.Errorf("expected verifier error got %v", err)
+	}
+	if v := (Captcha{Verifier: "nope"}).Valid(); v.IsValid() {
+		t.Error("expected unknown verifier to be invalid")
+	}
+}
@@ -0,0 +1,308 @@
+package config
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+// DefaultDKIMHeaders are the headers signed when DKIM.Headers is empty. Headers
+// missing from a message are not signed.
+var DefaultDKIMHeaders = []string{
+	"From", "To", "Subject", "Date", "Message-ID", "MIME-Version", "Content-Type",
+}
+
+// DKIM configures signing of outgoing emails. Signing is disabled when all
+// fields are empty.
+//
+// The public key must be published in the TXT record
+// <selector>._domainkey.<domain>.
+type DKIM struct {
+	Domain   string `hcl:"domain"`
+	Selector string `hcl:"selector"`
+
+	// KeyFile is a PEM encoded RSA or ed25519 private key, or a base64 encoded
+	// ed25519 seed.
+	KeyFile string `hcl:"key_file"`
+
+	// Headers are the names of the signed headers, defaults to
+	// DefaultDKIMHeaders.
+	Headers []string `hcl:"headers"`
+}
+
+// Enabled returns true if emails are signed.
+func (d DKIM) Enabled() bool {
+	return d.Domain != "" || d.Selector != "" || d.KeyFile != ""
+}
+
+// Valid validates d settings.
+func (d DKIM) Valid() *Validation {
+	v := &Validation{Namespace: "dkim"}
+	if !d.Enabled() {
+		return v
+	}
+	if d.Domain == "" {
+		v.Set("domain", missingField)
+	}
+	if d.Selector == "" {
+		v.Set("selector", missingField)
+	}
+	if d.KeyFile == "" {
+		v.Set("key_file", missingField)
+	} else if _, err := d.Signer(); err != nil {
+		v.Set("key_file", err.Error())
+	}
+	for _, h := range d.Headers {
+		if strings.EqualFold(h, "From") {
+			return v
+		}
+	}
+	if len(d.Headers) > 0 {
+		v.Set("headers", "must include From")
+	}
+	return v
+}
+
+// Signer returns the signer configured by d, nil is returned when signing is
+// disabled.
+func (d DKIM) Signer() (*DKIMSigner, error) {
+	if !d.Enabled() {
+		return nil, nil
+	}
+	key, err := loadDKIMKey(d.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	return NewDKIMSigner(d.Domain, d.Selector, key, d.Headers)
+}
+
+// loadDKIMKey reads the private key in file.
+func loadDKIMKey(file string) (crypto.Signer, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(b)
+	if block == nil {
+		seed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(b)))
+		if err != nil || len(seed) != ed25519.SeedSize {
+			return nil, errors.New("not a PEM encoded key or base64 encoded ed25519 seed")
+		}
+		return ed25519.NewKeyFromSeed(seed), nil
+	}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		if s, ok := key.(crypto.Signer); ok {
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported key type %q", block.Type)
+}
+
+// DKIMSigner signs emails with the relaxed/relaxed canonicalization of RFC
+// 6376, using rsa-sha256 or ed25519-sha256 (RFC 8463) depending on the key.
+type DKIMSigner struct {
+	domain    string
+	selector  string
+	headers   []string
+	key       crypto.Signer
+	algorithm string
+
+	// now is replaced in tests.
+	now func() time.Time
+}
+
+// NewDKIMSigner returns a signer for the domain and selector, headers defaults
+// to DefaultDKIMHeaders. key must be a *rsa.PrivateKey of at least 1024 bits
+// or an ed25519.PrivateKey.
+func NewDKIMSigner(domain, selector string, key crypto.Signer, headers []string) (*DKIMSigner, error) {
+	s := &DKIMSigner{
+		domain:   domain,
+		selector: selector,
+		headers:  headers,
+		key:      key,
+		now:      time.Now,
+	}
+	if len(s.headers) == 0 {
+		s.headers = DefaultDKIMHeaders
+	}
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		if k.N.BitLen() < 1024 {
+			return nil, errors.New("dkim: rsa keys must be at least 1024 bits")
+		}
+		s.algorithm = "rsa-sha256"
+	case ed25519.PrivateKey:
+		s.algorithm = "ed25519-sha256"
+	default:
+		return nil, fmt.Errorf("dkim: unsupported key %T", key)
+	}
+	return s, nil
+}
+
+// Sign returns msg with a DKIM-Signature header added. Line endings of the
+// returned message are normalized to CRLF, it must be sent as it is for the
+// signature to stay valid.
+func (s *DKIMSigner) Sign(msg []byte) ([]byte, error) {
+	msg = crlf(msg)
+	var header, body []byte
+	if i := bytes.Index(msg, []byte("\r\n\r\n")); i >= 0 {
+		header, body = msg[:i+2], msg[i+4:]
+	} else {
+		header = msg
+	}
+	fields := headerFields(header)
+	bh := sha256.Sum256(relaxedBody(body))
+
+	// headers are signed from the bottom, each name once per occurrence in h=.
+	used := make(map[int]bool)
+	var names []string
+	var signed bytes.Buffer
+	for _, name := range s.headers {
+		for i := len(fields) - 1; i >= 0; i-- {
+			if used[i] || !strings.EqualFold(fieldName(fields[i]), name) {
+				continue
+			}
+			used[i] = true
+			names = append(names, strings.ToLower(name))
+			signed.WriteString(relaxedHeader(fields[i]))
+			break
+		}
+	}
+	if !contains(names, "from") {
+		return nil, errors.New("dkim: message has no From header")
+	}
+	value := fmt.Sprintf("v=1; a=%s; c=relaxed/relaxed; d=%s; s=%s; t=%d; h=%s; bh=%s; b=",
+		s.algorithm, s.domain, s.selector, s.now().Unix(),
+		strings.Join(names, ":"), base64.StdEncoding.EncodeToString(bh[:]),
+	)
+	signed.WriteString(strings.TrimSuffix(relaxedHeader("DKIM-Signature: "+value), "\r\n"))
+	digest := sha256.Sum256(signed.Bytes())
+	var opts crypto.SignerOpts = crypto.SHA256
+	if s.algorithm == "ed25519-sha256" {
+		// RFC 8463 signs the sha256 digest with PureEdDSA.
+		opts = crypto.Hash(0)
+	}
+	sig, err := s.key.Sign(rand.Reader, digest[:], opts)
+	if err != nil {
+		return nil, err
+	}
+	var o bytes.Buffer
+	o.WriteString("DKIM-Signature: ")
+	o.WriteString(value)
+	o.WriteString(base64.StdEncoding.EncodeToString(sig))
+	o.WriteString("\r\n")
+	o.Write(msg)
+	return o.Bytes(), nil
+}
+
+func contains(s []string, v string) bool {
+	for _, x := range s {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// crlf converts bare LF line endings of msg to CRLF.
+func crlf(msg []byte) []byte {
+	var o bytes.Buffer
+	for i, b := range msg {
+		if b == '\n' && (i == 0 || msg[i-1] != '\r') {
+			o.WriteByte('\r')
+		}
+		o.WriteByte(b)
+	}
+	return o.Bytes()
+}
+
+// headerFields splits header into fields, continuation lines are kept with
+// their field.
+func headerFields(header []byte) []string {
+	var fields []string
+	for _, line := range strings.SplitAfter(string(header), "\r\n") {
+		if line == "" {
+			continue
+		}
+		if (line[0] == ' ' || line[0] == '\t') && len(fields) > 0 {
+			fields[len(fields)-1] += line
+			continue
+		}
+		fields = append(fields, line)
+	}
+	return fields
+}
+
+func fieldName(field string) string {
+	i := strings.IndexByte(field, ':')
+	if i < 0 {
+		return ""
+	}
+	return strings.TrimSpace(field[:i])
+}
+
+// relaxedHeader returns the relaxed canonicalization of field, RFC 6376
+// section 3.4.2.
+func relaxedHeader(field string) string {
+	i := strings.IndexByte(field, ':')
+	if i < 0 {
+		return ""
+	}
+	name := strings.ToLower(strings.TrimSpace(field[:i]))
+	value := strings.Replace(field[i+1:], "\r\n", "", -1)
+	value = strings.Join(strings.FieldsFunc(value, isWSP), " ")
+	return name + ":" + value + "\r\n"
+}
+
+// relaxedBody returns the relaxed canonicalization of body, RFC 6376 section
+// 3.4.4.
+func relaxedBody(body []byte) []byte {
+	lines := strings.Split(string(body), "\r\n")
+	for i, line := range lines {
+		line = strings.TrimRightFunc(line, isWSP)
+		var o strings.Builder
+		space := false
+		for _, r := range line {
+			if isWSP(r) {
+				space = true
+				continue
+			}
+			if space {
+				o.WriteByte(' ')
+				space = false
+			}
+			o.WriteRune(r)
+		}
+		lines[i] = o.String()
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	return []byte(strings.Join(lines, "\r\n") + "\r\n")
+}
+
+func isWSP(r rune) bool {
+	return r == ' ' || r == '\t'
+}
@@ -0,0 +1,269 @@
+package config
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+type captureClient struct {
+	msg []byte
+}
+
+func (c *captureClient) Send(ctx context.Context, from string, to []string, msg []byte) error {
+	c.msg = msg
+	return nil
+}
+
+func (c *captureClient) Host() string {
+	return "localhost"
+}
+
+func (c *captureClient) Valid() *Validation {
+	return &Validation{Namespace: "capture"}
+}
+
+// verifyDKIM checks the DKIM-Signature header of msg with pub and returns the
+// signature tags.
+func verifyDKIM(t *testing.T, msg []byte, pub crypto.PublicKey) map[string]string {
+	t.Helper()
+	i := strings.Index(string(msg), "\r\n\r\n")
+	if i < 0 {
+		t.Fatal("message has no body")
+	}
+	fields := headerFields(msg[:i+2])
+	if !strings.HasPrefix(fields[0], "DKIM-Signature:") {
+		t.Fatalf("expected DKIM-Signature first got %q", fields[0])
+	}
+	tags := make(map[string]string)
+	value := strings.TrimSpace(strings.TrimPrefix(fields[0], "DKIM-Signature:"))
+	for _, tag := range strings.Split(value, ";") {
+		kv := strings.SplitN(strings.TrimSpace(tag), "=", 2)
+		tags[kv[0]] = kv[1]
+	}
+	bh := sha256.Sum256(relaxedBody(msg[i+4:]))
+	if base64.StdEncoding.EncodeToString(bh[:]) != tags["bh"] {
+		t.Errorf("body hash mismatch")
+	}
+	var signed strings.Builder
+	used := make(map[int]bool)
+	for _, name := range strings.Split(tags["h"], ":") {
+		for j := len(fields) - 1; j > 0; j-- {
+			if !used[j] && strings.EqualFold(fieldName(fields[j]), name) {
+				used[j] = true
+				signed.WriteString(relaxedHeader(fields[j]))
+				break
+			}
+		}
+	}
+	unsigned := strings.TrimSuffix(fields[0], "\r\n")
+	unsigned = unsigned[:strings.LastIndex(unsigned, "b=")+2]
+	signed.WriteString(strings.TrimSuffix(relaxedHeader(unsigned), "\r\n"))
+	digest := sha256.Sum256([]byte(signed.String()))
+	sig, err := base64.StdEncoding.DecodeString(tags["b"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], sig); err != nil {
+			t.Errorf("invalid rsa signature: %v", err)
+		}
+	case ed25519.PublicKey:
+		if !ed25519.Verify(k, digest[:], sig) {
+			t.Error("invalid ed25519 signature")
+		}
+	}
+	return tags
+}
+
+func TestRelaxedCanonicalization(t *testing.T) {
+	// RFC 6376 section 3.4.5
+	var h string
+	for _, f := range headerFields([]byte("A: X\r\nB : Y\t\r\n\tZ  \r\n")) {
+		h += relaxedHeader(f)
+	}
+	if h != "a:X\r\nb:Y Z\r\n" {
+		t.Errorf("unexpected header canonicalization %q", h)
+	}
+	b := relaxedBody([]byte(" C \r\nD \t E\r\n\r\n\r\n"))
+	if string(b) != " C\r\nD E\r\n" {
+		t.Errorf("unexpected body canonicalization %q", b)
+	}
+	if b := relaxedBody([]byte("\r\n\r\n")); len(b) != 0 {
+		t.Errorf("expected empty body got %q", b)
+	}
+}
+
+func TestDKIMSigner(t *testing.T) {
+	t.Run("ed25519", func(ts *testing.T) {
+		// RFC 8463 appendix A
+		seed, _ := base64.StdEncoding.DecodeString("nWGxne/9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A=")
+		key := ed25519.NewKeyFromSeed(seed)
+		s, err := NewDKIMSigner("football.example.com", "brisbane", key, nil)
+		if err != nil {
+			ts.Fatal(err)
+		}
+		s.now = func() time.Time { return time.Unix(1528637909, 0) }
+		msg := "From: Joe SixPack <joe@football.example.com>\n" +
+			"To: Suzie Q <suzie@shopping.example.net>\n" +
+			"Subject: Is dinner ready?\n" +
+			"Date: Fri, 11 Jul 2003 21:00:37 -0700 (PDT)\n" +
+			"Message-ID: <20030712040037.46341.5F8J@football.example.com>\n" +
+			"\n" +
+			"Hi.\n" +
+			"\n" +
+			"We lost the game.  Are you hungry yet?\n" +
+			"\n" +
+			"Joe.\n"
+		signed, err := s.Sign([]byte(msg))
+		if err != nil {
+			ts.Fatal(err)
+		}
+		tags := verifyDKIM(ts, signed, key.Public())
+		expect := map[string]string{
+			"a":  "ed25519-sha256",
+			"c":  "relaxed/relaxed",
+			"d":  "football.example.com",
+			"s":  "brisbane",
+			"t":  "1528637909",
+			"h":  "from:to:subject:date:message-id",
+			"bh": "2jUSOH9NhtVGCQWNr9BrIAPreKQjO6Sn7XIkfJVOzv8=",
+		}
+		for k, v := range expect {
+			if tags[k] != v {
+				ts.Errorf("%s: expected %q got %q", k, v, tags[k])
+			}
+		}
+	})
+	t.Run("rsa", func(ts *testing.T) {
+		dir, err := ioutil.TempDir("", "dkim")
+		if err != nil {
+			ts.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		key, err := rsa.GenerateKey(rand.Reader, 1024)
+		if err != nil {
+			ts.Fatal(err)
+		}
+		keyFile := filepath.Join(dir, "dkim.pem")
+		b := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+		if err := ioutil.WriteFile(keyFile, b, 0600); err != nil {
+			ts.Fatal(err)
+		}
+		m := &Matrix{}
+		if err := m.LoadTemplates(); err != nil {
+			ts.Fatal(err)
+		}
+		e := Email{
+			Invite: Invite{From: "sydent@example.org"},
+			DKIM: DKIM{
+				Domain:   "example.org",
+				Selector: "mail",
+				KeyFile:  keyFile,
+			},
+		}
+		if v := e.Valid(m.GetTemplate()); !v.IsValid() {
+			ts.Fatal(v)
+		}
+		c := &captureClient{}
+		k, err := e.Mail(c, m.GetTemplate())
+		if err != nil {
+			ts.Fatal(err)
+		}
+		err = k.SendMail(context.Background(), "verification", "sydent@example.org", []string{"alice@example.com"}, map[string]string{
+			"token": "123456",
+			"link":  "https://example.org/verify",
+		})
+		if err != nil {
+			ts.Fatal(err)
+		}
+		tags := verifyDKIM(ts, c.msg, &key.PublicKey)
+		if tags["a"] != "rsa-sha256" || tags["h"] != "from:to:subject:date:message-id:mime-version:content-type" {
+			ts.Errorf("unexpected tags %v", tags)
+		}
+	})
+	t.Run("no from", func(ts *testing.T) {
+		_, key, _ := ed25519.GenerateKey(rand.Reader)
+		s, err := NewDKIMSigner("example.org", "mail", key, nil)
+		if err != nil {
+			ts.Fatal(err)
+		}
+		if _, err := s.Sign([]byte("To: alice@example.com\n\nhello\n")); err == nil {
+			ts.Error("expected messages without From to be refused")
+		}
+	})
+}
+
+func TestDKIMValid(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dkim")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	seedFile := filepath.Join(dir, "seed")
+	seed := base64.StdEncoding.EncodeToString(make([]byte, ed25519.SeedSize))
+	if err := ioutil.WriteFile(seedFile, []byte(seed+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	small, err := rsa.GenerateKey(rand.Reader, 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	smallFile := filepath.Join(dir, "small.pem")
+	b := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(small)})
+	if err := ioutil.WriteFile(smallFile, b, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if v := (DKIM{}).Valid(); !v.IsValid() {
+		t.Errorf("expected disabled dkim to be valid got %s", v)
+	}
+	if v := (DKIM{Domain: "example.org", Selector: "mail", KeyFile: seedFile}).Valid(); !v.IsValid() {
+		t.Errorf("expected ed25519 seed to be valid got %s", v)
+	}
+	for _, d := range []DKIM{
+		{Domain: "example.org", KeyFile: seedFile},
+		{Selector: "mail", KeyFile: seedFile},
+		{Domain: "example.org", Selector: "mail"},
+		{Domain: "example.org", Selector: "mail", KeyFile: filepath.Join(dir, "missing")},
+		{Domain: "example.org", Selector: "mail", KeyFile: smallFile},
+		{Domain: "example.org", Selector: "mail", KeyFile: seedFile, Headers: []string{"Subject"}},
+	} {
+		if d.Valid().IsValid() {
+			t.Errorf("expected %+v to be invalid", d)
+		}
+	}
+	m := &Matrix{}
+	if err := m.LoadTemplates(); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"sendgrid", "http"} {
+		e := Email{
+			Providers: []Provider{{Name: name, State: "enabled", Settings: map[string]interface{}{
+				"url":     "https://api.example.org/send",
+				"api_key": "secret",
+			}}},
+			Invite: Invite{From: "sydent@example.org"},
+		}
+		if v := e.Valid(m.GetTemplate()); !v.IsValid() {
+			t.Errorf("%s: expected to be valid got %s", name, v)
+		}
+		e.DKIM = DKIM{Domain: "example.org", Selector: "mail", KeyFile: seedFile}
+		if v := e.Valid(m.GetTemplate()); v.IsValid() || !strings.Contains(v.String(), "DKIM-Signature") {
+			t.Errorf("%s: expected dkim to be refused got %s", name, v)
+		}
+	}
+}
@@ -8,17 +8,36 @@ import (
 
 func LoadFromEnv() *Matrix {
 	return &Matrix{
-		Mode: os.Getenv("MX_MODE"),
+		Mode:   os.Getenv("MX_MODE"),
+		Locale: env("MX_LOCALE"),
 		Server: Server{
-			Name:           env("MX_SERVER_NAME"),
-			Port:           env("MX_SERVER_PORT"),
-			ClientHTTPBase: env("MX_CLIENT_HTTP_BASE"),
+			Name:                 env("MX_SERVER_NAME"),
+			Port:                 env("MX_SERVER_PORT"),
+			ClientHTTPBase:       env("MX_CLIENT_HTTP_BASE"),
+			LookupPepperRotation: env("MX_SERVER_LOOKUP_PEPPER_ROTATION"),
+			TrustedProxies:       envList("MX_SERVER_TRUSTED_PROXIES"),
 			Crypto: Crypto{
 				Algorithm:  env("MX_SERVER_CRYPTO_ALG"),
 				Version:    env("MX_SERVER_CRYPTO_VER"),
 				SingingKey: env("MX_SERVER_CRYPTO_SIGN_KEY"),
 				VerifyKey:  env("MX_SERVER_CRYPTO_VERIFY_KEY"),
 			},
+			CertFile: env("MX_SERVER_CERT_FILE"),
+			KeyFile:  env("MX_SERVER_KEY_FILE"),
+			HTTPPort: env("MX_SERVER_HTTP_PORT"),
+			ACME: ACME{
+				Domains:      envList("MX_SERVER_ACME_DOMAINS"),
+				Email:        env("MX_SERVER_ACME_EMAIL"),
+				CacheDir:     env("MX_SERVER_ACME_CACHE_DIR"),
+				DirectoryURL: env("MX_SERVER_ACME_DIRECTORY_URL"),
+				CAFile:       env("MX_SERVER_ACME_CA_FILE"),
+			},
+			Replication: Replication{
+				Port:     env("MX_SERVER_REPLICATION_PORT"),
+				CertFile: env("MX_SERVER_REPLICATION_CERT_FILE"),
+				KeyFile:  env("MX_SERVER_REPLICATION_KEY_FILE"),
+				CAFile:   env("MX_SERVER_REPLICATION_CA_FILE"),
+			},
 		},
 		DB: DB{
 			Driver: env("MX_DB_DRIVER"),
@@ -35,6 +54,61 @@ func LoadFromEnv() *Matrix {
 				ResponsePage: env("MX_EMAIL_VERIFY_RESPONSE_TEMPLATE"),
 			},
 			Providers: providers(os.Environ()),
+			Outbox: Outbox{
+				Workers:           env("MX_EMAIL_OUTBOX_WORKERS"),
+				DomainConcurrency: env("MX_EMAIL_OUTBOX_DOMAIN_CONCURRENCY"),
+				MaxAttempts:       env("MX_EMAIL_OUTBOX_MAX_ATTEMPTS"),
+			},
+			DKIM: DKIM{
+				Domain:   env("MX_EMAIL_DKIM_DOMAIN"),
+				Selector: env("MX_EMAIL_DKIM_SELECTOR"),
+				KeyFile:  env("MX_EMAIL_DKIM_KEY_FILE"),
+				Headers:  envList("MX_EMAIL_DKIM_HEADERS"),
+			},
+		},
+		Admin: Admin{
+			Token: env("MX_ADMIN_TOKEN"),
+		},
+		Janitor: Janitor{
+			Interval:      env("MX_JANITOR_INTERVAL"),
+			Batch:         env("MX_JANITOR_BATCH"),
+			Sessions:      env("MX_JANITOR_SESSIONS"),
+			InviteTokens:  env("MX_JANITOR_INVITE_TOKENS"),
+			EphemeralKeys: env("MX_JANITOR_EPHEMERAL_KEYS"),
+		},
+		RateLimit: RateLimit{
+			Backend: env("MX_RATE_LIMIT_BACKEND"),
+			IP: Limit{
+				Burst:  env("MX_RATE_LIMIT_IP_BURST"),
+				Period: env("MX_RATE_LIMIT_IP_PERIOD"),
+			},
+			Address: Limit{
+				Burst:  env("MX_RATE_LIMIT_ADDRESS_BURST"),
+				Period: env("MX_RATE_LIMIT_ADDRESS_PERIOD"),
+			},
+			Domain: Limit{
+				Burst:  env("MX_RATE_LIMIT_DOMAIN_BURST"),
+				Period: env("MX_RATE_LIMIT_DOMAIN_PERIOD"),
+			},
+		},
+		Captcha: Captcha{
+			Verifier:   env("MX_CAPTCHA_VERIFIER"),
+			Threshold:  env("MX_CAPTCHA_THRESHOLD"),
+			Window:     env("MX_CAPTCHA_WINDOW"),
+			Difficulty: env("MX_CAPTCHA_DIFFICULTY"),
+			Secret:     env("MX_CAPTCHA_SECRET"),
+			URL:        env("MX_CAPTCHA_URL"),
+			SiteKey:    env("MX_CAPTCHA_SITE_KEY"),
+		},
+		Canonical: Canonical{
+			EmailProviders: envList("MX_CANONICAL_EMAIL_PROVIDERS"),
+		},
+		Msisdn: Msisdn{
+			Verification: MsisdnVerification{
+				Originator: env("MX_MSISDN_VERIFY_ORIGINATOR"),
+				Template:   env("MX_MSISDN_VERIFY_TEMPLATE"),
+			},
+			Providers: providersWithPrefix("MX_MSISDN_PROVIDER_", os.Environ()),
 		},
 	}
 }
@@ -43,19 +117,36 @@ func env(name string) string {
 	return os.Getenv(name)
 }
 
+// envList returns the comma separated values of the environment variable name.
+func envList(name string) []string {
+	var o []string
+	for _, v := range strings.Split(env(name), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			o = append(o, v)
+		}
+	}
+	return o
+}
+
 type eVar struct {
 	k []string
 	v string
 }
 
 func providers(environ []string) []Provider {
+	return providersWithPrefix("MX_EMAIL_PROVIDER_", environ)
+}
+
+// providersWithPrefix reads provider settings from environment variables
+// starting with prefix.
+func providersWithPrefix(prefix string, environ []string) []Provider {
 	var values []eVar
 	for _, v := range environ {
 		p := strings.Split(v, "=")
 		if len(p) == 2 {
-			if strings.HasPrefix(p[0], "MX_EMAIL_PROVIDER_") {
+			if strings.HasPrefix(p[0], prefix) {
 				values = append(values, eVar{
-					k: strings.Split(strings.TrimPrefix(p[0], "MX_EMAIL_PROVIDER_"), "_"),
+					k: strings.Split(strings.TrimPrefix(p[0], prefix), "_"),
 					v: p[1],
 				})
 			}
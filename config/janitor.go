@@ -0,0 +1,121 @@
+package config
+
+import (
+	"strconv"
+	"time"
+)
+
+// Janitor defaults, see Janitor.
+const (
+	DefaultJanitorInterval       = time.Hour
+	DefaultJanitorBatch          = 500
+	DefaultInviteTokenRetention  = 365 * 24 * time.Hour
+	DefaultEphemeralKeyRetention = 365 * 24 * time.Hour
+)
+
+// Janitor configures the background job which deletes expired validation
+// sessions, invite tokens and ephemeral public keys.
+//
+// Retention periods are durations like 720h. Sessions are kept at least for
+// ThreepidSessionValidationLifetime, invite tokens are counted from when they
+// were received and ephemeral keys from when they were stored. A retention of
+// 0 keeps the rows of that table forever.
+type Janitor struct {
+	// Interval is how often the janitor runs, defaults to 1h.
+	Interval string `hcl:"interval"`
+
+	// Batch is the maximum number of rows deleted by a single statement,
+	// defaults to 500.
+	Batch string `hcl:"batch"`
+
+	Sessions      string `hcl:"sessions"`
+	InviteTokens  string `hcl:"invite_tokens"`
+	EphemeralKeys string `hcl:"ephemeral_keys"`
+}
+
+// SessionLifetime is ThreepidSessionValidationLifetime as a time.Duration.
+const SessionLifetime = ThreepidSessionValidationLifetime * time.Millisecond
+
+// Valid validates j settings.
+func (j Janitor) Valid() *Validation {
+	v := &Validation{Namespace: "janitor"}
+	if j.Interval != "" {
+		d, err := time.ParseDuration(j.Interval)
+		if err != nil {
+			v.Set("interval", err.Error())
+		} else if d <= 0 {
+			v.Set("interval", "must be positive")
+		}
+	}
+	if j.Batch != "" {
+		n, err := strconv.Atoi(j.Batch)
+		if err != nil {
+			v.Set("batch", err.Error())
+		} else if n <= 0 {
+			v.Set("batch", "must be positive")
+		}
+	}
+	retention := []struct {
+		name, value string
+		min         time.Duration
+	}{
+		{"sessions", j.Sessions, SessionLifetime},
+		{"invite_tokens", j.InviteTokens, 0},
+		{"ephemeral_keys", j.EphemeralKeys, 0},
+	}
+	for _, r := range retention {
+		if r.value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(r.value)
+		switch {
+		case err != nil:
+			v.Set(r.name, err.Error())
+		case d < 0:
+			v.Set(r.name, "must not be negative")
+		case d != 0 && d < r.min:
+			v.Set(r.name, "must be at least "+r.min.String())
+		}
+	}
+	return v
+}
+
+// Every returns how often the janitor runs.
+func (j Janitor) Every() time.Duration {
+	return duration(j.Interval, DefaultJanitorInterval)
+}
+
+// Limit returns the maximum number of rows deleted by a single statement.
+func (j Janitor) Limit() int {
+	return positive(j.Batch, DefaultJanitorBatch)
+}
+
+// SessionRetention returns how long validation sessions are kept after they
+// were last modified, zero means forever.
+func (j Janitor) SessionRetention() time.Duration {
+	return duration(j.Sessions, SessionLifetime)
+}
+
+// InviteTokenRetention returns how long invite tokens are kept, zero means
+// forever.
+func (j Janitor) InviteTokenRetention() time.Duration {
+	return duration(j.InviteTokens, DefaultInviteTokenRetention)
+}
+
+// EphemeralKeyRetention returns how long ephemeral public keys are kept, zero
+// means forever.
+func (j Janitor) EphemeralKeyRetention() time.Duration {
+	return duration(j.EphemeralKeys, DefaultEphemeralKeyRetention)
+}
+
+// duration parses s, def is returned when s is empty or invalid.
+func duration(s string, def time.Duration) time.Duration {
+	if s == "" {
+		return def
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return def
+	}
+	return d
+}
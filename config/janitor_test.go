@@ -0,0 +1,28 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestJanitor(t *testing.T) {
+	var j Janitor
+	if v := j.Valid(); !v.IsValid() {
+		t.Fatal(v)
+	}
+	if j.Every() != DefaultJanitorInterval || j.Limit() != DefaultJanitorBatch {
+		t.Errorf("expected defaults got %v and %d", j.Every(), j.Limit())
+	}
+	if j.SessionRetention() != SessionLifetime {
+		t.Errorf("expected sessions to be kept for %v got %v", SessionLifetime, j.SessionRetention())
+	}
+	j = Janitor{InviteTokens: "0", EphemeralKeys: "48h"}
+	if j.InviteTokenRetention() != 0 || j.EphemeralKeyRetention() != 48*time.Hour {
+		t.Errorf("unexpected retention %v and %v", j.InviteTokenRetention(), j.EphemeralKeyRetention())
+	}
+	j = Janitor{Interval: "-1h", Batch: "none", Sessions: "1h", InviteTokens: "-1h"}
+	v := j.Valid()
+	if len(v.Fields) != 4 {
+		t.Errorf("expected 4 invalid fields got %v", v)
+	}
+}
@@ -0,0 +1,171 @@
+package config
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"text/template/parse"
+)
+
+// DefaultLocale is the locale of the embedded templates.
+const DefaultLocale = "en"
+
+var localeRegex = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{1,8})*$`)
+
+// NormalizeLocale returns locale as a lower case language tag separated by -,
+// like pt-br for pt_BR.
+func NormalizeLocale(locale string) string {
+	return strings.ToLower(strings.Replace(strings.TrimSpace(locale), "_", "-", -1))
+}
+
+// splitTemplateName returns the template and locale of the name of a localized
+// template like verification.fr, locale is empty for templates in the default
+// locale.
+func splitTemplateName(name string) (string, string) {
+	if i := strings.LastIndexByte(name, '.'); i > 0 {
+		return name[:i], NormalizeLocale(name[i+1:])
+	}
+	return name, ""
+}
+
+// LocalizedTemplate returns the name and the locale of the variant of the
+// template name for the first of locales which has one, falling back to the
+// configured locale and then to the template in DefaultLocale.
+//
+// Localized templates are named after the template and the locale, like
+// verification.fr or invite.pt-br. A locale like pt-br falls back to pt.
+func (m *Matrix) LocalizedTemplate(name string, locales ...string) (string, string) {
+	candidates := append(append([]string(nil), locales...), m.Locale)
+	for _, l := range candidates {
+		for l = NormalizeLocale(l); l != ""; {
+			if m.tpl.Has(name + "." + l) {
+				return name + "." + l, l
+			}
+			if l == DefaultLocale {
+				return name, DefaultLocale
+			}
+			i := strings.LastIndexByte(l, '-')
+			if i < 0 {
+				break
+			}
+			l = l[:i]
+		}
+	}
+	return name, DefaultLocale
+}
+
+// TemplateProblem is a localized template which is missing, or which does not
+// use all the keys of the template in the default locale.
+type TemplateProblem struct {
+	Template string
+	Locale   string
+
+	// Missing is true when there is no variant of Template for Locale.
+	Missing bool
+
+	// Keys are the keys used by Template which are not used by its variant.
+	Keys []string
+}
+
+// CheckTemplates returns the problems of the localized templates. Every locale
+// is expected to have a variant of each template which is localized in any
+// locale.
+func (m *Matrix) CheckTemplates() []TemplateProblem {
+	if m.tpl == nil {
+		return nil
+	}
+	names := make(map[string]bool)
+	locales := make(map[string]bool)
+	for _, t := range m.tpl.Names() {
+		name, locale := splitTemplateName(t)
+		if locale == "" {
+			continue
+		}
+		names[name] = true
+		locales[locale] = true
+	}
+	var o []TemplateProblem
+	for name := range names {
+		for locale := range locales {
+			p := TemplateProblem{Template: name, Locale: locale}
+			if !m.tpl.Has(name + "." + locale) {
+				p.Missing = true
+				o = append(o, p)
+				continue
+			}
+			if !m.tpl.Has(name) {
+				continue
+			}
+			used := m.tpl.keys(name + "." + locale)
+			for _, k := range sortedKeys(m.tpl.keys(name)) {
+				if !used[k] {
+					p.Keys = append(p.Keys, k)
+				}
+			}
+			if len(p.Keys) > 0 {
+				o = append(o, p)
+			}
+		}
+	}
+	sort.Slice(o, func(i, j int) bool {
+		if o[i].Template != o[j].Template {
+			return o[i].Template < o[j].Template
+		}
+		return o[i].Locale < o[j].Locale
+	})
+	return o
+}
+
+func sortedKeys(m map[string]bool) []string {
+	var o []string
+	for k := range m {
+		o = append(o, k)
+	}
+	sort.Strings(o)
+	return o
+}
+
+// walkTemplate adds the keys of the data used by node to keys, like token for
+// {{.token}}.
+func walkTemplate(node parse.Node, keys map[string]bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Nodes {
+			walkTemplate(c, keys)
+		}
+	case *parse.ActionNode:
+		walkTemplate(n.Pipe, keys)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Cmds {
+			walkTemplate(c, keys)
+		}
+	case *parse.CommandNode:
+		for _, c := range n.Args {
+			walkTemplate(c, keys)
+		}
+	case *parse.FieldNode:
+		keys[n.Ident[0]] = true
+	case *parse.ChainNode:
+		walkTemplate(n.Node, keys)
+	case *parse.IfNode:
+		walkBranch(&n.BranchNode, keys)
+	case *parse.RangeNode:
+		walkBranch(&n.BranchNode, keys)
+	case *parse.WithNode:
+		walkBranch(&n.BranchNode, keys)
+	case *parse.TemplateNode:
+		walkTemplate(n.Pipe, keys)
+	}
+}
+
+func walkBranch(n *parse.BranchNode, keys map[string]bool) {
+	walkTemplate(n.Pipe, keys)
+	walkTemplate(n.List, keys)
+	walkTemplate(n.ElseList, keys)
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func localizedMatrix(t *testing.T, dir, locale string, files map[string]string) *Matrix {
+	t.Helper()
+	m := &Matrix{Locale: locale}
+	for name, body := range files {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(body), 0600); err != nil {
+			t.Fatal(err)
+		}
+		m.Templates = append(m.Templates, Template{Name: name, Path: path})
+	}
+	if err := m.LoadTemplates(); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestLocalizedTemplate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "locale")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	m := localizedMatrix(t, dir, "", map[string]string{
+		"verification.fr": "{{.token}} {{.link}}",
+		"verification.pt": "{{.token}} {{.link}}",
+	})
+	sample := []struct {
+		locales []string
+		name    string
+		locale  string
+	}{
+		{nil, "verification", "en"},
+		{[]string{"fr"}, "verification.fr", "fr"},
+		{[]string{"FR_ca"}, "verification.fr", "fr"},
+		{[]string{"pt-BR"}, "verification.pt", "pt"},
+		{[]string{"de", "fr"}, "verification.fr", "fr"},
+		{[]string{"en-US", "fr"}, "verification", "en"},
+		{[]string{"de"}, "verification", "en"},
+	}
+	for _, v := range sample {
+		name, locale := m.LocalizedTemplate("verification", v.locales...)
+		if name != v.name || locale != v.locale {
+			t.Errorf("%v: expected %s %s got %s %s", v.locales, v.name, v.locale, name, locale)
+		}
+	}
+	m.Locale = "fr"
+	if name, _ := m.LocalizedTemplate("verification", "de"); name != "verification.fr" {
+		t.Errorf("expected configured locale got %s", name)
+	}
+	if name, _ := m.LocalizedTemplate("invite", "fr"); name != "invite" {
+		t.Errorf("expected fallback to the default template got %s", name)
+	}
+}
+
+func TestCheckTemplates(t *testing.T) {
+	dir, err := ioutil.TempDir("", "locale")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	m := localizedMatrix(t, dir, "", map[string]string{
+		"verification.fr": "{{.token}}",
+		"verification.de": "{{if .token}}{{.token}}{{end}} {{.link}}",
+		"invite.de":       "{{.room_name}}",
+	})
+	problems := make(map[string]TemplateProblem)
+	for _, p := range m.CheckTemplates() {
+		problems[p.Template+"."+p.Locale] = p
+	}
+	if len(problems) != 3 {
+		t.Errorf("expected 3 problems got %+v", problems)
+	}
+	if p := problems["invite.fr"]; !p.Missing {
+		t.Errorf("expected invite.fr to be missing got %+v", p)
+	}
+	if p := problems["invite.de"]; p.Missing || !contains(p.Keys, "token") {
+		t.Errorf("expected invite.de to miss token got %+v", p)
+	}
+	if p := problems["verification.fr"]; !contains(p.Keys, "link") || contains(p.Keys, "token") {
+		t.Errorf("expected verification.fr to miss link got %+v", p)
+	}
+	if p, ok := problems["verification.de"]; ok {
+		t.Errorf("expected verification.de to use all keys got %+v", p)
+	}
+	if p := localizedMatrix(t, dir, "", nil).CheckTemplates(); len(p) != 0 {
+		t.Errorf("expected no problems without localized templates got %+v", p)
+	}
+}
@@ -6,7 +6,8 @@ import (
 	"fmt"
 	"html"
 	"io/ioutil"
-	"text/template"
+	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/gernest/sydent-go/embed"
@@ -39,9 +40,10 @@ const dateFormatGMT = "Mon, 02 Jan 2006 15:04:05 MST"
 
 // Kmail provide methods for sending emails.
 type Kmail struct {
-	tpl    *template.Template
+	tpl    *Templates
 	emebd  embed.Embed
 	client Client
+	dkim   *DKIMSigner
 }
 
 func readFile(vfs embed.Embed, tpl string) ([]byte, error) {
@@ -53,35 +55,80 @@ func readFile(vfs embed.Embed, tpl string) ([]byte, error) {
 	return ioutil.ReadAll(f)
 }
 
-func New(client Client, tpl *template.Template) (*Kmail, error) {
+func New(client Client, tpl *Templates) (*Kmail, error) {
 	return &Kmail{tpl: tpl, client: client}, nil
 }
 
+// Sign makes k sign emails with s before sending them, s can be nil to disable
+// signing.
+func (k *Kmail) Sign(s *DKIMSigner) *Kmail {
+	k.dkim = s
+	return k
+}
+
 // SendMail uses tmpl template to send and email, data is a context object which
 // is passed to the cached template and rendered to generate the email message.
 func (k *Kmail) SendMail(ctx context.Context, tmpl, from string, to []string, data map[string]string) error {
 	if ctx.Err() != nil {
 		return nil
 	}
+	msg, err := k.Compose(tmpl, from, to, data)
+	if err != nil {
+		return err
+	}
+	if k.dkim != nil {
+		msg, err = k.dkim.Sign(msg)
+		if err != nil {
+			return err
+		}
+	}
+	return k.client.Send(ctx, from, to, msg)
+}
+
+// Compose returns the email SendMail would send, without signing it. The date,
+// messageid, from and to keys of data are set. k can be created with a nil
+// client to only render emails.
+func (k *Kmail) Compose(tmpl, from string, to []string, data map[string]string) ([]byte, error) {
 	if data == nil {
 		data = make(map[string]string)
 	}
 	midRand := models.RandomString(16)
 	now := time.Now()
-	data["messageid"] = fmt.Sprintf("<%d%s%s>", models.MS(&now), midRand, k.client.Host())
+	host := hostname()
+	if k.client != nil {
+		host = k.client.Host()
+	}
+	data["messageid"] = fmt.Sprintf("<%d%s%s>", models.MS(&now), midRand, host)
 	data["date"] = FormatDate(now, false, false)
 	data["from"] = from
 	data["to"] = to[0]
+	return k.render(tmpl, data)
+}
+
+// render returns the email rendered from the template tmpl with data.
+//
+// Raw MIME templates get the _forhtml and _forurl variants of every key, with
+// the value escaped for html and for url query parameters.
+func (k *Kmail) render(tmpl string, data map[string]string) ([]byte, error) {
+	if msg := k.tpl.Message(tmpl); msg != nil {
+		return msg.Render(Header{
+			From:      data["from"],
+			To:        data["to"],
+			Date:      data["date"],
+			MessageID: data["messageid"],
+		}, data)
+	}
+	escaped := make(map[string]string, len(data)*3)
 	for k, v := range data {
-		data[k+"_forhtml"] = html.EscapeString(v)
-		data[k+"_forurl"] = fmt.Sprintf("%q", v)
+		escaped[k] = v
+		escaped[k+"_forhtml"] = html.EscapeString(v)
+		escaped[k+"_forurl"] = url.QueryEscape(v)
 	}
 	var buf bytes.Buffer
-	err := k.tpl.ExecuteTemplate(&buf, tmpl, data)
-	if err != nil {
-		return err
+	if err := k.tpl.ExecuteTemplate(&buf, tmpl, escaped); err != nil {
+		return nil, err
 	}
-	return k.client.Send(from, to, buf.Bytes())
+	return buf.Bytes(), nil
 }
 
 func (k *Kmail) Valid() *Validation {
@@ -99,3 +146,74 @@ func FormatDate(ts time.Time, local, useGMT bool) string {
 	}
 	return ts.Format(s)
 }
+
+// Outbox defaults, see Outbox.
+const (
+	DefaultOutboxWorkers           = 4
+	DefaultOutboxDomainConcurrency = 2
+	DefaultOutboxMaxAttempts       = 10
+)
+
+// Outbox configures the workers sending queued emails. Emails are queued in the
+// database and sent in the background, failed deliveries are retried with
+// exponential backoff until MaxAttempts, then the email is kept as a dead
+// letter until it is requeued.
+type Outbox struct {
+	// Workers is the number of emails sent at the same time, defaults to 4.
+	Workers string `hcl:"workers"`
+
+	// DomainConcurrency is the number of emails sent at the same time to a
+	// single recipient domain, defaults to 2.
+	DomainConcurrency string `hcl:"domain_concurrency"`
+
+	// MaxAttempts is the number of delivery attempts before giving up,
+	// defaults to 10.
+	MaxAttempts string `hcl:"max_attempts"`
+}
+
+// Valid validates o settings.
+func (o Outbox) Valid() *Validation {
+	v := &Validation{Namespace: "outbox"}
+	for _, f := range []struct{ name, value string }{
+		{"workers", o.Workers},
+		{"domain_concurrency", o.DomainConcurrency},
+		{"max_attempts", o.MaxAttempts},
+	} {
+		if f.value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(f.value)
+		if err != nil {
+			v.Set(f.name, err.Error())
+		} else if n <= 0 {
+			v.Set(f.name, "must be positive")
+		}
+	}
+	return v
+}
+
+// WorkerCount returns the number of emails sent at the same time.
+func (o Outbox) WorkerCount() int {
+	return positive(o.Workers, DefaultOutboxWorkers)
+}
+
+// PerDomain returns the number of emails sent at the same time to a single
+// domain.
+func (o Outbox) PerDomain() int {
+	return positive(o.DomainConcurrency, DefaultOutboxDomainConcurrency)
+}
+
+// Attempts returns the number of delivery attempts before an email is moved to
+// the dead letters.
+func (o Outbox) Attempts() int64 {
+	return int64(positive(o.MaxAttempts, DefaultOutboxMaxAttempts))
+}
+
+// positive parses s, def is returned when s is empty or not a positive number.
+func positive(s string, def int) int {
+	n, err := strconv.Atoi(s)
+	if err != nil || n <= 0 {
+		return def
+	}
+	return n
+}
@@ -0,0 +1,368 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+var _ Client = (*HTTPMailClient)(nil)
+var _ Client = (*SendmailClient)(nil)
+var _ Client = (*FileMailClient)(nil)
+var _ Client = (*MaildirClient)(nil)
+
+// DefaultSendGridURL is the endpoint used by the sendgrid provider when no url
+// is configured.
+const DefaultSendGridURL = "https://api.sendgrid.com/v3/mail/send"
+
+// DefaultSendmailPath is the sendmail binary used by the sendmail provider when
+// no path is configured.
+const DefaultSendmailPath = "/usr/sbin/sendmail"
+
+// sendmailTimeout is how long the sendmail binary is allowed to run.
+const sendmailTimeout = time.Minute
+
+// HTTPMailClient sends emails by posting them as json object to a http mail
+// api, the payload is the one of the SendGrid v3 mail send api.
+//
+// The rendered message is parsed to extract the subject and the text/plain and
+// text/html parts. Any 2xx response is considered a successful delivery.
+type HTTPMailClient struct {
+	URL    string
+	APIKey string
+	Client HTTPClient
+}
+
+// NewHTTPMailClient returns HTTPMailClient which gives up on requests after 30
+// seconds.
+func NewHTTPMailClient(endpoint, apiKey string) *HTTPMailClient {
+	return &HTTPMailClient{
+		URL:    endpoint,
+		APIKey: apiKey,
+		Client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+type httpMail struct {
+	Personalizations []httpMailPersonalization `json:"personalizations"`
+	From             httpMailAddress           `json:"from"`
+	Subject          string                    `json:"subject"`
+	Content          []httpMailContent         `json:"content"`
+}
+
+type httpMailPersonalization struct {
+	To []httpMailAddress `json:"to"`
+}
+
+type httpMailAddress struct {
+	Email string `json:"email"`
+}
+
+type httpMailContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+func (h *HTTPMailClient) Send(ctx context.Context, from string, to []string, msg []byte) error {
+	subject, content, err := parseMail(msg)
+	if err != nil {
+		return err
+	}
+	p := httpMail{
+		From:    httpMailAddress{Email: from},
+		Subject: subject,
+		Content: content,
+	}
+	var recipients []httpMailAddress
+	for _, v := range to {
+		recipients = append(recipients, httpMailAddress{Email: v})
+	}
+	p.Personalizations = []httpMailPersonalization{{To: recipients}}
+	b, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, h.URL, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", AgentName)
+	if h.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+h.APIKey)
+	}
+	res, err := h.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	io.Copy(ioutil.Discard, res.Body)
+	if res.StatusCode/100 != 2 {
+		return fmt.Errorf("mail: api responded with %s", res.Status)
+	}
+	return nil
+}
+
+func (h *HTTPMailClient) Host() string {
+	u, err := url.Parse(h.URL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+func (h *HTTPMailClient) Valid() *Validation {
+	v := &Validation{Namespace: "http_mail_provider"}
+	if h.URL == "" {
+		v.Set("url", missingField)
+	} else if u, err := url.Parse(h.URL); err != nil {
+		v.Set("url", err.Error())
+	} else if u.Scheme != "http" && u.Scheme != "https" {
+		v.Set("url", "must be a http or https url")
+	}
+	if h.APIKey == "" {
+		v.Set("api_key", missingField)
+	}
+	return v
+}
+
+// parseMail returns the decoded subject and the text/plain and text/html
+// parts of the rendered message msg.
+func parseMail(msg []byte) (string, []httpMailContent, error) {
+	m, err := mail.ReadMessage(bytes.NewReader(msg))
+	if err != nil {
+		return "", nil, err
+	}
+	dec := new(mime.WordDecoder)
+	subject, err := dec.DecodeHeader(m.Header.Get("Subject"))
+	if err != nil {
+		return "", nil, err
+	}
+	mediaType := "text/plain"
+	params := map[string]string{}
+	if ct := m.Header.Get("Content-Type"); ct != "" {
+		mediaType, params, err = mime.ParseMediaType(ct)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		b, err := ioutil.ReadAll(decodeMailBody(m.Body, m.Header.Get("Content-Transfer-Encoding")))
+		if err != nil {
+			return "", nil, err
+		}
+		return subject, []httpMailContent{{Type: mediaType, Value: string(b)}}, nil
+	}
+	var content []httpMailContent
+	r := multipart.NewReader(m.Body, params["boundary"])
+	for {
+		part, err := r.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		partType := "text/plain"
+		if ct := part.Header.Get("Content-Type"); ct != "" {
+			partType, _, err = mime.ParseMediaType(ct)
+			if err != nil {
+				return "", nil, err
+			}
+		}
+		if partType != "text/plain" && partType != "text/html" {
+			continue
+		}
+		b, err := ioutil.ReadAll(decodeMailBody(part, part.Header.Get("Content-Transfer-Encoding")))
+		if err != nil {
+			return "", nil, err
+		}
+		content = append(content, httpMailContent{Type: partType, Value: string(b)})
+	}
+	if len(content) == 0 {
+		return "", nil, fmt.Errorf("mail: no text/plain or text/html part in %s message", mediaType)
+	}
+	return subject, content, nil
+}
+
+// MailParts returns the decoded subject and the text/plain and text/html parts
+// of the email msg, a part is empty when msg does not have it.
+func MailParts(msg []byte) (subject, text, html string, err error) {
+	subject, content, err := parseMail(msg)
+	if err != nil {
+		return "", "", "", err
+	}
+	for _, c := range content {
+		switch c.Type {
+		case "text/plain":
+			text = c.Value
+		case "text/html":
+			html = c.Value
+		}
+	}
+	return subject, text, html, nil
+}
+
+func decodeMailBody(r io.Reader, encoding string) io.Reader {
+	switch strings.ToLower(encoding) {
+	case "quoted-printable":
+		return quotedprintable.NewReader(r)
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, r)
+	default:
+		return r
+	}
+}
+
+// SendmailClient sends emails by piping them to a local sendmail compatible
+// binary, like the ones shipped with postfix, exim or msmtp.
+type SendmailClient struct {
+	Path string
+}
+
+func (s *SendmailClient) Send(ctx context.Context, from string, to []string, msg []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, sendmailTimeout)
+	defer cancel()
+	args := append([]string{"-i", "-f", from, "--"}, to...)
+	cmd := exec.CommandContext(ctx, s.Path, args...)
+	cmd.Stdin = bytes.NewReader(msg)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("sendmail: %v: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return nil
+}
+
+func (s *SendmailClient) Host() string {
+	return hostname()
+}
+
+func (s *SendmailClient) Valid() *Validation {
+	v := &Validation{Namespace: "sendmail_provider"}
+	if s.Path == "" {
+		v.Set("path", missingField)
+		return v
+	}
+	st, err := os.Stat(s.Path)
+	if err != nil {
+		v.Set("path", err.Error())
+	} else if st.IsDir() || st.Mode()&0111 == 0 {
+		v.Set("path", "not an executable file")
+	}
+	return v
+}
+
+// FileMailClient appends emails to a file in mbox format instead of sending
+// them. This is meant for development and testing.
+//
+// Lines of the message starting with "From ", after any number of ">", are
+// quoted with one more ">" like mboxrd does, so readers don't take them for
+// the start of the next message.
+type FileMailClient struct {
+	Path string
+	mu   sync.Mutex
+}
+
+func (f *FileMailClient) Send(ctx context.Context, from string, to []string, msg []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	o, err := os.OpenFile(f.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(o, "From %s %s\n%s\n\n",
+		from, time.Now().UTC().Format(time.ANSIC), quoteFrom(bytes.TrimRight(msg, "\r\n")),
+	)
+	if err != nil {
+		o.Close()
+		return err
+	}
+	return o.Close()
+}
+
+// quoteFrom returns msg with the lines starting with "From ", after any number
+// of ">", prefixed with ">".
+func quoteFrom(msg []byte) []byte {
+	lines := bytes.Split(msg, []byte("\n"))
+	for i, line := range lines {
+		if bytes.HasPrefix(bytes.TrimLeft(line, ">"), []byte("From ")) {
+			lines[i] = append([]byte(">"), line...)
+		}
+	}
+	return bytes.Join(lines, []byte("\n"))
+}
+
+func (f *FileMailClient) Host() string {
+	return hostname()
+}
+
+func (f *FileMailClient) Valid() *Validation {
+	v := &Validation{Namespace: "file_mail_provider"}
+	if f.Path == "" {
+		v.Set("path", missingField)
+	}
+	return v
+}
+
+// MaildirClient delivers emails to a maildir instead of sending them, so they
+// can be read with any mail client. This is meant for development and testing.
+type MaildirClient struct {
+	Dir string
+	mu  sync.Mutex
+	seq int64
+}
+
+func (m *MaildirClient) Send(ctx context.Context, from string, to []string, msg []byte) error {
+	for _, sub := range []string{"tmp", "new", "cur"} {
+		if err := os.MkdirAll(filepath.Join(m.Dir, sub), 0700); err != nil {
+			return err
+		}
+	}
+	m.mu.Lock()
+	m.seq++
+	name := fmt.Sprintf("%d.P%dQ%d.%s", time.Now().Unix(), os.Getpid(), m.seq, hostname())
+	m.mu.Unlock()
+	tmp := filepath.Join(m.Dir, "tmp", name)
+	if err := ioutil.WriteFile(tmp, msg, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(m.Dir, "new", name))
+}
+
+func (m *MaildirClient) Host() string {
+	return hostname()
+}
+
+func (m *MaildirClient) Valid() *Validation {
+	v := &Validation{Namespace: "maildir_provider"}
+	if m.Dir == "" {
+		v.Set("dir", missingField)
+	}
+	return v
+}
+
+func hostname() string {
+	h, err := os.Hostname()
+	if err != nil {
+		return "localhost"
+	}
+	return h
+}
@@ -1,6 +1,14 @@
 package config
 
 import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/gernest/sydent-go/embed"
@@ -22,3 +30,192 @@ func TestGetSample(t *testing.T) {
 	c := Sample()
 	c.WriteToFile("config.out")
 }
+
+func TestEmailProvider(t *testing.T) {
+	m := &Matrix{}
+	if err := m.LoadTemplates(); err != nil {
+		t.Fatal(err)
+	}
+	tpl := m.GetTemplate()
+	dir, err := ioutil.TempDir("", "mail")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	send := func(ts *testing.T, cfg Email) {
+		ts.Helper()
+		cfg.Invite.From = "sydent@example.org"
+		if v := cfg.Valid(tpl); !v.IsValid() {
+			ts.Fatal(v)
+		}
+		p, err := cfg.Provider(tpl)
+		if err != nil {
+			ts.Fatal(err)
+		}
+		err = p.SendMail(context.Background(), "verification", "sydent@example.org", []string{"alice@example.com"}, map[string]string{
+			"token": "123456",
+			"link":  "https://example.org/verify",
+		})
+		if err != nil {
+			ts.Fatal(err)
+		}
+	}
+	t.Run("http", func(ts *testing.T) {
+		var got httpMail
+		var auth string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			auth = r.Header.Get("Authorization")
+			json.NewDecoder(r.Body).Decode(&got)
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer srv.Close()
+		send(ts, Email{
+			Providers: []Provider{
+				{Name: "sendgrid", State: "enabled", Settings: map[string]interface{}{
+					"url":     srv.URL,
+					"api_key": "secret",
+				}},
+			},
+		})
+		if auth != "Bearer secret" {
+			ts.Errorf("expected bearer auth got %q", auth)
+		}
+		if got.From.Email != "sydent@example.org" || got.Subject != "Your Matrix Validation Token" {
+			ts.Errorf("unexpected payload %+v", got)
+		}
+		if len(got.Personalizations) != 1 || len(got.Personalizations[0].To) != 1 ||
+			got.Personalizations[0].To[0].Email != "alice@example.com" {
+			ts.Errorf("unexpected recipients %+v", got.Personalizations)
+		}
+		if len(got.Content) != 2 || got.Content[0].Type != "text/plain" || got.Content[1].Type != "text/html" {
+			ts.Fatalf("unexpected content %+v", got.Content)
+		}
+		if !strings.Contains(got.Content[0].Value, "the code is 123456") {
+			ts.Errorf("expected the token in the text part got %q", got.Content[0].Value)
+		}
+	})
+	t.Run("sendmail", func(ts *testing.T) {
+		out := filepath.Join(dir, "sendmail.out")
+		script := filepath.Join(dir, "sendmail")
+		err := ioutil.WriteFile(script, []byte("#!/bin/sh\necho \"$@\" > "+out+"\ncat >> "+out+"\n"), 0700)
+		if err != nil {
+			ts.Fatal(err)
+		}
+		send(ts, Email{
+			Providers: []Provider{
+				{Name: "sendmail", State: "enabled", Settings: map[string]interface{}{
+					"path": script,
+				}},
+			},
+		})
+		b, err := ioutil.ReadFile(out)
+		if err != nil {
+			ts.Fatal(err)
+		}
+		lines := strings.SplitN(string(b), "\n", 2)
+		if lines[0] != "-i -f sydent@example.org -- alice@example.com" {
+			ts.Errorf("unexpected arguments %q", lines[0])
+		}
+		if !strings.Contains(lines[1], "Subject: Your Matrix Validation Token") {
+			ts.Errorf("expected the message on stdin got %q", lines[1])
+		}
+	})
+	t.Run("file", func(ts *testing.T) {
+		path := filepath.Join(dir, "mbox")
+		send(ts, Email{
+			Providers: []Provider{
+				{Name: "file", State: "enabled", Settings: map[string]interface{}{
+					"path": path,
+				}},
+			},
+		})
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			ts.Fatal(err)
+		}
+		if !strings.HasPrefix(string(b), "From sydent@example.org ") || !strings.Contains(string(b), "To: alice@example.com") {
+			ts.Errorf("unexpected mbox %q", string(b))
+		}
+	})
+	t.Run("file quotes from lines", func(ts *testing.T) {
+		f := &FileMailClient{Path: filepath.Join(dir, "quoted.mbox")}
+		msg := "Subject: hello\r\n\r\nFrom the team\r\n>From quoted\r\nFrom: not a header\r\n"
+		for i := 0; i < 2; i++ {
+			if err := f.Send(context.Background(), "sydent@example.org", []string{"alice@example.com"}, []byte(msg)); err != nil {
+				ts.Fatal(err)
+			}
+		}
+		b, err := ioutil.ReadFile(f.Path)
+		if err != nil {
+			ts.Fatal(err)
+		}
+		separators := 0
+		for _, line := range strings.Split(string(b), "\n") {
+			if strings.HasPrefix(line, "From ") {
+				separators++
+			}
+		}
+		if separators != 2 {
+			ts.Errorf("expected one From line per message got %d in %q", separators, string(b))
+		}
+		for _, want := range []string{"\n>From the team\r\n", "\n>>From quoted\r\n", "\nFrom: not a header"} {
+			if !strings.Contains(string(b), want) {
+				ts.Errorf("expected %q in %q", want, string(b))
+			}
+		}
+	})
+	t.Run("maildir", func(ts *testing.T) {
+		maildir := filepath.Join(dir, "Maildir")
+		send(ts, Email{
+			Providers: []Provider{
+				{Name: "maildir", State: "enabled", Settings: map[string]interface{}{
+					"dir": maildir,
+				}},
+			},
+		})
+		files, err := ioutil.ReadDir(filepath.Join(maildir, "new"))
+		if err != nil {
+			ts.Fatal(err)
+		}
+		if len(files) != 1 {
+			ts.Fatalf("expected one email got %d", len(files))
+		}
+		tmp, err := ioutil.ReadDir(filepath.Join(maildir, "tmp"))
+		if err != nil {
+			ts.Fatal(err)
+		}
+		if len(tmp) != 0 {
+			ts.Errorf("expected tmp to be empty got %d files", len(tmp))
+		}
+	})
+	t.Run("invalid", func(ts *testing.T) {
+		for _, p := range []Provider{
+			{Name: "carrier-pigeon", State: "disabled"},
+			{Name: "smtp", State: "on"},
+			{Name: "smtp", State: "enabled", Settings: map[string]interface{}{
+				"host":     "localhost",
+				"port":     "twenty-five",
+				"username": "sydent",
+				"password": "secret",
+			}},
+			{Name: "sendgrid", State: "enabled"},
+			{Name: "http", State: "enabled", Settings: map[string]interface{}{
+				"url":     "ftp://example.org",
+				"api_key": "secret",
+			}},
+			{Name: "sendmail", State: "enabled", Settings: map[string]interface{}{
+				"path": filepath.Join(dir, "missing"),
+			}},
+			{Name: "file", State: "enabled"},
+			{Name: "maildir", State: "enabled"},
+		} {
+			cfg := Email{
+				Providers: []Provider{p},
+				Invite:    Invite{From: "sydent@example.org"},
+			}
+			if v := cfg.Valid(tpl); v.IsValid() || !strings.Contains(v.String(), "provider") {
+				ts.Errorf("%s: expected validation error got %s", p.Name, v)
+			}
+		}
+	})
+}
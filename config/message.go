@@ -0,0 +1,238 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"strings"
+	"text/template"
+)
+
+// Parts of a structured email template, see Message.
+const (
+	messageSubject = "subject"
+	messageText    = "text"
+	messageHTML    = "html"
+)
+
+// Templates are the loaded templates. Raw templates like the response pages,
+// text messages and emails written as MIME are in the embedded
+// template.Template, structured email templates are kept apart.
+type Templates struct {
+	*template.Template
+	messages map[string]*Message
+}
+
+// Message returns the structured email template name, nil is returned if name
+// is not a structured template.
+func (t *Templates) Message(name string) *Message {
+	if t == nil {
+		return nil
+	}
+	return t.messages[name]
+}
+
+// Has returns true if there is a template called name.
+func (t *Templates) Has(name string) bool {
+	if t == nil {
+		return false
+	}
+	return t.messages[name] != nil || (t.Template != nil && t.Lookup(name) != nil)
+}
+
+// Names returns the sorted names of all templates.
+func (t *Templates) Names() []string {
+	if t == nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	for name := range t.messages {
+		seen[name] = true
+	}
+	if t.Template != nil {
+		for _, v := range t.Templates() {
+			seen[v.Name()] = true
+		}
+	}
+	return sortedKeys(seen)
+}
+
+// keys returns the keys of the data used by the template name.
+func (t *Templates) keys(name string) map[string]bool {
+	if msg := t.Message(name); msg != nil {
+		return msg.keys()
+	}
+	keys := make(map[string]bool)
+	if v := t.Lookup(name); v != nil && v.Tree != nil {
+		walkTemplate(v.Tree.Root, keys)
+	}
+	return keys
+}
+
+// Message is a structured email template. It is a single file defining the
+// subject, text and html templates
+//
+//	{{define "subject"}}Your validation token{{end}}
+//	{{define "text"}}Follow {{.link}} to verify your email.{{end}}
+//	{{define "html"}}<a href="{{.link}}">Verify your email</a>{{end}}
+//
+// The subject and at least one of text and html are required. Missing keys are
+// rendered as empty strings. The html part is
+// rendered with html/template so values are escaped for the context they are
+// used in, like urls in attributes. The parts are assembled into a
+// multipart/alternative message by Render.
+type Message struct {
+	subject *template.Template
+	text    *template.Template
+	html    *htmltemplate.Template
+}
+
+// isMessage returns true if src is a structured email template.
+func isMessage(name, src string) bool {
+	t, err := template.New(name).Parse(src)
+	return err == nil && t.Lookup(messageSubject) != nil
+}
+
+// ParseMessage parses the structured email template src.
+func ParseMessage(name, src string) (*Message, error) {
+	t, err := template.New(name).Option("missingkey=zero").Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	h, err := htmltemplate.New(name).Option("missingkey=zero").Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	m := &Message{
+		subject: t.Lookup(messageSubject),
+		text:    t.Lookup(messageText),
+	}
+	if m.subject == nil {
+		return nil, fmt.Errorf("%s: missing %s template", name, messageSubject)
+	}
+	if v := h.Lookup(messageHTML); v != nil {
+		m.html = v
+	}
+	if m.text == nil && m.html == nil {
+		return nil, fmt.Errorf("%s: missing %s or %s template", name, messageText, messageHTML)
+	}
+	return m, nil
+}
+
+// Header are the headers of a rendered Message.
+type Header struct {
+	From      string
+	To        string
+	Date      string
+	MessageID string
+}
+
+// Render executes the parts of m with data and returns the email. Non ascii
+// subjects are encoded as RFC 2047 words and the parts are quoted-printable
+// encoded. Lines end with CRLF.
+func (m *Message) Render(h Header, data interface{}) ([]byte, error) {
+	var subject bytes.Buffer
+	if err := m.subject.Execute(&subject, data); err != nil {
+		return nil, err
+	}
+	s := strings.Join(strings.Fields(subject.String()), " ")
+	if s == "" {
+		return nil, errors.New("mail: empty subject")
+	}
+	var text, html bytes.Buffer
+	if m.text != nil {
+		if err := m.text.Execute(&text, data); err != nil {
+			return nil, err
+		}
+	}
+	if m.html != nil {
+		if err := m.html.Execute(&html, data); err != nil {
+			return nil, err
+		}
+	}
+	var o bytes.Buffer
+	for _, f := range []struct{ name, value string }{
+		{"Date", h.Date},
+		{"From", h.From},
+		{"To", h.To},
+		{"Message-ID", h.MessageID},
+		{"Subject", mime.QEncoding.Encode("utf-8", s)},
+		{"MIME-Version", "1.0"},
+	} {
+		if f.value != "" {
+			fmt.Fprintf(&o, "%s: %s\r\n", f.name, f.value)
+		}
+	}
+	if m.text == nil || m.html == nil {
+		contentType := "text/plain; charset=UTF-8"
+		body := text.Bytes()
+		if m.html != nil {
+			contentType = "text/html; charset=UTF-8"
+			body = html.Bytes()
+		}
+		fmt.Fprintf(&o, "Content-Type: %s\r\nContent-Transfer-Encoding: quoted-printable\r\n\r\n", contentType)
+		if err := writeQuotedPrintable(&o, body); err != nil {
+			return nil, err
+		}
+		return o.Bytes(), nil
+	}
+	var parts bytes.Buffer
+	w := multipart.NewWriter(&parts)
+	fmt.Fprintf(&o, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", w.Boundary())
+	for _, p := range []struct {
+		contentType string
+		body        []byte
+	}{
+		{"text/plain; charset=UTF-8", text.Bytes()},
+		{"text/html; charset=UTF-8", html.Bytes()},
+	} {
+		pw, err := w.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {p.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+			"Content-Disposition":       {"inline"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeQuotedPrintable(pw, p.body); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	o.Write(parts.Bytes())
+	return o.Bytes(), nil
+}
+
+func writeQuotedPrintable(w io.Writer, body []byte) error {
+	q := quotedprintable.NewWriter(w)
+	if _, err := q.Write(bytes.TrimSpace(body)); err != nil {
+		return err
+	}
+	if err := q.Close(); err != nil {
+		return err
+	}
+	_, err := w.Write([]byte("\r\n"))
+	return err
+}
+
+// keys returns the keys of the data used by the parts of m.
+func (m *Message) keys() map[string]bool {
+	keys := make(map[string]bool)
+	for _, t := range []*template.Template{m.subject, m.text} {
+		if t != nil && t.Tree != nil {
+			walkTemplate(t.Tree.Root, keys)
+		}
+	}
+	if m.html != nil && m.html.Tree != nil {
+		walkTemplate(m.html.Tree.Root, keys)
+	}
+	return keys
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestParseMessage(t *testing.T) {
+	sample := []struct {
+		src string
+		err string
+	}{
+		{`{{define "text"}}hello{{end}}`, "missing subject template"},
+		{`{{define "subject"}}hi{{end}}`, "missing text or html template"},
+		{`{{define "subject"}}hi{{end}}{{define "html"}}<p>{{.name}}</p>{{end}}`, ""},
+		{`{{define "subject"}}hi{{end}}{{define "text"}}{{.name{{end}}`, "bad character"},
+	}
+	for _, v := range sample {
+		_, err := ParseMessage("test", v.src)
+		if v.err == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", v.src, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), v.err) {
+			t.Errorf("%s: expected %q got %v", v.src, v.err, err)
+		}
+	}
+}
+
+func TestMessageRender(t *testing.T) {
+	msg, err := ParseMessage("test", `
+{{define "subject"}}Invitation de {{.sender}}{{end}}
+{{define "text"}}{{.sender}} vous invite: https://example.org/#/room?email={{urlquery .to}}{{end}}
+{{define "html"}}<p>{{.sender}}</p><a href="https://example.org/#/room?email={{.to}}&room={{.room}}">join</a>{{if .missing}}x{{end}}{{end}}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := msg.Render(Header{
+		From: "sydent@example.org", To: "bob+test@example.com",
+		Date: "Mon, 02 Jan 2006 15:04:05 +0000", MessageID: "<1@example.org>",
+	}, map[string]string{
+		"sender": "<Élodie>",
+		"to":     "bob+test@example.com",
+		"room":   "a&b",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), "Subject: =?utf-8?q?Invitation_de_<=C3=89lodie>?=\r\n") {
+		t.Errorf("expected encoded subject got\n%s", b)
+	}
+	subject, text, html, err := MailParts(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subject != "Invitation de <Élodie>" {
+		t.Errorf("expected decoded subject got %q", subject)
+	}
+	expect := "<Élodie> vous invite: https://example.org/#/room?email=bob%2Btest%40example.com\r\n"
+	if text != expect {
+		t.Errorf("expected %q got %q", expect, text)
+	}
+	expect = `<p>&lt;Élodie&gt;</p><a href="https://example.org/#/room?email=bob%2btest%40example.com&room=a%26b">join</a>` + "\r\n"
+	if html != expect {
+		t.Errorf("expected %q got %q", expect, html)
+	}
+}
+
+func TestComposeRaw(t *testing.T) {
+	tpl := &Templates{Template: template.Must(template.New("raw").Parse(
+		"Subject: hi\n\n{{.name_forhtml}} {{.name_forurl}} {{.to}}\n",
+	))}
+	k, err := New(nil, tpl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := k.Compose("raw", "sydent@example.org", []string{"bob@example.com"}, map[string]string{
+		"name": "a&b c",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(string(b), "a&amp;b c a%26b+c bob@example.com\n") {
+		t.Errorf("unexpected email\n%s", b)
+	}
+}
@@ -0,0 +1,346 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gernest/sydent-go/embed"
+)
+
+// Endpoints address policies apply to, see AddressPolicy.Endpoints.
+const (
+	EndpointRequestToken = "request_token"
+	EndpointInvite       = "invite"
+	EndpointBind         = "bind"
+)
+
+// DisposableDomains is the embedded list of disposable email providers, see
+// AddressPolicy.BlockDisposable.
+const DisposableDomains = "/policy/disposable_domains.txt"
+
+// PolicyReload is how often the list files of address policies are checked
+// for changes.
+const PolicyReload = 10 * time.Second
+
+// AddressPolicy restricts the addresses of a medium which can be validated,
+// invited or bound. An address is denied when it matches the deny list, or
+// when there is an allow list and it does not match it.
+//
+//	address_policy "corporate_invites" {
+//	  medium    = "email"
+//	  endpoints = ["invite"]
+//	  allow     = ["example.com"]
+//	}
+//
+// Email entries are domains, which match their subdomains too, or full
+// addresses. Msisdn entries are prefixes of the number in international
+// format, like 44 or +4420.
+type AddressPolicy struct {
+	Name   string `hcl:",key"`
+	Medium string `hcl:"medium"`
+
+	// Endpoints are the endpoints the policy applies to, any of request_token,
+	// invite and bind. The policy applies to all of them when it is empty.
+	Endpoints []string `hcl:"endpoints"`
+
+	Allow []string `hcl:"allow"`
+	Deny  []string `hcl:"deny"`
+
+	// AllowFile and DenyFile are files with one entry per line, empty lines
+	// and lines starting with # are ignored. They are reloaded when they
+	// change.
+	AllowFile string `hcl:"allow_file"`
+	DenyFile  string `hcl:"deny_file"`
+
+	// BlockDisposable adds the embedded list of disposable email providers to
+	// the deny list.
+	BlockDisposable bool `hcl:"block_disposable"`
+}
+
+// AppliesTo returns true if p restricts addresses of medium used with
+// endpoint.
+func (p AddressPolicy) AppliesTo(endpoint, medium string) bool {
+	return p.Medium == medium && (len(p.Endpoints) == 0 || contains(p.Endpoints, endpoint))
+}
+
+// Valid validates p settings.
+func (p AddressPolicy) Valid() *Validation {
+	v := &Validation{Namespace: p.Name}
+	if p.Name == "" {
+		v.Set("name", missingField)
+	}
+	switch p.Medium {
+	case "":
+		v.Set("medium", missingField)
+	case "email", "msisdn":
+	default:
+		v.Set("medium", algorithmNotSupported)
+	}
+	for _, e := range p.Endpoints {
+		switch e {
+		case EndpointRequestToken, EndpointInvite, EndpointBind:
+		default:
+			v.Set("endpoints", fmt.Sprintf("unknown endpoint %q", e))
+		}
+	}
+	if len(p.Allow) == 0 && len(p.Deny) == 0 && p.AllowFile == "" &&
+		p.DenyFile == "" && !p.BlockDisposable {
+		v.Set("deny", "policy has no allow or deny entries")
+	}
+	for _, f := range []struct{ name, path string }{
+		{"allow_file", p.AllowFile},
+		{"deny_file", p.DenyFile},
+	} {
+		if f.path == "" {
+			continue
+		}
+		if _, err := ioutil.ReadFile(f.path); err != nil {
+			v.Set(f.name, err.Error())
+		}
+	}
+	if p.BlockDisposable && p.Medium != "email" {
+		v.Set("block_disposable", "only supported for email")
+	}
+	return v
+}
+
+// AddressPolicies are the policies checked before sending tokens, storing
+// invites and binding addresses.
+type AddressPolicies []AddressPolicy
+
+// Valid validates all policies in p.
+func (p AddressPolicies) Valid() *Validation {
+	v := &Validation{Namespace: "address_policy"}
+	seen := make(map[string]bool)
+	for _, a := range p {
+		if seen[a.Name] {
+			v.Set(a.Name, "duplicate policy")
+		}
+		seen[a.Name] = true
+		v.add(a)
+	}
+	return v
+}
+
+// Checker returns AddressChecker enforcing p.
+func (p AddressPolicies) Checker() (*AddressChecker, error) {
+	c := &AddressChecker{}
+	for _, a := range p {
+		r := &addressRule{policy: a}
+		if len(a.Allow) > 0 {
+			r.allow = append(r.allow, staticList(a.Medium, a.Allow))
+		}
+		if a.AllowFile != "" {
+			r.allow = append(r.allow, fileList(a.Medium, a.AllowFile))
+		}
+		if len(a.Deny) > 0 {
+			r.deny = append(r.deny, staticList(a.Medium, a.Deny))
+		}
+		if a.DenyFile != "" {
+			r.deny = append(r.deny, fileList(a.Medium, a.DenyFile))
+		}
+		if a.BlockDisposable {
+			b, err := readFile(embed.New(), DisposableDomains)
+			if err != nil {
+				return nil, err
+			}
+			r.deny = append(r.deny, &addressList{
+				medium:  a.Medium,
+				entries: parseList(a.Medium, b),
+			})
+		}
+		for _, l := range append(r.allow, r.deny...) {
+			if _, err := l.load(); err != nil {
+				return nil, err
+			}
+		}
+		c.rules = append(c.rules, r)
+	}
+	return c, nil
+}
+
+// AddressChecker enforces address policies. Safe for concurrent use.
+type AddressChecker struct {
+	rules []*addressRule
+}
+
+// Check returns the name of the policy denying address of medium to be used
+// with endpoint, an empty string is returned when it is allowed.
+//
+// The error reports list files which failed to reload, their previous entries
+// are used to check address.
+func (c *AddressChecker) Check(endpoint, medium, address string) (string, error) {
+	if c == nil {
+		return "", nil
+	}
+	var lastErr error
+	for _, r := range c.rules {
+		if !r.policy.AppliesTo(endpoint, medium) {
+			continue
+		}
+		denied, err := r.denies(address)
+		if err != nil {
+			lastErr = err
+		}
+		if denied {
+			return r.policy.Name, lastErr
+		}
+	}
+	return "", lastErr
+}
+
+type addressRule struct {
+	policy      AddressPolicy
+	allow, deny []*addressList
+}
+
+func (r *addressRule) denies(address string) (bool, error) {
+	var lastErr error
+	for _, l := range r.deny {
+		ok, err := l.match(address)
+		if err != nil {
+			lastErr = err
+		}
+		if ok {
+			return true, lastErr
+		}
+	}
+	if len(r.allow) == 0 {
+		return false, lastErr
+	}
+	for _, l := range r.allow {
+		ok, err := l.match(address)
+		if err != nil {
+			lastErr = err
+		}
+		if ok {
+			return false, lastErr
+		}
+	}
+	return true, lastErr
+}
+
+// addressList is a set of entries. Lists with a path are loaded from the file
+// and reloaded when its modification time or size changed, the file is checked
+// at most every reload.
+type addressList struct {
+	medium string
+	path   string
+	reload time.Duration
+
+	mu      sync.Mutex
+	entries map[string]bool
+	checked time.Time
+	modTime time.Time
+	size    int64
+}
+
+func staticList(medium string, entries []string) *addressList {
+	return &addressList{
+		medium:  medium,
+		entries: parseList(medium, []byte(strings.Join(entries, "\n"))),
+	}
+}
+
+func fileList(medium, path string) *addressList {
+	return &addressList{medium: medium, path: path, reload: PolicyReload}
+}
+
+// load returns the entries of l, reloading the file if it changed. The current
+// entries are kept when reloading fails.
+func (l *addressList) load() (map[string]bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.path == "" {
+		return l.entries, nil
+	}
+	now := time.Now()
+	if l.entries != nil && now.Sub(l.checked) < l.reload {
+		return l.entries, nil
+	}
+	l.checked = now
+	stat, err := os.Stat(l.path)
+	if err != nil {
+		return l.entries, err
+	}
+	if l.entries != nil && stat.ModTime().Equal(l.modTime) && stat.Size() == l.size {
+		return l.entries, nil
+	}
+	b, err := ioutil.ReadFile(l.path)
+	if err != nil {
+		return l.entries, err
+	}
+	l.entries = parseList(l.medium, b)
+	l.modTime = stat.ModTime()
+	l.size = stat.Size()
+	return l.entries, nil
+}
+
+func (l *addressList) match(address string) (bool, error) {
+	entries, err := l.load()
+	if l.medium == "msisdn" {
+		n := normalizeEntry(l.medium, address)
+		for i := len(n); i > 0; i-- {
+			if entries[n[:i]] {
+				return true, err
+			}
+		}
+		return false, err
+	}
+	address = strings.ToLower(strings.TrimSpace(address))
+	if entries[address] {
+		return true, err
+	}
+	i := strings.LastIndexByte(address, '@')
+	if i < 0 {
+		return false, err
+	}
+	domain := strings.TrimSuffix(address[i+1:], ".")
+	for {
+		if entries[domain] {
+			return true, err
+		}
+		j := strings.IndexByte(domain, '.')
+		if j < 0 {
+			return false, err
+		}
+		domain = domain[j+1:]
+	}
+}
+
+func parseList(medium string, b []byte) map[string]bool {
+	entries := make(map[string]bool)
+	s := bufio.NewScanner(bytes.NewReader(b))
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if e := normalizeEntry(medium, line); e != "" {
+			entries[e] = true
+		}
+	}
+	return entries
+}
+
+func normalizeEntry(medium, e string) string {
+	if medium == "msisdn" {
+		return strings.Map(func(r rune) rune {
+			if r >= '0' && r <= '9' {
+				return r
+			}
+			return -1
+		}, e)
+	}
+	e = strings.ToLower(strings.TrimSpace(e))
+	if !strings.Contains(e, "@") || strings.HasPrefix(e, "@") {
+		e = strings.TrimSuffix(strings.TrimLeft(e, "@*."), ".")
+	}
+	return e
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAddressPolicies(t *testing.T) {
+	dir, err := ioutil.TempDir("", "policy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	denyFile := filepath.Join(dir, "deny.txt")
+	if err := ioutil.WriteFile(denyFile, []byte("# blocked\nevil.org\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	m, err := LoadFile([]byte(`
+address_policy "corporate_invites" {
+  medium    = "email"
+  endpoints = ["invite"]
+  allow     = ["example.com", "partner@friends.net"]
+}
+
+address_policy "blocked" {
+  medium           = "email"
+  deny_file        = "` + denyFile + `"
+  block_disposable = true
+}
+
+address_policy "numbers" {
+  medium = "msisdn"
+  deny   = ["+44 20"]
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := m.AddressPolicies.Valid(); !v.IsValid() {
+		t.Fatal(v)
+	}
+	c, err := m.AddressPolicies.Checker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sample := []struct {
+		endpoint, medium, address string
+		policy                    string
+	}{
+		{EndpointRequestToken, "email", "alice@example.org", ""},
+		{EndpointInvite, "email", "alice@example.com", ""},
+		{EndpointInvite, "email", "Alice@Mail.Example.COM", ""},
+		{EndpointInvite, "email", "partner@friends.net", ""},
+		{EndpointInvite, "email", "other@friends.net", "corporate_invites"},
+		{EndpointInvite, "email", "alice@example.org", "corporate_invites"},
+		{EndpointBind, "email", "bob@evil.org", "blocked"},
+		{EndpointRequestToken, "email", "bob@mx.evil.org", "blocked"},
+		{EndpointRequestToken, "email", "bob@mailinator.com", "blocked"},
+		{EndpointRequestToken, "email", "bob@notevil.org", ""},
+		{EndpointRequestToken, "msisdn", "442071234567", "numbers"},
+		{EndpointRequestToken, "msisdn", "447700900123", ""},
+	}
+	for _, s := range sample {
+		got, err := c.Check(s.endpoint, s.medium, s.address)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != s.policy {
+			t.Errorf("%s %s: expected %q got %q", s.endpoint, s.address, s.policy, got)
+		}
+	}
+
+	for _, r := range c.rules {
+		for _, l := range r.deny {
+			l.reload = 0
+		}
+	}
+	if err := ioutil.WriteFile(denyFile, []byte("example.org\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	for address, policy := range map[string]string{
+		"bob@evil.org":      "",
+		"alice@example.org": "blocked",
+	} {
+		got, err := c.Check(EndpointRequestToken, "email", address)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != policy {
+			t.Errorf("%s: expected %q after reload got %q", address, policy, got)
+		}
+	}
+	if err := os.Remove(denyFile); err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.Check(EndpointRequestToken, "email", "alice@example.org")
+	if err == nil || got != "blocked" {
+		t.Errorf("expected previous entries and an error got %q %v", got, err)
+	}
+
+	var nilChecker *AddressChecker
+	if got, err := nilChecker.Check(EndpointBind, "email", "bob@evil.org"); got != "" || err != nil {
+		t.Errorf("expected nil checker to allow got %q %v", got, err)
+	}
+}
+
+func TestAddressPolicyValid(t *testing.T) {
+	invalid := AddressPolicies{
+		{Name: "empty", Medium: "email"},
+		{Name: "medium", Medium: "fax", Deny: []string{"1"}},
+		{Name: "endpoint", Medium: "email", Endpoints: []string{"lookup"}, Deny: []string{"a.com"}},
+		{Name: "file", Medium: "email", DenyFile: "/does/not/exist"},
+		{Name: "disposable", Medium: "msisdn", BlockDisposable: true},
+	}
+	for _, p := range invalid {
+		if p.Valid().IsValid() {
+			t.Errorf("%s: expected policy to be invalid", p.Name)
+		}
+	}
+	dup := AddressPolicies{
+		{Name: "a", Medium: "email", Deny: []string{"a.com"}},
+		{Name: "a", Medium: "email", Deny: []string{"b.com"}},
+	}
+	if dup.Valid().IsValid() {
+		t.Error("expected duplicate policies to be invalid")
+	}
+}
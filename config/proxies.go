@@ -0,0 +1,98 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Proxies are the trusted proxies of Server.TrustedProxies.
+type Proxies struct {
+	nets []*net.IPNet
+}
+
+// Proxies returns the trusted proxies of s, an ip is trusted as a network of
+// its own.
+func (s Server) Proxies() (*Proxies, error) {
+	p := &Proxies{}
+	for _, v := range s.TrustedProxies {
+		n, err := parseNetwork(v)
+		if err != nil {
+			return nil, err
+		}
+		p.nets = append(p.nets, n)
+	}
+	return p, nil
+}
+
+func parseNetwork(v string) (*net.IPNet, error) {
+	if strings.Contains(v, "/") {
+		_, n, err := net.ParseCIDR(v)
+		if err != nil {
+			return nil, err
+		}
+		return n, nil
+	}
+	ip := net.ParseIP(v)
+	if ip == nil {
+		return nil, fmt.Errorf("%q is neither an ip nor a cidr", v)
+	}
+	bits := 8 * net.IPv6len
+	if v4 := ip.To4(); v4 != nil {
+		ip, bits = v4, 8*net.IPv4len
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+}
+
+// Trusted returns true if ip is a trusted proxy.
+func (p *Proxies) Trusted(ip net.IP) bool {
+	if p == nil || ip == nil {
+		return false
+	}
+	for _, n := range p.nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns the ip of the client which sent req. It is the peer of the
+// connection unless the peer is a trusted proxy, in which case X-Forwarded-For
+// is walked from the right, skipping trusted proxies, and X-Real-IP is used
+// when there is no X-Forwarded-For. Headers sent by other peers are ignored,
+// they are set by the client.
+//
+// All proxies are untrusted when p is nil.
+func (p *Proxies) ClientIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return host
+	}
+	if !p.Trusted(ip) {
+		return ip.String()
+	}
+	if xff := req.Header["X-Forwarded-For"]; len(xff) > 0 {
+		hops := strings.Split(strings.Join(xff, ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := net.ParseIP(strings.TrimSpace(hops[i]))
+			if hop == nil {
+				break
+			}
+			ip = hop
+			if !p.Trusted(hop) {
+				break
+			}
+		}
+		return ip.String()
+	}
+	if hop := net.ParseIP(strings.TrimSpace(req.Header.Get("X-Real-IP"))); hop != nil {
+		return hop.String()
+	}
+	return ip.String()
+}
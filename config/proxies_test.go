@@ -0,0 +1,51 @@
+package config
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProxies(t *testing.T) {
+	p, err := Server{TrustedProxies: []string{"10.0.0.0/8", "192.168.1.1", "fd00::/8"}}.Proxies()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sample := []struct {
+		remote, xff, realIP string
+		proxies             *Proxies
+		expect              string
+	}{
+		{remote: "203.0.113.1:1234", proxies: p, expect: "203.0.113.1"},
+		{remote: "[2001:db8::1]:1234", proxies: p, expect: "2001:db8::1"},
+		// headers of untrusted peers are ignored
+		{remote: "203.0.113.1:1234", xff: "198.51.100.1", realIP: "198.51.100.2", proxies: p, expect: "203.0.113.1"},
+		{remote: "10.0.0.1:1234", xff: "198.51.100.1", realIP: "198.51.100.2", expect: "10.0.0.1"},
+		{remote: "10.0.0.1:1234", xff: "198.51.100.1", proxies: p, expect: "198.51.100.1"},
+		{remote: "192.168.1.1:1234", realIP: "198.51.100.2", proxies: p, expect: "198.51.100.2"},
+		{remote: "192.168.1.2:1234", realIP: "198.51.100.2", proxies: p, expect: "192.168.1.2"},
+		// the client picks the left of the header, trusted proxies append
+		{remote: "10.0.0.1:1234", xff: "198.51.100.9, 198.51.100.1, 10.0.0.2", proxies: p, expect: "198.51.100.1"},
+		{remote: "[fd00::1]:1234", xff: "2001:db8::2", proxies: p, expect: "2001:db8::2"},
+		{remote: "10.0.0.1:1234", xff: "10.0.0.3, 10.0.0.2", proxies: p, expect: "10.0.0.3"},
+		{remote: "10.0.0.1:1234", xff: "nonsense, 10.0.0.2", proxies: p, expect: "10.0.0.2"},
+		{remote: "10.0.0.1:1234", xff: "198.51.100.1 , nonsense", proxies: p, expect: "10.0.0.1"},
+	}
+	for _, s := range sample {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = s.remote
+		if s.xff != "" {
+			req.Header.Set("X-Forwarded-For", s.xff)
+		}
+		if s.realIP != "" {
+			req.Header.Set("X-Real-IP", s.realIP)
+		}
+		if got := s.proxies.ClientIP(req); got != s.expect {
+			t.Errorf("%s %q %q: expected %s got %s", s.remote, s.xff, s.realIP, s.expect, got)
+		}
+	}
+	for _, v := range []string{"10.0.0.0/33", "example.com"} {
+		if _, err := (Server{TrustedProxies: []string{v}}).Proxies(); err == nil {
+			t.Errorf("%s: expected an error", v)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"strconv"
+	"time"
+)
+
+// Rate limit backends, see RateLimit.Backend.
+const (
+	RateLimitMemory   = "memory"
+	RateLimitDatabase = "database"
+)
+
+// DefaultRateLimitPeriod is the time it takes to earn back a request when
+// Limit.Period is empty.
+const DefaultRateLimitPeriod = time.Minute
+
+// RateLimit configures the limits on requests which make us send emails or
+// text messages, like requestToken and store-invite.
+//
+// Each limit is a token bucket holding Burst requests, a request is allowed
+// when the bucket is not empty and one request is earned back every Period.
+// Clients over a limit get M_LIMIT_EXCEEDED with retry_after_ms.
+type RateLimit struct {
+	// Backend is where the buckets are kept. memory keeps them in the process,
+	// database shares them between all the replicas using the same database.
+	// Defaults to memory.
+	Backend string `hcl:"backend"`
+
+	// IP limits requests from a single client ip address.
+	IP Limit `hcl:"ip"`
+
+	// Address limits requests for a single email address or phone number.
+	Address Limit `hcl:"address"`
+
+	// Domain limits requests for the email addresses of a single domain.
+	Domain Limit `hcl:"domain"`
+}
+
+// Valid validates r settings.
+func (r RateLimit) Valid() *Validation {
+	v := &Validation{Namespace: "rate_limit"}
+	switch r.Backend {
+	case "", RateLimitMemory, RateLimitDatabase:
+	default:
+		v.Set("backend", algorithmNotSupported)
+	}
+	for _, c := range []*Validation{
+		r.IP.valid("ip"),
+		r.Address.valid("address"),
+		r.Domain.valid("domain"),
+	} {
+		if !c.IsValid() {
+			v.Children = append(v.Children, c)
+		}
+	}
+	return v
+}
+
+// Enabled returns true if any limit is enabled.
+func (r RateLimit) Enabled() bool {
+	return r.IP.Enabled() || r.Address.Enabled() || r.Domain.Enabled()
+}
+
+// Retention returns how long a bucket takes to fill up again, buckets which were
+// not updated for longer can be deleted.
+func (r RateLimit) Retention() time.Duration {
+	var d time.Duration
+	for _, l := range []Limit{r.IP, r.Address, r.Domain} {
+		if full := time.Duration(l.Size()) * l.Every(); full > d {
+			d = full
+		}
+	}
+	return d
+}
+
+// Limit is a token bucket, see RateLimit. The limit is disabled when Burst is
+// empty.
+type Limit struct {
+	// Burst is the number of requests allowed at once.
+	Burst string `hcl:"burst"`
+
+	// Period is the time it takes to earn back a request, like 10m. Defaults to
+	// 1m.
+	Period string `hcl:"period"`
+}
+
+func (l Limit) valid(name string) *Validation {
+	v := &Validation{Namespace: name}
+	if l.Burst != "" {
+		n, err := strconv.Atoi(l.Burst)
+		if err != nil {
+			v.Set("burst", err.Error())
+		} else if n <= 0 {
+			v.Set("burst", "must be positive")
+		}
+	}
+	if l.Period != "" {
+		d, err := time.ParseDuration(l.Period)
+		if err != nil {
+			v.Set("period", err.Error())
+		} else if d < time.Millisecond {
+			v.Set("period", "must be at least 1ms")
+		}
+	}
+	return v
+}
+
+// Enabled returns true if requests are limited.
+func (l Limit) Enabled() bool {
+	return l.Size() > 0
+}
+
+// Size returns the number of requests allowed at once.
+func (l Limit) Size() int {
+	return positive(l.Burst, 0)
+}
+
+// Every returns the time it takes to earn back a request.
+func (l Limit) Every() time.Duration {
+	d := duration(l.Period, DefaultRateLimitPeriod)
+	if d < time.Millisecond {
+		return DefaultRateLimitPeriod
+	}
+	return d
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"strconv"
+)
+
+// DefaultReplicationPort is the port peers push associations to when
+// Replication.Port is not set.
+const DefaultReplicationPort = "1001"
+
+// Replication configures the mutual TLS listener which receives associations
+// pushed by peers.
+//
+// Peers are identified by the common name of their client certificate, which
+// must be signed by a certificate authority in CAFile. The same certificate and
+// authorities are used when pushing associations to peers.
+type Replication struct {
+	Port     string `hcl:"port"`
+	CertFile string `hcl:"cert_file"`
+	KeyFile  string `hcl:"key_file"`
+	CAFile   string `hcl:"ca_file"`
+}
+
+// Enabled returns true if the replication listener is configured.
+func (r Replication) Enabled() bool {
+	return r.CertFile != "" || r.KeyFile != "" || r.CAFile != ""
+}
+
+// Valid validates r settings. Empty settings are valid, replication is
+// disabled then.
+func (r Replication) Valid() *Validation {
+	v := &Validation{Namespace: "replication"}
+	if !r.Enabled() {
+		return v
+	}
+	if r.Port != "" {
+		if _, err := strconv.Atoi(r.Port); err != nil {
+			v.Set("port", err.Error())
+		}
+	}
+	if r.CertFile == "" {
+		v.Set("cert_file", missingField)
+	}
+	if r.KeyFile == "" {
+		v.Set("key_file", missingField)
+	}
+	if r.CAFile == "" {
+		v.Set("ca_file", missingField)
+	}
+	if !v.IsValid() {
+		return v
+	}
+	if _, err := tls.LoadX509KeyPair(r.CertFile, r.KeyFile); err != nil {
+		v.Set("cert_file", err.Error())
+	}
+	if _, err := r.certPool(); err != nil {
+		v.Set("ca_file", err.Error())
+	}
+	return v
+}
+
+// Address returns the address the replication listener binds to.
+func (r Replication) Address() string {
+	port := r.Port
+	if port == "" {
+		port = DefaultReplicationPort
+	}
+	return fmt.Sprintf(":%s", port)
+}
+
+func (r Replication) certPool() (*x509.CertPool, error) {
+	return loadCertPool(r.CAFile)
+}
+
+// ServerTLS returns tls configuration for the replication listener. Client
+// certificates are verified when given, requests without one must be rejected
+// by the handler.
+func (r Replication) ServerTLS() (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(r.CertFile, r.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := r.certPool()
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    pool,
+		ClientAuth:   tls.VerifyClientCertIfGiven,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// ClientTLS returns tls configuration for pushing associations to peers. The
+// certificate is presented as client certificate and peers are verified against
+// the configured certificate authorities.
+func (r Replication) ClientTLS() (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(r.CertFile, r.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := r.certPool()
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      pool,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestReplication(t *testing.T) {
+	dir := "../service/fixture/certs/replication/"
+	r := Replication{
+		CertFile: dir + "localhost.pem",
+		KeyFile:  dir + "localhost-key.pem",
+		CAFile:   dir + "ca.pem",
+	}
+	if v := r.Valid(); !v.IsValid() {
+		t.Fatal(v)
+	}
+	if r.Address() != ":1001" {
+		t.Errorf("expected default port got %s", r.Address())
+	}
+	serverTLS, err := r.ServerTLS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var commonName string
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if len(req.TLS.VerifiedChains) > 0 {
+			commonName = req.TLS.VerifiedChains[0][0].Subject.CommonName
+		}
+	}))
+	srv.TLS = serverTLS
+	srv.StartTLS()
+	defer srv.Close()
+
+	peer := Replication{
+		CertFile: dir + "peer.example.com.pem",
+		KeyFile:  dir + "peer.example.com-key.pem",
+		CAFile:   dir + "ca.pem",
+	}
+	clientTLS, err := peer.ClientTLS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: clientTLS}}
+	res, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if commonName != "peer.example.com" {
+		t.Errorf("expected verified client peer.example.com got %q", commonName)
+	}
+
+	// a client certificate which is not signed by the ca is never verified.
+	commonName = ""
+	self, err := tls.LoadX509KeyPair("../service/fixture/certs/localhost.pem", "../service/fixture/certs/localhost-key.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientTLS.Certificates = []tls.Certificate{self}
+	client = &http.Client{Transport: &http.Transport{TLSClientConfig: clientTLS}}
+	if res, err := client.Get(srv.URL); err == nil {
+		res.Body.Close()
+	}
+	if commonName != "" {
+		t.Errorf("expected unverified client got %q", commonName)
+	}
+
+	bad := Replication{CertFile: dir + "missing.pem"}
+	v := bad.Valid()
+	if v.IsValid() || !strings.Contains(v.String(), "key_file") || !strings.Contains(v.String(), "ca_file") {
+		t.Errorf("expected validation errors got %s", v)
+	}
+}
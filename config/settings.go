@@ -1,15 +1,17 @@
 package config
 
 import (
+	"crypto/tls"
 	"database/sql"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
-	"log"
+	"net/url"
 	"os"
 	"regexp"
 	"strconv"
 	"text/template"
+	"time"
 
 	"github.com/cenkalti/backoff"
 	"github.com/gernest/sydent-go/embed"
@@ -20,8 +22,9 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/gernest/signedjson"
-	// we only support postgres
+	// database drivers, see DB.
 	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
 )
 
 // ApplicationName the name of the application.
@@ -50,21 +53,49 @@ type Matrix struct {
 	Server    Server     `hcl:"server"`
 	DB        DB         `hcl:"db"`
 	Email     Email      `hcl:"email"`
+	Msisdn    Msisdn     `hcl:"msisdn"`
+	Terms     Terms      `hcl:"terms"`
+	Admin     Admin      `hcl:"admin"`
+	Janitor   Janitor    `hcl:"janitor"`
+	RateLimit RateLimit  `hcl:"rate_limit"`
+	Captcha   Captcha    `hcl:"captcha"`
 	Templates []Template `hcl:"templates"`
 	Peers     []Peer     `hcl:"peer"`
-	tpl       *template.Template
+
+	// AddressPolicies restrict the addresses which can be validated, invited
+	// or bound.
+	AddressPolicies AddressPolicies `hcl:"address_policy"`
+
+	// Canonical configures the canonical form of addresses.
+	Canonical Canonical `hcl:"canonical"`
+
+	// Locale is the locale of emails and pages when the request has no
+	// preference, defaults to DefaultLocale.
+	Locale string `hcl:"locale"`
+
+	tpl *Templates
 }
 
 func (m *Matrix) LoadTemplates() error {
 	fs := embed.New()
-	tpl := template.New("email")
+	tpl := &Templates{
+		Template: template.New("email"),
+		messages: make(map[string]*Message),
+	}
 	for k, v := range MergeTemplates(EmbedTemplates(), m.Templates) {
-		t := tpl.New(k)
 		b, err := readFile(fs, v.Path)
 		if err != nil {
 			return err
 		}
-		_, err = t.Parse(string(b))
+		if isMessage(k, string(b)) {
+			msg, err := ParseMessage(k, string(b))
+			if err != nil {
+				return err
+			}
+			tpl.messages[k] = msg
+			continue
+		}
+		_, err = tpl.New(k).Parse(string(b))
 		if err != nil {
 			return err
 		}
@@ -73,7 +104,7 @@ func (m *Matrix) LoadTemplates() error {
 	return nil
 }
 
-func (m *Matrix) GetTemplate() *template.Template {
+func (m *Matrix) GetTemplate() *Templates {
 	return m.tpl
 }
 
@@ -104,6 +135,18 @@ func (m *Matrix) Valid() *Validation {
 	if !e.IsValid() {
 		v.Children = append(v.Children, e)
 	}
+	if s := m.Msisdn.Valid(m.GetTemplate()); !s.IsValid() {
+		v.Children = append(v.Children, s)
+	}
+	v.add(m.Terms)
+	v.add(m.Janitor)
+	v.add(m.RateLimit)
+	v.add(m.Captcha)
+	v.add(m.AddressPolicies)
+	v.add(m.Canonical)
+	if m.Locale != "" && !localeRegex.MatchString(NormalizeLocale(m.Locale)) {
+		v.Set("locale", "not a valid language tag")
+	}
 
 	return v
 }
@@ -113,15 +156,34 @@ func (m *Matrix) Valid() *Validation {
 // specific configuration is used to enable/disable services. So, this is the
 // one and only server that runs on boot up.
 //
-// Only TLS server is supported. Failure to provide tls certificates will result
-// in validation error.
-//
-// TODO: Add let's encrypt support with auto-tls.
+// The server uses TLS when either CertFile and KeyFile or ACME are configured,
+// otherwise it serves plain http and is expected to sit behind a TLS
+// terminating proxy.
 type Server struct {
 	Name           string `hcl:"name"`
 	Port           string `hcl:"port"`
 	ClientHTTPBase string `hcl:"client_http_base"`
 	Crypto         Crypto `hcl:"crypto"`
+
+	CertFile string `hcl:"cert_file"`
+	KeyFile  string `hcl:"key_file"`
+	ACME     ACME   `hcl:"acme"`
+
+	// HTTPPort is the port of a plain http listener which redirects to the TLS
+	// server. It also answers ACME http-01 challenges.
+	HTTPPort string `hcl:"http_port"`
+
+	// LookupPepperRotation is how often the pepper used for v2 hashed lookups
+	// is replaced, for instance 720h. The pepper is never rotated automatically
+	// when this is empty.
+	LookupPepperRotation string `hcl:"lookup_pepper_rotation"`
+
+	// TrustedProxies are the ips or cidr networks of the proxies in front of
+	// the server. Their X-Forwarded-For and X-Real-IP headers are used to find
+	// the ip of clients, the headers are ignored for other peers.
+	TrustedProxies []string `hcl:"trusted_proxies"`
+
+	Replication Replication `hcl:"replication"`
 }
 
 // Valid validates s settings.
@@ -144,9 +206,64 @@ func (s Server) Valid() *Validation {
 	if cv := s.Crypto.Valid(); !cv.IsValid() {
 		v.Children = append(v.Children, cv)
 	}
+	v.add(s.Replication)
+	if s.CertFile != "" || s.KeyFile != "" {
+		if s.CertFile == "" {
+			v.Set("cert_file", missingField)
+		}
+		if s.KeyFile == "" {
+			v.Set("key_file", missingField)
+		}
+		if s.ACME.Enabled() {
+			v.Set("acme", "can not be used with cert_file and key_file")
+		}
+		if s.CertFile != "" && s.KeyFile != "" {
+			if _, err := tls.LoadX509KeyPair(s.CertFile, s.KeyFile); err != nil {
+				v.Set("cert_file", err.Error())
+			}
+		}
+	}
+	v.add(s.ACME)
+	if s.HTTPPort != "" {
+		if _, err := strconv.Atoi(s.HTTPPort); err != nil {
+			v.Set("http_port", err.Error())
+		} else if !s.TLS() {
+			v.Set("http_port", "requires tls")
+		}
+	}
+	if s.LookupPepperRotation != "" {
+		d, err := time.ParseDuration(s.LookupPepperRotation)
+		if err != nil {
+			v.Set("lookup_pepper_rotation", err.Error())
+		} else if d <= 0 {
+			v.Set("lookup_pepper_rotation", "must be positive")
+		}
+	}
+	for _, p := range s.TrustedProxies {
+		if _, err := parseNetwork(p); err != nil {
+			v.Set("trusted_proxies", err.Error())
+		}
+	}
 	return v
 }
 
+// PepperRotation returns the interval for rotating the lookup pepper, zero
+// means the pepper is not rotated.
+func (s Server) PepperRotation() time.Duration {
+	d, _ := time.ParseDuration(s.LookupPepperRotation)
+	return d
+}
+
+// TLS returns true if the server is configured to use TLS.
+func (s Server) TLS() bool {
+	return (s.CertFile != "" && s.KeyFile != "") || s.ACME.Enabled()
+}
+
+// HTTPAddress returns the address of the http listener redirecting to TLS.
+func (s Server) HTTPAddress() string {
+	return fmt.Sprintf(":%s", s.HTTPPort)
+}
+
 // Address returns the server address to bind to.
 func (s Server) Address() string {
 	return fmt.Sprintf(":%s", s.Port)
@@ -205,6 +322,16 @@ const missingField = "missing"
 const notValidFile = " not a valid file path"
 const algorithmNotSupported = "not supported"
 
+// Supported database drivers.
+const (
+	Postgres = "postgres"
+	SQLite   = "sqlite3"
+
+	// Memory keeps everything in memory, nothing is persisted and Conn is not
+	// used.
+	Memory = "memory"
+)
+
 type DB struct {
 	Driver string `hcl:"driver"`
 	Conn   string `hcl:"conn"`
@@ -212,9 +339,11 @@ type DB struct {
 
 func (db DB) Valid() *Validation {
 	v := &Validation{Namespace: "db"}
-	if db.Driver == "" {
+	switch db.Driver {
+	case "":
 		v.Set("driver", missingField)
-	} else {
+	case Memory:
+	case Postgres, SQLite:
 		if db.Conn == "" {
 			v.Set("conn", missingField)
 		} else {
@@ -229,6 +358,8 @@ func (db DB) Valid() *Validation {
 				}
 			}
 		}
+	default:
+		v.Set("driver", algorithmNotSupported)
 	}
 	return v
 }
@@ -290,16 +421,19 @@ type Email struct {
 	Providers    []Provider   `hcl:"provider"`
 	Invite       Invite       `hcl:"invite"`
 	Verification Verification `hcl:"verification"`
+	Outbox       Outbox       `hcl:"outbox"`
+	DKIM         DKIM         `hcl:"dkim"`
 }
 
 // embedded templates
 var (
-	InviteTpl                = "/email/invite_template.eml"
-	InviteVector             = "/email/invite_template_vector.eml"
-	VerificationTpl          = "/email/verification_template.eml"
-	VerificationVector       = "/email/verification_template_vector.eml"
+	InviteTpl                = "/email/invite_template.tmpl"
+	InviteVector             = "/email/invite_template_vector.tmpl"
+	VerificationTpl          = "/email/verification_template.tmpl"
+	VerificationVector       = "/email/verification_template_vector.tmpl"
 	VerifyResponsePage       = "/email/verify_response_page_template"
 	VerifyResponsePageVector = "/email/verify_response_page_template_vector_im"
+	SMSVerificationTpl       = "/sms/verification_template.txt"
 )
 
 type Template struct {
@@ -327,6 +461,9 @@ func EmbedTemplates() []Template {
 		{
 			Name: "verify_response_vector", Path: VerifyResponsePage,
 		},
+		{
+			Name: "sms_verification", Path: SMSVerificationTpl,
+		},
 	}
 }
 
@@ -353,58 +490,133 @@ func MergeTemplates(a, b []Template) map[string]Template {
 	return m
 
 }
-func (e Email) Provider(templates *template.Template) (EmailProvider, error) {
+func (e Email) Provider(templates *Templates) (EmailProvider, error) {
+	c, err := e.Client()
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		return NoopMail{}, nil
+	}
+	return e.Mail(c, templates)
+}
+
+// Mail returns Kmail which sends emails with client, signing them when DKIM is
+// enabled.
+func (e Email) Mail(client Client, templates *Templates) (*Kmail, error) {
+	k, err := New(client, templates)
+	if err != nil {
+		return nil, err
+	}
+	s, err := e.DKIM.Signer()
+	if err != nil {
+		return nil, err
+	}
+	return k.Sign(s), nil
+}
+
+// Client returns the client of the first enabled email provider, nil is
+// returned when no provider is enabled.
+func (e Email) Client() (Client, error) {
 	for _, v := range e.Providers {
-		if v.State == "enabled" {
-			if v.Name == "smtp" {
-				var s SMTPEmail
-				s.Enabled = true
-				if x, ok := v.Settings["username"]; ok {
-					s.Username = x.(string)
-				}
-				if x, ok := v.Settings["password"]; ok {
-					s.Password = x.(string)
-				}
-				if x, ok := v.Settings["host"]; ok {
-					s.Host = x.(string)
-				}
-				if x, ok := v.Settings["port"]; ok {
-					xs := x.(string)
-					if xs != "" {
-						m, err := strconv.Atoi(xs)
-						if err != nil {
-							log.Fatalf("incorrect port value :%v", err)
-						}
-						s.Port = int64(m)
-					}
-				}
-				c := NewSMAPCLient(s)
-				e, err := New(c, templates)
+		if v.State != "enabled" {
+			continue
+		}
+		switch v.Name {
+		case "smtp":
+			s := SMTPEmail{
+				Enabled:  true,
+				Username: v.setting("username"),
+				Password: v.setting("password"),
+				Host:     v.setting("host"),
+				TLS:      v.setting("tls"),
+				Auth:     v.setting("auth"),
+				Timeout:  v.setting("timeout"),
+				Pool:     v.setting("pool"),
+				CAFile:   v.setting("ca_file"),
+			}
+			if port := v.setting("port"); port != "" {
+				n, err := strconv.ParseInt(port, 10, 64)
 				if err != nil {
-					return nil, err
+					return nil, fmt.Errorf("invalid smtp port %q", port)
 				}
-				return e, nil
+				s.Port = n
+			}
+			return NewSMAPCLient(s), nil
+		case "sendgrid":
+			endpoint := v.setting("url")
+			if endpoint == "" {
+				endpoint = DefaultSendGridURL
+			}
+			return NewHTTPMailClient(endpoint, v.setting("api_key")), nil
+		case "http":
+			return NewHTTPMailClient(v.setting("url"), v.setting("api_key")), nil
+		case "sendmail":
+			path := v.setting("path")
+			if path == "" {
+				path = DefaultSendmailPath
 			}
+			return &SendmailClient{Path: path}, nil
+		case "file":
+			return &FileMailClient{Path: v.setting("path")}, nil
+		case "maildir":
+			return &MaildirClient{Dir: v.setting("dir")}, nil
+		default:
+			return nil, fmt.Errorf("unknown email provider %q", v.Name)
 		}
-
 	}
-	return NoopMail{}, nil
+	return nil, nil
+}
+
+// emailProviders are the names of the supported email providers.
+var emailProviders = map[string]bool{
+	"smtp":     true,
+	"sendgrid": true,
+	"http":     true,
+	"sendmail": true,
+	"file":     true,
+	"maildir":  true,
 }
 
-func (e Email) Valid(templates *template.Template) *Validation {
+func (e Email) Valid(templates *Templates) *Validation {
 	v := &Validation{Namespace: "email"}
-	p, err := e.Provider(templates)
-	if err != nil {
-		v.Fields = append(v.Fields, Field{
-			Name: "provider", Value: err.Error(),
-		})
-	} else {
-		v.add(p)
+	for _, p := range e.Providers {
+		if !emailProviders[p.Name] {
+			v.Set("provider", fmt.Sprintf("unknown email provider %q", p.Name))
+		}
+		switch p.State {
+		case "", "enabled", "disabled":
+		default:
+			v.Set("provider", fmt.Sprintf("%s: unknown state %q", p.Name, p.State))
+		}
+	}
+	if v.IsValid() {
+		p, err := e.Provider(templates)
+		if err != nil {
+			v.Set("provider", err.Error())
+		} else {
+			v.add(p)
+		}
+	}
+	if name := e.providerName(); e.DKIM.Enabled() && (name == "http" || name == "sendgrid") {
+		v.Set("dkim", fmt.Sprintf("%s: the provider api does not send the DKIM-Signature, sign with the provider instead", name))
 	}
 	v.add(e.Invite)
+	v.add(e.Outbox)
+	v.add(e.DKIM)
 	return v
 }
 
+// providerName returns the name of the first enabled email provider.
+func (e Email) providerName() string {
+	for _, v := range e.Providers {
+		if v.State == "enabled" {
+			return v.Name
+		}
+	}
+	return ""
+}
+
 type EmailProvider interface {
 	Validator
 	Mail
@@ -435,7 +647,7 @@ func (i Invite) Valid() *Validation {
 // Verification stores details used when sending token validation emails.
 type Verification struct {
 	From         string `hcl:"from"`
-	Template     string `hcl:template"`
+	Template     string `hcl:"template"`
 	ResponsePage string `hcl:"response_page_template"`
 }
 
@@ -447,9 +659,147 @@ func (i Verification) Valid() *Validation {
 	return v
 }
 
+// Msisdn defines configuration for validating phone numbers over sms.
+type Msisdn struct {
+	Providers    []Provider         `hcl:"provider"`
+	Verification MsisdnVerification `hcl:"verification"`
+}
+
+// MsisdnVerification stores details used when sending token validation text
+// messages.
+type MsisdnVerification struct {
+	// Originator is the sender name or number shown to the recipient.
+	Originator string `hcl:"originator"`
+	Template   string `hcl:"template"`
+}
+
+// Provider returns the first enabled sms provider. NoopSMS is returned when no
+// provider is enabled.
+func (m Msisdn) Provider(templates *Templates) (SMSProvider, error) {
+	for _, v := range m.Providers {
+		if v.State != "enabled" {
+			continue
+		}
+		switch v.Name {
+		case "http":
+			c := NewHTTPSMSClient(v.setting("url"), v.setting("api_key"))
+			return NewSMS(c, templates), nil
+		case "file":
+			c := &FileSMSClient{Path: v.setting("path")}
+			return NewSMS(c, templates), nil
+		default:
+			return nil, fmt.Errorf("unknown sms provider %q", v.Name)
+		}
+	}
+	return NoopSMS{}, nil
+}
+
+func (m Msisdn) Valid(templates *Templates) *Validation {
+	v := &Validation{Namespace: "msisdn"}
+	p, err := m.Provider(templates)
+	if err != nil {
+		v.Set("provider", err.Error())
+	} else {
+		v.add(p)
+	}
+	return v
+}
+
+// Admin configures the admin api. The api is disabled when Token is empty.
+type Admin struct {
+	// Token is the bearer token required by all admin endpoints.
+	Token string `hcl:"token"`
+}
+
+// Enabled returns true if the admin api is enabled.
+func (a Admin) Enabled() bool {
+	return a.Token != ""
+}
+
+// Terms defines the policies which users must accept before using the v2 api.
+type Terms struct {
+	Policies []Policy `hcl:"policy"`
+}
+
+// Policy is a versioned policy document, available in one or more languages.
+//
+// Acceptance is recorded against the version, so bumping the version requires
+// every user to accept the policy again.
+type Policy struct {
+	Name      string           `hcl:",key"`
+	Version   string           `hcl:"version"`
+	Documents []PolicyDocument `hcl:"document"`
+}
+
+// PolicyDocument is the translation of a policy in the language Lang.
+type PolicyDocument struct {
+	Lang string `hcl:",key"`
+	Name string `hcl:"name"`
+	URL  string `hcl:"url"`
+}
+
+// Valid validates t settings.
+func (t Terms) Valid() *Validation {
+	v := &Validation{Namespace: "terms"}
+	seen := make(map[string]bool)
+	for _, p := range t.Policies {
+		pv := &Validation{Namespace: p.Name}
+		if p.Name == "" {
+			pv.Set("name", missingField)
+		}
+		if p.Version == "" {
+			pv.Set("version", missingField)
+		}
+		if len(p.Documents) == 0 {
+			pv.Set("document", missingField)
+		}
+		for _, d := range p.Documents {
+			if d.Lang == "" {
+				pv.Set("document", "missing language")
+			}
+			if d.Name == "" {
+				pv.Set(d.Lang+".name", missingField)
+			}
+			u, err := url.Parse(d.URL)
+			if err != nil || u.Scheme == "" || u.Host == "" {
+				pv.Set(d.Lang+".url", "not a valid url")
+			} else if seen[d.URL] {
+				pv.Set(d.Lang+".url", "used by more than one policy document")
+			}
+			seen[d.URL] = true
+		}
+		if !pv.IsValid() {
+			v.Children = append(v.Children, pv)
+		}
+	}
+	return v
+}
+
+// Policy returns the policy which has a document at documentURL.
+func (t Terms) Policy(documentURL string) (*Policy, bool) {
+	for i := range t.Policies {
+		for _, d := range t.Policies[i].Documents {
+			if d.URL == documentURL {
+				return &t.Policies[i], true
+			}
+		}
+	}
+	return nil, false
+}
+
 // Provider email provider settings.
 type Provider Container
 
+func (p Provider) setting(name string) string {
+	if x, ok := p.Settings[name]; ok {
+		if s, ok := x.(string); ok {
+			return s
+		}
+		return fmt.Sprint(x)
+	}
+	return ""
+}
+
 // Container abstract struct for storing namespaced settings.
 type Container struct {
 	Name     string                 `hcl:",key"`
@@ -457,32 +807,92 @@ type Container struct {
 	Settings map[string]interface{} `hcl:"settings"`
 }
 
-// SMTPEmail smtp plain auth configurations.
+// SMTPEmail smtp client configurations.
 type SMTPEmail struct {
 	Enabled  bool
 	Username string
 	Password string
 	Host     string
 	Port     int64
+
+	// TLS is one of SMTPStartTLS, SMTPImplicitTLS or SMTPNoTLS. By default
+	// STARTTLS is used when the server supports it.
+	TLS string
+
+	// Auth is one of SMTPAuthPlain, SMTPAuthLogin, SMTPAuthCRAMMD5 or
+	// SMTPAuthNone, defaults to SMTPAuthPlain.
+	Auth string
+
+	// Timeout bounds dialing the server and sending an email, like 30s.
+	Timeout string
+
+	// Pool is the number of idle connections kept open for reuse, defaults to
+	// 0 which opens a new connection for every email.
+	Pool string
+
+	// CAFile is a bundle of certificate authorities trusted in addition to the
+	// system ones.
+	CAFile string
 }
 
 func (s SMTPEmail) Valid() *Validation {
 	v := &Validation{Namespace: "smtp_provider"}
-	if s.Username == "" {
-		v.Set("username", missingField)
+	switch s.Auth {
+	case "", SMTPAuthPlain, SMTPAuthLogin, SMTPAuthCRAMMD5:
+		if s.Username == "" {
+			v.Set("username", missingField)
+		}
+		if s.Password == "" {
+			v.Set("password", missingField)
+		}
+	case SMTPAuthNone:
+	default:
+		v.Set("auth", algorithmNotSupported)
 	}
-	if s.Password == "" {
-		v.Set("password", missingField)
+	switch s.TLS {
+	case "", SMTPStartTLS, SMTPImplicitTLS, SMTPNoTLS:
+	default:
+		v.Set("tls", algorithmNotSupported)
 	}
-	if s.Password == "" {
+	if s.Host == "" {
 		v.Set("host", missingField)
 	}
 	if s.Port == 0 {
 		v.Set("port", missingField)
 	}
+	if s.Timeout != "" {
+		d, err := time.ParseDuration(s.Timeout)
+		if err != nil {
+			v.Set("timeout", err.Error())
+		} else if d <= 0 {
+			v.Set("timeout", "must be positive")
+		}
+	}
+	if s.Pool != "" {
+		n, err := strconv.Atoi(s.Pool)
+		if err != nil {
+			v.Set("pool", err.Error())
+		} else if n < 0 {
+			v.Set("pool", "must not be negative")
+		}
+	}
+	if s.CAFile != "" {
+		if _, err := loadCertPool(s.CAFile); err != nil {
+			v.Set("ca_file", err.Error())
+		}
+	}
 	return v
 }
 
+// PoolSize returns the number of idle connections kept open for reuse.
+func (s SMTPEmail) PoolSize() int {
+	n, err := strconv.Atoi(s.Pool)
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
 func (c Crypto) Key() *signedjson.Key {
 	pub, _ := signedjson.DecodeBase64(c.VerifyKey)
 	priv, _ := signedjson.DecodeBase64(c.SingingKey)
@@ -575,5 +985,21 @@ func Sample() *Matrix {
 				ResponsePage: "verify_response",
 			},
 		},
+		Msisdn: Msisdn{
+			Providers: []Provider{
+				{
+					Name:  "http",
+					State: "disabled",
+					Settings: map[string]interface{}{
+						"url":     "$SYDENT_SMS_URL",
+						"api_key": "$SYDENT_SMS_APIKEY",
+					},
+				},
+			},
+			Verification: MsisdnVerification{
+				Originator: "Matrix",
+				Template:   "sms_verification",
+			},
+		},
 	}
 }
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"bytes"
+	"context"
+)
+
+var _ SMSProvider = (*Ktext)(nil)
+var _ SMSProvider = NoopSMS{}
+
+// SMS is an interface for sending text messages to msisdn addresses.
+type SMS interface {
+	SendSMS(ctx context.Context, tmpl, originator, to string, data map[string]string) error
+}
+
+// SMSProvider is a SMS implementation which can validate its own settings.
+type SMSProvider interface {
+	Validator
+	SMS
+}
+
+// NoopSMS implements SMS interface but does not actually send the message.
+type NoopSMS struct{}
+
+// SendSMS does nothing and always returns nil.
+func (NoopSMS) SendSMS(ctx context.Context, tmpl, originator, to string, data map[string]string) error {
+	return nil
+}
+
+func (NoopSMS) Valid() *Validation {
+	return &Validation{Namespace: "noop"}
+}
+
+// SMSClient delivers rendered text messages.
+type SMSClient interface {
+	Validator
+	Send(ctx context.Context, originator, to string, body []byte) error
+}
+
+// Ktext renders text message templates and sends them using a SMSClient.
+type Ktext struct {
+	tpl    *Templates
+	client SMSClient
+}
+
+// NewSMS returns SMS which renders templates from tpl and sends them with
+// client.
+func NewSMS(client SMSClient, tpl *Templates) *Ktext {
+	return &Ktext{tpl: tpl, client: client}
+}
+
+// SendSMS renders tmpl with data and sends the result to the msisdn to. to must
+// be in E.164 format without the leading +.
+func (k *Ktext) SendSMS(ctx context.Context, tmpl, originator, to string, data map[string]string) error {
+	if ctx.Err() != nil {
+		return nil
+	}
+	if data == nil {
+		data = make(map[string]string)
+	}
+	data["to"] = to
+	data["originator"] = originator
+	var buf bytes.Buffer
+	err := k.tpl.ExecuteTemplate(&buf, tmpl, data)
+	if err != nil {
+		return err
+	}
+	return k.client.Send(ctx, originator, to, bytes.TrimSpace(buf.Bytes()))
+}
+
+func (k *Ktext) Valid() *Validation {
+	return k.client.Valid()
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+)
+
+var _ SMSClient = (*HTTPSMSClient)(nil)
+var _ SMSClient = (*FileSMSClient)(nil)
+
+// HTTPSMSClient sends text messages by posting them as json object to a http
+// endpoint of a sms gateway.
+//
+// The request body is {"from":"originator","to":"msisdn","body":"text"} and any
+// 2xx response is considered a successful delivery.
+type HTTPSMSClient struct {
+	URL    string
+	APIKey string
+	Client HTTPClient
+}
+
+// NewHTTPSMSClient returns HTTPSMSClient which uses http.DefaultClient.
+func NewHTTPSMSClient(endpoint, apiKey string) *HTTPSMSClient {
+	return &HTTPSMSClient{
+		URL:    endpoint,
+		APIKey: apiKey,
+		Client: http.DefaultClient,
+	}
+}
+
+func (h *HTTPSMSClient) Send(ctx context.Context, originator, to string, body []byte) error {
+	b, err := json.Marshal(map[string]string{
+		"from": originator,
+		"to":   to,
+		"body": string(body),
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, h.URL, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", AgentName)
+	if h.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+h.APIKey)
+	}
+	res, err := h.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	io.Copy(ioutil.Discard, res.Body)
+	if res.StatusCode/100 != 2 {
+		return fmt.Errorf("sms: gateway responded with %s", res.Status)
+	}
+	return nil
+}
+
+func (h *HTTPSMSClient) Valid() *Validation {
+	v := &Validation{Namespace: "http_sms_provider"}
+	if h.URL == "" {
+		v.Set("url", missingField)
+	} else if u, err := url.Parse(h.URL); err != nil {
+		v.Set("url", err.Error())
+	} else if u.Scheme != "http" && u.Scheme != "https" {
+		v.Set("url", "must be a http or https url")
+	}
+	return v
+}
+
+// FileSMSClient appends text messages to a file instead of sending them. This is
+// meant for development and testing.
+type FileSMSClient struct {
+	Path string
+	mu   sync.Mutex
+}
+
+func (f *FileSMSClient) Send(ctx context.Context, originator, to string, body []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	o, err := os.OpenFile(f.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(o, "From: %s\nTo: %s\n\n%s\n\n", originator, to, body)
+	if err != nil {
+		o.Close()
+		return err
+	}
+	return o.Close()
+}
+
+func (f *FileSMSClient) Valid() *Validation {
+	v := &Validation{Namespace: "file_sms_provider"}
+	if f.Path == "" {
+		v.Set("path", missingField)
+	}
+	return v
+}
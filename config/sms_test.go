@@ -0,0 +1,101 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMsisdnProvider(t *testing.T) {
+	m := &Matrix{}
+	if err := m.LoadTemplates(); err != nil {
+		t.Fatal(err)
+	}
+	tpl := m.GetTemplate()
+	t.Run("file", func(ts *testing.T) {
+		dir, err := ioutil.TempDir("", "sms")
+		if err != nil {
+			ts.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "sms.txt")
+		cfg := Msisdn{
+			Providers: []Provider{
+				{Name: "file", State: "enabled", Settings: map[string]interface{}{
+					"path": path,
+				}},
+			},
+		}
+		if v := cfg.Valid(tpl); !v.IsValid() {
+			ts.Fatal(v)
+		}
+		p, err := cfg.Provider(tpl)
+		if err != nil {
+			ts.Fatal(err)
+		}
+		err = p.SendSMS(context.Background(), "sms_verification", "Matrix", "447700900123", map[string]string{
+			"token": "123456",
+		})
+		if err != nil {
+			ts.Fatal(err)
+		}
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			ts.Fatal(err)
+		}
+		expect := "From: Matrix\nTo: 447700900123\n\nYour code is 123456\n\n"
+		if string(b) != expect {
+			ts.Errorf("expected %q got %q", expect, string(b))
+		}
+	})
+	t.Run("http", func(ts *testing.T) {
+		var got map[string]string
+		var auth string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			auth = r.Header.Get("Authorization")
+			json.NewDecoder(r.Body).Decode(&got)
+		}))
+		defer srv.Close()
+		cfg := Msisdn{
+			Providers: []Provider{
+				{Name: "http", State: "enabled", Settings: map[string]interface{}{
+					"url":     srv.URL,
+					"api_key": "secret",
+				}},
+			},
+		}
+		p, err := cfg.Provider(tpl)
+		if err != nil {
+			ts.Fatal(err)
+		}
+		err = p.SendSMS(context.Background(), "sms_verification", "Matrix", "447700900123", map[string]string{
+			"token": "123456",
+		})
+		if err != nil {
+			ts.Fatal(err)
+		}
+		if auth != "Bearer secret" {
+			ts.Errorf("expected bearer auth got %q", auth)
+		}
+		if got["to"] != "447700900123" || got["from"] != "Matrix" || got["body"] != "Your code is 123456" {
+			ts.Errorf("unexpected payload %v", got)
+		}
+	})
+	t.Run("unknown", func(ts *testing.T) {
+		cfg := Msisdn{
+			Providers: []Provider{
+				{Name: "carrier-pigeon", State: "enabled"},
+			},
+		}
+		v := cfg.Valid(tpl)
+		if v.IsValid() || !strings.Contains(v.String(), "carrier-pigeon") {
+			ts.Errorf("expected validation error got %s", v)
+		}
+	})
+}
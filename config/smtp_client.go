@@ -1,36 +1,124 @@
 package config
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"net"
 	"net/smtp"
+	"strings"
+	"sync"
+	"time"
 )
 
 var _ Client = (*SMTPClient)(nil)
 
 type Client interface {
 	Validator
-	Send(from string, to []string, msg []byte) error
+	Send(ctx context.Context, from string, to []string, msg []byte) error
 	Host() string
 }
 
+// SMTP TLS modes, see SMTPEmail.TLS. Without a mode the connection is upgraded
+// with STARTTLS when the server supports it.
+const (
+	// SMTPStartTLS upgrades the connection with STARTTLS, sending fails when
+	// the server does not support it.
+	SMTPStartTLS = "starttls"
+
+	// SMTPImplicitTLS uses TLS from the start of the connection, usually on
+	// port 465.
+	SMTPImplicitTLS = "implicit"
+
+	// SMTPNoTLS sends emails in plain text. Credentials are only sent over
+	// plain text connections to localhost.
+	SMTPNoTLS = "none"
+)
+
+// SMTP authentication mechanisms, see SMTPEmail.Auth.
+const (
+	SMTPAuthPlain   = "plain"
+	SMTPAuthLogin   = "login"
+	SMTPAuthCRAMMD5 = "cram-md5"
+	SMTPAuthNone    = "none"
+)
+
+// DefaultSMTPTimeout bounds dialing the smtp server and sending an email.
+const DefaultSMTPTimeout = 30 * time.Second
+
+// smtpIdleTimeout is how long a pooled connection may stay unused before it is
+// closed instead of being reused.
+const smtpIdleTimeout = time.Minute
+
+// SMTPClient sends emails to a smtp server. Connections are reused when
+// SMTPEmail.Pool is set, otherwise a new connection is opened for every email.
 type SMTPClient struct {
-	config SMTPEmail
-	auth   smtp.Auth
-	host   string
+	config  SMTPEmail
+	auth    smtp.Auth
+	host    string
+	tls     *tls.Config
+	timeout time.Duration
+	pool    int
+
+	mu   sync.Mutex
+	idle []*smtpConn
 }
 
 func NewSMAPCLient(cfg SMTPEmail) *SMTPClient {
-	host := fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
-	auth := smtp.PlainAuth(ApplicationName, cfg.Username, cfg.Password, cfg.Host)
-	return &SMTPClient{
-		config: cfg,
-		auth:   auth,
-		host:   host,
+	s := &SMTPClient{
+		config:  cfg,
+		host:    fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+		tls:     &tls.Config{ServerName: cfg.Host},
+		timeout: duration(cfg.Timeout, DefaultSMTPTimeout),
+		pool:    cfg.PoolSize(),
+	}
+	switch cfg.Auth {
+	case SMTPAuthNone:
+	case SMTPAuthLogin:
+		s.auth = &loginAuth{username: cfg.Username, password: cfg.Password, host: cfg.Host}
+	case SMTPAuthCRAMMD5:
+		s.auth = smtp.CRAMMD5Auth(cfg.Username, cfg.Password)
+	default:
+		s.auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
 	}
+	if cfg.CAFile != "" {
+		// errors are reported by SMTPEmail.Valid
+		if pool, err := loadCertPool(cfg.CAFile); err == nil {
+			s.tls.RootCAs = pool
+		}
+	}
+	return s
 }
 
-func (s *SMTPClient) Send(from string, to []string, msg []byte) error {
-	return smtp.SendMail(s.host, s.auth, from, to, msg)
+// Send sends msg using a pooled connection or a new one. Dialing and sending
+// are aborted when ctx is done or after SMTPEmail.Timeout.
+func (s *SMTPClient) Send(ctx context.Context, from string, to []string, msg []byte) error {
+	c, err := s.get(ctx)
+	if err != nil {
+		return s.err(ctx, err)
+	}
+	stop := c.watch(ctx, s.timeout)
+	err = c.send(from, to, msg)
+	stop()
+	if err != nil {
+		c.client.Close()
+		return s.err(ctx, err)
+	}
+	s.put(c)
+	return nil
+}
+
+// err returns the error of ctx instead of the i/o error caused by aborting the
+// connection. The connection deadline can expire right before ctx reports it.
+func (s *SMTPClient) err(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if d, ok := ctx.Deadline(); ok && !time.Now().Before(d) {
+		return context.DeadlineExceeded
+	}
+	return err
 }
 
 func (s *SMTPClient) Host() string {
@@ -40,3 +128,198 @@ func (s *SMTPClient) Host() string {
 func (s *SMTPClient) Valid() *Validation {
 	return s.config.Valid()
 }
+
+// get returns an idle connection which is still usable, or dials a new one.
+func (s *SMTPClient) get(ctx context.Context) (*smtpConn, error) {
+	for {
+		s.mu.Lock()
+		if len(s.idle) == 0 {
+			s.mu.Unlock()
+			break
+		}
+		c := s.idle[len(s.idle)-1]
+		s.idle = s.idle[:len(s.idle)-1]
+		s.mu.Unlock()
+		if time.Since(c.lastUsed) > smtpIdleTimeout {
+			c.quit(s.timeout)
+			continue
+		}
+		stop := c.watch(ctx, s.timeout)
+		err := c.client.Reset()
+		stop()
+		if err != nil {
+			c.client.Close()
+			continue
+		}
+		return c, nil
+	}
+	return s.dial(ctx)
+}
+
+// put keeps c for reuse when the pool is not full, otherwise c is closed.
+func (s *SMTPClient) put(c *smtpConn) {
+	s.mu.Lock()
+	if len(s.idle) < s.pool {
+		c.lastUsed = time.Now()
+		s.idle = append(s.idle, c)
+		s.mu.Unlock()
+		return
+	}
+	s.mu.Unlock()
+	c.quit(s.timeout)
+}
+
+func (s *SMTPClient) dial(ctx context.Context) (*smtpConn, error) {
+	d := net.Dialer{Timeout: s.timeout}
+	conn, err := d.DialContext(ctx, "tcp", s.host)
+	if err != nil {
+		return nil, err
+	}
+	c := &smtpConn{conn: conn}
+	stop := c.watch(ctx, s.timeout)
+	defer stop()
+	var text net.Conn = conn
+	if s.config.TLS == SMTPImplicitTLS {
+		tc := tls.Client(conn, s.tls)
+		if err := tc.Handshake(); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		text = tc
+	}
+	c.client, err = smtp.NewClient(text, s.config.Host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := c.setup(s); err != nil {
+		c.client.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+type smtpConn struct {
+	conn     net.Conn
+	client   *smtp.Client
+	lastUsed time.Time
+}
+
+// setup greets the server, upgrades the connection to TLS and authenticates
+// as configured in s.
+func (c *smtpConn) setup(s *SMTPClient) error {
+	if err := c.client.Hello(hostname()); err != nil {
+		return err
+	}
+	switch s.config.TLS {
+	case SMTPStartTLS:
+		if ok, _ := c.client.Extension("STARTTLS"); !ok {
+			return errors.New("smtp: server does not support STARTTLS")
+		}
+		if err := c.client.StartTLS(s.tls); err != nil {
+			return err
+		}
+	case "":
+		// like smtp.SendMail, the connection is upgraded when the server
+		// supports it.
+		if ok, _ := c.client.Extension("STARTTLS"); ok {
+			if err := c.client.StartTLS(s.tls); err != nil {
+				return err
+			}
+		}
+	}
+	if s.auth != nil {
+		if ok, _ := c.client.Extension("AUTH"); !ok {
+			return errors.New("smtp: server does not support AUTH")
+		}
+		return c.client.Auth(s.auth)
+	}
+	return nil
+}
+
+func (c *smtpConn) send(from string, to []string, msg []byte) error {
+	if err := c.client.Mail(from); err != nil {
+		return err
+	}
+	for _, addr := range to {
+		if err := c.client.Rcpt(addr); err != nil {
+			return err
+		}
+	}
+	w, err := c.client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+func (c *smtpConn) quit(timeout time.Duration) {
+	c.conn.SetDeadline(time.Now().Add(timeout))
+	if err := c.client.Quit(); err != nil {
+		c.client.Close()
+	}
+}
+
+// watch bounds the following reads and writes on c by timeout and the deadline
+// of ctx, they fail as soon as ctx is done. The returned function must be
+// called once they are done.
+func (c *smtpConn) watch(ctx context.Context, timeout time.Duration) (stop func()) {
+	deadline := time.Now().Add(timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	c.conn.SetDeadline(deadline)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		select {
+		case <-ctx.Done():
+			c.conn.SetDeadline(time.Unix(1, 0))
+		case <-done:
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+		c.conn.SetDeadline(time.Time{})
+	}
+}
+
+// loginAuth implements the LOGIN authentication mechanism, which is not
+// provided by net/smtp. Like smtp.PlainAuth credentials are only sent over TLS
+// or to localhost.
+type loginAuth struct {
+	username, password, host string
+}
+
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS && !isLocalhost(server.Name) {
+		return "", nil, errors.New("smtp: unencrypted connection")
+	}
+	if server.Name != a.host {
+		return "", nil, errors.New("smtp: wrong host name")
+	}
+	return "LOGIN", nil, nil
+}
+
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+	switch strings.ToLower(strings.TrimSpace(string(fromServer))) {
+	case "username:":
+		return []byte(a.username), nil
+	case "password:":
+		return []byte(a.password), nil
+	}
+	return nil, fmt.Errorf("smtp: unexpected LOGIN challenge %q", fromServer)
+}
+
+func isLocalhost(name string) bool {
+	return name == "localhost" || name == "127.0.0.1" || name == "::1"
+}
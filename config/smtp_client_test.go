@@ -0,0 +1,395 @@
+package config
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const smtpFixture = "../service/fixture/certs/replication/"
+
+// fakeSMTP is a minimal in-process smtp server.
+type fakeSMTP struct {
+	ln       net.Listener
+	tls      *tls.Config
+	implicit bool
+	starttls bool
+
+	// reject is a recipient refused with 550.
+	reject string
+
+	// stall makes the server accept connections without ever replying.
+	stall bool
+
+	mu       sync.Mutex
+	conns    int
+	users    []string
+	messages []fakeMessage
+}
+
+type fakeMessage struct {
+	from string
+	to   []string
+	data string
+}
+
+// newFakeSMTP starts serving f on a random port of 127.0.0.1.
+func newFakeSMTP(t *testing.T, f *fakeSMTP) *fakeSMTP {
+	t.Helper()
+	cert, err := tls.LoadX509KeyPair(smtpFixture+"localhost.pem", smtpFixture+"localhost-key.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.ln = ln
+	f.tls = &tls.Config{Certificates: []tls.Certificate{cert}}
+	go f.serve()
+	return f
+}
+
+func (f *fakeSMTP) Close() {
+	f.ln.Close()
+}
+
+func (f *fakeSMTP) port() int64 {
+	return int64(f.ln.Addr().(*net.TCPAddr).Port)
+}
+
+func (f *fakeSMTP) config(tlsMode, auth string) SMTPEmail {
+	return SMTPEmail{
+		Enabled:  true,
+		Username: "sydent",
+		Password: "secret",
+		Host:     "localhost",
+		Port:     f.port(),
+		TLS:      tlsMode,
+		Auth:     auth,
+		Timeout:  "5s",
+		CAFile:   smtpFixture + "ca.pem",
+	}
+}
+
+func (f *fakeSMTP) serve() {
+	for {
+		conn, err := f.ln.Accept()
+		if err != nil {
+			return
+		}
+		f.mu.Lock()
+		f.conns++
+		f.mu.Unlock()
+		go f.handle(conn)
+	}
+}
+
+func (f *fakeSMTP) handle(conn net.Conn) {
+	defer conn.Close()
+	if f.stall {
+		time.Sleep(10 * time.Second)
+		return
+	}
+	secure := f.implicit
+	if f.implicit {
+		conn = tls.Server(conn, f.tls)
+	}
+	text := textproto.NewConn(conn)
+	text.PrintfLine("220 localhost ESMTP fake")
+	var msg fakeMessage
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		verb := strings.ToUpper(strings.Fields(line + " ")[0])
+		arg := strings.TrimSpace(line[len(verb):])
+		switch verb {
+		case "EHLO", "HELO":
+			ext := []string{"localhost"}
+			if f.starttls && !secure {
+				ext = append(ext, "STARTTLS")
+			}
+			ext = append(ext, "AUTH PLAIN LOGIN CRAM-MD5", "8BITMIME")
+			for i, e := range ext {
+				sep := "-"
+				if i == len(ext)-1 {
+					sep = " "
+				}
+				text.PrintfLine("250%s%s", sep, e)
+			}
+		case "STARTTLS":
+			text.PrintfLine("220 ready")
+			conn = tls.Server(conn, f.tls)
+			text = textproto.NewConn(conn)
+			secure = true
+		case "AUTH":
+			user, ok := f.auth(text, arg)
+			if !ok {
+				text.PrintfLine("535 authentication failed")
+				continue
+			}
+			f.mu.Lock()
+			f.users = append(f.users, user)
+			f.mu.Unlock()
+			text.PrintfLine("235 authenticated")
+		case "MAIL":
+			msg = fakeMessage{from: address(arg)}
+			text.PrintfLine("250 ok")
+		case "RCPT":
+			to := address(arg)
+			if to == f.reject {
+				text.PrintfLine("550 no such user")
+				continue
+			}
+			msg.to = append(msg.to, to)
+			text.PrintfLine("250 ok")
+		case "DATA":
+			text.PrintfLine("354 go ahead")
+			b, err := text.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			msg.data = string(b)
+			f.mu.Lock()
+			f.messages = append(f.messages, msg)
+			f.mu.Unlock()
+			text.PrintfLine("250 queued")
+		case "RSET", "NOOP":
+			msg = fakeMessage{}
+			text.PrintfLine("250 ok")
+		case "QUIT":
+			text.PrintfLine("221 bye")
+			return
+		default:
+			text.PrintfLine("502 not implemented")
+		}
+	}
+}
+
+// auth runs the authentication exchange and returns the authenticated user.
+func (f *fakeSMTP) auth(text *textproto.Conn, arg string) (string, bool) {
+	parts := strings.Fields(arg)
+	read := func(challenge string) string {
+		text.PrintfLine("334 %s", base64.StdEncoding.EncodeToString([]byte(challenge)))
+		line, _ := text.ReadLine()
+		b, _ := base64.StdEncoding.DecodeString(line)
+		return string(b)
+	}
+	switch strings.ToUpper(parts[0]) {
+	case "PLAIN":
+		var resp string
+		if len(parts) > 1 {
+			b, _ := base64.StdEncoding.DecodeString(parts[1])
+			resp = string(b)
+		} else {
+			resp = read("")
+		}
+		p := strings.Split(resp, "\x00")
+		return p[1], len(p) == 3 && p[1] == "sydent" && p[2] == "secret"
+	case "LOGIN":
+		user := read("Username:")
+		pass := read("Password:")
+		return user, user == "sydent" && pass == "secret"
+	case "CRAM-MD5":
+		challenge := "<1234.5678@localhost>"
+		p := strings.Fields(read(challenge))
+		mac := hmac.New(md5.New, []byte("secret"))
+		mac.Write([]byte(challenge))
+		return p[0], len(p) == 2 && p[0] == "sydent" && p[1] == hex.EncodeToString(mac.Sum(nil))
+	}
+	return "", false
+}
+
+func address(arg string) string {
+	i := strings.IndexByte(arg, '<')
+	j := strings.IndexByte(arg, '>')
+	if i < 0 || j < i {
+		return ""
+	}
+	return arg[i+1 : j]
+}
+
+func (f *fakeSMTP) stats() (conns int, users []string, messages []fakeMessage) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.conns, append([]string(nil), f.users...), append([]fakeMessage(nil), f.messages...)
+}
+
+func TestSMTPClient(t *testing.T) {
+	ctx := context.Background()
+	msg := []byte("Subject: hello\n\nhello\n.leading dot\n")
+	sendAll := func(ts *testing.T, c *SMTPClient, n int) {
+		ts.Helper()
+		for i := 0; i < n; i++ {
+			to := fmt.Sprintf("alice%d@example.com", i)
+			if err := c.Send(ctx, "sydent@example.org", []string{to}, msg); err != nil {
+				ts.Fatal(err)
+			}
+		}
+	}
+	t.Run("starttls", func(ts *testing.T) {
+		for _, auth := range []string{"", SMTPAuthPlain, SMTPAuthLogin, SMTPAuthCRAMMD5} {
+			srv := newFakeSMTP(ts, &fakeSMTP{starttls: true})
+			cfg := srv.config(SMTPStartTLS, auth)
+			if v := cfg.Valid(); !v.IsValid() {
+				ts.Fatal(v)
+			}
+			sendAll(ts, NewSMAPCLient(cfg), 2)
+			conns, users, messages := srv.stats()
+			srv.Close()
+			if conns != 2 {
+				ts.Errorf("%s: expected a connection per email without pool got %d", auth, conns)
+			}
+			if len(users) != 2 || users[0] != "sydent" {
+				ts.Errorf("%s: expected to authenticate as sydent got %v", auth, users)
+			}
+			if len(messages) != 2 {
+				ts.Fatalf("%s: expected 2 messages got %d", auth, len(messages))
+			}
+			m := messages[1]
+			if m.from != "sydent@example.org" || len(m.to) != 1 || m.to[0] != "alice1@example.com" {
+				ts.Errorf("%s: unexpected envelope %+v", auth, m)
+			}
+			if m.data != "Subject: hello\n\nhello\n.leading dot\n" {
+				ts.Errorf("%s: unexpected data %q", auth, m.data)
+			}
+		}
+	})
+	t.Run("implicit", func(ts *testing.T) {
+		srv := newFakeSMTP(ts, &fakeSMTP{implicit: true})
+		defer srv.Close()
+		cfg := srv.config(SMTPImplicitTLS, SMTPAuthLogin)
+		cfg.Pool = "1"
+		sendAll(ts, NewSMAPCLient(cfg), 3)
+		conns, users, messages := srv.stats()
+		if conns != 1 || len(users) != 1 {
+			ts.Errorf("expected the pooled connection to be reused got %d connections and %d logins", conns, len(users))
+		}
+		if len(messages) != 3 {
+			ts.Errorf("expected 3 messages got %d", len(messages))
+		}
+	})
+	t.Run("require starttls", func(ts *testing.T) {
+		srv := newFakeSMTP(ts, &fakeSMTP{})
+		defer srv.Close()
+		c := NewSMAPCLient(srv.config(SMTPStartTLS, SMTPAuthPlain))
+		err := c.Send(ctx, "sydent@example.org", []string{"alice@example.com"}, msg)
+		if err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+			ts.Errorf("expected STARTTLS to be required got %v", err)
+		}
+		if _, _, messages := srv.stats(); len(messages) != 0 {
+			ts.Errorf("expected nothing to be sent got %d messages", len(messages))
+		}
+	})
+	t.Run("opportunistic starttls", func(ts *testing.T) {
+		// a local relay without STARTTLS
+		srv := newFakeSMTP(ts, &fakeSMTP{})
+		defer srv.Close()
+		cfg := srv.config("", SMTPAuthNone)
+		cfg.Username, cfg.Password = "", ""
+		sendAll(ts, NewSMAPCLient(cfg), 1)
+		if _, _, messages := srv.stats(); len(messages) != 1 {
+			ts.Errorf("expected 1 message got %d", len(messages))
+		}
+
+		tlsSrv := newFakeSMTP(ts, &fakeSMTP{starttls: true})
+		defer tlsSrv.Close()
+		sendAll(ts, NewSMAPCLient(tlsSrv.config("", SMTPAuthPlain)), 1)
+		if _, users, messages := tlsSrv.stats(); len(users) != 1 || len(messages) != 1 {
+			ts.Errorf("expected to authenticate and send over STARTTLS got %d logins and %d messages", len(users), len(messages))
+		}
+	})
+	t.Run("no auth", func(ts *testing.T) {
+		srv := newFakeSMTP(ts, &fakeSMTP{})
+		defer srv.Close()
+		cfg := srv.config(SMTPNoTLS, SMTPAuthNone)
+		cfg.Username, cfg.Password = "", ""
+		if v := cfg.Valid(); !v.IsValid() {
+			ts.Fatal(v)
+		}
+		sendAll(ts, NewSMAPCLient(cfg), 1)
+		_, users, messages := srv.stats()
+		if len(users) != 0 || len(messages) != 1 {
+			ts.Errorf("expected an unauthenticated email got %v and %d messages", users, len(messages))
+		}
+	})
+	t.Run("rejected", func(ts *testing.T) {
+		srv := newFakeSMTP(ts, &fakeSMTP{starttls: true, reject: "nobody@example.com"})
+		defer srv.Close()
+		cfg := srv.config(SMTPStartTLS, SMTPAuthPlain)
+		cfg.Pool = "1"
+		c := NewSMAPCLient(cfg)
+		err := c.Send(ctx, "sydent@example.org", []string{"nobody@example.com"}, msg)
+		if e, ok := err.(*textproto.Error); !ok || e.Code != 550 {
+			ts.Errorf("expected 550 got %#v", err)
+		}
+		sendAll(ts, c, 1)
+	})
+	t.Run("timeout", func(ts *testing.T) {
+		srv := newFakeSMTP(ts, &fakeSMTP{starttls: true, stall: true})
+		defer srv.Close()
+		c := NewSMAPCLient(srv.config(SMTPStartTLS, SMTPAuthPlain))
+		tctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		err := c.Send(tctx, "sydent@example.org", []string{"alice@example.com"}, msg)
+		if err != context.DeadlineExceeded {
+			ts.Errorf("expected context.DeadlineExceeded got %v", err)
+		}
+		if d := time.Since(start); d > 2*time.Second {
+			ts.Errorf("expected send to be aborted got %v", d)
+		}
+	})
+	t.Run("valid", func(ts *testing.T) {
+		for _, cfg := range []SMTPEmail{
+			{Host: "localhost", Port: 25, Username: "u", Password: "p", TLS: "ssl"},
+			{Host: "localhost", Port: 25, Username: "u", Password: "p", Auth: "digest-md5"},
+			{Host: "localhost", Port: 25, Auth: SMTPAuthLogin},
+			{Host: "localhost", Port: 25, Auth: SMTPAuthNone, Timeout: "soon"},
+			{Host: "localhost", Port: 25, Auth: SMTPAuthNone, Pool: "-1"},
+			{Host: "localhost", Port: 25, Auth: SMTPAuthNone, CAFile: "missing.pem"},
+			{Port: 25, Auth: SMTPAuthNone},
+		} {
+			if cfg.Valid().IsValid() {
+				ts.Errorf("expected %+v to be invalid", cfg)
+			}
+		}
+	})
+}
+
+func TestSMTPProvider(t *testing.T) {
+	c, err := Email{
+		Providers: []Provider{
+			{Name: "smtp", State: "enabled", Settings: map[string]interface{}{
+				"host":    "localhost",
+				"port":    "465",
+				"tls":     SMTPImplicitTLS,
+				"auth":    SMTPAuthCRAMMD5,
+				"timeout": "10s",
+				"pool":    "4",
+			}},
+		},
+	}.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := c.(*SMTPClient)
+	if s.config.TLS != SMTPImplicitTLS || s.config.Auth != SMTPAuthCRAMMD5 || s.timeout != 10*time.Second || s.pool != 4 {
+		t.Errorf("unexpected smtp client %+v", s)
+	}
+	if s.Host() != "localhost:465" {
+		t.Errorf("unexpected host %q", s.Host())
+	}
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTerms(t *testing.T) {
+	m, err := LoadFile([]byte(`
+terms {
+  policy "privacy_policy" {
+    version = "1.2"
+
+    document "en" {
+      name = "Privacy Policy"
+      url  = "https://example.org/somewhere/privacy-1.2-en.html"
+    }
+
+    document "fr" {
+      name = "Politique de confidentialité"
+      url  = "https://example.org/somewhere/privacy-1.2-fr.html"
+    }
+  }
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := m.Terms.Valid(); !v.IsValid() {
+		t.Fatal(v)
+	}
+	if len(m.Terms.Policies) != 1 || len(m.Terms.Policies[0].Documents) != 2 {
+		t.Fatalf("unexpected terms %#v", m.Terms)
+	}
+	p, ok := m.Terms.Policy("https://example.org/somewhere/privacy-1.2-fr.html")
+	if !ok || p.Name != "privacy_policy" || p.Version != "1.2" {
+		t.Errorf("expected privacy_policy 1.2 got %#v", p)
+	}
+	if _, ok := m.Terms.Policy("https://example.org/unknown"); ok {
+		t.Error("expected unknown url to have no policy")
+	}
+
+	bad := Terms{
+		Policies: []Policy{
+			{Name: "privacy_policy", Documents: []PolicyDocument{
+				{Lang: "en", Name: "Privacy Policy", URL: "privacy.html"},
+			}},
+		},
+	}
+	v := bad.Valid()
+	if v.IsValid() || !strings.Contains(v.String(), "version") || !strings.Contains(v.String(), "en.url") {
+		t.Errorf("expected validation errors got %s", v)
+	}
+}
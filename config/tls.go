@@ -0,0 +1,155 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// ACME configures automatic certificate management for the main server, for
+// instance with Let's Encrypt.
+type ACME struct {
+	// Domains are the host names certificates are requested for. ACME is
+	// disabled when there are no domains.
+	Domains []string `hcl:"domains"`
+	Email   string   `hcl:"email"`
+
+	// CacheDir is the directory where account keys and certificates are kept
+	// between restarts.
+	CacheDir string `hcl:"cache_dir"`
+
+	// DirectoryURL is the directory of the ACME server, defaults to Let's
+	// Encrypt production.
+	DirectoryURL string `hcl:"directory_url"`
+
+	// CAFile is a bundle of certificate authorities trusted when talking to the
+	// ACME server. This is only needed for test servers like pebble.
+	CAFile string `hcl:"ca_file"`
+}
+
+// Enabled returns true if certificates are managed with ACME.
+func (a ACME) Enabled() bool {
+	return len(a.Domains) > 0
+}
+
+// Valid validates a settings.
+func (a ACME) Valid() *Validation {
+	v := &Validation{Namespace: "acme"}
+	if !a.Enabled() {
+		return v
+	}
+	if a.CacheDir == "" {
+		v.Set("cache_dir", missingField)
+	}
+	if a.CAFile != "" {
+		if _, err := loadCertPool(a.CAFile); err != nil {
+			v.Set("ca_file", err.Error())
+		}
+	}
+	return v
+}
+
+// Manager returns autocert.Manager which obtains certificates for a.Domains
+// and stores them in a.CacheDir.
+func (a ACME) Manager() (*autocert.Manager, error) {
+	if err := os.MkdirAll(a.CacheDir, 0700); err != nil {
+		return nil, err
+	}
+	client := &acme.Client{
+		DirectoryURL: a.DirectoryURL,
+		UserAgent:    AgentName,
+	}
+	if a.CAFile != "" {
+		pool, err := loadCertPool(a.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		client.HTTPClient = &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{RootCAs: pool},
+			},
+		}
+	}
+	return &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		Cache:      autocert.DirCache(a.CacheDir),
+		HostPolicy: autocert.HostWhitelist(a.Domains...),
+		Email:      a.Email,
+		Client:     client,
+	}, nil
+}
+
+func loadCertPool(file string) (*x509.CertPool, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(b) {
+		return nil, errors.New(file + notValidFile)
+	}
+	return pool, nil
+}
+
+// CertReloader serves a certificate loaded from disk, the certificate is
+// replaced by calling Reload. Safe for concurrent use.
+type CertReloader struct {
+	certFile, keyFile string
+	mu                sync.RWMutex
+	cert              *tls.Certificate
+}
+
+// NewCertReloader loads the key pair and returns CertReloader serving it.
+func NewCertReloader(certFile, keyFile string) (*CertReloader, error) {
+	r := &CertReloader{certFile: certFile, keyFile: keyFile}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload loads the key pair again. The current certificate is kept when
+// loading fails.
+func (r *CertReloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	r.cert = &cert
+	r.mu.Unlock()
+	return nil
+}
+
+// GetCertificate implements tls.Config.GetCertificate.
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// RedirectHTTPS returns a handler which redirects requests to the same url over
+// https on port.
+func RedirectHTTPS(port string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if port != "" && port != "443" {
+			host = net.JoinHostPort(host, port)
+		}
+		u := *r.URL
+		u.Scheme = "https"
+		u.Host = host
+		http.Redirect(w, r, u.String(), http.StatusMovedPermanently)
+	})
+}
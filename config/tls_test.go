@@ -0,0 +1,162 @@
+package config
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func copyFile(t *testing.T, src, dst string) {
+	b, err := ioutil.ReadFile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(dst, b, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCertReloader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	fixture := "../service/fixture/certs/replication/"
+	copyFile(t, fixture+"localhost.pem", certFile)
+	copyFile(t, fixture+"localhost-key.pem", keyFile)
+
+	r, err := NewCertReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commonName := func() string {
+		c, err := r.GetCertificate(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		x, err := x509.ParseCertificate(c.Certificate[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return x.Subject.CommonName
+	}
+	if name := commonName(); name != "localhost" {
+		t.Fatalf("expected localhost got %q", name)
+	}
+	copyFile(t, fixture+"peer.example.com.pem", certFile)
+	copyFile(t, fixture+"peer.example.com-key.pem", keyFile)
+	if name := commonName(); name != "localhost" {
+		t.Fatalf("expected certificate to change on reload only got %q", name)
+	}
+	if err := r.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if name := commonName(); name != "peer.example.com" {
+		t.Errorf("expected peer.example.com got %q", name)
+	}
+
+	// a broken key pair keeps the current certificate.
+	copyFile(t, fixture+"localhost.pem", certFile)
+	if err := r.Reload(); err == nil {
+		t.Error("expected mismatched key pair to fail")
+	}
+	if name := commonName(); name != "peer.example.com" {
+		t.Errorf("expected peer.example.com got %q", name)
+	}
+}
+
+func TestRedirectHTTPS(t *testing.T) {
+	sample := []struct {
+		port, url, location string
+	}{
+		{"443", "http://example.com/_matrix/identity/v2?a=b", "https://example.com/_matrix/identity/v2?a=b"},
+		{"8443", "http://example.com:8080/x", "https://example.com:8443/x"},
+	}
+	for _, v := range sample {
+		rec := httptest.NewRecorder()
+		RedirectHTTPS(v.port).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, v.url, nil))
+		if rec.Code != http.StatusMovedPermanently {
+			t.Errorf("expected 301 got %d", rec.Code)
+		}
+		if got := rec.Header().Get("Location"); got != v.location {
+			t.Errorf("expected %s got %s", v.location, got)
+		}
+	}
+}
+
+// TestACME obtains a certificate from a local ACME server like pebble. It only
+// runs when SYDENT_ACME_DIRECTORY is set, SYDENT_ACME_CA must point to the
+// certificate the ACME server is using for https and SYDENT_ACME_DOMAIN is the
+// domain whose tls-alpn-01 challenge is answered by this test.
+//
+// pebble validates tls-alpn-01 challenges on port 5001 by default, set
+// SYDENT_ACME_TLS_PORT when using a different port.
+func TestACME(t *testing.T) {
+	directory := os.Getenv("SYDENT_ACME_DIRECTORY")
+	if directory == "" {
+		t.Skip("SYDENT_ACME_DIRECTORY is not set")
+	}
+	domain := os.Getenv("SYDENT_ACME_DOMAIN")
+	if domain == "" {
+		domain = "localhost"
+	}
+	port := os.Getenv("SYDENT_ACME_TLS_PORT")
+	if port == "" {
+		port = "5001"
+	}
+	dir, err := ioutil.TempDir("", "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	a := ACME{
+		Domains:      []string{domain},
+		Email:        "admin@" + domain,
+		CacheDir:     dir,
+		DirectoryURL: directory,
+		CAFile:       os.Getenv("SYDENT_ACME_CA"),
+	}
+	if v := a.Valid(); !v.IsValid() {
+		t.Fatal(v)
+	}
+	m, err := a.Manager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ls, err := tls.Listen("tcp", ":"+port, m.TLSConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{Handler: http.NotFoundHandler()}
+	go srv.Serve(ls)
+	defer srv.Shutdown(context.Background())
+
+	cert, err := m.GetCertificate(&tls.ClientHelloInfo{ServerName: domain})
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := x.VerifyHostname(domain); err != nil {
+		t.Error(err)
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Error("expected certificate to be cached on disk")
+	}
+}
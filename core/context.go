@@ -3,6 +3,7 @@ package core
 import (
 	"github.com/gernest/sydent-go/config"
 	"github.com/gernest/sydent-go/logger"
+	"github.com/gernest/sydent-go/models"
 	"github.com/gernest/sydent-go/store"
 	"go.uber.org/zap"
 )
@@ -11,8 +12,40 @@ type Ctx struct {
 	Config            *config.Matrix
 	Log               logger.Logger
 	Email             config.Mail
+	SMS               config.SMS
 	Store             store.Store
 	ReplicationClient config.HTTPClient
+
+	// OnBind wakes up the worker delivering onbind notifications to
+	// homeservers.
+	OnBind Signal
+
+	// Replication wakes up the worker pushing local associations to peers.
+	Replication Signal
+
+	// Outbox wakes up the workers sending queued emails.
+	Outbox Signal
+
+	// Limits keeps the buckets of the rate limits, Store is used when it is
+	// nil. See config.RateLimit.
+	Limits store.Store
+
+	// Captcha verifies the challenges solved by clients, it is nil when
+	// challenges are disabled. See config.Captcha.
+	Captcha config.CaptchaVerifier
+
+	// Policy checks the addresses which can be validated, invited or bound
+	// against config.Matrix.AddressPolicies, all addresses are allowed when
+	// it is nil.
+	Policy *config.AddressChecker
+
+	// Canonical returns the canonical form of addresses, the Store applies it
+	// too. No provider rules are applied when it is nil.
+	Canonical *models.Canonicalizer
+
+	// Proxies are the trusted proxies the ip of clients is read through, see
+	// config.Server.TrustedProxies. No proxy is trusted when it is nil.
+	Proxies *config.Proxies
 }
 
 // Namespace returns a new Ctx with the logger namespaced to ns.
@@ -21,7 +54,16 @@ func (ctx *Ctx) Namespace(ns string) *Ctx {
 		Config:            ctx.Config,
 		Log:               ctx.Log.With(zap.Namespace(ns)),
 		Email:             ctx.Email,
+		SMS:               ctx.SMS,
 		Store:             ctx.Store,
 		ReplicationClient: ctx.ReplicationClient,
+		OnBind:            ctx.OnBind,
+		Replication:       ctx.Replication,
+		Outbox:            ctx.Outbox,
+		Limits:            ctx.Limits,
+		Captcha:           ctx.Captcha,
+		Policy:            ctx.Policy,
+		Canonical:         ctx.Canonical,
+		Proxies:           ctx.Proxies,
 	}
 }
@@ -0,0 +1,19 @@
+package core
+
+// Signal wakes up a background worker. Notifications are coalesced, so many
+// calls to Notify before the worker gets to run result in a single wake up.
+type Signal chan struct{}
+
+// NewSignal returns a Signal ready for use.
+func NewSignal() Signal {
+	return make(Signal, 1)
+}
+
+// Notify wakes up the worker listening on s. It never blocks, and it is safe to
+// call on a nil Signal.
+func (s Signal) Notify() {
+	select {
+	case s <- struct{}{}:
+	default:
+	}
+}
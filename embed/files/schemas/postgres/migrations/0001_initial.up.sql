@@ -24,8 +24,8 @@ CREATE TABLE IF NOT EXISTS peers (
     id bigserial primary key,
     name varchar(255) not null,
     port integer default null,
-    lastSentVersion integer,
-    lastPokeSucceededAt integer,
+    lastSentVersion bigint,
+    lastPokeSucceededAt bigint,
     active integer not null default 0
 );
 CREATE UNIQUE INDEX IF NOT EXISTS name on peers(name);
@@ -43,10 +43,11 @@ CREATE TABLE IF NOT EXISTS local_threepid_associations (
     id bigserial primary key,
     medium varchar(16) not null,
     address varchar(256) not null,
-    mxid varchar(256) not null,
-    ts integer not null,
-    notBefore bigint not null,
-    notAfter bigint not null
+    -- mxid, notBefore and notAfter are null for removed associations
+    mxid varchar(256),
+    ts bigint not null,
+    notBefore bigint,
+    notAfter bigint
 );
 CREATE UNIQUE INDEX IF NOT EXISTS medium_address on local_threepid_associations(medium, address);
 
@@ -79,4 +80,51 @@ CREATE TABLE IF NOT EXISTS threepid_token_auths (
     token varchar(32) not null,
     sendAttemptNumber integer not null,
     foreign key (validationSession) references threepid_validation_sessions(id)
-);
\ No newline at end of file
+);
+
+ALTER TABLE global_threepid_associations ADD COLUMN IF NOT EXISTS lookup_hash varchar(256);
+CREATE INDEX IF NOT EXISTS global_threepid_associations_lookup_hash on global_threepid_associations(lookup_hash);
+
+CREATE TABLE IF NOT EXISTS hashing_metadata (
+    lookup_pepper varchar(256) not null
+);
+
+CREATE TABLE IF NOT EXISTS accounts (
+    user_id varchar(256) primary key,
+    creation_ts bigint not null
+);
+
+CREATE TABLE IF NOT EXISTS tokens (
+    token varchar(256) primary key,
+    user_id varchar(256) not null,
+    foreign key (user_id) references accounts(user_id)
+);
+CREATE INDEX IF NOT EXISTS tokens_user_id on tokens(user_id);
+
+CREATE TABLE IF NOT EXISTS accepted_terms_urls (
+    user_id varchar(256) not null,
+    policy varchar(256) not null,
+    version varchar(256) not null,
+    url varchar(1024) not null,
+    creation_ts bigint not null,
+    primary key (user_id, url, version),
+    foreign key (user_id) references accounts(user_id)
+);
+
+CREATE TABLE IF NOT EXISTS onbind_notifications (
+    id bigserial primary key,
+    destination varchar(256) not null,
+    payload text not null,
+    attempts bigint not null default 0,
+    next_attempt_ts bigint not null,
+    creation_ts bigint not null,
+    last_error text
+);
+CREATE INDEX IF NOT EXISTS onbind_notifications_next_attempt_ts on onbind_notifications(next_attempt_ts);
+
+ALTER TABLE peers ALTER COLUMN lastSentVersion TYPE bigint;
+ALTER TABLE peers ALTER COLUMN lastPokeSucceededAt TYPE bigint;
+ALTER TABLE local_threepid_associations ALTER COLUMN ts TYPE bigint;
+ALTER TABLE local_threepid_associations ALTER COLUMN mxid DROP NOT NULL;
+ALTER TABLE local_threepid_associations ALTER COLUMN notBefore DROP NOT NULL;
+ALTER TABLE local_threepid_associations ALTER COLUMN notAfter DROP NOT NULL;
\ No newline at end of file
@@ -0,0 +1,6 @@
+-- progress of import-sydent, last_id is the highest id copied from each table
+-- of the sydent database.
+CREATE TABLE IF NOT EXISTS sydent_import (
+    table_name varchar(64) primary key,
+    last_id bigint not null
+);
@@ -0,0 +1,2 @@
+-- nothing to do, earlier versions read the timestamps as milliseconds too.
+SELECT 1;
@@ -0,0 +1,15 @@
+-- rendered emails waiting to be sent, rows are deleted once sent. Messages
+-- which can not be delivered are kept with state dead until requeued.
+CREATE TABLE IF NOT EXISTS email_outbox (
+    id bigserial primary key,
+    sender varchar(256) not null,
+    recipients text not null,
+    domain varchar(256) not null,
+    message text not null,
+    state varchar(16) not null default 'pending',
+    attempts bigint not null default 0,
+    next_attempt_ts bigint not null,
+    creation_ts bigint not null,
+    last_error text
+);
+CREATE INDEX IF NOT EXISTS email_outbox_state_next_attempt_ts on email_outbox(state, next_attempt_ts);
@@ -0,0 +1,8 @@
+-- token buckets of the rate limits, tokens is the number of requests left when
+-- the bucket was last updated.
+CREATE TABLE IF NOT EXISTS rate_limits (
+    key varchar(512) primary key,
+    tokens double precision not null,
+    updated_ts bigint not null
+);
+CREATE INDEX IF NOT EXISTS rate_limits_updated_ts on rate_limits(updated_ts);
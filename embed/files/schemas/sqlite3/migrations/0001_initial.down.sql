@@ -0,0 +1,14 @@
+DROP TABLE IF EXISTS invite_tokens;
+DROP TABLE IF EXISTS ephemeral_public_keys;
+DROP TABLE IF EXISTS peer_pubkeys;
+DROP TABLE IF EXISTS peers;
+DROP TABLE IF EXISTS invite_tokens;
+DROP TABLE IF EXISTS local_threepid_associations;
+DROP TABLE IF EXISTS global_threepid_associations;
+DROP TABLE IF EXISTS threepid_token_auths;
+DROP TABLE IF EXISTS threepid_validation_sessions;
+DROP TABLE IF EXISTS hashing_metadata;
+DROP TABLE IF EXISTS accepted_terms_urls;
+DROP TABLE IF EXISTS tokens;
+DROP TABLE IF EXISTS accounts;
+DROP TABLE IF EXISTS onbind_notifications;
\ No newline at end of file
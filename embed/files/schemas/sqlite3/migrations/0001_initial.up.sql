@@ -0,0 +1,124 @@
+CREATE TABLE IF NOT EXISTS invite_tokens (
+    id integer primary key autoincrement,
+    medium varchar(16) not null,
+    address varchar(256) not null,
+    room_id varchar(256) not null,
+    sender varchar(256) not null,
+    token varchar(256) not null,
+    received_ts bigint,
+    -- When the invite was received by us from the homeserver
+    sent_ts bigint -- When the token was sent by us to the user
+);
+CREATE INDEX IF NOT EXISTS invite_token_medium_address on invite_tokens(medium, address);
+CREATE INDEX IF NOT EXISTS invite_token_token on invite_tokens(token);
+
+CREATE TABLE IF NOT EXISTS ephemeral_public_keys(
+    id integer primary key autoincrement,
+    public_key varchar(256) not null,
+    verify_count bigint default 0,
+    persistence_ts bigint
+);
+CREATE UNIQUE INDEX IF NOT EXISTS ephemeral_public_keys_index on ephemeral_public_keys(public_key);
+
+CREATE TABLE IF NOT EXISTS peers (
+    id integer primary key autoincrement,
+    name varchar(255) not null,
+    port integer default null,
+    lastSentVersion bigint,
+    lastPokeSucceededAt bigint,
+    active integer not null default 0
+);
+CREATE UNIQUE INDEX IF NOT EXISTS name on peers(name);
+
+CREATE TABLE IF NOT EXISTS peer_pubkeys (
+    id integer primary key autoincrement,
+    peername varchar(255) not null,
+    alg varchar(16) not null,
+    key text not null,
+    foreign key (peername) references peers (name)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS peername_alg on peer_pubkeys(peername, alg);
+
+-- ids of local associations must never be reused, replication uses them to
+-- find changes. autoincrement guarantees this.
+CREATE TABLE IF NOT EXISTS local_threepid_associations (
+    id integer primary key autoincrement,
+    medium varchar(16) not null,
+    address varchar(256) not null,
+    -- mxid, notBefore and notAfter are null for removed associations
+    mxid varchar(256),
+    ts bigint not null,
+    notBefore bigint,
+    notAfter bigint
+);
+CREATE UNIQUE INDEX IF NOT EXISTS medium_address on local_threepid_associations(medium, address);
+
+CREATE TABLE IF NOT EXISTS global_threepid_associations (
+    id integer primary key autoincrement,
+    medium varchar(16) not null,
+    address varchar(256) not null,
+    mxid varchar(256) not null,
+    ts bigint not null,
+    notBefore bigint not null,
+    notAfter bigint not null,
+    originServer varchar(255) not null,
+    originId integer not null,
+    sgAssoc text not null,
+    lookup_hash varchar(256)
+);
+CREATE INDEX IF NOT EXISTS medium_lower_address on global_threepid_associations (medium, lower(address));
+CREATE UNIQUE INDEX IF NOT EXISTS originServer_originId on global_threepid_associations (originServer, originId);
+CREATE INDEX IF NOT EXISTS global_threepid_associations_lookup_hash on global_threepid_associations(lookup_hash);
+
+CREATE TABLE IF NOT EXISTS threepid_validation_sessions (
+    id integer primary key autoincrement,
+    medium varchar(16) not null,
+    address varchar(256) not null,
+    clientSecret varchar(32) not null,
+    validated int default 0,
+    mtime bigint not null
+);
+CREATE TABLE IF NOT EXISTS threepid_token_auths (
+    id integer primary key autoincrement,
+    validationSession integer not null,
+    token varchar(32) not null,
+    sendAttemptNumber integer not null,
+    foreign key (validationSession) references threepid_validation_sessions(id)
+);
+
+CREATE TABLE IF NOT EXISTS hashing_metadata (
+    lookup_pepper varchar(256) not null
+);
+
+CREATE TABLE IF NOT EXISTS accounts (
+    user_id varchar(256) primary key,
+    creation_ts bigint not null
+);
+
+CREATE TABLE IF NOT EXISTS tokens (
+    token varchar(256) primary key,
+    user_id varchar(256) not null,
+    foreign key (user_id) references accounts(user_id)
+);
+CREATE INDEX IF NOT EXISTS tokens_user_id on tokens(user_id);
+
+CREATE TABLE IF NOT EXISTS accepted_terms_urls (
+    user_id varchar(256) not null,
+    policy varchar(256) not null,
+    version varchar(256) not null,
+    url varchar(1024) not null,
+    creation_ts bigint not null,
+    primary key (user_id, url, version),
+    foreign key (user_id) references accounts(user_id)
+);
+
+CREATE TABLE IF NOT EXISTS onbind_notifications (
+    id integer primary key autoincrement,
+    destination varchar(256) not null,
+    payload text not null,
+    attempts bigint not null default 0,
+    next_attempt_ts bigint not null,
+    creation_ts bigint not null,
+    last_error text
+);
+CREATE INDEX IF NOT EXISTS onbind_notifications_next_attempt_ts on onbind_notifications(next_attempt_ts);
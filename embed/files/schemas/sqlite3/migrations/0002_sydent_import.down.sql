@@ -0,0 +1 @@
+DROP TABLE IF EXISTS sydent_import;
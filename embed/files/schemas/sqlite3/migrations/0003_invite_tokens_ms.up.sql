@@ -0,0 +1,4 @@
+-- invite token timestamps were stored in seconds by sydent and by earlier
+-- versions, they are read as milliseconds like every other timestamp.
+UPDATE invite_tokens SET received_ts = received_ts * 1000 WHERE received_ts < 100000000000;
+UPDATE invite_tokens SET sent_ts = sent_ts * 1000 WHERE sent_ts < 100000000000;
@@ -0,0 +1,21 @@
+-- log of changes to associations, invite tokens and peers. Rows are written
+-- in the transaction of the change and never deleted, before and after are
+-- json snapshots of what changed. Rows are only updated by gdpr erase, which
+-- blanks the address, mxid, ip, snapshots and actor of an erased subject.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id integer primary key autoincrement,
+    ts bigint not null,
+    action varchar(32) not null,
+    actor_kind varchar(16) not null,
+    actor text not null,
+    ip text not null,
+    medium varchar(16) not null,
+    address varchar(256) not null,
+    mxid varchar(256) not null,
+    peer varchar(255) not null,
+    before text,
+    after text
+);
+CREATE INDEX IF NOT EXISTS audit_log_ts on audit_log(ts);
+CREATE INDEX IF NOT EXISTS audit_log_medium_address on audit_log(medium, address);
+CREATE INDEX IF NOT EXISTS audit_log_mxid on audit_log(mxid);
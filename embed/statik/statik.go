@@ -8,6 +8,6 @@ import (
 )
 
 func init() {
-	data := "PK\x03\x04\x14\x00\x08\x00\x08\x00\x11\xa0\x8fN\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x19\x00	\x00email/invite_template.emlUT\x05\x00\x01\xe2\xe2\xb4\\\xec\x17ko\xdb\xc8\xf1s\x05\xf0?L\x19\xe8\xae\x07\x90\xa2\xed\xc4\xb9\x9c,\xa9p\xf3@|\x81\x9b\x87}9\xb4E!,\xc9\x119\xf1rw\xbb\xbb\x94\xac\x04\xfe\xef\xc5,)Kv\xecKs\xfdR\xf4.B\xcc\xddy\xef\xcc\xec\xcc\xec3\xe1q\x0c\x9f>\x8dJ\xe1\xf1\xea*\x1a\xbc\xb0\xba	\x80\x85\xd5\x0d\x03\xceu\xd8z\xcd\x9bStNT\x98\x9e<\x0b\xc0\xa6\xdbR\xc9\xb8\xb36\xff\x80\x85\x0f\x08\xd7\xad\xe75\x8a\x12\xed|)d\x1b\xa4\x9f\x9e\x9c>O\xdf\xa3u\xa4\xd5\x18\xf6G{\xd1\xe0\xa9V\x1e\x95O\xcf\xd7\x06\xc7\xd0\xb4\xd2\x93\x11\xd6gBz\xb4JxZ\xe2\x11D\x83?\xe4\xbaU\xa5\xb0\xebi\xfc\xfd\xbb\xe7\xe2d\xf5\xf3\xdb\xa7\xa4\xdf>\xfe\xab\xf8\x8b<~+\xab'\x1f}\xfe\xd3\xdb\x0f\x8f\xdf\xbc\xfa1\x8e\x06\xd1 M\xbfDv[\xb5\xc7K\x9f\x19)H\x1dAQ\x0b\xeb\xd0O\x7f:\x7f\x91>\xd9\x12>#g\xb4#\x1f\xac'%I!\xebzI	\x7f\xf8\xdc\xa8\xf8\xbc%9#\xc5z\xaeD\x83WWP\x0b\x07\xa4\x96\xe4\xb1\x84\xb5n\x81\x94\xd7 \xc0j\xdd\xb0\xb3r+\x8a\x0b\xf4X\xce\x19\xb2a\xd2*\x1a\x9c\no\xe9r\x04\xe7\x1a>hR\xe0k\x84B\xab%Z'\xd8\x88\x04\x90|\x8d\x16\x0c\x15\x17 \xa0\xa3\x87B\x12*\x0f\x1c\xc1hP{o\xdc8\xcb\x9aN\x96\xb6UV\xea\xc2e\xc6j\x0e\x91\xcb\xbc]\xa7\x1d2Uz5\xaa}#A[h\x1d\x06}\x8eT%1-$\x15\x17\xd1@\x92\xba\x80\x1c\xa5^\x81\xef\x8dZ\x92\x80w\xa4=\xfc\xc9\xe2\xbfZ\xb2\xe8\xe0imu\x83	\xbc \x8b\x0b}\x99\xc0\x99X\x08K	\xd0\xeb3\x96}\xacJ\xab\xa9\xfc\x8e\xbd\xb6\xb1\xcf\x92\xf6#j2aL\xf6 cOd\x9f>\x8d\x82G8\xbf\xfe\x8c\x8d 9\x0d\x998_h\xdbZyu\xf5\x8d\xa3J\xb5VN\x83\x90\xe1\xf0\xe1\xf1px\xf0\x82\xffoO\xcb\xbby\xb7\xe5%\x95\xa8<\xf95\xaf\x85!\xfe,\xf7\xf9/\x8bJ\xb1<8<\xdc\xffa8|\xf8\xc2\xeb\x0bT\xc3\xe1\xc3gA\xe5\x05\xaa\xab\xab\xe1\xf0\xe0\xb1\xb1\xb4\x14\x1e\xe7\x17\xb8\xee\x91hjl\xd0\n9\xdf\xc1]]}s\x1d\xcc\xe9\xe6\x1c\xbc\xd9\xda\x1e@b)\xbc\xb0s>\xc3\x86j\x0b\xda\xd2v\xd9c\xaf\xc5\xdd\x91g[\xe2\xaaE\xe7\xe7\xa2(\xd0\xb9y0\x9dY>\x87\xde\xe6h\x1d\xda9\x95[\xe2\x1epM\xc7\xe1\x8a\x06\xc7\xb9n}\x9fjc\x06\x9c^\xfb\x1a\xc8\x81P\xa0\x0d*p^\xf0}-a\xa1-g<Zm\xd0\x8a\\b\x02%\x16\xa8\xbc\x15\x92\x1c\x96	X\x142\xf5\xd4pr7M\xab\xa8\x08\xd9\x1d\x0d\xf4\x12-\x9c\xbcI\xc0\xb5\xc6h\xebIUPY\xdd\x1a\xbe\xa0>\x81\x05I\x04o\x85r\x0b\xb4	,5\x15\x08B\x95\xb0\xa4\x125\x14BJRU\x12\xd4W6\x08u\xe0u4\xd0\xe1\xd6\x08c\\\x02\xb9\xa5\xb2B\x86C\x07\xbea\x04\xb8\xb5\xf3\xd8\xb8 \xb6i\x8b\x1a\x1amq\x04'\x1e\n\xa1 G\xbe(%3\x1b\xbdB\x1b\x0dN\x14\x9f\xdcCW*\x83\xfa\xf7\xfa\xe4M\xf63\xe6\xef\xce\x9f\x02\xa7\xd9\xc6\xac\x13\xf6\x8aB\x0fz\x01\xe75\xa9\xca\xdd\xd2\x9d\xf2e\x11j\xbd\xaa\xd1b4\xe0\xda\xa1\x10K\x10[\xef\xbe<?\x7f\x03\xc7oN\x82\x9bM\x9bKr,)\x98\xeb\xda\xdc\x15\x96r\xde{\x0d\xa5\xf0\x02V5I\xe7\xd9i\xc5E\x80\xd7\x18\x0dvk\n\xd4\xe4\xbc\xb6\xeb\xd16\xb4P\xe2\x82\x14\xbb\x88\xebA\xaf9	*\x8c\xd5\xeck\xd7\xc7\\\xb7\xb6@\xb0\xb8@\x8b\xaa@\xa0\xc6HlP\xf9\xde\xf7z\xb1\x91\x99\x16\xba1\xc2S.\x11\xce\xd0\xb2\xfa\x04\x9e\x86\xc2u\xbd\x80\xb3g\xaf:\xc7\x1f\x1b#7Naj*\xba\x80\xd5(\x0d\x97\xd4hPX\x14\x1eA\xe1\xeav\xfc\xb4l{\xe5\x16\xf0\xd2\xa3*\xbb:*\x8c\xc8I\x92'\xectX\x14E\xcd\xa1\xc0Kr!\xd5\xb4B\x17\xdcp^\x0bu\xe1\x92\xadG~]\x87\xd967\x8bRx,\x8fn\xf4\xb5\xd3\x87\xeb\x8f/\xe5\xe1\xdf\xfe\x8eu\xf3\xc3\xf2Q~|\xfa\xc4\xbdz^\x16\xaf\xf5{\xf5N\xbdz\x173\xb9_\x1b\x9c\xc6\xa1Wq\xa5\xee{\xdd\x97X\xef\xecu\xcc\xff\xb5\xadn\xf2\xc7R\x17l\x030\xf7,\x1aLB\xbf\x90BU\xd3\x18U<\x8b\x06\x00\x00\x13n\xfc\xfd:\xec\x9d_\xf3U\xdd\x1a_8\xc7\xc4\xb9.\xd7\xf0\xa9#l\x84\xadH\x8da\xcf\\\x1eE\x83Pm\x8c\xc5\x04\n]\xe2\x86f\xa5m\x99\xe6\x16\xc5\xc5\x18\xc2'e\xc8Q\x8f\xac\xc9c\xea\x8c(p\x0c\xc6b\xba\xb2\xc2lD=0\xa2\xba\x16\xb3\xd0\xca\xa7\x0b\xd1\x90\\\x8f\xe1\xdb\xd7\x9c\xbbgB\xb9o\x13x\x89r\x89\x9e\n\x91\xc0\xb1%!\x93\x80H\xcf\xd0\xd2\xe2h\x87\xb9\xd0R\xdb1<xt\xc8\xbf]\x8c\xa3\x8f8\x86\xfd\x03\xe37fQ\xe9\xeb1\xec\xef\xed\x0d\x87=\xc8\x88\xb2$U\x8d\xe1`\xe7\xb0\x0fH)\xb4\xd7'\xed\xb8\x1e?\xda\xa1\x18u\xf3\xd4-\x92]\xc15RU\xfb1<\xf9>p1\xe8NKsmK\xb4i\xae\xbd\xe7y\xef\x91\xb9\xe4\x8bB%<\xc0C\xfe]k\x94\xba\xd2\x1b}\x1c\xb8TH\xaa\xd4\x18,\xeb9\xda\x0d\\*q\xe1wOt\x1d\xfc,D\x7f\x93\x1a\xd9NnL8\xfe\xbby\xe2\xb9A\x00\x95\xd3\x98\xc3\x15\xef\xa0:\xb4\xbd\x05\xe9\xa0\xe5\x0c&\x99/\xef\xc6\x05i\xc1\xb5\xb7\xc5m\x89\x82\xd6B\n\xe7\xa6q\xe7\xe3\xfbh\xe1^3nR\xdce\xcc\x0d\x8a\xbb\xed\xbdA\xe2\xcb\x8dM\x1c\x85_\xb2h\xf7\xdf\x84\x9a\n\x9c-\xa61OE7'?j\xaa~\x9b\xee\x1f\xec]\x1e\xee\x8f\x8c\xaa\xe2.\x91\xa6\xf1\xfe\xc1^\xdcg\xd04>\xdc\x8fAH?\x8d\xff\xd11\xfc3\xce\xbed\xef/\x1fi\x92\xdd\xeb\xb6I\x16\"0\x0b\x15\xc6\xcc^R2\xc9\xccfw\xff\xb8\xc3\xa5\xe7W\x8d\xd7;\xcc_5fO\x04\xd4\x16\x17\x9dg\xbfv\xa8\x8egwM\xe9\x93L\xcc\xfe\xcbq;\x1a|f\xd7j\xb5\x1aUZW\x12G\x85n\xb2\"\xcc\xe3\xf1\xac\x9b\xcbYer\x1f\x13\xfaE7\xb43c<\xeb'\xf8\xde\xca{\x98\x841\xbd\"\x17\xe6\xfcx\xd6\xcd\xfb\x1dW\xe7\xcf\x15\xe6I4\xe0Q\xf0\xc6\x13\x80\xd1\x8d\xceI\xe2\xe8\xbb.\xe6;\x91\xefk\x84\xe8K\xdb\x0d\xbd\xbf?\x17\xfe\xe7\x9f\x0b\xf1\xec\xc7\xbbn\xd3\x88\xd3b'\xce9W\x85\x89\x99\xddxW\xecd\xc1\xef\xcf\x8b\xff\xcb\xe7\xc5g\x11\xfeM\xbf2v\xbc\xd1?6>\xf3O\x07\xb8\xa3u\xde3\xf7\xdc9\x13\xddj\xc2\xdb\xc6\xdb\xed\xfaql\x92\xf5\xd3\xfd\x97_\x16i\xfa\x9f\xbd\x85\x98\xee\xdf\x01\x00\x00\xff\xffPK\x07\x08\xf5^\x80\x04\xbe\x06\x00\x00m\x14\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00\x11\xa0\x8fN\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00 \x00	\x00email/invite_template_vector.emlUT\x05\x00\x01\xe2\xe2\xb4\\\xecW\xdds\xdb\xc6\x11\x7f.f\xf0?l\xe8a\xd2\xce\x10\xa4?d\xd9\xa1Hv\x14;\x8a\x95TIl)\xe9\xa4/\x9c\x03n\x01\x9cu\xb8C\xef\x16\xa4h\x8f\xfe\xf7\xce\x1e\x08~Y\xb2\xdb\xbc4\xd3\xda\x1c\x0b\xb8\xbb\xdd\xbd\xfd\xc6\xfe^\n\xc21\xbc\x7f?\x94\x82\xf0\xf66\x8e\xce\x9c\xad\xc2F\xeel\xc5\x1bW6,\xc9\xf2\xe2\x02\xbd\x17\x05&\xe7/\xc3f\xd5.\x95\xe4\xb3\xcb&}\x8b\x19\x85\x03\xdf\xbe\xcfK\x14\x12\xdd|!t\x13\xa4_\x9c_|\x9b\xfc\x8a\xce+k\xc6\xf0h\xf80\x8e^XCh(\xb9Z\xd58\x86\xaa\xd1\xa4j\xe1h$4\xa13\x82\xd4\x02O \x8e\xfe\x94\xda\xc6H\xe1V\xd3\xde\xb37\xdf\x8a\xf3\xe5\xdf_\xbfP\xf6\xf5\xf1\x8f\xe2\x1b}\xfaZ\x17\xcf\xdfQ\xfa\xcb\xeb\xb7\xc7?\xff\xf0}/\x8e\xe2(I>Evx5\xe1\x0d\x8dj-\x949\x81\xac\x14\xce#M\x7f\xb9:K\x9eo	_*_[\xaf(h\xaf\x8cV\x06\xf9\xaeWj\xc0\x0f\xb6\x1b\x0d\xdb+\x95\xaf\xb5X\xcd\x8d\xa8\xf0\xf6\x16J\xe1A\x99\x85\"\x94\xb0\xb2\x0d(C\x16\x048k+vV\xeaDv\x8d\x84r\xce;\x1d\x935q\xf4FY\x1a\xc2\x95\x85\xb7V\x19\xa0\x12!\xb3f\x81\xce\x0bV\x01j\x8d\xc2#\xe4Vk\xbb\x0c\xc7Z\x99kHQ\xdb\xe5\x905*\x89j?\x1e\x8d\x1c\xcbQ\xd5H\xd4\xf5\xe8\xc1\x88o\x19\xbd\x7f?\x0c\xb7q\xec\xfe\x8a\x95Pz\x1a\xa2<\xcf\xadk\x9c\xbe\xbd\xfd\xd2\xab\xc24NO\x83\x90~\xff\xc9i\xbf\xff\xf8\x8c\xff/0#\xeb\x86\xaa\xe2\xc5\xbc\x12\xe4\xd4\x0d\xbf*\x89\x86\x14\xad\xf8]\xd4*\x90>\xe2\xbf,)A\xf9\xf8\xe9\xd3G_\xf7\xfbO\xce\xc8^\xa3\xe9\xf7\x9f\xbc\x0c7^\xa3\xb9\xbd\xed\xf7\x1f\x1f\xd7N-\x04\xe1\xfc\x1aW\xebC\xacK\xac\xd0	=\xdf9\xbb\xbd\xfdr\xe3\xa7ig\x06/\xb6\xaa\x87-\xb1\x10$\xdc\x9cM\xe8\xa8\xb6[[\xda60n#\xee\x8e\x10n\x89\x8b\x06=\xcdE\x96\xa1\xf7\xf3\xa0:\xb3|\xb8{\xc8\xd1xts%\xb7\xc4\xeb\x8d\x0d\x1dG\x8b\xa3\x0d\xca\x830`k4\xe0m\xe32\x0e\xb9\xd6\"\xb5\xae\x8d\xb9\xa8kH\x1b\xa5	l\x9b\x11\x17\xc1\xffC\xeb\x8a8j\xd9Hp\xa1H\xc8\xad\xe3TCgkt\"\xd5,\xaa\xaa\x1a\xa32\xd1f\xb0o\xea\xda:R\xa6\x80\xc2\xd9\xa6\xe6\xb4\xa7A\x1c\xe5J#\x90\x13\xc6\xe7\xe8\x06\xb0\xb0*C\x10F\xc2BI\xb4\x90	\xad\x95)\x06Ax\xd1\xea\xe5\x81,X*\xd1\xb1\x86~\x00\xa9S\xb2@\x1fG\x9b\xfd\xbd\xcb\xc1\xaf<a\xe5\x83\xd8\xaa\xc9J\xa8\xac\xc3\x90\xb4?\xb7Ym,!P)(\x94\xccRi\x0d\x06Q\xf2=\x8dGxQ:[\xe1\x00\xce\x94\xc3\xdc\xde\x80up)r\xe1T\xe7\x97%\xa6\x03\xb0.\x8e\xd4O\x97|zj\xa4\xb3J\xf2qeS\xa5\xdb\xcb\xaeJa\xae\xfd\xa0s??\xe3\xe84\xb5\x0d\x01\xaf\xc7\xbc\xfc\xc6\xa1\xb8\x06*\x9dm\x8a\x12\x92p\x00\x82k\xce\x03\xa1\xa8\x82\xed[\xeb\x10D\xe6\xac\xf7 `\xa9$\x82\x13\xa6@\xb0\xf9~ \xe3\x88\xfd4\x84\xf3\x1c\xbc\xad0\x08\x82\n\xab\x14\x9d\x0f\x06\x86[\x96%\x87\"\xf8\xaf\xdd=\x7f\xf3b\x00\x97Zd\xd7l\xd3w\x8a\x88#\xd4\xd2*\xad\xe3Ht\xbd\xc0\x1f\xc8$\x0b\x1eE\xa5\xd1{\xbd\x82\xa5u\xd7@\xb6@\x16=l\x05\xd8<\x0f\x84%\x82SY\x89\x9e\xe2\xc8 \x05\xd2\xa0\xfen\xfc\xd6\xf1\x0dN\xfcii\xe07\xdb8\xe0\x97\x97\x82\x04$\xf0\xa3\x05k\x10|i\x1b-\xb9m\x91\xb3\x9aCy\x98\x08\x9c\x00\x92y\xd2&\x84\xbaU&\x8e4\x92\x0f\xa1w\x8di\xf9\xec\xd2\x80G\xb7`\x8b\x99\xabv\x96\x1328\xc6\xb5\x99\xd4\x86c\xa9\xa8\x0cfT\xd6\x13\x08\xb9\x10&C\x19G\x99[\xd5d\xc1	\xcaJ$ \xccJc\xb5-V \x16B\xe9P\"d\xa5X\x85\xd2\x11 1CCNh\xf5\x0e%x\xcc\x1a\x87p\xce\x15e\x90Z\xcb\xb9\xe0.\xdb:]'\x86\xf2\xc0m\xd0\xa1^\xed\x96\xf1\x983f\xdd\xc2%2U\xdd\xa4Z\xf9\x12CJ~\xa7\xe8U\x93\xc6\xd1\x9fOk\x91\x95\x08\x7fS\x19\x1a\x8f\x7fi51+\xf6e\x88`[\x88xCh\xe4\x10\xaeJ\xe5\xa1Ba\xbaL\xcc\x84\x81\xac\xf1d+\xf5\x0eC\xfa\x07\xd7\xab\xb4\xa1 `\xa3@\x90\xb2@\x17$3W\x8a\x06sE\xc0\xdf\xfd@\xe6k.\xb7m\xe0i\x05\xca\x18\xbb\x08a\x0b\xd6_\x08\x89\xac|\xdb\x82v\x1c\xb0mP\xb6f	\xeb\x1e\xd5\x11\xee\xf4\xb8.\xbf\xd8\xcc\xd6\xc1\x838\xda\xf7\xfb~\xbaH\xd4j\x81\x8e{\x96\xd8\xd1\xcc\xe6m\x16t\x8dGv\x92\xb9\xb6\xbb>\x85\x12RKm\xa6\x88\xba\xd6k\x99\x1ej\xddx\xc8\x1b\xad\x01\x8dL\xc8&\xc8\xde1!_\xd8X\xfe\x04k\x14\xce\x84.\x05\x82\xfb\x03\x9b\x1f\xacY(\xaf\x08\xbaOm\xb5i\xc7\xc3\xdf7\x86l' \x87\x9au>\xd9\x1b~.\x9e\xac\xde\xbd\xd2O\x7f\xfb\x07\x96\xd5\xd7\x8b\xa3\xf4\xf4\xe2\xb9\xff\xe1[\x99\xfdd\x7f5o\xcc\x0fozLN\xab\x1a\xa7\xbd0\xd0\x94T\xe9\xf5@\xf4)\xd6;\x07\"\xe6\xffO\xe7\xa1\xc9\x17\xd2f\xac\x030\xf7,\x8e&\xfc\x04-L1\xed\xa1\xe9\xcd\xe2\x08\x00`\xc2\xd3\xe1\xfa=\xac=\xad\xb8\x02\xb7\xcag\xde3qj\xe5\n\xde\xb7\x84\x95p\x852cxX\xdf\x9c\xc4Q\xf8n\xd6\x0e\x07mR\xafi\x96\xd6\xc9$\xe5\xae=\x86\xf0Hx\xe7d}X*\xc2\xc4\xd7\x82k\xb2v\x98,\x9d\xa8;Q\x0fjQl\xc4\xe4\xd6P\x92\x8bJ\xe9\xd5\x18\xbejK]\x18\xff\xd5\x00^\xa1^ \xa9L\x0c\xe0\xd4)\xa1\x07\xe1 \xb9D\xa7\xf2\x93\x1d\xe6\xccj\xeb\xc6\xf0\xe0\xe8)\xffvO\xbcz\x87cx\xf4\xb8\xa6N-%\xa9\x1c\xc3\xa3\x87\x0f\xfb\xfd\xf5V-\xa4T\xa6\x18\xc3\xe3\x1dc\x1f(c\xd0m,m\xb9\x8e\x8fv(\x86\xed\xd0}@\xb2+\xb8DU\x944\x86\xe7\xcf\x02\x17o\xdd\xa9ij\x9dD\x97\xa4\x96\x88A\xc1Q}\x03\xdej%\xe1\x01>\xe5\xdf\xe6Fm\x0b\xdb\xdd\xc7\x81K\x84V\x85\x19\x83\xe3{Nv\x03\x97h\xcci\xdf\xa2\x03}?r\xe9Q\xfe\x0c%|\x01\xaa\xe2\xb1E\x18\xda\x880\x96T>\x0f\xd3\xaf\x18\xc00\xb7\x96x\x12\xe9dv\xd6=;~qvz\xfc\xa1\x84M\x06\x8eB\nv\xf99\xdaI\xd0	'\xe1n\xb2R\xf8\\(9\xedq\xce\xf4v\x8e\xdacw\xb0\xd3\xee\xca\x19LF$\xef>\x0b\xd2B|\x0f\xc5m\x89\xda9N\x0b\xef\xa7\xbd\xd6q\xf7\xd1\xc2\xbdj\xecS\xdc\xa5\xcc\x1e\xc5\xdd\xfa\xee\x91\x90\xect\xe2T\xf8\x98F\xbb\xff&\xaa*\xc0\xbbl\xda;\x04*\xaa*F\xfc\x8dsF\xe8\xb0\x99\xb0\xdc$\xe0\x94\xc4WB\xebam\x8a^\x9b\xdc\xd3\xde\xf1Qo\x9d\xd4\xed\xbb\xd04\xed\xf1\xc7\xa87\xfa\x94\xe6\x1f7n2\xba\xd7\x81\x93Q\x88\xc5,4\xbcz\xf6J\x0d&\xa3\xba[\xdd\x8f#\xb8\x13\xfe.H\xb8\xc3\xbc\x81\x86;7\xae\xb3T\xac+\xdca\xfe\xa1W?\xc3\xbf?*\xfc\xeb\xcd\xbe\xbf\x0b\xe1\x0f'#\xc1\x9f\xcfm\x98\xff \x18\x11\x18\"\xc6\xd1\x7f\x05#\xeex\xe3\x03\xa8\x18\xdc\xe3\xf0\x9f\x8dr,w\"\x0e*a\xb9\\\x0e\x0bk\x0b\x8d\xc3\xccV\xa3,\x80\xc8\xde\xac\x05\x93\xec\xec\xc1}LHy\x8b4\x99\xb17[\xc3Nf	S\xf6\x9dL<c\xb6\x17\xf9\x00M{\xb3\x16\xa2\xb6\\[\x98\x1aG\x1c\x85\xfb`\xea\x8e\xbdk\xb4z\x90\x0f\x9be\xca\xbdjR\xcfv\xf0\xeb\x0e\xe9\x04\xab\xd9\x1e\x94\x9d\x8c\xb0\x9a}\xc6\xb3\xfbx\xf6\xc0a\x1f@\xdb\xcei\xff\xfb\xf8\xf6\xd0\x13[\xa8\xbb\x978\xff'x\xf7\xc0\x1b\xfb\xd0\xf7\xd0!\x9f\xf1/\xe3\xdf\xceewLN\xf7\x0c\xc0w\x0e\xc7\x073\xd8v\xeejW\xeb\xb9|2Zc\xcdO\xe3\xdc$\xf9\xf7\x909\xd3\xfd+\x00\x00\xff\xffPK\x07\x08cE\xa2\x8b\xf7\x07\x00\x00 \x19\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00\x11\xa0\x8fN\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x1f\x00	\x00email/verification_template.emlUT\x05\x00\x01\xe2\xe2\xb4\\\xecTA\x93\xd3F\x13=\x7f\xaa\xd2\x7f\xe8\xcf\x97\\$\x1br \x94\xd7\xb8j\xb3\x0b\x85Cm\xe2\x05\x03\xc5\xb1\xa5iY\xcd\x8ef\x94\x9e\x96\xbdf\x8b\xff\x9e\x1a\xc96^8$asKN\x9a\xe9\x99\xee\xd7\xfd\xe6\xe9]\xa2\xd2\x14\xee\xee\xc6\x06\x95>\x7fN\x93\x17\xe2\x9b>P\x89ob`\xe5\xfb\xad\xfa\xb8\xb9\xa2\x10pM\xf9\xe2\xb2\x0f6\xc3\x96M<{\xd3\x15\x1f\xa9\xd4)|\xf0\x9d\xc0\x15\xaa\xf0-\xbcC\xcb\x06\x95\xbd\x83\x95\xbf!\x97&W\x8b\xab\xe7\xf9;\x92\xc0\xdeM\xe1\xf1\xf8Q\x9a\\x\xa7\xe44_\xedZ\x9aB\xd3Y\xe5\x16E'h\x95\xc4\xa1\xf2\x86\xce M\xfeW\xf8\xce\x19\x94\xdd\xb3\xd1O\xaf\x9f\xe3b\xfb\xfe\xfa\x82\xfd\xf5\x93_\xf1g{~m\xd7O?i\xf1\xf6\xfa\xe3\x93\xe5\xab_Fi\x92&y\xfeg\xd7\xbe\x86V\xba\xd5Ik\x91\xdd\x19\x945J }\xf6v\xf5\"\x7f\xfa\xe5\xe2%\x87\xd6\x07\xd6\xbe{v\x96\x1dE\xac\x97d\xad\xcf\xe2\xea=A\x8d\x1b\x02\xa1\x92xC\x06\x10\x84~\xef((\xa8\x87.\x10h\xcd\x01\xa8A\xb6\x80\xc6\x08\x85\x00[\xd6\x1a\x10\x9a\x9e\xb4\xb1\x975\xb0!\xa7\xac\xbb4	$\x1b\x921,\xaa!s\x8b\x01v\xbe\x83m\xed\xa1A\xb3\xaf\xb7\xc7\xc8\xfa\xa3\x06w{$\x82\xca[\xeb\xb7\xec\xd6`\xd9\xdd\xa4\x89z(}\xd3Z\xd2\xe1|C\xc2\x15\x97\xc3\x1b\xf9*\xe6\xcb\xfd\xee\xa6q\xac\xbb\xbbq\xcc\x8f\x0f\x9d&\x8b\xfd\xb5\xd229\xed\xb1Y(\x00B\xe9\x0de}\xdd\xb8\x02\x0e\x83vn\xc8\x9df\x02\n\xb9\x1f\x14p\x8bB\x11\xb4\xc1\x9b\xd8a\xe8\xca\xfa\x0b_\x19\xb4\x960\x10\x18\x0eBk\x14sB\xdd8VK\x93\xf3\xc2w\xba\x17[\xdf\xe7^w\x1c\x00\x1d\xf8\x96\x1c\x04\xc5\xa8\x1a\x03\x95\x17`\xa7$\xbe%\xc1\xc2R\x06\x86Jr*h9\x90\xc9@\x08m\xae\xdc\xc4\xee\x9b\xa6s{Z\xd2\xc4oH`\xb1\x1c\xc3B\xa1D\x07\x05E~M|\xd1\xd6o\xe3\x99\x8b0\n\xc3\x0f\xc2n\x9d\xc1;\xbfXN\xdeS\xf1zu\x01\x81\xd7\x0e\xad\xed\xe3\x8b\xd8\x82#M\x13_\xc1\xaaf\xb7\x0e\xf7\xe1 \x07/\x80n\xb7\xadI\xa8\xe7\xcbQ/\xa4\xe3(/W\xab%\x9c/\x17\xfdLmWX\x0e\xb1\x10\xa03i\x12\xba\"\x94\xc2E\x0c\xa8\x07\x83\x8a\xb0\xad\xd9F\x05\n\x96=\xd3\xc3\x0b\xb9\x0dI\x180k\x0e\xeae7>\xe1\xd0P\xc5\x8eB\x7f\xf7\x80\x9cE\x08h\xc5o\xd8P\xd8\x13\xec;)\xa3\xe0+\x12r%\x01Gy5\xe4\xb4/\x1d\xc0W\x87\x9ay\x94\x1e*\x17\x96\xe0M\xaf\xeb\x90\xc1E\xaf\xa2\xe3\x02\xde\\\xbe\n=\xccy\xdb\xda\x03'\xf16\x97\xb1\x1b\x0f5\xd96\xd2\x92&\xa5\x10*\x81\xa3\xedW\x14\x06o\xbb=\xb8\x00\xdd*93\xcc\x8c-\x16lY\x99\x06\x0c!,\xeb(A\xba\xe5\xa0\x91\x1a\xef(\x8c\x1f\xe0\x1f\xb56\xf6\xef\xda\xc7\xec\xff\x97\xbf]\xac>,\x9fC\xcc\x9e\xa7\xc9\xec\xf8%4\xf1\xdb\x90\xe2\xb1\xe8\xa8\xd3*\x7f:\x82I<QVK\xf3\xd9d\xf8\xa6\xc9,\xe8\xae_\x14\xde\xec\xe0.M\x00\x00*\xef4\xaf\xb0a\xbb\x9b\xc2\xe8j'\x8c\x06\x96\xe2G\xd9a7\xca\xe0%\xd9\x0d)\x97\x98\xc1\xb90\xda\x0c\x02\xba\x90\x87\xe8\x10g'u\x02\x7f\xa2)<\xfe\xb1\xd5}\xb4AY\xb3\x9b\xc2\xa3\xf6\xf6,M>\xa7\xc9lrhb69L\x10\xdb\x89\xdfv>\x98\xe5l\xd2\xce\xfb\xd9\xdb\xf9?\xe3\x99ir0Mx\x98g\xa6\xc9}\xd3\x84\xef\xf0\xcc\x93\xe9f\x08\xb5P\xf5lt\xf4\xd0\xd1\xfc\xe2Pn\xc8:-8\x9b\xe0\xfc${<\x1e{\x81\xd2\xb7\xbb\xa1\xe9\xbe!v\xea\x07\xd4-\x15P\x88\xdf\x06\x92S\xcc#\xd4I\xec\xfb\\\xfb\x9b\x02\x0f2\xef4\x19\xdc\xfbX\xb5\x90A\x13\xf7\x8c\xfc\x04\xf3??\x7f\x98\x9f\x7fK\xe5\xbf\xd9\xd6\x8flL\x0en4\xd9\x1b\xed_\xb1\xfb<O\x93?\x02\x00\x00\xff\xffPK\x07\x08j\xe2\x95\x13\xf9\x03\x00\x001\x0b\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00\x11\xa0\x8fN\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00&\x00	\x00email/verification_template_vector.emlUT\x05\x00\x01\xe2\xe2\xb4\\\xb4Xos\x139\xf2~\xfds\xd5|\x87\xc6\xd4\x16\xbf\xab\xf2\xd8\xb0\x17\x02\xe78\xae\xca\x06Xr\\\x96\x85d\xb9\xe2\xde\\iF=3\xdah\xd4sR\x8f\x1dC\xedw\xbfj\x8d\x9d\xd8\xc1\xc1\xec\x91\x90\x17\xb6\xa5\xd6\xd3\xff\x1e\xfdyx\xa1\x18\xc7\xf0\xf9\xf3P+\xc6?\xfeHz\xaf<\xd5q\xa0\xf0T\xcb\xc09\xc5\x9fL\xf2\xe3\x14CP%\xa6'/\xe2`\xdd\xfd4Z\xe6\xce\xda\xecw\xccy\x0c\x1f\xa9\xf5\xf0\xde\x10\xc3\x07e\x8dVl\xc8\xc19]\xa0Kz\xa7'\xa7/\xd3\x0f\xe8\x83!7\x86'\xc3\xc7I\xef\x98\x1c\xa3\xe3\xf4|\xd1\xe0\x18\xea\xd6\xb2i\x94\xe7\x91\xb2\x8c\xde)63<\x80\xa4\xf7\x7f\x19\xb5N+\xbf8\xec?{\xffR\x9d\xcc\xff\xf9\xee\xd8\xd0\xbb\xfd_\xd4O\xf6\xe8\x9d-\x9f\x7f\xe2\xec\xb7w\xbf\xef\xff\xfa\xe6\xef\xfd\xa4\x97\xf4\xd2t\x97\xd9M\xd7\x8c\x97<j\xac2\xee\x00\xf2J\xf9\x80|\xf8\xdb\xf9\xab\xf4\xf9\xb5\xe1\x0b\x13\x1a\n\x86c\xf4\xc6Y\xe3P|\xbdFk	\xb8B\x8f\x0f\xe4\xf7Gj\xa1R3\x04\x15.PC\x1b\x80	<\x96&0z\xe0\xca\x04\xc0Z\x19\x0bJk\x8f!\xc0\xdcp\x05\xde\x10\x0fM\x0d\xa9 \x015\xe8 P\xebs\x1c$=m\x02{\x93\xb5\x8c\x1a\x94\xd3\x100o=B\xa8\x94G\x0ds\xf2\x17\xa1Q9BA>\xae\x9ec\x06\\)~\x14 k\x8de \x07\xa7\x8a\xbd\xb9\x1cJ\x80'\x05\x18\x86\xb9\n\xe0QY\xbb\x80\x05\xb50\xaf\x08j\xa5\xb1\x0b\xd0\xe3\x7fZ\x0c<\x88S\xb9r\x90[\x93_\x08\x8e\xe0\x17d-\xcd\x8d+\xc1\x1aw\x01LI/\xa7\xba\xb1\xc8\x18\xe7g\xe8Ma\xf2\xae\xf7T\x08\x88\xdf\xccy,q|\xfe<\x94\xf5B\x9f\xa4\xf7\xabE\x15\x10\x1cE\x0c\xc5]P\xc6Zp\x88ZJ\xd8\x06\x84\xe3\xcaS\x8d\x03xe<\x16t	\xe4\xe1L\x15\xca\x9bUhs\xcc\x06@>\xe9\x99\xb7g2{\xe4\xb4'\xa3e\xba\xa6\xccX\\U@\xe0\xb5\xd1\xee\x11C\xad.nK;\xa8\x02\xed\x02\xb4	\x1eK\xe5\xf5Z\xfb\"\xd0y\xa5\xdcE\x88m\x17\xce\xcbg\xd2;\xca\xa8\xe5\xb8\x07b\x9a?yT\x17\xc0\x95\xa7\xb6\xac \xed6\x87\x92\n\x06`Tu\xe4GNu\xdd:\xa9\x19\x82\xca=\x85\x00\n\xe6F#x\xe5J\x94*\xe6d\xad\xca\xc8\xc7\xb2&=\xd54a\x08'\x05\x04\xaa1\x02A\x8du\x86>\xc4JE/\xf3\xcaX\x04\x12nv\xa3'\xef\x8f\x07pf\x95\xf4\xd2\xc3\xcf\x86\x19\xfd`ik\xacMz1.\xa9d\xb8\x81\xc9\x04\x01Um1\x04\xbb\x88\x9c\x03\xa6\x12\x05z\xd8\x01PQD\xc3\n\xc1\x9b\xbc\xc2\xc0I\xcf!G\xd3\x18\xfe*CaE\xe6\x8d.1\xc4\"\xbe\x9d\xbb\xee\xd8\x90//\x14+H\xe1\x17\x02rBqj\xad\x86\x9c\x1c{\xb2\x1d\x916\x81d?hY\x93\xb5\x913]0I\xcf\"\x87\xd8F\xdf\xban\x1d\xcd\x1d\x04\xf43\xc9XV5\x9efFc,\x8c\x0fq\xa8kG\xdc\x8f\x92FM\x81A\xe9\x99r9\xea\xa4\x97\xfbE#[Yq^!\x03c^9\xb2T.@\xcd\x94\xb1*\xb3\x08LZ-\xe2FT\xa01G\xc7^Y\xf3	\xaf6\xed\x89\x93\x83\x0d\xb9\xcb\\\xb6\xf9Y\xdc\xe6+b\x08\xbd\x1c\x1b/\xb4[;\x05\xc6\xc2\x98\x18TN\x1a\xc5\xaai3kB\x85\x91\xdb?\x1b~\xddfI\xef\xff\x8f\x1a\x95W\x08\xff09\xba\x80\x7f\xe9\"q\x0b\xa9e\xec \xc6<\xf1\x92\xd1\xe9!\x9c\x0b\x9bkTn\xc5\xc4\xb8\xd5\xdb\xc0T\x9bO\x18\xf7Q,}<{\x04\xe0*\x80\x882C\x1f\x91eU\x86\x0e\x0b\xc3 7G4\x0b\x8d\xec\xdb\xeb\xc6\xf3\x02\x8cs4\x8bm\x8b\xd9\x9f\xcaqsu4\xad\x15\xe0\xea\xd0bj\x04ayv\xad\x0c\x8d4\xab+\xce\x8a_\x92fW`9.7\xea\xbeI\x17\x8d\xd6\xc8\xf1\xe4JPk\x91Q\xd1\xb1`\xb0$\xa6^!\x87A\xd23\x8e\xb1\xf4JN\xdf\x8c\xb8c\x8aj\x1a\xbb\xc4\x0c\xd0\xd86@\xd1Z\x0b\xe8t\xca\x94\xa2T\xc7E\xbeH\xb2pN`Qy9\x83<\x82\x92\xf3A\xd2\x8f\xd9\xccL0\x0c\x15s\x13\xc6\xa3Q\xdd\xa5J\xbe\x1c\xfeo\xf7\xd8\xf5\x15\xea\xd1J\xcc\x07\x1b\xb7\xe7\xe9_\x17\x9f^\xdb\xa7\x1f\xff\x85U\xfd\xb7\xd9^vt\xfa<\xbcy\xa9\xf3\xb7\xf4\xc1\xbdwo\xde\xf7\xc5\x9c\x17\x0d\x1e\xf6\xe3\x8dXqm\x977\xea\xae\xa5[oTY\xffg/\xd4\xc9\x03M\xb9\xc4\x00\xb2z\x9a\xf4&\xf2	V\xb9\xf2\xb0\x8f\xae?Mz\x00\x00\x93\n\x95^~\x8f\xbf\x03/d\x07^\x07\x9f\x87 \xc6\x19\xe9\x05|\xee\x0ck\xe5K\xe3\xc6\xf0\xb8\xb9<Hz\xf1\xe6i<\x0e:R/m\xe6\xe4u\x9a\xc9\xa9=\x86\xf8\x91\xca\xc8\xc1r\xb22\x8ci\xbco\xc7\xd0xL\xe7^5+\xa8\x87\x8d*\xaf`\nr\x9c\x16\xaa6v1\x86G\xddVW.<\x1a\xc0k\xb43d\x93\xab\x01\x1cy\xa3\xec N\xa4grm\x1e\xac-\xce\xc9\x92\x1f\xc3\xc3\xbd\xa7\xf2\xb7>\x13\xcc'\x1c\xc3\x93\x1f\x1b^\x85e4Wcx\xf2\xf8\xf1\x0f?,\x87\x1a\xa5\xb5q\xe5\x18~\\K\xf6\xa1q\x0e\xfdU\xa6\xdd\xaa\xfd\xbd5\x8b\xa1\x94\xf5\x0b\x93u\xe0\nMY\xf1\x18\x9e?\x8b\xabdhk\xa4\x19y\x8d>\xcd\x88Y\x9e\x95{\xcd%\x04\xb2F\xc3C|*\x7fW\x1e-\x95\xb4\xf2'\x8dK\x955\xa5\x1b\x83\x17?\x07\xeb\x8dK-\x16\xbc\x99\xd1\x8dx\xbf\xe2t\xafx\x86\x1a\x1e\x80\xa9\x1b\xf2\xac\x1c\xaf \x92\xde\xd0\x11\x9b\xe2\xdf\xf19\xa3\x060,\x88\xe4\xb1\xa6V\xa8\xab\xfc\x9e\xed\x1f\xbf:\xda\xff\x12\xe3\x8a\x83\xa3H\xc2\x15CGk\x14\x9d\x08\x0d\xd7\xe9\xca\xf1\xc20\xfa\xb0/\xac\xe9\xafMu\xd3\xfe\xc6H7\xaa\xa70\x19\xb1\xde>\x17\xd1b\x87o\xc2]\x1bE\xaf\xb9U!\x1c\xf6\xbb\xd2\xddf\x0b\xb7\x86\xb1i\xb1-\x98\x0d\x8b\xed\xf1n\x98\xb0^\xc5$d\xf8ZD\xeb\xff&\xa6.!\xf8\xfc\xb0\xbf:?\x97\xcf\xe8\x91\xa9\xcb\xf8=\x15\xb8\xb4{\xb05\xae\xecw|>\xec\xef\xef\xf5\x97<\xee\xbe+\xcb\x87}\xb9\x7f\xfa\xa3]\xa1~=\x9b\xc9\xe8\xd6\x8aMF\xb1\xf8S!\xdc\xd6\xf9f\xba\xae%&\xa3\xe6k\xa6w/3\xb69\xbaC\xe9\xb1#\x9f\xfbP%\xdb\\\xfdY\xa5\xb2#\xec\x89\x82\xcacq\xd8\xbfR3\xfd\xe9\xf1\xcaE\x87\xb4\xeed2R\xd3\x1d\x88_(\xa1\xf8,\x92\xdc\x8d\xc7p\xcb\xb2U\x14\xab}0\x9f\xcf\x87%Qiq\x98S=\xca\xa3n\xeaO;\xfd$A\xdc\xd2\xef\xed@\xc8E'\xb8\x04\xac?]\xaa/\x81\x89o\xc4o\x06\x92WS\x17P\x88\xaa\xad?\xed\xd4[\x87t\xad\xe0\xb6C\x92\x87[U\xddnr}\xbf\xe0\xdb\xe1c\xa9\x05wXI3\xbfj\x92\xddz|4\xd35e\xb9\x8b\x96XO7\x84\xe7d\x84\xf5\xf4\xee\xd4\xe76\xb7\xf7\xa3H\xb7z\xba[\x95\xba\xcd\xc5N\xe5\xfa\x0d\x0d\xf8B\xd8\xae\x9a\xf0\xdd\xeav\x9b\xd3{P\xbc\xdb\xdc\xdc\xad\n\xfe\x96*^\x8b\xe4\x0d\x12\x7f\x9fR\xde\xe6\xf0\xee\xd5\xf36/\xf7\xa4\xa8\xbf\xa1\x92\x9b\x82\xfbf1\xbfSuo\xf3{\xd7J|\x9b\x8f{R\xe7\xdb\\\xedR\xec\xb7\xb5\xe0\xf6\x07\xfb\xd6\xc7\xfc\x8d'\xe4\xf5\xb3\xb1\xfb\xb5\xd4\x11\x93\xd1R\x1d\xefV\xe6i\xfam\xff\x97 v\xff\x0d\x00\x00\xff\xffPK\x07\x08D\xb2p\xa9g\x07\x00\x00\x14\x18\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00\x11\xa0\x8fN\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00#\x00	\x00email/verify_response_page_templateUT\x05\x00\x01\xe2\xe2\xb4\\\xb2Qt\xf1w\x0e\x89\x0cpU\xc8(\xc9\xcd\xb1\xe3\xe5\xb2\x81\xd3\xa9\x89) :7\xb5$Q!9#\xb1\xa88\xb5\xc4V\xa9\xb4$M\xd7BIA\x1f$S\x92Y\x92\x93jg\xa3\x0f\xa1y\xb9l\xf4az\x92\xf2S*At\x81]u\xb5^njqqbzjm\xad\x8d~\x01X\x15LV\x1fj\x15 \x00\x00\xff\xffPK\x07\x08\x9b\xcd\x93\xfdc\x00\x00\x00\x84\x00\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00\x11\xa0\x8fN\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00-\x00	\x00email/verify_response_page_template_vector_imUT\x05\x00\x01\xe2\xe2\xb4\\\x9cSM\x8b\xdb0\x10\xbd\x1b\xfc\x1f\xa6\xde\xc3\xb6\x10GI\xba)\x8b\xd7	\x94\xb6\xd0\xdb\x16\xb6\x97\x1e\x15k,\x8b\x95,#M\x12gC\xfe{\xb1#\xe7c\xfb\x01-\xc2\x1ey\xde\xbc7#\xfc\x94\xbf\xf9\xfc\xf8\xe9\xfb\x8fo_\xa0\"\xa3\x97q\x94\x9f\"r\xd1E\x83\xc4\xa1\xa8\xb8\xf3H\x8bdMez\x9f\x00\xeb\x10R\xa4q\x99\xb3c\x8c\xa3\xdc\xd3\xae\xdf\xc4\xd1\xca\x8a\x1d\xec\xe3\x08\x00\xa0\xb45\xa5%7J\xef2\xb8}l\xb0\x86'^\xfb\xdb\x11|E\xbdAR\x05\x1f\xc1G\xa7\xb8\x1e\xf5@\xfa\x84N\x95\x0f\x17\xe4\xc2j\xeb2\xb8\xb9\x9bw\xeb\x12\xf1\xea\x053\x98\xce\x1a\nY\xc3\x9dTu\x06S4\x0fqt\x88\xa3\x9b\x0d\x16d\x9d\xb6\xd2\x0e\x03\x0d5|M6\xd0\x08[J\xb9V\xb2\xce\xa0\xc0\x9a\xd0\x05\xa0\xe1B\xa8Zf0\x9d4mP4\xe8=\x978\xc8m\x95\xa0*\x83\xf7\x93c\xc5k9\x8d%\xfd\"\xd6Ow\x9e%]Y\"k2\xb8;k\x04\xa4\xa3_\x8d\x1a\xf2N\xc9\xea\xb7\x00\xd9&\x83\xf9Y\xe7\xf8N\xb7\xb8zV\x94\xae\xac\x13\xe8R\xc7\x85Z\xfb\xd3\xa9\xfa\nc_\xfe\x02\xff\x19y\xdd\xa0M}\xc5\x85\xddf0i\xda\xfe\x99\x0d\x1b'W\xfc\xedd\xd4\xaf\xf1t\xfe\xee\xba\xf5\xbf\x13\xff\x87\x13\x98\xbcx\x96\xce\xaekqrWy\xdf\xad\xab\xe3f0mZ\xb8)\x8a\x02\xbc\xd5J\x1c\x0d\x90\xb3\xc1\xe89\x1bnIg\xf8.\n\xb5\x01%\x16\xc9\xd9uI\x97VF\x82w\xc5\"a\xcc)Kce\x982\x92aK\xe8j\xae\xfbd\xdaU\x8f\xfdF&\xc05-\x92P\x98\x1c\x0d\xb6Hf\xf3\x0f	T\xd8\xfd\xf7\xf0\xd1\xdfB&\xd4\xe6\xb2spg\xdf\xb6Y\xee\xf7\xe3\x908\x1cr\xd6\\\x12\xd803\x0bW\xfeg\x00\x00\x00\xff\xffPK\x07\x08\xd7\xca\xbd\xd4\xd5\x01\x00\x00\x0c\x04\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00\x11\xa0\x8fN\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00 \x00	\x00schemas/full_identity_schema.sqlUT\x05\x00\x01\xe2\xe2\xb4\\\xe4\x96Mo\xdb<\x0c\xc7\xef\xf9\x14::@\n<O\x87\xee\xd2S\xdae@\x805\xdd\xe6t\xebMP,&\x16\"K\x86D\xbb\xcd\xb7\x1f$\xbf\xc5q\xa2x\x97^vj#\xd2\x14\xf9\xfbS\xa2\x1e\x7f.\xe6\xeb\x05Y\xcf\x1f\xbe-\xc8\xf2+Y=\xaf\xc9\xe2u\x19\xafc\"T)\x10(\xea=(K\xa2	!\x84\x08N6bg\xc1\x08&InD\xc6\xcc\x81\xec\xe10\xf3\xd6\x0c\xb8(2R2\x93\xa4\xccD\xff\x7f\x9e\x12\xa5\x91\xa8B\xca\xca\x81qn\xc0\xda\xd6\xe3\xf6n\xe0b\xb4\xce\xa8\xe0!\x17\x0b\x8a\x83	y\xf8\x9c\x83\xbb@\x02\xa2\x04N\xd1\xba\x82\x84\xc2j\xfd\xe6\x86\xfcNA\x11L\xa1\xae\x9f\xbc1\xdb\xba\x93\xcd\x81\x14\x96l\x8d\xce\xbcK\xaa3\xb0`J0Mb\xd8E\xec\x05\xab2r\xb1\x9cS\x1d\x07\xb5\xb7\x15\x16\xccdz?\xa9\xb5X\xae\xbe,^\x03Z\xd0\n3m`j\xd5\x97*\xaa\xec\xb3\x86\xf6_D\xae\xb2\x1c\x04\xf4\x7f\xa6\xf7\x93I\xa0[ O!\x03\xc3$\xcd\x8b\x8d\x14	\xdd\xc3\xc1\x8ei\x9a\xce=$X	Fl\x0f4\xd1\x85\xa3W\xf1\xe5\xb0e\x85D\xf2_\x1d\x08\x8c\x15\x16A%\xd0\x89p\xc4\xf5e\xb5\xfc\xf1r\x1e\xc2\xd9\xe4\xa9P\x1c\xde\x1d\x8d\xf3\xb5u\xff_A\x93\x03\x98q\x07H\xb1\x0c\x8e(\xdc\x9dR\xc8\xb5A\"\x14\xc2\x0eL[~g\x96\xccb\x0c\n\x7f9\x12^E\xef\xd9\x19\xbf\xeb=\xc4E\x92\x00p\xe0s\xec;\xb0\x04E	m\xf8f\xe7\x0e\xf38\x96\xbe\x06\xad\xaa\xaa#\xf7k\x04\x1e\x07\xd6A\x1dE\xc9}p\x8d\x14\x93\xbb\xc0=\xe4\x9a\x0d\xe1\x1dO\x96\xb7\xda\x80\xd8)o\x8e\x9a]\xa6\xc4\xc0\x16\x8ck+\xdbH\xe9\xd7\xc7\xe1h\xc2P\x97Q\x8d\xa5\xa9\xb6\xddc\xe6\xf2\xbd\x82I\xea\x84I\x8a\xa9\x01\xc8\x05\xa7\xccZ\x9d\x08\x86B\x7f\xe0\xe5\x9c\xbd\x87of\xb4\x83\xf6\xa9\x1b[\xe3\x038\xbc\xcd\xd9\x1d\x98\xe7[\x04sj\x1d\x87xx\x1f\x06X\x9d\xb9\x1dC\xd4wRo\xfe9\xec\x95U\x1b\xb7\x1a\xfb\xf1\x16:j\x95\xdf\x92_\xc8\xc1\xee\xe6\x8eY\xff\xbc]\x19w\xb5\xa2R\xbf\x819\xd65\xacF\xa3\xac\xff,j\xf4\x1d\xd5B\xc7\xb5\xd2\xb6\xa0\xab;\x1e\x7f6kAL\xef\x1f\x9f\x9f\x9e\x96\xebpg\xb5!K&\x05\xf7\x11\xa9\x05k?\xb4\xb3\x12)@a\x0c\x89\x01l\xfd>\xdd\x0e\xa6o\x95\"x\x8dO\xe7n\x86\"\x1b4\xd8\x91\xc0\xc1\xe2\xabw\x07+0\x1dWt\x07+\xaeX]\xe8\xba\xfe\x0bpX\x91{C\xce\x11!\xcbqUd\x1b0\x17\xe2\xf4F\xc2`\xf3\xdel\x08	\x1a	\xee\xe6\xc5\x9f\x00\x00\x00\xff\xffPK\x07\x08\xeb\x15\x1f6\xad\x02\x00\x00n\x0b\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00\x11\xa0\x8fN\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00%\x00	\x00schemas/full_identity_schema_down.sqlUT\x05\x00\x01\xe2\xe2\xb4\\\x94\xce\xb1\n\xc2@\x10\x04\xd0\xde\xaf\xb8\xff\xb0R\x8c\x10\x10\x14\x93\xc2n\xd9$\x83Y\xb2\xe6\x8e\xec%\xe0\xdf\x8bWXyD\xeby3\xcc\xe1z\xbe\xb8z\xb7?\x15\xae<\xba\xe2VVu\xe5d\\$\x82\xa2\x1f0\xdav\xf3\xd5 \xf4x`b\xa507*-\x0dx\xe6l\x00\xa67[!\xb9\xec\x97;\xea[V\x8a\xfd\x04\x04\xe9\x88\xcd|+\x1c\xc5g\x1bw\xf5\xcd\x9f\x95\x8fMW\x88\xe7\xd8\xaf\xd2\x85U\xba\xb4J\x06\xb3\xb4\xfe\n\x00\x00\xff\xffPK\x07\x08<\x05\xf4G\x87\x00\x00\x00u\x01\x00\x00PK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\x11\xa0\x8fN\xf5^\x80\x04\xbe\x06\x00\x00m\x14\x00\x00\x19\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81\x00\x00\x00\x00email/invite_template.emlUT\x05\x00\x01\xe2\xe2\xb4\\PK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\x11\xa0\x8fNcE\xa2\x8b\xf7\x07\x00\x00 \x19\x00\x00 \x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81\x0e\x07\x00\x00email/invite_template_vector.emlUT\x05\x00\x01\xe2\xe2\xb4\\PK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\x11\xa0\x8fNj\xe2\x95\x13\xf9\x03\x00\x001\x0b\x00\x00\x1f\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81\\\x0f\x00\x00email/verification_template.emlUT\x05\x00\x01\xe2\xe2\xb4\\PK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\x11\xa0\x8fND\xb2p\xa9g\x07\x00\x00\x14\x18\x00\x00&\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81\xab\x13\x00\x00email/verification_template_vector.emlUT\x05\x00\x01\xe2\xe2\xb4\\PK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\x11\xa0\x8fN\x9b\xcd\x93\xfdc\x00\x00\x00\x84\x00\x00\x00#\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81o\x1b\x00\x00email/verify_response_page_templateUT\x05\x00\x01\xe2\xe2\xb4\\PK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\x11\xa0\x8fN\xd7\xca\xbd\xd4\xd5\x01\x00\x00\x0c\x04\x00\x00-\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81,\x1c\x00\x00email/verify_response_page_template_vector_imUT\x05\x00\x01\xe2\xe2\xb4\\PK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\x11\xa0\x8fN\xeb\x15\x1f6\xad\x02\x00\x00n\x0b\x00\x00 \x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81e\x1e\x00\x00schemas/full_identity_schema.sqlUT\x05\x00\x01\xe2\xe2\xb4\\PK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\x11\xa0\x8fN<\x05\xf4G\x87\x00\x00\x00u\x01\x00\x00%\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81i!\x00\x00schemas/full_identity_schema_down.sqlUT\x05\x00\x01\xe2\xe2\xb4\\PK\x05\x06\x00\x00\x00\x00\x08\x00\x08\x00\xcb\x02\x00\x00L\"\x00\x00\x00\x00"
+	data := "PK\x03\x04\x14\x00\x08\x00\x08\x00\xc4,R]\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x1a\x00	\x00email/invite_template.tmplUT\x05\x00\x01@[\xd4j\xecWQo\xe3\xb8\x11~\xd7\xaf\x98*\xf0^\x0f\x90\xa5\xc4\xbb\xb9\xb6^\xd9E\xb0\x87`\xd3\xe2p\x8bK\xd0\xa2(\n\x83\x12\xc7\x12\xcf\x14\xc9\x1bRv\\C\xff\xbd %;\x8e\xe3\xecm\xdb\x87\x16\xed\xd1\x0f\x928\x9c\x99\x8f3\xc3\xe1\xe7\xdd\x8e\xe3R(\x84\xd8\xb6\xc5\x8fX\xba\xb8\xebv;M\x90\x0e\xdf\x8b\x1a\x19GZ\xac\x99l\x11\xe2\xbf\xe8\x16j\xb6F(\x10\x15\x08\xb5\x16\x0e98\x0de\xcdz]T\xbc\xeb\xa2\xe8\xc9\xb0\xc3G/\x89>\x8a\xc4O\xa7\x16\x957\xc8\x855\x92m\x17\x8a5\xd8uP3{0\xb7\xd5-\x08\xe540 \xad\x1b\xd8\xed\xd2\x82X\xb9B\x87|\xe1g\xf6JZE\xdf1G\xe21\x85\x07\x0d?j\xa1\xc0\xd5\x08\xa5Vk$\xcb\x9c\xd0*\x01\x14\xaeF\x02#\xca\x150\xe8\xd7C)\x05*\x07K\xd2MT;g\xec4\xcb\x9a J5U\x19\xd7\xa5\xcd\x0ci\x1f\x01\x9b9\xda\x8e{\xe1X\xe9MZ\xbbF\x82&h-\x06wV\xa8J\xe2\xb8\x94\xa2\\ER\xa8\x15\x14(\xf5\xc6G%@Z\x0b\x06?\x08\xed\xe0\xd7\x84?\xb5\x82\xd0\xc2\x87\x9at\x83	\xdc\n\xc2\xa5~L\xe0\x9e-\x19\x89\x04\xc4\xf7\xf7\xde\xf4\x8d\xe2\xa4\x05\xff::\x80#\xa1]*\x9a\x8c\x19\x93]d>\x08\xd9n\x97\xfa\xe7B\xf0\xae\xfb=6L\xc8\xd9n\xd7\x92\xfc\xa9E\xdaB\xeat\xd7\xbd\xb1\xa2R-\xc9Y03z{3\x9a\xdc\x8e&\xb7O;\x1dMn\x17\xfd\xd7hr+8*'\xdcv4\xb9eF\x8c&\xb7\xeb\xab\xd1\xe4\xd6\x9b\x18#\x9f\\__\xfdn\xf4\xf6\xd6\xe9\x15\xaa\xd1\xdbow\xbb4\xbcv\xddh\xf2\x8d!\xb1f\x0e\x17+\xdc\xf6\"456HL.\x8eD]\xf7\xe6\x90\xbdgP\x8fr\xda\xaf`k\xe6\x18-<\xf2\x17\xeb\x9ed]\xf7\xa6/\x19zi\xf2l\x95\xbd\xa9Z\xb4n\xc1\xca\x12\xad]\x04\xf8\xcf\x94^\x8a\x0f:\xadEZ\x08~f\xf9 \xf1E\x1f\xdd\x14\xbauC\x8dM\xa3}qj\xaa@X`\n\xb4A\x05\xd61\xc5\x19qXj\xf2u\x8e\xa4\x0d\x12+$&\xc0\xb1D\xe5\x88Ia\x91'@\xc8\xe4\xd8\x89\xc6\xd7t\xd3\xb4J\x94\xa1\xa8#\xbdF\x82\xbbO	\xd8\xd6\x18MN\xa8\n*\xd2\xad	\xe70\x81\xa5\x90\x08\x8e\x98\xb2K\xa4\x04\xd6Z\x94\x08LqX\x0b\x8e\x1aJ&\xa5PU\x12\xbcW\x14lZp:\xd2\xe1\xa80cl\x02\x05	^\xa1\x9f\x86~\xfa\x19\x04\xb0[\xeb\xb0\xf1\xbb\xe2\xd0\xb4e\x0d\x8d&L\xe1\xceA\xc9\x14\x14\xe8\x8fG\xe8\x0cFo\x90\xa2;\xe5\xb7\xed\xe0;\xb4\x96U\xc1\xf9\x9f\xf4\xdd\xa7\xec\xcfX\xfc\xf0\xf0\x01|\x91\xedA\xdd)\x87\xa4\xd0\x81^\xc2C-TeO\\\x8f\xfd\x11aj\xbb\xa9\x910\xf2\xddB!r`O\x91\xfd\xf8\xf0\xf0	n>\xdd\x85\x10\x9b\xb6\x90\xc2zC\x01\xacm\x0b[\x92(\xfc\xb7\xd3\xc0\x99c\xb0\xa9\x85\xb4\xceG\xac\\\x85\xf9\x1a\xa3\xe3.\x02\xb5\xb0N\xd36\xdd\xe7\x14\xfa\xb6i\xfd\xca\x83\xdb$\xd87\xa4}\x94\xed\x90l\xddR\x89@\xb8DBU\"\x88\xc6HlP\xb9!\xeaz9\x98\x1c\x97\xba1\xcc\x89B\"\xdc#y\xdf	|\x08}\xea\xf0\x02\xf7\xdf\xfe\xb1\x0f\xf9\x8d1r\xa8\x86\xb0Z\x94\x1e\x8c\x86\x1a\xa5\xf1\x0d4*	\x99CP\xb89M\x9c\x96\xed\xe0\x9a\x00\x1f\x1d*\x1evQ2\xc3\n!\x85\x13\xd8\xbb de\xed\x93\x80\x8f\xc2\x86\x12\xd3\nm\x1aE\x0f5S+\x9b\xecc\x11\x9di\xfa\xbeG\xfa\xa6\x9f\xff\x8a\xeb\xd2m\x0d\x82\x9f\x99G\xb9\x7f\x80d\xaa\x9a\xc5\xa8\xe2y\x04\x00\x90\xfb;\xa6\x7f\xf5#\xb7n\xeb\xcbwkp\x16n\x8f\xac\xb46\x9eG\x85\xe6[\xd8\x85e\x0d\xa3J\xa8)\\\x9a\xc7\xf7Q\x17E\x860\x81Rs\x1c\xe4\x1bM|\\\x10\xb2\xd5\x14\xc2c\xecg\xde\x07\xddM-\x1c\x8e\xada%N\xc1\x10\x8e7\xc4L\xb0raX\xb5\xb7\xb0\xd4\xca\x8d\x97\xac\x11r;\x85\xaf\xbe\xf7\xe7\xf6\x9e)\xfbU\x02\x1fQ\xae\xd1\x89\x92%pC\x82I\xdf\xbc\x95\x1d\xdf#\x89e\xef!\xe8\x96Zj\x9a\xc2\xc5\xbbk\xff;\x12X\xf1w\x9c\xc2\xd5\xc4\xb8\x01\x8f\xe0\xae\x9e\xc2\xd5\xe5\xe5\xa8\x9f0\x8cs\xa1\xaa)L\xf6\xdb\xbb\x10J!\xc1\xeex\xfd7\xef\xf6\xd2\xd4\x87\xefT\xfcd\xaeFQ\xd5n\n\xbf\xfd\x8d_\xefg\xceA+4q\xa4q\xa1\x9d\xd3\xcd\x14\xde\x99G\xb0Z\n\x0e\x17x\xed\x7f\xbd'\xa9+=\xf8\xf1\x89\x193)*5\x05\xf2\x1e\xde\x1fef,q\xe9\x9e6p\xc8l\x16R;d={J{\xees{T\x01\xcewC\x10|\x16\xfb\x94\x0ce\xb2\x1f\xb9\xa3\xe7\x13~\xe4\x8e\xcf!\xcf\xdcQ\x1d\xedG\xeex0\x15\x82xb\xeb\xc4c)\x99\xb5\xb3\xd8\x03{u\xe9\xab\x10\x8eG~\x0e\xc8\xf18\x0f\xf5xx\xd8\x03\x1e\x1f\xf6\xcf\xa09\x1e\xb9h*\xb0T\xceb\x7f\xe5?g5\xa2\xa9\x86\xcf\xf1\xd5\xe4\xf2\xf1\xfa*5\xaa\x8a\xfb\x8a\x99\xc5W\x93\xcbx(\x96Y|}\x15\x03\x93n\x16\xff\xb5W\xf8[\x9c}\xde\xff\xe7\xf7\x93g\xaf\x05,\xcfB\xe4\xe7Q\x94\x9b\xf9G\x91\xe4\x99\xe9\xdf\xffsL1gP\x13.g\xf1\xbfB\x0c\xe3\xf99\xa2\x99gl\xfe\xefQ\xc6\xe8\x05\xaa\xcdf\x93VZW\x12\xd3R7Y\x19(e<\xef\xa9\xa5w\x98\xbc\xa2\x83n\xd9\xd3N\xaf\x17\xcf\x07\x0e:@<\xaf\xc3\x8c\x19\xdc\xd8@T\xe3yOX{\xa5>\x96\x1b,\x92H\xd3	\x85\x05\xad\xa0\xd1\x85\x90\x98~\x1dr{Hp(\x87\x9c\x85\xc7\xf3x\x7f)\xd7\xfd\xef\xa7\xb8?\xc3l\x7f\x9e\xd0\xfe3<\xf6\xcb\xe8\xeb)k\x8d\xe7\x7f8\xf7\xbf)\xf5\xa9\xdd'\xab\xa0\xb9?\x9e\xcf\xd8\xed 2\xf3_H\xee\xff\x1a\xc9=I\xed\xff1\xd7=Db\xa0\xbc'\x91	\x9f/\xb8\xc1\xd9\x9b\xf0\x15~\xf2\xfcb<\\\x86\xfe+\xcfzR\x94g\xfe^\x99G\xbb\x1d*\xdeu\xd1?\x06\x00PK\x07\x08\x05\xcf\xc5o\xff\x05\x00\x00\xb3\x11\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00\xc4,R]\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00!\x00	\x00email/invite_template_vector.tmplUT\x05\x00\x01@[\xd4j\xecW\xdd\x93\xdb\xb6\x11\x7f\xc7_\xb1\x91\xe7\x9cvF\xa2\xec\xf3W+\xf3\xd4\xb9:s\xb1;M\xd3\x89\xfd\x92'\x0dH,I\xe4@,\x03,\xa5\x935\xfa\xdf;\x0bJ:\xe9>\x9c\xe6\xa1\xd3LS\xe8\x81$\x80]\xec\xc7\x0f\xab\xfdm6\x06+\xeb\x11F\xb1/~\xc2\x92G\xdb\xedfC\x01\xb2\xdd\xf7\xa2Am0,\x96\xda\xf5\x08\xa3\x1f\xa9\x87F/\x11\nD\x0f\xd6/-\xa3\x01&(\x1b=\xc8\xa27\xdb\xadR\xb7\x8a\x19odE\xbd\xb7c\x99\xce\"zQhl\xec\x9c^/\xbcnq\xbb\x85F\xc7\x83\xba5\xf5`=\x13h\x08D-l6Y\x11ty\x8d\x8cf!3{!\xf2\xea\x07K\x9c\xc1'\x82\x9f\xc8z\xe0\x06\xa1$\xbf\xc4\x105[\xf2\xd09\xd4\x11\xa1\"\xe7h\x95\x96\x9d\xf5\xd7P\xa0\xa3U\xa6T\xc3\xdc\xc5\xd9t\x1aD\x8bm\xa7\xba\xeb\xa6O\xa6r\xc4t\xb3\xc9\xe4\xb9\xb0f\xbb\xfd\x0b\xb6\xda\xba\x8b\xcd\xa6\x0f\xee\xe7\x1e\xc3\x1a2\xa6\xed\xf6i\xb4\xb5\xef\x83\xbbHj\xce^\\\x9e\x9d_\x9d\x9d_-\xb1d\n\x99m\xcf\xce\xaf\x16\xad\xe6`o\xce\xce\xaf\xacA\xcf\x96\xd7g\xe7W\xba\xb3\xb2\xed\xf9\xd9\xf9\x95h\x98\xa09\x7f\xf5\xea\xf9\x9f\xcf^\\1]\xa3?{\xf1\xcdf\x93\xa5\xd7\xed\xf6\xec\xfcu\x17\xecR3.\xaeq=,a\xd7`\x8bA\xbb\xc5\xd1\xd2v\xfb\xf4\x10\x9a\x13K\x8f\x026\xec\xd0K\xcd:,\xc4\xf0{\xfbn\xd7\xb6\xdb\xa7C>\xc2}\x95\x0f\xa6\xf0i\xddc\xe4\x85.K\x8cq\x91\xcc?\x11\xba\xbf|\x90\xe9#\x86\x855\x0fl\xdf\xad\x08\xa2$\xd3`#h\x0f\xd4\xa1\x87H}(%\xdd\xce\xe9\x82\xc2\x90o\xdduP\xf4\xd61\xd0\x80\x86\xefR\xfc3\n\xb5\x1a\xa4X{\xa3\x83\x81\x8a\x82\x80\x0c\x03u\x18t\xe1DS\xdb\xf6\xde\x96I\xd3\x0cb\xdfu\x14\xd8\xfa\x1a\xea@}\x97 >V\x95u\x08\x1c\xb4\x8f\x15\x861,\xc9\x96\x08\xda\x1bXZ\x83\x04\xa5v\xce\xfaz\x9ct\xd7\x83UQ.\x08q\x83A\xec\x8bc(\x8255Fu\x98>9\x1a\xe2:2\xb6\xe2\xa9\x81\xb6/\x1bh)`\xa6\xd4?\x070{b\x04n4\xa7\x8b\xb2\xb2\xce\x81\xc7\xe1\x16\xf6\x11\xe1]\x13\xa8\xc51\\\xd9\x80\x15\xdd\x00\x05\xf8\xa8+\x1d\xec>$+,\xc6@A\xd9\xef?\xca\xe2\xa57\x81\xac\x91\xd5\x96\n\xeb\xe4\xa4O\x8d\xf6\xd7q<\x04])uYP\xcf \x1f3\xa5\xfe\x1aP_\x037\x81\xfa\xba\x81I\x9a\x06-\x17,\x02\xa3n\x93\xbb\xb7\x1e!\xe82P\x8c\xa0ae\x0dB\xd0\xbeF\xa0\xea4sJ\"\x93\xc1\x87\n\"\xb5\x98\xf4@\x8bm\x81!&\xaf\xd2!\xabF\x82\x9fB6\xcc~\xf8\xe1\xdd\x18>:]^\x8b'\xdfZf\xc9\xc9\xb0\xd7:\xa7\xf4\xfe\xda\xc7;*\x99 \xa2n\x1d\xc6\xe8\xd6\xb0\xa2p\x0dL5\x8a\xe6l\x90\xa7\xaa\x92S\xa4d\x04[6\x18Yy\xe4\xb4\x93\xaa#\xf7\xa4\xca\xec\xf2\x99)\xf5\xfd\xca\xc3\x8f\xd4\x07\x90\x97o4k\x98\xc0?\x08\xc8#\xc4\x86zg\xa4<q '\xb9\xbb\x9bwA\x91\x11\x99\xa2O\xb9\x1d,Q\x0e9\xca'\x84\xde\xcb3\x00\xad<D\x0cKqV\x84\xba@\x82\xbe\x14\x930\xe0F\x02\x18ae\xb9I.\xb4\x14\x19\xb4Yj_\xa2QeXwL\x104\x97\x0d20\x96\x8d'G\xf5\x1a\xf4R[\x97n\x03\x93\xd1\xebtK4\x18,\xd1s\xd0\xce~F\x03\x11\xcb> |\xf0\x8c\xc1#\x8b\xd7r\xb3>\x0e\xf7q\x87\x07\x1bA\xea]@\xb7>\xbe\xae3\x01\xca\xaeL\x1b\x94]]_8\x1b\x1bL\x00\xfc\xd6\xf2\xfb\xbeP\x7f\xb8\xect\xd9 \xfc\xdd\x96\xe8#\xfeq0\xc3\xaf%\x8c)s\xc3\x8d\xc3\x1bFo2\xf8\xd4\xd8\x08-j\xbf\xc7_\xa9=\x94}dj\xedg\x14\xa8\xa7\xa0\xdb\xa2\x97\x8bC\xb7\xc7K\xecp\x89!)\x16\xa1\x02=V\x96\xa1\n\xd4\xa6m\xb1\x93\x9bu\x9bp^\x83\xf5\x9e\x96)\xef\x99R\xdfi\x83b\xf8Pf\x8e\x9c\xbf-B\xd4\x89\xfc\xae\x0e\xed7\x1e\xd5\xb1=\xaa\xc4\xc7!\xb4cu\x1a\xf0S\x94\x18tv\x89A\xea\x92>2\x8b\xaa!\xfb\xfb\xeab\xf6\x8a\xe3X\xedk\x11\x1a(\x88\xe5h#\x95\xc8\xed\x80\x17\xa1s}\x84\xaaw\x0e\xd0\x9b	\xd3\x04%2>\xe1\xc4\x92O\x7f\xb0\x0eu\xf0\xa9\x14\x81\x96r\xa0v\xae,m\xb4\x0c\xfb\x7f\xd2\xf6Po3\xf5@3\xd0p\xeb\xa4\x19\xc8\xbf2T\xf2\xbaC\x90\x99\xb9\xca\xe5\x01N\xfb\xfab\x84~4W\x00\x00\xb9\xf4\x1e\xc3\xab\x8c<\xf2Zj\xef\xba\xc3\x8b\xd4UL\xcb\x18GsU\x90Y\xc3&mku\xa8\xad\x9f\xc1\xb3\xee\xe6\xad\xda*\xd5\x05\x1c\x0f\xa9\x1e\xd6W\x14\xcc\xa4\x90\xfa5\x83\xf4\x98\xc8\xcc\xdba\xad\xb1\x8c\x93\xd8\xe9\x12g\xd0\x05\x9c\xac\x82\xee\x92\x96'\x9d\xae\xf7\x1a*\xf2<\xa9tk\xddz\x06_\x0f\xc0\xd7>~=\x86\xf7\xe8\x96\xc8\xb6\xd4c\xb8\x0cV\xbbqZ\x98|\xc4`\xab\xe1\x84$[\x92\xa30\x83'/_\xc9\xefh!\xda\xcf8\x83\xe7\xe7\x1d\xef\xec\xb1\x86\x9b\x19<\x7f\xf6\xecl\x98\xe8\xb41\xd6\xd738\xdf\xbb\xf7\xc4z\x8f\x016\xc7\xfb_\xbf\xdc\xaff\x12\xbe\xbb\xcb\xb7\xea\x1a\xb4u\xc33\xf8\xd3\x1b\xd9/3\x0f\x99VP0\x18&\x051S;\x83\x97\xdd\x0dDr\xd6\xc0\x13|%\xbf\xe1$G5\xed\xce\x91\xc4L\xb4\xb3\xb5\x9fA\x90\x13\xde\x1eef\xe2\xb0\xe2#\x07NM\xfc\xc2Y/\xab7h\xe0+\xb0\xad\xfc'k\xcf\x83\xb8'\xb6\xd5\"\xb5tz\x0cYE\xc4\xf2'\x0b\x9b\x13\x7f\xde\xbc~wu\xf9\xfa\xae\xf4\x01V\xd3\x84\xab\x1d\xe4\xa6\xb7\x98\xcb\x05XG\xf0\xe3T\x17\xad\xb9\x18	\x1ev\x18\xdd\x8f\x9c\xc3\xe9\x84\x8c\x9c\xcd\x1c\xf2)\x1f\x81x?r6IU\xca\xe0\x1d]wN,\x9d\x8e\xf1b$\x86=\xba\xf5Q\x13\x8eG\xfe\x90!\xc7\xe3aS\x8f\x87\x98\xbd\xb3Gr\xfe\x05k\x8eGn\xdb\x1ab(/Fw\x1bn\xdb\xd6S)\xe3\xc1k\x97\xba\xf0\x89\xa8\x9d\xa4~{\x12[\xed\\\xd6\xf9z$\x9d\x037\x17\xa3\xd7/G;\xdc\x0e\xef\xda\xf1\xc5HJ\xeeh\xfaeK\xbe\xecY>},t\xf94\xe5`\xaeT\xde\xcd\xdf\xdbq>\xed\x86\xf7\xff\x0c\x919\xa8O\xc6\xe4:=\x9a\x80\xd5\xc5\xe8\xd7R\x95\xdf<C\xf9\x05b\xf2\xcb|\xe4\xd7\xd0\x90\x7f\x8f}\xdc%\x1d\xa3\xf9\xdf\x1eb\x95Y>\xd5su\xc8\xd5o\x83\x98\x80\x10\x13\xf5\xdf &\x87@\xdc\xe3')2\x01\x7f\xeem\xc0\xa8r}\x07\xca\xab\xd5*\xab\x89j\x87YI\xed\xb4L\xc4e4\x1f\x08\x8c\xc4x\xfc\x88\x0cr5\x90\x1b\x91\x1b\xcdwLG$\xa4\xd9{PF\xda\x9d\xe1\x98\x98\xc8\xd0h>\x90\xa2A\xe8\x96\x18)\xa1\x85\x8f\x11\xa3\x83\xa7;~t\xf8\x16Gw\x1fE\x98K\xad8bL\x87]9\xb6\xf3\x13\xf2\x94O\xb1\x9d\xff\x9fA\x1d1\xa8\x93X\xdd#S\xfbx\xfd\xaf3\xaa\xd3(\xdc\x92\xab\x13\xbc\xfc.\x18\xd6I$N\xc9\xd6\xdd`\xfc\xce\x19\x970\xae!Z\xf7\xfa\xc2\x07{\x9fGz\xd3\xd3V\xe8\xd0\xfe\xc8W>\x1d\x1a\xe2|*dm\xae6\x1b\xf4f\xbbU\xff\x1a\x00PK\x07\x08\x03jX\x178\x07\x00\x00D\x16\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00\xc4,R]\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00 \x00	\x00email/verification_template.tmplUT\x05\x00\x01@[\xd4j\xecTMo\xe36\x10\xbd\xf3W\xbc\xfa\xd2\x8b,\xb7=\x15Y\xad\x81 [ F\x11\xd4\xe8\x1a\xbb\xd8\xe3H\x1cY\xd3P\xa4JRV\\\xc3\xff\xbd\xa0$;\x9b\xa0\xa7Mo\xedI\xe2\xc7\xcc\x9by\xf3\xf8N'\xcd\xb5X\xc6\"\xf4\xe5\x1f\\\xc5\xc5\xf9\xfc\xc5\xf5\x1e\x0f\x14\xbd<\xe1\x13\x19\xd1\x14\xc5Y\xec\xdc#\xdb\xd3\x89\xad>\x9f\x95z\x0e\x8c\xfc\x94\xa2\xd4=\x1b\xe32\xa5>3\x1a:0<W,\x07\xd6 x\xfe\xb3\xe7\x10\x11\x1d\xfa\xc0\x88\x8d\x04pKb@Z{\x0e\x01\x83\xc4\x06\x84vD\xcd\x9d\xdfC4\xdb(\xf1\xa8\x02\xfb\x03\xfb\x1c\x9bz\n\x1c(\xe0\xe8z\x0c\x8dCKzN7Cd\xe3QK\xc7\x19\x88Q;c\xdc v\x0f#\xf6QE\x87\xca\xb5\x9d\xe18\x1d\x1f\xd8K-\xd5\xd4\xa2\xabS\xb8\x7fY\xdbMj6O\xc1\xa9\xef\xcd|\xa52\xc26\x8e\xb0\xe29\x80P9\xcd\xd9\x983\xfdA\x02N\xa7<&\xd2\x9e\xe3@\x9e\xed\xf7\x114\x90\xe7\x04\xd7\xd2c*-\xf4U\xf3\xccS\x86\xce0\x05\x86\x96\xe0yO^\x7fEY\xae\x94\xba-]\x1f\xe7\x11\xdd(5\xcfJ\x02\xc8\xc2ul\x11\"Y\x9d\xe2j\xe7!6\xb2w\x1d{*\x0dg\xd0\\\xb1\x8d\x9e\x8c\x04\xd6\x19<\x93YFiS\xd9m\xdb\xdb\x99\x0b\xe5\x0e\xec\xb1\xd9\xe6\xd8DTdQr\xa2T\xa7\x19vnHg6\xa1D<p\x08\xb4\x17\xbb\xcf\xf0\xc9m\xb6\xab\xcf\\\xfe\xbe\xbbC\x90\xbd%c\xc6\xfdM\xaa\xc0rT\xae\xc6\xae\x11\xbb\x0f/\xc1\xb0\x84\xf3 {\x1c\x1a\xf6<\x12eyT\xce\xb5\x91\xfb\xddn\x8b\xdb\xedf\xec\xa8\xebK#!%\x02Y\xadB_\x86\xcaK\x99\xd6\xd1AS$\x0c\x8d\x98\xa48O\xd5\xc8\xf04\x17{`\x1f&\xc8FBt\xfe\x98_\xe9\x9b\x1eB\x18o^`\xb3\x94\x1f\x9dw\x07\xd1\x1cfn]\xef\xab$\xef\x9a=\xdb\x8a!IN-\xdb8&\x0ep\xf5\x9cr\x99\x94FQJ\xc3\xf88\xaa8d\xb8\x1b\x85s\xfd\xc1\xc7\x0f\xbf\xa6\xb9i\xdcv\x9d\xb9\x081\xdd\x96*\x15\xe3\xd0\xb0\xe9\x12%\xaa\xf2L\x91ayx\xc5^p\xa6\x9f\xa1=\xf8)\xb2M\x82aT\xd4Q)F\xa2$\x81Z\x9df]5Iv\xfc$!&Z\x9c\xe5\x90\xab\x7fx\xd5MlMz\xd5\xc5w\x1f~\xbb\xdb}\xd9\xfe\x82\xb4\xb3V\xc5\xe5\xc3\xa4\xd7\xaah9\x12\xaa\x86|\xe0\xf8~\xd1\xc7z\xf9\xf3\x02\xab\xb5*\xa2D\xc3\xebb5}U\x11\xe21}K\xa7\x8f8)\x00\xa8\x9d\x8d\xcb\x9aZ1\xc7\x1b,\x1e\x8e^Hc\xeb\xdd\"\xbb\xac\x16\x19\xee\xd9\x1c8JE\x19n\xbd\x90\xc9\x10\xc8\x86eH\xaf\xf6\xdds\x9a \x7f\xf1\x0d~\xfc\xa9\x8b\xd3fK~/\xf6\x06?tO\xef\xd4Y\x15\xab\x19\xbeX\xcdu\xa7:\xd6\xaa\xe8\xd6\x93k\x15\xabn\xad\xd2\xf2\xdf1/u1/\xbc\xcd\xbc\xd4K\xf3\xc27\x98\xd7\xb5\xb3\x82\xd0x\xae\xdf/\xaef\xb6X\xdf]\x9cp\xea\xe7k/,V\xb4\xbe\xc6\xe6y\xee<*\xd7\x1d\xa7\xf6S|\xb2\x15\x97t\xe91p\x89\xd2\xbb!\xb0\x7f\xc6\xbb\xc2\\w\xbe\xcd:_\x85\xbf\xc9A\xd5\xd8g>\xa7,\xfd\xa8\x80\x17fzE\xfb\xdfS\xdf\xe0\xa9\xafY\xfc\xefZ\xeb\xcc\xc4j\xf6\x9b\xd5\xe4\x9e\xa7\x13[}>\xab\xbf\x07\x00PK\x07\x08\xf8O~\xe4W\x03\x00\x00~	\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00\xc4,R]\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00'\x00	\x00email/verification_template_vector.tmplUT\x05\x00\x01@[\xd4j\xb4Wmo\xdc\xb8\x11\xfe\xce_1QP\xa4\x05\xf6%w\xc8%\xc5F^\xc0Mq\x97\x00\xbd^q\x0e\n\xdc\xa7\x82\x12G\x12k\x8a\xa3\x92\xa3]o\x0c\xff\xf7b(\xc9\xd6\xfa\xec5\x0eqf?HKr\x9ey\xe1Cj\xe6\xfa\xda`e=B\x16\xfb\xe2\xbfXrvs\xf3\x1b\xf5\x01~\xb5\xc4\xf0o\xed\xac\xd1l\xc9\xc3g\xbaD\x7f}\x8d\xde\xdc\xdc(u\xa7\xc6x%:\xea#:G\xc0\x0d\x06|\xa1\xd4o\xd4C\xa3w\x08:^\xa2\x81>\x02\x13\x04\xacmd\x0c\xc0\x8d\x8d\x80\xad\xb6\x0e\xb41\x01c\x84\xbd\xe5\x06\x82%^\xd9\x16\x96\x82\x03\xd4\xa1\x87H}(q\xa1\x8c\x8d\x1cl\xd13\x1a\xd0\xde@\xc4\xb2\x0f\x08\xb1\xd1\x01\x0d\xec)\\\xc6N\x97\x08\x15	<\xc2\x1e\x0b\xe0F\xf3\xab\x08Eo\x1d\x03y\xf8Ys\xb0W+\xa5>U`\x19\xf6:B@\xed\xdc\x01\x0e\xd4\xc3\xbe!h\xb5\xc1\xc1\xbb\x80\xff\xeb1\xf2\"M\x95\xdaC\xe9ly)(\xe2ZE\xce\xd1\xde\xfa\x1a\x9c\xf5\x97\xc0\xa4Jj;\x87,\xda\x08;\x0c\xb6\xb2\xe5\x908\xaa\x04#\x1c\xc7\xbb\x91\x14\xaeDY\xb2\xf9/\x87:\"xJ\xfa\x9aE\x01\xf6\xd69\xf0\x88FR\xd7G\x84\x0fM\xa0\x16\x17\xf0\xa3\x0dX\xd1\x15P\x80\x0b]\xe9`'\xaf\xf6X,\x80\x82\xb2\xbf\\\xc8\xe4\xb97\x81\xac\x91\xd9\x96\n\xebp\x88\\\xb0\x8d5\xfe\x15C\xab/\x1f\x0b7\xea\n\xdd\x01\x8c\x8d\x01k\x1d\xccl\xcfVJ}n\xb4\xbf\x8c/\x94\x12\x96(\xa5\xce\x0b\xea9Qf\xa3\xd4\xdf\x02\xeaK\xe0&P_7\xb0L\xc3\xa0%a\x11\x18u\x9b\xb8PR\xdb\xf6^r\x84\xa0\xcb@1\x82\x86\xbd5\x08A\xfb\x1a%k%9\xa7\x0b\n)\x8dJw]\\\xc1\xa7\n\"\xb5\x98p\xa0\xc5\xb6\xc0\x10Sr\x92\x91}c\x1d\x02		\x87\xd1O\xbf~X\xc0\x85\xd3\xb2s\x01~\xb2\xcc\x18\x16\xe3Z\xeb\x9cJ^I\xee\xe2=H&\x88\xa8[\x871\xbaC\xa2\x170\xd5(\xc8\xabA\x9f\xaaJl\x0b\x1b\x82-\x1b\x8c\xac<rZI\xd5,<9;E\xb0\xa6\xc6\xb8R\xea\x97\xbd\x87t\xbc\xe4\xe5\xef\x9a5,\xe1\x9f\x04\xe4\x85\xca\xd4;\x03%y\x0e\xe4d#\xc2,K\x02#\xbc7\xa2S\xf4\x89\"\x83'\xca!G\xf9\x0b\xa1\xf7\xf2\x0c@{\x0f\x11\xc3N\x82\x15\xa5.\xd0\xce\x1aL9	1\x0dI\x02\xc7S'!\xb4\x14\x19\xb4\xd9i_\xa2Qe8tr^5\x97\x0d20\x96\x8d'G\xf5\x01\xf4N[\xa7\x0b\x87\xc0d\xf4!\x1d7\x0d\x06K\xf4\x1c\xb4\xb3_\xf0\xf6h~\xf2\x8c\xc1#K\xd4r\x92/\xd2I\x9e\xf8 d\xf2l\x83\x90lv\xd07B\x94\xe4QI\x06eU\xd7\x17\xce\xc6\x06\x13\x8f\x7f\xb2\xfc\xb1/\xd4\x9f\xcf;]6\x08\xff\xb0%\xfa\x88\x7f\x19\xdc\xf0\x07Ic\xda9L1\xe2\x15\xa37+\xf8,\x0coQ\xfb\x89\x7f\xe9<\xf7\x91\xa9\xb5_PNLJz\xba]D\xff\xd6|\x02\xd9aH\xc0\xa2T\xa0\xc7\xca2T\x81\xda\xb4,vr@\xef6\x9c\x0f`\xbd\xa7]\xda\xb0\x95R?\xcb\x85r{\xf5\xcc\x82\xbf\xbd\x94\x98:\xd1\x1f\xef\xa6i\xa1\x95]\x1a\x123\xb1Jb\x1cn\xbd\x85:N\xf81K\x0c:+\x17\x90\xafA\xcf\xdc\xa2j\xd8\xfd\xc5\xc8F3\x01\xc7\x85\xb2\x9e\xb1\x0eZ\xee\xd6\x82XL\x1b\xd0]\xe7\xc6+,B\xe7\xfa\x08U\xef\x1c\xa07K\xa6%Jf|\xe2\x89%\xbf\x82\xcf\x04\x0eu\xf0\xd0R@\xd0r\x1d\xa81\x94\x9d\x8d\x96\xa1a\xee\xe2f\xbdn\xd3\xe0\x8aB\xbdR\x0f|M\x1an\x9d|M\xf2\x17\x86J>t\x082\xb2U\xb9<\xc0i_\x9fe\xe8\xb3\xad\x02\x00\xc8\x1b\xd4fx\x15\xc9#\x1f\x84\x98\x87\x0e\xcf\xd2gi]\xc6\x98mUA\xe6\x00\xd7iY\xabCm\xfd\x06^wW\xef\xd5\x8dR]\xc0\xc5\xb0\xd5\xc3\xfc\x9e\x82Y\x16r\x7fm =\x962\xf2~\x98k,\xe32}e6\xd0\x05\\\xee\x83\xee\x12\xca\xcbN\xd7\x13BE\x9e\x97\x95n\xad;l\xe0\xd5@|\xed\xe3\xab\x05|D\xb7C\xb6\xa5^\xc0y\xb0\xda-\xd2\xc4\xf2B\xbe\x15\x83\x85\xa4[\x92\xa3\xb0\x81\x97o~\x90\xdfl\"\xda/\xb8\x81\xef\xbe\xefx\xf4\xc7\x1an6\xf0\xdd\xeb\xd7\x7f\x1a\x06:m\x8c\xf5\xf5\x06\xbe\x9f\xc2{i\xbd\xc7\x00\xd7\xf3\xf5o\xdfL\xb3+I\xdf\xfd\xe9;\xb8\x06m\xdd\xf0\x06\xfe\xfaN\xd6\xcb\xc8C\xae\x15\x14\x0c\x86eA\xcc\xd4n\xe0Mw\x05\x91\x9c5\xf0\x12\x7f\x90\xdf`\xc9QM\xa3\x1d\xd9\x98\xa5v\xb6\xf6\x1b\x08b\xe1\xfdlg\x96\x0e+\x9e\x05p\xec\xe2	[o\xaawh\xe0\x05\xd8\xb6\xa3\xc0\xdasRW+Ol\xab\xff\xa4\x8f\xb4^\xc0\xaa\"\x92\xfaC\xc3\xf5Q@\xef\xde~\xf8\xf1\xfc\xed}\xf5[^\xad\x13\xb1F\xce\xad\xefH\x97\x0b\xb3f\xfc\xe3t1Zs\x96	!F\x92N\x92s8\x1e\x10\xc9\xd9l!_\xf3\x8c\xc5\x93\xe4l\x12T\xda\xc2{X\xf7,\x96N\xc7x\x96\x89c\x8f.}\xd4\x85\xb9\xe4\x0f92\x97\x87]\x9d\x8b\xb8=\xfa#\x9b~\xc2\x9b\xb9\xe4\xb6\xad!\x86\xf2,\x9b\xee\x89\xb1\x14\\\xdb\xb6N\xefKA[\x0e\xf5G\xe7\xebLj\x05n\xce\xb2\xb7o\xb2\x91\xa9\xc3\xbbv|\x96\xc9%\x9b\xadO\x9b>\x1dJ\xbe~,W\xf9:%}\xab\x1e\xd4\xcd\xbb\xed\xbc\x10\xce\xd7\xdd\xe3\x0b\x9f\xbdF\x9e\xe3O\xf2\x8cu\xf3\xc9`\xbeAI=\xb70\xc9\x1f-\xb3O\xfa\x9ckh\x02Vg\xd9m)\x9em?L\x06\x12\xd5\x8e*\xf9|\xad\xb7'\xf1~W\xc6\x0b\x0fSim\x03\xc6\x87\xb5&\x17&\xde\xef\xf7\xfbUMT;\\\x95\xd4\xae\xcbT\xf3g\xdb\xa1\xf6\x17\x0f\x16\x7f\x00\x07\xb9\x1az\x05\xc1\xca\xb6c\xe3 (R\xf4\xdci?\xe1\x8f\x94\x02\x83;1\xf5\x1b\xd9v\xe8;\x06\xa0\xbb\xde\xe3AD\n\xf0h?r2\x9b\xcf\xd2\xaa\x9c\xb40v1'\xd7\xc8\x16\x9eXP<vKt\xdbYOt\xd2B\x8e\xed\xf6\xa8e\xca\xd7\xd8n\x9f\xado\x9a[\x9b\xe4\x9b\xf4R#\xf6\x91<o\x7f5\x03\xbe\x95\xb1\x88}\xbc\xe7z2\xf7\xbfk\xc9\xa6\xfc\x7fm_6\xb75\xc9\xf3\xf7j#\xf0\x91<o\xff\xf6t\x06\xef\xda\xbb#\xee~U\x8f7\xb73\xc9\xb3\xf7}#\xee\x91|\xa3^\xf0\xc9,\x1e\xb7\x8a\xf7\x13\xf9u\xfd\xe2\xdc\xdc$\xcf\xdcC\x8e\xa8G\xf2m\xfa\xca\x99\x81[y\xaa\xd7|8\xfb\x0f\xd7\x80\x8f\x14\xe5\xc7%\xe1m\x19(\xff\xf2\xf5\xd0	\xe4kiS\xb7\xea\xfa\x1a\xbd\xb9\xb9Q\xff\x1f\x00PK\x07\x08tm\xcb\x11\x99\x06\x00\x00]\x15\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00\xacU\x97N\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00#\x00	\x00email/verify_response_page_templateUT\x05\x00\x01\xc4\xec\xbe\\\x00z\x00\x85\xff<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\" />\n<title></title>\n</head>\n<body>\n<p>{{.message}}</p>\n</body>\n</html>\n\x03\x00PK\x07\x08\x0dp\xb3\xe9\x81\x00\x00\x00z\x00\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00\xacU\x97N\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00-\x00	\x00email/verify_response_page_template_vector_imUT\x05\x00\x01\xc4\xec\xbe\\\x9cS\xcd\x8a\xdb<\x14\xdd\xfb)\xee\xe7,\xe6+\xc4Q\x92N\xca\xe0q\x0c\xa5-t7\x85\xe9\xa6K\xd9\xba\x96\xc5H\x96\x91n\x12gB\xde\xbd\xc8v~&t\x16-\xc2\\\xeb\xfe\x9cs\x84\x8e\xb2\xff\xbe>}\xf9\xf9\xeb\xc77\xa8\xc9\xe8<\xcaN\x01\xb9\xc8\xa3\xcc q(k\xee<\xd2:\xdeP\x95<\xc4\xc0\xf2(#E\x1a\xf3\x8c\x0d1\xca<\xedC\x8c\n+\xf6p\x88\x00\x00*\xdbPRq\xa3\xf4>\x85\xbb\xa7\x16\x1bx\xe6\x8d\xbf\x9b\xc2w\xd4[$U\xf2)|v\x8a\xebi_H\x9e\xd1\xa9\xea\xf12[Zm]\n\x93\xfbUXW\x05\xaf^1\x85\xc5\xb2\xa5!i\xb8\x93\xaaIa\x81\xe61:F\x93-\x96d\x9d\xb6\xd2\xc2\xe1M\x03\xdf\x90\x1dF\x08;J\xb8V\xb2I\xa1\xc4\x86\xd0\x0d\xf9\x96\x0b\xa1\x1a\x99\xc2b\xdev=\x98A\xef\xb9\xc4\x11i\xa7\x04\xd5)|\x9c\xf7\xe5[$\x8d\x15\xdd\xe2\x04M\x17\x0dIa\x89\xacI\xe1\xfe\x0c0\xa8O\xc2\xec\xb5\xc21\xed\x94\xac\xff\x94'\xdb\xa6\xb0:\x83\xf4\x14\xc9\x0e\x8b\x17EIa\x9d@\x978.\xd4\xc6\x9f\x8e\xd27\x18\xfb\xfa~\xf5\xdd\xc2\x0dx\x97\xf8\x9a\x0b\xbbKa\xdev\xfd\xb7<\xfd8Y\xf0\xff\xe7\xd3~\xcd\x16\xab\x0foh\xffz\xee\x1fFz\xbe\x82\x97/\xd2\xd9M#\xce\x1e\xaa\x1e\xc2\xba>g\n\x8b\xb6\x83IY\x96\xe0\xadV\"\\v\xc6F\x1fgl|\x00\xc1\xcfy\x94	\xb5\x05%\xd6\xf1\xc5Yq\x1ee\xcaH\xf0\xae\\\xc7\x8c9ei\xa6\x0cSF2\xec\x08]\xc3u\x9fLB\xf3\xccoe\x0c\\\xd3:\x1e\x1b\xe3\xc1I\xebx\xb9\xfa\x14C\x8d\xe1\x96\xc7Mx^L\xa8\xed\x15\xed\xe8\xc1\xc0\xd9\xe6\x87\xc3l\xdc\x1f\x8f\x19k/\xddl\x14\xcbj2:\x8f~\x0f\x00PK\x07\x08\xdab\x7f\x1e\xd1\x01\x00\x00\xdb\x03\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00\xc4,R]\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x1d\x00	\x00policy/disposable_domains.txtUT\x05\x00\x01@[\xd4jlR\xbd\x92\x9e0\x0c\xecy\n\xcf\\{\xc7\\ri\xd3\xa5\xcf#d\x84-@\x01[\x8cdB\xc8\xd3gd\xc0\xdfO\xd2\xedj\xa5\x95,\xf9\xc5}\xe3\x08\x94\xd4q\xef\x02\xe9\xc2\n\xdd\x8c\x0e#\xd0\xec\x16\xe1_\x14P\xf4\xd5u3\xfb	\x83\xebv\x07!\x08\xaa\xba\x85g\xf2\x84\xea6\xcac\xf3r\xa4\xfc\xb83\xf9\xea\xb2\xac\xd8\xba\xef	](m\xdc\x82\xe2fJ\xf8\xeat\xed\xc2\xd9\x1a\x04\xab\x7ffn\x9b\xf77k\xdfz\x8e\xcd\xa7\xf7Hi\xcd\xf8\xff@\xc2\xdc|~\xce\xf8\xf8\xa8\xc9\x908u\xfc\xbb\xe4u\xab$\x94\"\x117\x81\xd4\x83\x84\xb6\x94\x19[X\xb3M]\xba\x06\xe1\xc5\x846bS28\x05\xf4S\xd1z\x98\x90\x8a\xab\xb1\x013\x90\xd4\x86\x03\xe6\x04\x01\x0eiE\x11\x9ag(jG\x7f\x9e\"\xff\xe6\x04|J\xa1\xd4\xf3S\xc8\x9e\xf2\x18a\x19\x1e#e\x97\xc5~\x04\x81\x89\x84\xea|\x94<\x0f\x89\xb2m\xfe\xa8\xfc\x89\xc7\xb3\xcd\xc5\x82\x1e\xb2\x1fK\xb11[D\xeb}\xc1\x94 \xb3T\xe9\xa46\x8f\xa9	\x95\xa0\x8a\n\xfe\xc0\x94\xee.\x13y\x8cpA\x98\xf2\x81\xf6\x8cq9\x0f\x11\xf7,\xa0c\xad(\xcb<$\x1dA\xa6\x19\x14EK\x9d.\x10\xbf\xd8\x81\x0c\xd89V-p\xe0U\"\x1e\xde\xe6\xfcv\x9d\xfcF\xec\xad\xc6\xae.\x86\xeb\x7f\xba\x08W\x079'\xc8\xa3\xf0\x06\x1b\xecu\xbc|\xd6\x15C\x1b\xfc\xf6q\x1f\xdfqc\x01\xef\x88-o\xc3aC\xe9/q?\x7f\x9ey\\\xb8\x97\n\x13\xe6\xe6\xef\x00PK\x07\x08\xd5\xb1\x11.\x8d\x01\x00\x00\xb5\x03\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00\xc9\x1eR]\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x001\x00	\x00schemas/postgres/migrations/0001_initial.down.sqlUT\x05\x00\x01\xebB\xd4j\x94\x90\xc1JCA\x0cE\xf7~\xc5\xfcGW\x8a\x15\n\x82b\xbbp\x17\xf22\xb1\x13:/\x19&\x99\x82\x7f/v\xe1\xcaiu\x7f\xce\xe1r\x1f\xdf^^\xd3\xe1\xfe\xe1y\x9bvOi\xfb\xbe\xdb\x1f\xf6I\xf4,\xc1\x10vb\xf5\xcd\xdd\xaf\x0c\xb7\xc2+w\xac\xd0\xc6R\x85\xe0\xc4\x9f3\xb61\xf7o\xec\x062\xd3\xff2\xa7\x1aa\x85(\x9d\xb9I\x06t7\x12\x0c\xb1\xa9q\xac\xb6\xfcS\xf9\xc9_\x9e\x01\x1cQn\xa2g\xac\x92/Upv\xbfR/\xe8E\xf4\x08+\x07f\x0c\x9c\x94\x91\x88[p\x86\xe0\xbe:\x8c^\xa7\x1b\xae\x1d\x86D64f\xae\xe9\"\x9aA-\xe4C\x08CL}\xf35\x00PK\x07\x08\x94\x18\xa4`\xb3\x00\x00\x00-\x02\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00\xec\x1eR]\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00/\x00	\x00schemas/postgres/migrations/0001_initial.up.sqlUT\x05\x00\x01,C\xd4j\xc4WMo\"9\x10\xbd\xf3+|\x04\x89H3\xd9\x9d\xb9\xe4\xc4$\xac\x84DH6\x90\xdd\xd9\x93e\xda\x05X\xb8\xedV\xd9\xcd\x84\x7f\xbf\xb2\xfb\xfb\x037\xd9\x1de\x8e\xe0\xf2\xab\xaaW\xaf\xaa\xcb\xf7/\xf3\xd9fN6\xb3o\xcb9Y\xfcAVO\x1b2\xff\xbeXo\xd6D\xa8\x93\xb0@\xad>\x822d<\"\x84\x10\xc1\xc9V\xec\x0d\xa0`\x92$(b\x86gr\x84\xf3\xd4\x9f\xc6\xc0E\x1a\x93\x13\xc3\xe8\xc0p\xfc\xf9\xeb\x84(m\x89J\xa5\xcc\x0c\x18\xe7\x08\xc6\x94\x16\xb7_:&\xa8uL\x05\x0f\x99\x18P\x1c0d\xe1c\x0e\x19 D N\xc0\xa95.!\xa1l\x16\xe0\xcd\x0d\xf9\xfb\x00\x8a\xd8\x03\xe4\xf9\x93\x1f\xcc\x94\xe6d{&\xa9!;\xd4\xb179\xe8\x18\x0c\xe0	\xb0\x08\xccV\x88\x0d\xb0,\"\x87e@\xd9\x1c\xc7j\x8f\x92\x1a\xc0\xd1\xe4n\x94\xd7b\xb1z\x98\x7f\x0f\xd4\x82f4\xd3\x82L\xad\x1a\xc7f\x9c\x9dO\x0b\xb6\xdf\x81\x9cE\xd9\x01\xf4\x7fO\xeeF\xa3\x80Z 9@\x0c\xc8$M\xd2\xad\x14\x11=\xc2\xd9\\#\x9a\xca<T\xb0\x13\xa0\xd8\x9di\xa4S\xc7^\xc6/\x87\x1dK\xa5%\x9fr @#\x8c\x05\x15AU\x84\x1a\xaf\xaf\xab\xc5\x9f\xaf\xfd$\xf4\x06O\x85\xe2\xf0\xe6\xd8\xe8\xcf\xad\n|\x80\x9a\x04\x00\xafk \xc5b\xa8\xb1\xf0\xa5\xdd\x1c\x89FK\x84\xb2\xb0\x07,\xd3\xaf\xdaK2c\xd7\xa0\xec_\x8e	\xad\x1a\xcavg\xcf\xfa\x08\xeb4\x8a\x008\xf0Y\xc1c\xde\x9a\x91\x15'(\xc1\x0b\xbf\x15\xc9\xd71\xe93\xd0*\xcby\xec~]A\x8e\xa3\xd5\xc9\xe5*\x8e\x1c\xf2\x10OL\xee\x03S\xe8\x08gb\xe1\xcd\xb6.\xed4\x82\xd8+\x7f<.\xbcL\x08\xc2\x0e\xd0\x89\xca\x14\x85t\xde'\xd7\xd1Q\xc0P\x17\x91V\x8dlK\x1fS\x17\xef\x00MRGLR{@\x80Dp\xca\x8c\xd1\x91`V\xe8\x0f\x1c\xcd77$~\x13|\xean~\x03G\x17a\x8a\xbb_\xb3\x9d\x05$\x0c\xc1[;&	B\xac\xdd\xc4\xacG\xeaQ\x1cDM\xe3_'\x99\xff\xb2c[N+_u\xb5\x96>\xdf\xd3\xe5\xdd\xb9\x19`\xb5g\x8a\x86\xc6\xdf^\xea\xed//P\x87\xda\x16\x97\xd6t\xfa\xbb\x9f\xe4\xeeq\x9d\xed\xd6\xa9FW\x83\xb5\xff\x0c\x86\x86Wf\xb7\xe0\x17b0\xfb\x99\x93J\xb33k]\x16\xa8\xa8\xd4?\x00\xeb\xdf\xc3p5\x8a\xca\xfak\xe3\xfc\xda\xe4*	\xd5s\xa5eB\x83\x1e\xeb\xd7\xa6%\x11\x93\xbb\xfb\xa7\xc7\xc7\xc5&\xac\xac\xb2\xe7OL\n\xee\x15E\x0d\x18\xf3\xa1\xad\x1fI\x01\xca\xae!B\xb0%\xd2o\xb7m\x81\xe5!\x82\xafq\xf5\xe9\xc8\xe3\xb0\"\xee\x08\xacV\xe0`\xf2~\x05\xa1,\xb5\x87\xeb\xda\xa9\"k\x9dquAu\xcdM\xb1\x9b\x91\xdb5g\xd6B\x9c\xd8U\x1ao\x01/\xe04>\x1e\x1d\xe7\x8d\xafH\xa8\xa0c\xc1\xfd\x97e4[n\xe6/9%A5\xcf\x1e\x1e\xc8\xfd\xd3\xf2\xf5q\xd5\xa2Nj}L\x13z`\xe6P\xe6\xe7*[\xf2\xdd\xa7\xef\x90+ZG\x1c\x90\xfc\xb8f;\xf0es\x01\n\xb5\xa71X\xc6\x99eyys\x80\x04\x92\xe4\xd2\xae?\x1a@f\x91_\x17\x0b\xc1\xb85\xbb\xf3\xb2\xe8\xcc\xe1\x08!\xeb\xb1\xee\x07i\xc8_\xe3\xa5\xd4\xf3\x04\xe9\xf8\xea\x8d(\xa0\xab\xdc\xbe\xa1\xa6\"\xc9\xf2p`d\xfa\xb8\x0c\xcd\xad]\x1d\xf3WCq\x7f\x90SH\xac{8\x01\xc6\x86\xa6(\x83\xf46\x93I\xb4\x14\xd1\xd0\x9a\xef\x06[\xc8$EY\x1e\x7f\xfet\xfb{\xbbc\x03\x05\xcc\xa3\xa8\xca@\x8a\xac\xa7\x0evZ\xb8\x9f\xfc\x1f\xeeC\xeci\xb5\x15\x8aS\xa5\xad\xd8\x89\xe8\x1d\xeb\x1b\x07c\x85\xf2\x17B\xdc$\xec,5\xe3}\xdb-\xcb\x86XG\xd4\xed)\xad\xe0\xcd\xd2\xdc\xf8\"\x85\x83\x1c\xbb\xd7\x06\x05D\x8d>\x96\x01M\xf6\xd1B\xdb\x81h\xd5k7n\xd9\xb5\x87\xa7[\xb2\x0d\xc9\xc6i>'\xdb\xcf\xa4\xcd?\xcf\xf3<\x8d\xbbk\xee\xb6\x9fQ\x17\xef\x87\x96\xf6FD\xd6\xfc\x04\x10\xbf\xfd=\xbc<=\xfb~]\xbd.\x97\xff1\x9aj\xe5\xfeIh\xd9\xf2\xd8\x04\xfbw\x00PK\x07\x08\x04\x89\xb3k\x1a\x04\x00\x00y\x12\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00\xf6\"R]\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x007\x00	\x00schemas/postgres/migrations/0002_sydent_import.down.sqlUT\x05\x00\x01\xd1I\xd4j\x00$\x00\xdb\xffDROP TABLE IF EXISTS sydent_import;\n\x03\x00PK\x07\x08\xbe9f:+\x00\x00\x00$\x00\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00\xf6\"R]\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x005\x00	\x00schemas/postgres/migrations/0002_sydent_import.up.sqlUT\x05\x00\x01\xd1I\xd4j4\xce\xbfK\xc4@\x10\xc5\xf1~\xff\x8aW\xde\xc1\xad\x95\xd8X\x9d\x12\xe1@\x14\xbc\x14va\x92\x9dd\x07\xb3?\x98\x1d\x85\xfc\xf7\xc2E\xfb\x0f\xdf\xf7\xbcG\xd5\xb2(\xb7\x862CR-j\xbem\x81\xb3\x9d\xb0R\xb3A\x02\xa4\xc1\"#\xca\x12\xb9\x19$`*U8`\xd6\x92\xc04E\x18\x8d+;\xefQ\xe6\x9b\xdd\x13\x08d4R\xe3;\xf7\xfc\xd1\x9d\xfb\x0e\xfd\xf9\xe9\xb5\xc3\xe5\x05o\xef=\xba\xcf\xcb\xb5\xbf\xfe\xd9a\x1f\xc7\xc1\x01\xd8{C\xa6\xc4\xf8!\x9d\"\xe9\xe1\xe1\xfe\x88\xaa\x92H7|\xf1v\xba\xb9\xff\x8b\xa3,\x92\x0d\xb9\x18\xf2\xf7\xba\xba\xe3\xa3\xfb\x1d\x00PK\x07\x08m\xe2\xc9\xab\xaa\x00\x00\x00\xdc\x00\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00U#R]\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00:\x00	\x00schemas/postgres/migrations/0003_invite_tokens_ms.down.sqlUT\x05\x00\x01\x82J\xd4j\x00V\x00\xa9\xff-- nothing to do, earlier versions read the timestamps as milliseconds too.\nSELECT 1;\n\x03\x00PK\x07\x08J\x94\x9f\xb2]\x00\x00\x00V\x00\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00U#R]\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x008\x00	\x00schemas/postgres/migrations/0003_invite_tokens_ms.up.sqlUT\x05\x00\x01\x82J\xd4jt\x8eAK\x03Q\x0c\x84\xef\xfd\x15s\x16W\xd6\xb3\xf6 \xb8\xe0Q\xb4\xe2\xb1<\xfb\x06\x1a\xba\x9b'Ix\xf2\xfe\xbdtq\x95\x15\x9aS&C\xbe\x99\xae\x83h\x95 \xa2\x9c\xa8\x08\x99\xe8\x91\xa6O\xc7\x17\x8d\xf0(\xc6\x0cQ8\x0fE\xb3\xe3\xa3\xc1[\xa6\x06\x92\xe6\xb3b\xb2Qh\x9b\xaeC\xa5\xb9\x14\xf5k\xc4\x91\x0d\xc9\x08c\xcaH\x8eI\xc6Q\x16\xc6('\x82\x95\xd6P\xe2H\xfb\x8b\xbd\xd9\xbc=?>\xec\x86\x9fV\xfb\xb9\x95\xe3u\xd8\xc1x\xa0T\xe6}8\xb6+u\x85\xdb\xbe\xef\xf1\xfe4\xbc\x0c+\xe3~6\x96\xb9\xbb\xccvj\x9c\x1f\xb6\xbf\xdb\x8a\xb9\x1c\xff\xf3\xbe\x07\x00PK\x07\x08A\xd5\xdc\x18\xaf\x00\x00\x00>\x01\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00\x97#R]\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x006\x00	\x00schemas/postgres/migrations/0004_email_outbox.down.sqlUT\x05\x00\x01\xfeJ\xd4j\x00#\x00\xdc\xffDROP TABLE IF EXISTS email_outbox;\n\x03\x00PK\x07\x08\x1fv\xc6\x90*\x00\x00\x00#\x00\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00\x97#R]\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x004\x00	\x00schemas/postgres/migrations/0004_email_outbox.up.sqlUT\x05\x00\x01\xfeJ\xd4j|\x90Ao\xd3@\x10\x85\xef\xfe\x15\xef\xd6D\x8a+@\x82KO\x05\x8c\x14	\x8aD}\xe8\xcd\x9ax\x07{\xd4\xf5\xac\xd9\x1d\xd7\xe9\xbfG\xf6R	\x12\x9a\xf3\xfb\xbe\xdd\xf7\xa6,\x11Y\x1dGv\xe0\x81\xc4'\xcc$&\xda\xc1\x02\x0e\x8c\xc4j;\xc40'Pd8\xf6l\xec\x10\xb4\xcd\xd95\xbeqJ\xd4q*\xca\x12s/m\x8f\x96\x14\x1al\xd1\x1d{yZ__\xecG\x1e\x0d\xb3X\x8fdd\xcbk\xe40\xa9\x89G\xe4_\x13O\xec\xae\x8bO?\xaa\xdb\xbaB}\xfb\xf1k\x85\xfd\x17\xdc}\xafQ=\xec\xef\xeb\xfb\xdc\xb0	\x93\x1d\xc2\x11\x9b\x02\x00\xc4\xe1 ]\xe2(\xe41F\x19(>\xe3\x91\x9fwk\x9a\xd6mx\xa2\xd8\xf6\x147\xef\xde\x7f\xd8\xae\xcdt\xf2>\x13\x91[\x19\x85\xd5\x12\x8c\x8fv\x92\xba0\x90\xe8%\x7f\xc8\xeb\xff'\xe7\x8d/\xee\xdb\xbfT8\xfeI\x937\\\x8d\xacN\xb4\xbb\xca\xdf\x91\x19\x0f\xa3\xa5e\x92\xa8\x9d\xf3o2\xa7|\xb4\xe6\x0f\xdc\x9c\xe3\x19j#\x93I\xd0W\x01O\xc9\x1a\x8e1\xc4\xb5}\xb1\xbdy\xb9\xfd\xfe\xees\xf5p\xe1\xf6\xcd:\xad9\xad\x11\xf4\x1fj\xb3R\xbb\xd3\xb6\xdb\x9b\xe2\xf7\x00PK\x07\x08\x8e+K(5\x01\x00\x00v\x02\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00\xc4,R]\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x005\x00	\x00schemas/postgres/migrations/0005_rate_limits.down.sqlUT\x05\x00\x01@[\xd4j\x00\"\x00\xdd\xffDROP TABLE IF EXISTS rate_limits;\n\x03\x00PK\x07\x08\xfa\x9b\xfd\x9d)\x00\x00\x00\"\x00\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00\xc4,R]\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x003\x00	\x00schemas/postgres/migrations/0005_rate_limits.up.sqlUT\x05\x00\x01@[\xd4jt\x8eOK\xfb@\x14E\xf7\xf3)\xee2\x81\xf6\x07?\xc1UWUG\x08H\x05\x9bEwa\x92\xbc\x9a!\x93\x998\xef\x8d\xa5\xdf^\xf2G\xed\xc6\xf59\xdc{\xb6[H\xe8\xc9\xa3NMO\xc2\x08gHG\x88F\x08\xce\x0eVx\xb3\x18\x0c\xcb3\xf2i\xa8)Nb\xa4\x8fD,\x0cGg\xc1\xa5#\xaf\xa6\xbd\x8e\xd65\\\x0c\xc3\x19\x16\xa4\xb15B\xed?\xf5\xf8\xa6\xf7\xa5F\xb9\x7fx\xd1(\x9eqx-\xa1O\xc5\xb1<\xce\x97\xd5r\x89L\x01@OW|\x9a\xd8t&f\xf7\xff\xefr\x8c\xd1\x0e&^'\xb0\x99\x8d\xb5\xac\x0d\xa9v\x841Rc\xd9\x06\x0f\x1f\x04>9\xb7X\xeb{%\x8c\xda\xbe[/?\\\xe5\xbb\xef\xa4\xe2\xf0\xa4O\x7f'U7#\xc1\xdf\x92\xec\x97\xe4;\xf55\x00PK\x07\x08w\xe4\x0c\xbb\xd0\x00\x00\x00Q\x01\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00\xc4,R]\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x003\x00	\x00schemas/postgres/migrations/0006_audit_log.down.sqlUT\x05\x00\x01@[\xd4j\x00 \x00\xdf\xffDROP TABLE IF EXISTS audit_log;\n\x03\x00PK\x07\x08\xc8V\xef}'\x00\x00\x00 \x00\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00\x1b/R]\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x001\x00	\x00schemas/postgres/migrations/0006_audit_log.up.sqlUT\x05\x00\x01\xa6_\xd4j\x8c\x92\xcbn\xdb@\x0cE\xf7\xfe\x8a\xbb\x8c\x019@S\xa4\x9b\xac\xd2\xd6\x05\x0c\x14)\x90x\x91\x9dAih\x8b\xb1\xcc\x11\x86\x94\x1f\x7f_\x8c%\xa7\xce\xa3A\x96\x9aK\x1eR\x07\x9cL\xd0\xc4\x15\xe2\x12UM\xbab\x83G\x90Y\xac\x84\\\xa2Z\x01\xd1\xad8\xc3\xe3\x9a\xd5@\x1a\xd02'\xbb\xc4}\xdc\x19(1vI\xdcYG\x93	D\xe15\xc3\x13\xa9Q\x95	\x99\xed5\x0f\xfcc\xbf\xf2\x96\x13\x027\xec\x1c\n\x94\xbc\x8c\xa9Oh\xe9\x9c23\xb3\x9e,*L\xa9\xb5:\xbae\xce\xae&\x1f@\xe1l~\xd4\xe6\x80\xae\x0d\xe4\x1cP\x1e\xb0\nm\x02'2.\xb0\xab\xa5\xaa3\xadlH\xd7v\\\x85BHlV`\xb3\x97P@\xda\xe2lL^\x90*\x8f)\x0f$\xed9\x01\xd6\x95O\\\xf9\xe5\xe8\xc7\xfd\xf4v>\xc5\xfc\xf6\xfb\xef)f\xbfp\xf7g\x8e\xe9\xe3\xeca\xfe\x00\xea\x82\xf8\"\xfb\xbc\x18\x01\x80\x04\x94\xb22NB\x0d\xda$\x1bJ\x07\xac\xf9P\x1cS\xb7\x9c\x8a:4:\xb4k\x9a\xfe}\xd0\xb6\xa5T\xd5\x94.\xbe^\x8d\xdf\x16\xc4\xb4X\x8b\x86\xe7\xa2/\xdf\xde-\x82\xf3\xfe5^\xda\xf7^7\x1c\xa4\xdb|\xc4\xeb\x9d=W\\]\xbf\x19\x99m~\x94\xe7\xb39\xcb\xaf_\xf7\x0fw\x90\xb7\x1bL\x1c\xaf!\x7f\x8f\xc67'\xf1\xb3\xbb\x9f\xd3\xc7\xff\x89_\xe4;\xd1\x7f\xdf\x17n\x9f\xed\xec\x0d,N\xff\xf9\x82\xd2g\xc5I\xc2\xa7\x91Y\xc8K\xd0^\xc2\xf8f\xf4w\x00PK\x07\x08\xc5\x9c\xdd\xd4\x80\x01\x00\x00v\x03\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00\xfa R]\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x000\x00	\x00schemas/sqlite3/migrations/0001_initial.down.sqlUT\x05\x00\x01\x19F\xd4j\x94\x90\xc1JCA\x0cE\xf7~\xc5\xfcGW\x8a\x15\n\x82b\xbbp\x17\xf22\xb1\x13:/\x19&\x99\x82\x7f/v\xe1\xcaiu\x7f\xce\xe1r\x1f\xdf^^\xd3\xe1\xfe\xe1y\x9bvOi\xfb\xbe\xdb\x1f\xf6I\xf4,\xc1\x10vb\xf5\xcd\xdd\xaf\x0c\xb7\xc2+w\xac\xd0\xc6R\x85\xe0\xc4\x9f3\xb61\xf7o\xec\x062\xd3\xff2\xa7\x1aa\x85(\x9d\xb9I\x06t7\x12\x0c\xb1\xa9q\xac\xb6\xfcS\xf9\xc9_\x9e\x01\x1cQn\xa2g\xac\x92/Upv\xbfR/\xe8E\xf4\x08+\x07f\x0c\x9c\x94\x91\x88[p\x86\xe0\xbe:\x8c^\xa7\x1b\xae\x1d\x86D64f\xae\xe9\"\x9aA-\xe4C\x08CL}\xf35\x00PK\x07\x08\x94\x18\xa4`\xb3\x00\x00\x00-\x02\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00\x02!R]\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00.\x00	\x00schemas/sqlite3/migrations/0001_initial.up.sqlUT\x05\x00\x01$F\xd4j\xcc\x97K\x8f\"7\x10\x80\xef\xfc\x8a:\x82\x04\xab\xddIv/sb\x93\x89\x84\x14M\x1e\xcc&{\xb3L\xbb\xa0-\xdcv\xab\xecf\x87\x7f\x1f\xd9\xfd~\x8c\x1b\x94H\xd9#\xb8\\\x8f\xaf^\xed\x9f\xfe|\xda\xbe<\xc1\xcb\xf6\xf3\xafO\xb0\xfb\x05\x9e\x7f{\x81\xa7\xaf\xbb\xfd\xcb\x1e\xa4\xbeH\x87\xcc\x993j\x0b\xcb\x05\x00\x80\x14 \xb5\xc3\x13\x12\xe4$3NW8\xe3\x15x\xe1\x8c\xd4	a\x86\xda\xad\x83d\x86B\x16\x19\\8%)\xa7\xe5\x87O+\xd0\xc6\x81.\x94*\x05\xb8\x10\x84\xd66\x12\x0f\x1fG\"dL\xc6\xa4\x88\x89X\xd4\x02)&\x11\xfc\x8f	\x10&(/(\x98\xb3p\x90'YG\xb0\xd9\xc0\xdf)jp)V,\xe0\x1b\xb7\x8d8\x1c\xaePX8\x92\xc9\x82Hj2\xb4H\x17\xa4\xda1\xd7j\xec)+=\xf2\xba,jW\xe9q&h),\xd2b\xf5\xb8\xa8\xf2\xb2{\xfe\xf9\xe9k$/\xac\xc4\xccj\x98F\xf7\x8e\xed\xb2<_\xd7\xb4\xef\xd0\\z9R\x18\xfe^=.\x16\x91\xca\xc1<\xc5\x0c\x89+\x96\x17\x07%\x13v\xc6\xab\xbd\xb7\x80\xda\xab\xb1\xe4]\x90\xe4\xf1\xca\x12Sx\x92!{ \xf0\xc8\x0b\xe5\xe0}\xa5\x08\xc9J\xebP'\xd8&\xa4\xc3\xf8\xcb\xf3\xee\x8f/\xd3@&\x03aR\x0b|\xf5d\xa6\xe3l\x1d\x9f\xc1\x94#\xd2\xfd\x8d\xa5y\x86\x1d\"\x1f\x87M\x93\x1br\x0d\xe3\x1aE\xdbv\x8a[\xb7G\xed\xfe\xf2T\x8c\xeeU\xbc?\xfb\xdd\x9cq_$	\xa2@\xb1\xad\x99V-\x9b8y\xc1Fym\xb7\x05~\x1b\xd5\x10\x81\xd1e\xfcK\xff\xeb\x06P\x1e\xb1/\xa3\xbbyy+s\xcc\xb8:E&\x95O\x84\xc3W7\xb8t4\x84\xf2\xa4\xc3\xf1\xb2\xb6\xb2\x02\xc2#\x92/6['\xd8[_\xdd\x86\xa6V\xc3\xbcGF\xf7\"ol\xac\xbd\xbf\x1e\xd9f\x03RX0GP&\xe1\n\xb8\xb5&\x91\xdcI\xa3-d\x85u\xa0\xf1\x82\x04\x07\x04\xc2\xc2\xa2X\x03a\xaed\x12D\xfc\xac\xb1~\xe8d\xe0\x8c\xd7u\x94Z@\x92r}B\xfb\xae\x8f\x12N\x05'\xae\x1d\x86\x1b\xd2\xbe\x8b\xb5\x7fp\x86\xb9\x94\x10s)X\xcf\xab{\xb3\xf7_\xac\x91\xcd\x06\xb2W)\xd6\xfe\xe6g\xf4i\x03\xae\x85\xff\xb5=:$\xe0\x84A\xdag\x14\x083\xe3\xa7{\xd7\xeb\xa0\xc5\xab\xe8\xf4\xdd\xa7Ui\xbf\x99(\x03\xa3\xad\xadn\x0756\xef\x99B\xe3\x19\x1f!<1\xf1c\xb9:)s\xf8\xae\x925\xc2<\xe0z+\xefq:\xba\xe0\x07\xa7\x86|:\xf6a{\xc7fk)\xb7k\x81\xf4\x8f\xedi\xeb\xabfjX(c\xceE\xceRn\xd3^\xf4\x9d\xb1\x10I\xbd2\xdf\x90\xbaK>\x9e\xb6\xba\x04\xc2\xb5eumuS\xaduI\xb0&\xdcY\x8b\xddk\xeb\x06S<\xb6X\x0c\xac\xcbk\xc6\xfa\xb2#;\xb3H\x9a\x99t\xe1J\x8aP\xe5\xcc\xa2\xb5\xff\xdbhJ\x94D\xed\xf6\x98\x10\xbaF\xd3\x0f\x0f\xc3\xa2\xaf\xdc\xc5\xe0[\xbbn+?\x9c\xccFU\xdf\xa9\xab(\x88\xf09\xc7x\xe1\xd2\xfb\xdb\xbd\x85\xb8/\x19\xbe\xd1\x16\xfd/\xf0qt\xfe\x1b~\xeb\x1cf\xb9{.\xb2\x03\xd2\x1bzz\x0bwd\xbc\xb7yc\x89^J\x11\xda.\xc6\xc7\xf7\xa9\xd4'\x96\xa1\xe3\x82;^\xb1\xa9*-\xc7<\x7f\xeb\xd11\xa7\x99'\xe1[\xb5\xa6\xed\xbf\xf7GO\x9c\xceN,	%\x84e\xb1\x8e\xa7\xdf\x9c\xbd\xde\xf3m\xe2-4\xb25\xe9Q$\x11\x95|\x0f\x7f\x1dds83\xe6\x82_\x96U\xd2\xbe\xe1\xab\xe7K}\x7f\x96)\xe6\xce\xbf\xe0\x902\xcb\nRQ\xbc\xfd`r\xa3d2\xf7\xc6\xf0\x13\"&R\x90j\x8e?\xbc\x7f\xf8qX\xe2\x91\x04V^\xb4i\x80:\xea\xb5W\xbb\xae\xcd\xaf\xfe\x0d\xfb\x18=\xa3\x0fR\x0b\xa6\x8d\x93\xc7\xea\xd3\xf0\xfeY \xd0:\xa9C\x8c1N9\xbf*\xc3\xc5\xd4\x86\xe4\xe5\x04\x18\x15\xf8p\xdci|u\xac\x12~\x13\xe7,o\xff\xd4aHd(\xf82S\x9fS\x88\xd8\xd0\x11\xa3'\xe5\x96\x03\xb9\xd5\xe3\xe2\x9f\x01\x00PK\x07\x08\xaf(\x9c\xbc\x03\x04\x00\x00u\x11\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00\xf6\"R]\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x006\x00	\x00schemas/sqlite3/migrations/0002_sydent_import.down.sqlUT\x05\x00\x01\xd1I\xd4j\x00$\x00\xdb\xffDROP TABLE IF EXISTS sydent_import;\n\x03\x00PK\x07\x08\xbe9f:+\x00\x00\x00$\x00\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00\xf6\"R]\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x004\x00	\x00schemas/sqlite3/migrations/0002_sydent_import.up.sqlUT\x05\x00\x01\xd1I\xd4j4\xce\xbfK\xc4@\x10\xc5\xf1~\xff\x8aW\xde\xc1\xad\x95\xd8X\x9d\x12\xe1@\x14\xbc\x14va\x92\x9dd\x07\xb3?\x98\x1d\x85\xfc\xf7\xc2E\xfb\x0f\xdf\xf7\xbcG\xd5\xb2(\xb7\x862CR-j\xbem\x81\xb3\x9d\xb0R\xb3A\x02\xa4\xc1\"#\xca\x12\xb9\x19$`*U8`\xd6\x92\xc04E\x18\x8d+;\xefQ\xe6\x9b\xdd\x13\x08d4R\xe3;\xf7\xfc\xd1\x9d\xfb\x0e\xfd\xf9\xe9\xb5\xc3\xe5\x05o\xef=\xba\xcf\xcb\xb5\xbf\xfe\xd9a\x1f\xc7\xc1\x01\xd8{C\xa6\xc4\xf8!\x9d\"\xe9\xe1\xe1\xfe\x88\xaa\x92H7|\xf1v\xba\xb9\xff\x8b\xa3,\x92\x0d\xb9\x18\xf2\xf7\xba\xba\xe3\xa3\xfb\x1d\x00PK\x07\x08m\xe2\xc9\xab\xaa\x00\x00\x00\xdc\x00\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00U#R]\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x009\x00	\x00schemas/sqlite3/migrations/0003_invite_tokens_ms.down.sqlUT\x05\x00\x01\x82J\xd4j\x00V\x00\xa9\xff-- nothing to do, earlier versions read the timestamps as milliseconds too.\nSELECT 1;\n\x03\x00PK\x07\x08J\x94\x9f\xb2]\x00\x00\x00V\x00\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00U#R]\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x007\x00	\x00schemas/sqlite3/migrations/0003_invite_tokens_ms.up.sqlUT\x05\x00\x01\x82J\xd4jt\x8eAK\x03Q\x0c\x84\xef\xfd\x15s\x16W\xd6\xb3\xf6 \xb8\xe0Q\xb4\xe2\xb1<\xfb\x06\x1a\xba\x9b'Ix\xf2\xfe\xbdtq\x95\x15\x9aS&C\xbe\x99\xae\x83h\x95 \xa2\x9c\xa8\x08\x99\xe8\x91\xa6O\xc7\x17\x8d\xf0(\xc6\x0cQ8\x0fE\xb3\xe3\xa3\xc1[\xa6\x06\x92\xe6\xb3b\xb2Qh\x9b\xaeC\xa5\xb9\x14\xf5k\xc4\x91\x0d\xc9\x08c\xcaH\x8eI\xc6Q\x16\xc6('\x82\x95\xd6P\xe2H\xfb\x8b\xbd\xd9\xbc=?>\xec\x86\x9fV\xfb\xb9\x95\xe3u\xd8\xc1x\xa0T\xe6}8\xb6+u\x85\xdb\xbe\xef\xf1\xfe4\xbc\x0c+\xe3~6\x96\xb9\xbb\xccvj\x9c\x1f\xb6\xbf\xdb\x8a\xb9\x1c\xff\xf3\xbe\x07\x00PK\x07\x08A\xd5\xdc\x18\xaf\x00\x00\x00>\x01\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00\x97#R]\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x005\x00	\x00schemas/sqlite3/migrations/0004_email_outbox.down.sqlUT\x05\x00\x01\xfeJ\xd4j\x00#\x00\xdc\xffDROP TABLE IF EXISTS email_outbox;\n\x03\x00PK\x07\x08\x1fv\xc6\x90*\x00\x00\x00#\x00\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00\x97#R]\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x003\x00	\x00schemas/sqlite3/migrations/0004_email_outbox.up.sqlUT\x05\x00\x01\xfeJ\xd4j|\x91Ao\xd3@\x10\x85\xef\xfe\x15\xef\xd6Dj*@\x82KO\x05\x8c\x14	\x8aDs\xe8\xcd\x9ax\x07g\xd4\xf5\xac\x99\x1d\xd7\xe9\xbfG\xf6R	R\x9a\xf3|o\xf6{;\x9b\x0d\x8c5\xb0q\x00\xf7$1c\"q\xd1\x0e\x9e\xb0gdV\xbf\x84\xa5)\x83\x8c\x118\xb2s@\xd2\xb6\xcc\xae\xf0\x8ds\xa6\x8es\xb5\xd9`:H{@K\nM>\xc7\x03Gy\\\xb6\xcf\xe9\x07\x1e\x1c\x93\xf8\x01\xd9\xc9\xe7m\x140\xaaK\x84\xf1\xaf\x91G\x0eW\xd5\xa7\x1f\xf5\xcd\xae\xc6\xee\xe6\xe3\xd7\x1a\xdb/\xb8\xfd\xbeC}\xbf\xbd\xdb\xdd\x15\xc3&\x8d\xbeOG\xac*\x00\x90\x00Q\xe7\x8e\x0d\x83IO\xf6\x84\x07~\x02\x8d\x9eD[\xe3~\xf6_\xc8\xbc\xf4\xc4#Y{ [\xbd{\xffa\xbdX\xea\x18c!\x8c[\x19\x84\xd53\x9c\x8f~2\x0d\xa9'\xd1s\xf9\xbe\xfc\xc4\xff\xc2\xa5\xefs\xf6\xed_Q\x04\xfeIct\\\x0c\xacA\xb4\xbb(\xcf\x91;\xf7\x83g\xec\xa5\x13\xf5\x97\xfc\x9b\xc2)\x1f\xbd\xf9\x037/\xf1\x02\xb5\xc6\xe4\x92\xf4U R\xf6\x86\xcd\x92-\xf6\xd5\xfa\xfa\xf9\x0e\xdb\xdb\xcf\xf5\xfd\x99;4K\xb5\xe6T#\xe9?\xd4j\xa1.Om\xd7\xd7\xd5\xef\x01\x00PK\x07\x08\x994o<=\x01\x00\x00\x82\x02\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00\xc4,R]\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x004\x00	\x00schemas/sqlite3/migrations/0005_rate_limits.down.sqlUT\x05\x00\x01@[\xd4j\x00\"\x00\xdd\xffDROP TABLE IF EXISTS rate_limits;\n\x03\x00PK\x07\x08\xfa\x9b\xfd\x9d)\x00\x00\x00\"\x00\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00\xc4,R]\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x002\x00	\x00schemas/sqlite3/migrations/0005_rate_limits.up.sqlUT\x05\x00\x01@[\xd4jt\xceOK\xc3@\x14\x04\xf0\xfb~\x8a9&\xd0\n\n\x9ez\xaa\xbaB@*\xd8\x1cz\x0b\x9b\xf6\xd5,\xd9l\xea{o-\xfd\xf6\x92?j/=\xff\x86\x99Y.\xa1}K\x11u\xda\xb7\xa4\x82\xfe\x08m\x08\xec\x94\x10|\xe7U\x16SB\xe0e\xa4\x98\xba\x9ax\x082}%\x12\x15\x04:*\xce\x0dE3\xf454\xb7\xe1\xec\x04\xc1\x89\"\x9d\x0eN\xe9pg\x9e?\xec\xba\xb4(\xd7Oo\x16\xc5+6\xef%\xec\xae\xd8\x96\xdbq\xb2\x9a&\x91\x19\x00h\xe9\x82o\xc7\xfb\xc6q\xf6x\xff\x90\xe3\xc4\xbes|\x19`1&\xe6gL. \xf6\x8a\x98B\x98d^\xacTP\xfbO\x1f\xf5\xcfM\xbe\xfa\xbdQl^\xec\xee\xf6\x8d\xea\xaa\xa4\x8f\xd7\x92\xfdK\xbe2?\x03\x00PK\x07\x08\xb2\xcc\xc3M\xc8\x00\x00\x00E\x01\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00\xc4,R]\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x002\x00	\x00schemas/sqlite3/migrations/0006_audit_log.down.sqlUT\x05\x00\x01@[\xd4j\x00 \x00\xdf\xffDROP TABLE IF EXISTS audit_log;\n\x03\x00PK\x07\x08\xc8V\xef}'\x00\x00\x00 \x00\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00\x1b/R]\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x000\x00	\x00schemas/sqlite3/migrations/0006_audit_log.up.sqlUT\x05\x00\x01\xa6_\xd4j\x8c\x92Oo\xdb0\x0c\xc5\xef\xfe\x14\xef\xd8\x00N\x81u\xe8.=u\x9b\x07\x04\x18:\xa0\xcd\xa1\xb7\x80\xb6\x18\x9b\x8dM\x19\x12\x9d?\xdf~P\xect\xe9\x9f\x15=J|\xfc\x91zz\xf39Z_\xc3\xafQ5\xa45G\x98\x07\xc5\xe8+!\x13\xaf1\x87\xe8V\x8ca~\xc3\x1aA\xea\xd03\x87x\x89{\xbf\x8b\xa0\xc0\xd8\x051c\xcd\xe6s\x88\xc2\x1a\x86\x05\xd2HU\"$\xb65<\xf1\x8f\xfd\xca[\x0ep\xdc\xb2\xb1\xcbQ\xf2\xda\x87\xb1Bk\xe3\x90\x98\x89\xf5\x14\xbd\"*\xf5\xb1\xf1\x16\x13g\xd7\x90M w6\xdfk{\xc0\xd0;2v(\x0f\xa8]\x1f\xc0\x81\"\xe7\xd85R5\x89V\xb6\xa4\x9bx\\\x85\x9c\x0b\x1cc\x8en/.\x87\xf4\xf9\xd9\x98\xb4 U\xe6C\x1aH:r\x1c\xe2P>qe\x97\xd9\x8f\xfb\xe2vY`y\xfb\xfdw\x81\xc5/\xdc\xfdY\xa2x\\<,\x1f@\x83\x13[%?/2\x00\x10\x07Q\xe3\x9a\x03\xfa \x1d\x85\x036|\x00\x0d\xe6E\xab\xc0\x1d\xab\xe5G\xa5E\x94R\x8b\x1a\xd4\x1bth\xdb\xf1~\xb2pK\xa1j(\\|\xbd\x9a\xbd\x15\xf8\xb0\xda\x88\xbag\xd1\x97o\xef\x8a`\xbc\x7f\x8d\x97\xfe\xbd\xdb\x8e\x9d\x0c\xddG\xbc\xd1\xbfg\xc5\xd5\xf5\x9b\x91\xc9\xd9\x8f\xea)Bg\xf5\xeb\xd7\xfdS&\xd2v\x93\x13\xc7d\xa4s6\xbb9}\xc2\xe2\xeeg\xf1\xf8\xbfOX\xa5\xcc\xe8\xbf\xf3\x85\xc5\xcfv\x8e\x0e\xacN\xef|A\x19k\xf9\xc9\x84O#\x93!/A{q\xb3\x9b\xec\xef\x00PK\x07\x08\\\x0b\xeb\xa8\x89\x01\x00\x00\x82\x03\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00f\x1dR]\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x1d\x00	\x00sms/verification_template.txtUT\x05\x00\x01P@\xd4j\x00\x18\x00\xe7\xffYour code is {{.token}}\n\x03\x00PK\x07\x08\"\xe9\xf9\x83\x1f\x00\x00\x00\x18\x00\x00\x00PK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\xc4,R]\x05\xcf\xc5o\xff\x05\x00\x00\xb3\x11\x00\x00\x1a\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81\x00\x00\x00\x00email/invite_template.tmplUT\x05\x00\x01@[\xd4jPK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\xc4,R]\x03jX\x178\x07\x00\x00D\x16\x00\x00!\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81P\x06\x00\x00email/invite_template_vector.tmplUT\x05\x00\x01@[\xd4jPK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\xc4,R]\xf8O~\xe4W\x03\x00\x00~	\x00\x00 \x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81\xe0\x0d\x00\x00email/verification_template.tmplUT\x05\x00\x01@[\xd4jPK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\xc4,R]tm\xcb\x11\x99\x06\x00\x00]\x15\x00\x00'\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81\x8e\x11\x00\x00email/verification_template_vector.tmplUT\x05\x00\x01@[\xd4jPK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\xacU\x97N\x0dp\xb3\xe9\x81\x00\x00\x00z\x00\x00\x00#\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xb4\x81\x85\x18\x00\x00email/verify_response_page_templateUT\x05\x00\x01\xc4\xec\xbe\\PK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\xacU\x97N\xdab\x7f\x1e\xd1\x01\x00\x00\xdb\x03\x00\x00-\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xb4\x81`\x19\x00\x00email/verify_response_page_template_vector_imUT\x05\x00\x01\xc4\xec\xbe\\PK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\xc4,R]\xd5\xb1\x11.\x8d\x01\x00\x00\xb5\x03\x00\x00\x1d\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81\x95\x1b\x00\x00policy/disposable_domains.txtUT\x05\x00\x01@[\xd4jPK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\xc9\x1eR]\x94\x18\xa4`\xb3\x00\x00\x00-\x02\x00\x001\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81v\x1d\x00\x00schemas/postgres/migrations/0001_initial.down.sqlUT\x05\x00\x01\xebB\xd4jPK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\xec\x1eR]\x04\x89\xb3k\x1a\x04\x00\x00y\x12\x00\x00/\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81\x91\x1e\x00\x00schemas/postgres/migrations/0001_initial.up.sqlUT\x05\x00\x01,C\xd4jPK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\xf6\"R]\xbe9f:+\x00\x00\x00$\x00\x00\x007\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81\x11#\x00\x00schemas/postgres/migrations/0002_sydent_import.down.sqlUT\x05\x00\x01\xd1I\xd4jPK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\xf6\"R]m\xe2\xc9\xab\xaa\x00\x00\x00\xdc\x00\x00\x005\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81\xaa#\x00\x00schemas/postgres/migrations/0002_sydent_import.up.sqlUT\x05\x00\x01\xd1I\xd4jPK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00U#R]J\x94\x9f\xb2]\x00\x00\x00V\x00\x00\x00:\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81\xc0$\x00\x00schemas/postgres/migrations/0003_invite_tokens_ms.down.sqlUT\x05\x00\x01\x82J\xd4jPK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00U#R]A\xd5\xdc\x18\xaf\x00\x00\x00>\x01\x00\x008\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81\x8e%\x00\x00schemas/postgres/migrations/0003_invite_tokens_ms.up.sqlUT\x05\x00\x01\x82J\xd4jPK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\x97#R]\x1fv\xc6\x90*\x00\x00\x00#\x00\x00\x006\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81\xac&\x00\x00schemas/postgres/migrations/0004_email_outbox.down.sqlUT\x05\x00\x01\xfeJ\xd4jPK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\x97#R]\x8e+K(5\x01\x00\x00v\x02\x00\x004\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81C'\x00\x00schemas/postgres/migrations/0004_email_outbox.up.sqlUT\x05\x00\x01\xfeJ\xd4jPK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\xc4,R]\xfa\x9b\xfd\x9d)\x00\x00\x00\"\x00\x00\x005\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81\xe3(\x00\x00schemas/postgres/migrations/0005_rate_limits.down.sqlUT\x05\x00\x01@[\xd4jPK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\xc4,R]w\xe4\x0c\xbb\xd0\x00\x00\x00Q\x01\x00\x003\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81x)\x00\x00schemas/postgres/migrations/0005_rate_limits.up.sqlUT\x05\x00\x01@[\xd4jPK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\xc4,R]\xc8V\xef}'\x00\x00\x00 \x00\x00\x003\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81\xb2*\x00\x00schemas/postgres/migrations/0006_audit_log.down.sqlUT\x05\x00\x01@[\xd4jPK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\x1b/R]\xc5\x9c\xdd\xd4\x80\x01\x00\x00v\x03\x00\x001\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81C+\x00\x00schemas/postgres/migrations/0006_audit_log.up.sqlUT\x05\x00\x01\xa6_\xd4jPK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\xfa R]\x94\x18\xa4`\xb3\x00\x00\x00-\x02\x00\x000\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81+-\x00\x00schemas/sqlite3/migrations/0001_initial.down.sqlUT\x05\x00\x01\x19F\xd4jPK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\x02!R]\xaf(\x9c\xbc\x03\x04\x00\x00u\x11\x00\x00.\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81E.\x00\x00schemas/sqlite3/migrations/0001_initial.up.sqlUT\x05\x00\x01$F\xd4jPK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\xf6\"R]\xbe9f:+\x00\x00\x00$\x00\x00\x006\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81\xad2\x00\x00schemas/sqlite3/migrations/0002_sydent_import.down.sqlUT\x05\x00\x01\xd1I\xd4jPK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\xf6\"R]m\xe2\xc9\xab\xaa\x00\x00\x00\xdc\x00\x00\x004\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81E3\x00\x00schemas/sqlite3/migrations/0002_sydent_import.up.sqlUT\x05\x00\x01\xd1I\xd4jPK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00U#R]J\x94\x9f\xb2]\x00\x00\x00V\x00\x00\x009\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81Z4\x00\x00schemas/sqlite3/migrations/0003_invite_tokens_ms.down.sqlUT\x05\x00\x01\x82J\xd4jPK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00U#R]A\xd5\xdc\x18\xaf\x00\x00\x00>\x01\x00\x007\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81'5\x00\x00schemas/sqlite3/migrations/0003_invite_tokens_ms.up.sqlUT\x05\x00\x01\x82J\xd4jPK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\x97#R]\x1fv\xc6\x90*\x00\x00\x00#\x00\x00\x005\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81D6\x00\x00schemas/sqlite3/migrations/0004_email_outbox.down.sqlUT\x05\x00\x01\xfeJ\xd4jPK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\x97#R]\x994o<=\x01\x00\x00\x82\x02\x00\x003\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81\xda6\x00\x00schemas/sqlite3/migrations/0004_email_outbox.up.sqlUT\x05\x00\x01\xfeJ\xd4jPK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\xc4,R]\xfa\x9b\xfd\x9d)\x00\x00\x00\"\x00\x00\x004\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81\x818\x00\x00schemas/sqlite3/migrations/0005_rate_limits.down.sqlUT\x05\x00\x01@[\xd4jPK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\xc4,R]\xb2\xcc\xc3M\xc8\x00\x00\x00E\x01\x00\x002\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81\x159\x00\x00schemas/sqlite3/migrations/0005_rate_limits.up.sqlUT\x05\x00\x01@[\xd4jPK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\xc4,R]\xc8V\xef}'\x00\x00\x00 \x00\x00\x002\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81F:\x00\x00schemas/sqlite3/migrations/0006_audit_log.down.sqlUT\x05\x00\x01@[\xd4jPK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\x1b/R]\\\x0b\xeb\xa8\x89\x01\x00\x00\x82\x03\x00\x000\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81\xd6:\x00\x00schemas/sqlite3/migrations/0006_audit_log.up.sqlUT\x05\x00\x01\xa6_\xd4jPK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00f\x1dR]\"\xe9\xf9\x83\x1f\x00\x00\x00\x18\x00\x00\x00\x1d\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81\xc6<\x00\x00sms/verification_template.txtUT\x05\x00\x01P@\xd4jPK\x05\x06\x00\x00\x00\x00 \x00 \x00\xc8\x0c\x00\x009=\x00\x00\x00\x00"
 	fs.Register(data)
 }
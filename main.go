@@ -2,22 +2,36 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
+	"os/exec"
+	"os/signal"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
 
+	"github.com/gernest/sydent-go/clients"
 	"github.com/gernest/sydent-go/service"
 	"github.com/gernest/sydent-go/store"
+	"github.com/gernest/sydent-go/store/schema"
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/gernest/sydent-go/core"
-	"github.com/gernest/sydent-go/embed"
 	"github.com/gernest/sydent-go/logger"
-	"github.com/gernest/sydent-go/store/query"
-	"github.com/gernest/sydent-go/store/schema"
+	"github.com/gernest/sydent-go/models"
 	"go.uber.org/zap"
 
 	"github.com/gernest/sydent-go/config"
@@ -31,7 +45,7 @@ func main() {
 	app.Name = config.ApplicationName
 	app.Version = version
 	app.Usage = "matrix identity service in Go"
-	app.Commands = []cli.Command{id()}
+	app.Commands = []cli.Command{id(), rotatePepper(), peer(), migrate(), importSydent(), outbox(), audit(), gdpr(), templates(), email()}
 	err := app.Run(os.Args)
 	if err != nil {
 		fmt.Println(err)
@@ -39,31 +53,57 @@ func main() {
 	}
 }
 
+// loadConfig reads the configuration file passed as the first argument, the
+// configuration is loaded from environment variables when there is no file.
+func loadConfig(ctx *cli.Context) (*config.Matrix, error) {
+	return loadConfigFile(ctx.Args().First())
+}
+
+// loadConfigFile reads the configuration file, the configuration is loaded
+// from environment variables when file is empty.
+func loadConfigFile(file string) (*config.Matrix, error) {
+	var c *config.Matrix
+	if file != "" {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		c, err = config.LoadFile(config.ProcessFile(b))
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		c = config.LoadFromEnv()
+	}
+	if config.Empty(c) {
+		return nil, errors.New("missing configuration file")
+	}
+	return c, nil
+}
+
+// openStore connects to the database and makes sure the schema is up to date.
+// The returned io.Closer must be closed by the caller.
+func openStore(c *config.Matrix) (store.Store, io.Closer, error) {
+	storeMetrics := store.NewMetric(prometheus.Opts{
+		Namespace: "matrix",
+		Subsystem: "storage",
+	})
+	canonical, err := c.Canonical.Canonicalizer()
+	if err != nil {
+		return nil, nil, err
+	}
+	return store.Open(context.Background(), c.DB, canonical, storeMetrics)
+}
+
 func id() cli.Command {
 	return cli.Command{
 		Name:  "serve",
 		Usage: "starts the identity service server",
 		Action: func(ctx *cli.Context) error {
-			file := ctx.Args().First()
-			var c *config.Matrix
-			var err error
-			if file != "" {
-				b, err := ioutil.ReadFile(file)
-				if err != nil {
-					return err
-				}
-				c, err = config.LoadFile(config.ProcessFile(b))
-				if err != nil {
-					return err
-				}
-			} else {
-				c = config.LoadFromEnv()
-			}
-
-			if config.Empty(c) {
-				return errors.New("missing configuration file")
+			c, err := loadConfig(ctx)
+			if err != nil {
+				return err
 			}
-
 			lg, err := logger.New()
 			if err != nil {
 				return err
@@ -72,7 +112,7 @@ func id() cli.Command {
 			if !c.Validate(lg) {
 				return nil
 			}
-			db, err := sql.Open(c.DB.Driver, c.DB.Conn)
+			storage, db, err := openStore(c)
 			if err != nil {
 				return err
 			}
@@ -81,27 +121,1012 @@ func id() cli.Command {
 				Config: c,
 				Log:    lg.With(zap.Namespace("matrix")),
 			}
-			vfs := embed.New()
-			sq := query.New(db)
-			storeMetrics := store.NewMetric(prometheus.Opts{
-				Namespace: "matrix",
-				Subsystem: "storage",
-			})
-			storage := store.NewStore(sq, storeMetrics)
-			err = schema.IdentityUp(context.Background(), vfs, storage.DB())
+			opts.Store = storage
+			opts.OnBind = core.NewSignal()
+			opts.Replication = core.NewSignal()
+			opts.Outbox = core.NewSignal()
+			if c.RateLimit.Backend != config.RateLimitDatabase {
+				opts.Limits = store.NewMemory()
+			}
+			opts.Captcha, err = c.Captcha.CaptchaVerifier()
 			if err != nil {
 				return err
 			}
-			mail, err := c.Email.Provider(c.GetTemplate())
+			opts.Policy, err = c.AddressPolicies.Checker()
 			if err != nil {
 				return err
 			}
-			opts.Email = mail
-			opts.Store = storage
+			opts.Canonical, err = c.Canonical.Canonicalizer()
+			if err != nil {
+				return err
+			}
+			opts.Proxies, err = c.Server.Proxies()
+			if err != nil {
+				return err
+			}
+			mailClient, err := c.Email.Client()
+			if err != nil {
+				return err
+			}
+			if mailClient != nil {
+				outbox := service.NewOutbox(storage, opts.Outbox, mailClient)
+				opts.Email, err = c.Email.Mail(outbox, c.GetTemplate())
+				if err != nil {
+					return err
+				}
+				go service.OutboxWorker(opts.Namespace("outbox"), mailClient)(context.Background())
+			} else {
+				opts.Email = config.NoopMail{}
+			}
+			sms, err := c.Msisdn.Provider(c.GetTemplate())
+			if err != nil {
+				return err
+			}
+			opts.SMS = sms
+			err = service.EnsureLookupPepper(context.Background(), &opts)
+			if err != nil {
+				return err
+			}
+			if d := c.Server.PepperRotation(); d > 0 {
+				go service.PepperRotator(opts.Namespace("pepper"), d)(context.Background())
+			}
+			onbindClient := &http.Client{
+				Transport: clients.NewFederatedTripper(0),
+				Timeout:   time.Minute,
+			}
+			go service.OnbindNotifier(opts.Namespace("onbind"), onbindClient)(context.Background())
+			go service.Janitor(opts.Namespace("janitor"), service.NewJanitorMetric())(context.Background())
 			m := service.NewMetric()
+			errs := make(chan error, 2)
+			if r := c.Server.Replication; r.Enabled() {
+				clientTLS, err := r.ClientTLS()
+				if err != nil {
+					return err
+				}
+				opts.ReplicationClient = &http.Client{
+					Transport: &http.Transport{TLSClientConfig: clientTLS},
+					Timeout:   time.Minute,
+				}
+				go service.ReplicationPusher(opts.Namespace("replication"), opts.ReplicationClient)(context.Background())
+				serverTLS, err := r.ServerTLS()
+				if err != nil {
+					return err
+				}
+				replication := &http.Server{
+					Addr:      r.Address(),
+					Handler:   service.ReplicationService(opts.Namespace("replication"), m),
+					TLSConfig: serverTLS,
+				}
+				lg.Info("starting replication listener", zap.String("address", r.Address()))
+				go func() {
+					errs <- replication.ListenAndServeTLS("", "")
+				}()
+			}
 			e := service.Service(opts.Namespace(config.ApplicationName), m)
-			lg.Info("statring matrix identity service", zap.String("address", c.Server.Address()))
-			return http.ListenAndServe(c.Server.Address(), e)
+			lg.Info("statring matrix identity service",
+				zap.String("address", c.Server.Address()),
+				zap.Bool("tls", c.Server.TLS()),
+			)
+			err = listen(c.Server, lg, e, errs)
+			if err != nil {
+				return err
+			}
+			return <-errs
+		},
+	}
+}
+
+// listen starts the main server, errors from the listeners are sent to errs.
+//
+// TLS certificates are either managed with ACME or loaded from disk, in the
+// latter case they are loaded again on SIGHUP.
+func listen(s config.Server, lg logger.Logger, h http.Handler, errs chan<- error) error {
+	srv := &http.Server{
+		Addr:    s.Address(),
+		Handler: h,
+	}
+	if !s.TLS() {
+		go func() {
+			errs <- srv.ListenAndServe()
+		}()
+		return nil
+	}
+	redirect := config.RedirectHTTPS(s.Port)
+	if s.ACME.Enabled() {
+		m, err := s.ACME.Manager()
+		if err != nil {
+			return err
+		}
+		srv.TLSConfig = m.TLSConfig()
+		redirect = m.HTTPHandler(redirect)
+	} else {
+		r, err := config.NewCertReloader(s.CertFile, s.KeyFile)
+		if err != nil {
+			return err
+		}
+		srv.TLSConfig = &tls.Config{
+			GetCertificate: r.GetCertificate,
+			MinVersion:     tls.VersionTLS12,
+		}
+		hup := make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+		go func() {
+			for range hup {
+				if err := r.Reload(); err != nil {
+					lg.Error("failed to reload tls certificate", zap.Error(err))
+					continue
+				}
+				lg.Info("reloaded tls certificate")
+			}
+		}()
+	}
+	if s.HTTPPort != "" {
+		lg.Info("redirecting http to https", zap.String("address", s.HTTPAddress()))
+		go func() {
+			errs <- http.ListenAndServe(s.HTTPAddress(), redirect)
+		}()
+	}
+	go func() {
+		errs <- srv.ListenAndServeTLS("", "")
+	}()
+	return nil
+}
+
+func rotatePepper() cli.Command {
+	return cli.Command{
+		Name:      "rotate-pepper",
+		Usage:     "replaces the pepper used for hashed lookups",
+		ArgsUsage: "[config file]",
+		Action: func(ctx *cli.Context) error {
+			c, err := loadConfig(ctx)
+			if err != nil {
+				return err
+			}
+			storage, db, err := openStore(c)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+			pepper, err := service.RotateLookupPepper(context.Background(), &core.Ctx{
+				Config: c,
+				Store:  storage,
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Printf("lookup pepper rotated, new pepper is %s\n", pepper)
+			return nil
+		},
+	}
+}
+
+var configFlag = cli.StringFlag{
+	Name:  "config, c",
+	Usage: "configuration file, environment variables are used when not set",
+}
+
+func peer() cli.Command {
+	return cli.Command{
+		Name:  "peer",
+		Usage: "manages replication peers",
+		Subcommands: []cli.Command{
+			{
+				Name:      "add",
+				Usage:     "adds an inactive peer, or updates the port of an existing peer",
+				ArgsUsage: "<name>",
+				Flags: []cli.Flag{
+					configFlag,
+					cli.Int64Flag{
+						Name:  "port",
+						Usage: "replication port of the peer, defaults to " + config.DefaultReplicationPort,
+					},
+				},
+				Action: withStore(func(ctx *cli.Context, coreContext *core.Ctx) error {
+					name := ctx.Args().First()
+					if err := service.ValidPeerName(name); err != nil {
+						return err
+					}
+					var port sql.NullInt64
+					if ctx.IsSet("port") {
+						port.Int64 = ctx.Int64("port")
+						port.Valid = true
+					}
+					return coreContext.Store.AddPeer(cliContext(), name, port)
+				}),
+			},
+			{
+				Name:  "list",
+				Usage: "lists peers with their replication lag",
+				Flags: []cli.Flag{configFlag},
+				Action: withStore(func(ctx *cli.Context, coreContext *core.Ctx) error {
+					peers, err := service.PeerStatuses(context.Background(), coreContext)
+					if err != nil {
+						return err
+					}
+					w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+					fmt.Fprintln(w, "NAME\tPORT\tACTIVE\tKEYS\tLAST_SENT\tPENDING\tLAST_POKE")
+					for _, p := range peers {
+						port := config.DefaultReplicationPort
+						if p.Port != 0 {
+							port = strconv.FormatInt(p.Port, 10)
+						}
+						var algs []string
+						for alg := range p.PublicKeys {
+							algs = append(algs, alg)
+						}
+						sort.Strings(algs)
+						keys := strings.Join(algs, ",")
+						if keys == "" {
+							keys = "-"
+						}
+						lastPoke := "-"
+						if p.LastPokeSucceededAt != 0 {
+							lastPoke = time.Unix(0, p.LastPokeSucceededAt*int64(time.Millisecond)).UTC().Format(time.RFC3339)
+						}
+						fmt.Fprintf(w, "%s\t%s\t%v\t%s\t%d\t%d\t%s\n",
+							p.Name, port, p.Active, keys, p.LastSentVersion, p.Pending, lastPoke,
+						)
+					}
+					return w.Flush()
+				}),
+			},
+			{
+				Name:      "remove",
+				Usage:     "removes a peer and its public keys",
+				ArgsUsage: "<name>",
+				Flags:     []cli.Flag{configFlag},
+				Action: withStore(func(ctx *cli.Context, coreContext *core.Ctx) error {
+					return peerNotFound(ctx, coreContext.Store.RemovePeer(cliContext(), ctx.Args().First()))
+				}),
+			},
+			{
+				Name:      "set-key",
+				Usage:     "sets the public key used to verify associations signed by a peer",
+				ArgsUsage: "<name> <base64 key>",
+				Flags: []cli.Flag{
+					configFlag,
+					cli.StringFlag{
+						Name:  "alg",
+						Usage: "key algorithm",
+						Value: service.SIGNING_KEY_ALGORITHM,
+					},
+				},
+				Action: withStore(func(ctx *cli.Context, coreContext *core.Ctx) error {
+					alg, key := ctx.String("alg"), ctx.Args().Get(1)
+					if err := service.ValidPeerKey(alg, key); err != nil {
+						return err
+					}
+					return peerNotFound(ctx, coreContext.Store.SetPeerKey(cliContext(), ctx.Args().First(), alg, key))
+				}),
+			},
+			{
+				Name:      "activate",
+				Usage:     "enables replication with a peer",
+				ArgsUsage: "<name>",
+				Flags: []cli.Flag{
+					configFlag,
+					cli.BoolFlag{
+						Name:  "disable",
+						Usage: "disables replication with the peer instead",
+					},
+				},
+				Action: withStore(func(ctx *cli.Context, coreContext *core.Ctx) error {
+					active := !ctx.Bool("disable")
+					return peerNotFound(ctx, coreContext.Store.SetPeerActive(cliContext(), ctx.Args().First(), active))
+				}),
+			},
 		},
 	}
 }
+
+func migrate() cli.Command {
+	return cli.Command{
+		Name:  "migrate",
+		Usage: "manages the database schema",
+		Subcommands: []cli.Command{
+			{
+				Name:  "up",
+				Usage: "applies all pending migrations",
+				Flags: []cli.Flag{configFlag},
+				Action: withMigrator(func(ctx *cli.Context, m *schema.Migrator) error {
+					return m.Up(context.Background())
+				}),
+			},
+			{
+				Name:  "down",
+				Usage: "rolls back the newest applied migration",
+				Flags: []cli.Flag{configFlag},
+				Action: withMigrator(func(ctx *cli.Context, m *schema.Migrator) error {
+					return m.Down(context.Background())
+				}),
+			},
+			{
+				Name:      "to",
+				Usage:     "migrates up or down to a schema version, 0 drops everything",
+				ArgsUsage: "<version>",
+				Flags:     []cli.Flag{configFlag},
+				Action: withMigrator(func(ctx *cli.Context, m *schema.Migrator) error {
+					v, err := strconv.ParseInt(ctx.Args().First(), 10, 64)
+					if err != nil {
+						return fmt.Errorf("invalid schema version %q", ctx.Args().First())
+					}
+					return m.To(context.Background(), v)
+				}),
+			},
+			{
+				Name:  "status",
+				Usage: "lists migrations and when they were applied",
+				Flags: []cli.Flag{configFlag},
+				Action: withMigrator(func(ctx *cli.Context, m *schema.Migrator) error {
+					status, err := m.Status(context.Background())
+					if err != nil {
+						return err
+					}
+					w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+					fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED")
+					for _, s := range status {
+						applied := "pending"
+						if s.Applied {
+							applied = time.Unix(0, s.AppliedAt*int64(time.Millisecond)).UTC().Format(time.RFC3339)
+						}
+						if s.Up == "" {
+							applied += " (unknown to this binary)"
+						}
+						fmt.Fprintf(w, "%d\t%s\t%s\n", s.Version, s.Name, applied)
+					}
+					return w.Flush()
+				}),
+			},
+			{
+				Name:  "canonicalize",
+				Usage: "rewrites stored addresses to their canonical form and reports collisions",
+				Flags: []cli.Flag{
+					configFlag,
+					cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "reports what would change without changing anything",
+					},
+				},
+				Action: withStore(func(ctx *cli.Context, coreContext *core.Ctx) error {
+					m, ok := coreContext.Store.(*store.Matrix)
+					if !ok {
+						return fmt.Errorf("canonicalize does not support the %s driver", coreContext.Config.DB.Driver)
+					}
+					result, err := store.Canonicalize(cliContext(), m, ctx.Bool("dry-run"))
+					if err != nil {
+						return err
+					}
+					w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+					fmt.Fprintln(w, "TABLE\tCHECKED\tUPDATED\tCOLLISIONS")
+					for _, r := range result {
+						fmt.Fprintf(w, "%s\t%d\t%d\t%d\n", r.Table, r.Checked, r.Updated, len(r.Collisions))
+					}
+					if err := w.Flush(); err != nil {
+						return err
+					}
+					unresolved := 0
+					for _, r := range result {
+						for _, c := range r.Collisions {
+							state := "updated"
+							if !c.Updated {
+								state = "left alone"
+								unresolved++
+							}
+							fmt.Printf("\n%s %s %s (%s):\n", r.Table, c.Medium, c.Address, state)
+							for _, row := range c.Rows {
+								fmt.Printf("  id %d\t%s\t%s\n", row.ID, row.Address, row.MatrixID)
+							}
+						}
+					}
+					if unresolved > 0 {
+						return cli.NewExitError(fmt.Sprintf("%d collisions were left alone and must be resolved by hand", unresolved), 1)
+					}
+					return nil
+				}),
+			},
+		},
+	}
+}
+
+func importSydent() cli.Command {
+	return cli.Command{
+		Name:      "import-sydent",
+		Usage:     "copies the data of a sydent sqlite database, it can be run again to resume or pick up new rows",
+		ArgsUsage: "<path/to/sydent.db>",
+		Flags: []cli.Flag{
+			configFlag,
+			cli.IntFlag{
+				Name:  "batch",
+				Usage: "number of rows copied in one transaction",
+				Value: 1000,
+			},
+		},
+		Action: func(ctx *cli.Context) error {
+			file := ctx.Args().First()
+			if file == "" {
+				return errors.New("missing path to the sydent database")
+			}
+			if _, err := os.Stat(file); err != nil {
+				return err
+			}
+			if ctx.Int("batch") < 1 {
+				return errors.New("batch must be at least 1")
+			}
+			c, err := loadConfigFile(ctx.String("config"))
+			if err != nil {
+				return err
+			}
+			storage, db, err := openStore(c)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+			m, ok := storage.(*store.Matrix)
+			if !ok {
+				return fmt.Errorf("import-sydent does not support the %s driver", c.DB.Driver)
+			}
+			src, err := sql.Open(config.SQLite, "file:"+file+"?mode=ro")
+			if err != nil {
+				return err
+			}
+			defer src.Close()
+			progress := func(r store.SydentResult) {
+				fmt.Printf("%s: copied up to id %d\n", r.Table, r.LastID)
+			}
+			result, err := store.ImportSydent(context.Background(), src, m, ctx.Int("batch"), progress)
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "TABLE\tIMPORTED\tSKIPPED\tLAST_ID")
+			for _, r := range result {
+				if r.Missing {
+					fmt.Fprintf(w, "%s\t-\t-\tmissing in sydent database\n", r.Table)
+					continue
+				}
+				fmt.Fprintf(w, "%s\t%d\t%d\t%d\n", r.Table, r.Imported, r.Skipped, r.LastID)
+			}
+			w.Flush()
+			return err
+		},
+	}
+}
+
+// outbox returns the command managing the queue of outgoing emails.
+func outbox() cli.Command {
+	return cli.Command{
+		Name:  "outbox",
+		Usage: "inspects and requeues queued emails",
+		Subcommands: []cli.Command{
+			{
+				Name:  "list",
+				Usage: "lists pending emails, or dead letters with --dead",
+				Flags: []cli.Flag{
+					configFlag,
+					cli.BoolFlag{
+						Name:  "dead",
+						Usage: "lists emails which failed to send",
+					},
+					cli.IntFlag{
+						Name:  "limit",
+						Usage: "maximum number of emails to list",
+						Value: 100,
+					},
+				},
+				Action: withStore(func(ctx *cli.Context, coreContext *core.Ctx) error {
+					state := models.EmailPending
+					if ctx.Bool("dead") {
+						state = models.EmailDead
+					}
+					emails, err := coreContext.Store.ListEmails(context.Background(), state, ctx.Int("limit"))
+					if err != nil {
+						return err
+					}
+					w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+					fmt.Fprintln(w, "ID\tTO\tATTEMPTS\tNEXT_ATTEMPT\tLAST_ERROR")
+					for _, e := range emails {
+						lastError := e.LastError
+						if lastError == "" {
+							lastError = "-"
+						}
+						fmt.Fprintf(w, "%d\t%s\t%d\t%s\t%s\n",
+							e.ID, strings.Join(e.To, ","), e.Attempts,
+							models.FromMS(e.NextAttempt).UTC().Format(time.RFC3339), lastError,
+						)
+					}
+					return w.Flush()
+				}),
+			},
+			{
+				Name:      "requeue",
+				Usage:     "resets the attempts of emails and sends them again",
+				ArgsUsage: "<id>...",
+				Flags:     []cli.Flag{configFlag},
+				Action: withStore(func(ctx *cli.Context, coreContext *core.Ctx) error {
+					return eachEmail(ctx, coreContext.Store.RequeueEmail)
+				}),
+			},
+			{
+				Name:      "delete",
+				Usage:     "removes emails from the outbox without sending them",
+				ArgsUsage: "<id>...",
+				Flags:     []cli.Flag{configFlag},
+				Action: withStore(func(ctx *cli.Context, coreContext *core.Ctx) error {
+					return eachEmail(ctx, coreContext.Store.DeleteEmail)
+				}),
+			},
+		},
+	}
+}
+
+// auditBatch is the number of audit log entries read at once.
+const auditBatch = 1000
+
+func audit() cli.Command {
+	return cli.Command{
+		Name:  "audit",
+		Usage: "inspects the audit log of changes to associations, invite tokens and peers",
+		Subcommands: []cli.Command{
+			{
+				Name:  "query",
+				Usage: "lists audit log entries, oldest first",
+				Flags: []cli.Flag{
+					configFlag,
+					cli.StringFlag{
+						Name:  "medium",
+						Usage: "only entries of the medium, required with --address",
+					},
+					cli.StringFlag{
+						Name:  "address",
+						Usage: "only entries of the address",
+					},
+					cli.StringFlag{
+						Name:  "mxid",
+						Usage: "only entries of the matrix id",
+					},
+					cli.StringFlag{
+						Name:  "peer",
+						Usage: "only entries of the peer",
+					},
+					cli.StringFlag{
+						Name:  "action",
+						Usage: "only entries of the action, like bind or unbind",
+					},
+					cli.StringFlag{
+						Name:  "since",
+						Usage: "only entries recorded since, a RFC 3339 time or a duration like 24h",
+					},
+					cli.StringFlag{
+						Name:  "until",
+						Usage: "only entries recorded before, a RFC 3339 time or a duration like 1h",
+					},
+					cli.IntFlag{
+						Name:  "limit",
+						Usage: "maximum number of entries, 0 lists all of them",
+						Value: 100,
+					},
+					cli.BoolFlag{
+						Name:  "json",
+						Usage: "exports the entries as json, one object per line",
+					},
+				},
+				Action: withStore(func(ctx *cli.Context, coreContext *core.Ctx) error {
+					q := models.AuditQuery{
+						Medium:   ctx.String("medium"),
+						Address:  ctx.String("address"),
+						MatrixID: ctx.String("mxid"),
+						Peer:     ctx.String("peer"),
+						Action:   ctx.String("action"),
+					}
+					if q.Address != "" && q.Medium == "" {
+						return errors.New("--address requires --medium")
+					}
+					var err error
+					if q.Since, err = auditTime(ctx.String("since")); err != nil {
+						return fmt.Errorf("invalid --since: %v", err)
+					}
+					if q.Until, err = auditTime(ctx.String("until")); err != nil {
+						return fmt.Errorf("invalid --until: %v", err)
+					}
+					var w *tabwriter.Writer
+					enc := json.NewEncoder(os.Stdout)
+					if !ctx.Bool("json") {
+						w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+						fmt.Fprintln(w, "ID\tTIME\tACTION\tACTOR\tIP\tSUBJECT")
+					}
+					limit := ctx.Int("limit")
+					for n := 0; limit == 0 || n < limit; {
+						q.Limit = auditBatch
+						if limit != 0 && limit-n < q.Limit {
+							q.Limit = limit - n
+						}
+						entries, err := coreContext.Store.AuditLog(context.Background(), q)
+						if err != nil {
+							return err
+						}
+						for _, e := range entries {
+							if w == nil {
+								if err := enc.Encode(e); err != nil {
+									return err
+								}
+								continue
+							}
+							fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n",
+								e.ID, models.FromMS(e.TS).UTC().Format(time.RFC3339), e.Action,
+								auditActor(e.Actor), orDash(e.Actor.IP), auditSubject(e),
+							)
+						}
+						n += len(entries)
+						if len(entries) < q.Limit {
+							break
+						}
+						q.AfterID = entries[len(entries)-1].ID
+					}
+					if w != nil {
+						return w.Flush()
+					}
+					return nil
+				}),
+			},
+		},
+	}
+}
+
+func gdpr() cli.Command {
+	flags := []cli.Flag{
+		configFlag,
+		cli.StringFlag{
+			Name:  "medium",
+			Usage: "medium of the address, email or msisdn",
+		},
+		cli.StringFlag{
+			Name:  "address",
+			Usage: "address of the subject",
+		},
+		cli.StringFlag{
+			Name:  "mxid",
+			Usage: "matrix id of the subject, instead of an address",
+		},
+	}
+	return cli.Command{
+		Name:  "gdpr",
+		Usage: "exports or erases the data of an address or matrix id",
+		Subcommands: []cli.Command{
+			{
+				Name:  "export",
+				Usage: "prints the associations, invite tokens, validation sessions and audit log entries of the subject as json",
+				Flags: flags,
+				Action: withStore(func(ctx *cli.Context, coreContext *core.Ctx) error {
+					s, err := subjectFlags(ctx)
+					if err != nil {
+						return err
+					}
+					o, err := coreContext.Store.ExportSubject(context.Background(), s)
+					if err != nil {
+						return err
+					}
+					enc := json.NewEncoder(os.Stdout)
+					enc.SetIndent("", "  ")
+					return enc.Encode(o)
+				}),
+			},
+			{
+				Name: "erase",
+				Usage: "erases the associations, invite tokens and validation sessions of the subject, " +
+					"the running server replicates the removal of the associations to peers",
+				Flags: flags,
+				Action: withStore(func(ctx *cli.Context, coreContext *core.Ctx) error {
+					s, err := subjectFlags(ctx)
+					if err != nil {
+						return err
+					}
+					o, err := service.EraseSubject(cliContext(), coreContext, s)
+					if err != nil {
+						return err
+					}
+					enc := json.NewEncoder(os.Stdout)
+					enc.SetIndent("", "  ")
+					if err := enc.Encode(o); err != nil {
+						return err
+					}
+					if len(o.Skipped) > 0 {
+						return cli.NewExitError(fmt.Sprintf(
+							"%d addresses are bound to other matrix ids too and were left alone, they must be erased by address",
+							len(o.Skipped),
+						), 1)
+					}
+					return nil
+				}),
+			},
+		},
+	}
+}
+
+// subjectFlags returns the subject given with the medium, address and mxid
+// flags.
+func subjectFlags(ctx *cli.Context) (models.Subject, error) {
+	s := models.Subject{
+		Medium:   ctx.String("medium"),
+		Address:  ctx.String("address"),
+		MatrixID: ctx.String("mxid"),
+	}
+	return s, service.ValidSubject(s)
+}
+
+// auditTime parses a RFC 3339 time, or a duration which is subtracted from the
+// current time, and returns it in milliseconds. 0 is returned for empty s.
+func auditTime(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		t := time.Now().Add(-d)
+		return models.MS(&t), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return 0, err
+	}
+	return models.MS(&t), nil
+}
+
+func auditActor(a models.Actor) string {
+	if a.ID == "" {
+		return a.Kind
+	}
+	return a.Kind + ":" + a.ID
+}
+
+// auditSubject returns what the audit entry e changed.
+func auditSubject(e models.AuditEntry) string {
+	if e.Peer != "" {
+		return "peer " + e.Peer
+	}
+	s := e.Medium + " " + e.Address
+	if e.MatrixID != "" {
+		s += " " + e.MatrixID
+	}
+	return s
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func templates() cli.Command {
+	return cli.Command{
+		Name:  "templates",
+		Usage: "inspects email and page templates",
+		Subcommands: []cli.Command{
+			{
+				Name:  "check",
+				Usage: "reports localized templates which are missing or do not use all the keys of the default template",
+				Flags: []cli.Flag{configFlag},
+				Action: func(ctx *cli.Context) error {
+					c, err := loadConfigFile(ctx.String("config"))
+					if err != nil {
+						return err
+					}
+					if err := c.LoadTemplates(); err != nil {
+						return err
+					}
+					problems := c.CheckTemplates()
+					if len(problems) == 0 {
+						fmt.Println("ok")
+						return nil
+					}
+					w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+					fmt.Fprintln(w, "TEMPLATE\tLOCALE\tPROBLEM")
+					for _, p := range problems {
+						problem := "missing"
+						if !p.Missing {
+							problem = "missing keys " + strings.Join(p.Keys, ",")
+						}
+						fmt.Fprintf(w, "%s\t%s\t%s\n", p.Template, p.Locale, problem)
+					}
+					if err := w.Flush(); err != nil {
+						return err
+					}
+					return cli.NewExitError(fmt.Sprintf("%d template problems", len(problems)), 1)
+				},
+			},
+		},
+	}
+}
+
+func email() cli.Command {
+	return cli.Command{
+		Name:  "email",
+		Usage: "renders email templates",
+		Subcommands: []cli.Command{
+			{
+				Name:      "preview",
+				Usage:     "renders an email template to stdout, or opens its html part in a browser",
+				ArgsUsage: "<template>",
+				Flags: []cli.Flag{
+					configFlag,
+					cli.StringSliceFlag{
+						Name:  "data",
+						Usage: "key=value passed to the template, can be repeated",
+					},
+					cli.StringFlag{
+						Name:  "locale",
+						Usage: "renders the template localized for locale",
+					},
+					cli.StringFlag{
+						Name:  "to",
+						Usage: "recipient of the email",
+						Value: "alice@example.org",
+					},
+					cli.BoolFlag{
+						Name:  "browser",
+						Usage: "opens the html part in a browser instead of printing the email",
+					},
+				},
+				Action: func(ctx *cli.Context) error {
+					if ctx.NArg() != 1 {
+						return errors.New("missing template name")
+					}
+					c, err := loadConfigFile(ctx.String("config"))
+					if err != nil {
+						return err
+					}
+					if err := c.LoadTemplates(); err != nil {
+						return err
+					}
+					data := make(map[string]string)
+					for _, kv := range ctx.StringSlice("data") {
+						p := strings.SplitN(kv, "=", 2)
+						if len(p) != 2 {
+							return fmt.Errorf("invalid data %q, expected key=value", kv)
+						}
+						data[p[0]] = p[1]
+					}
+					name, _ := c.LocalizedTemplate(ctx.Args().First(), ctx.String("locale"))
+					if !c.GetTemplate().Has(name) {
+						return fmt.Errorf("unknown template %q", name)
+					}
+					from := c.Email.Invite.From
+					if from == "" {
+						from = "sydent@localhost"
+					}
+					k, err := config.New(nil, c.GetTemplate())
+					if err != nil {
+						return err
+					}
+					msg, err := k.Compose(name, from, []string{ctx.String("to")}, data)
+					if err != nil {
+						return err
+					}
+					if !ctx.Bool("browser") {
+						_, err = os.Stdout.Write(msg)
+						return err
+					}
+					_, _, html, err := config.MailParts(msg)
+					if err != nil {
+						return err
+					}
+					if html == "" {
+						return fmt.Errorf("%s has no html part", name)
+					}
+					return openBrowser(name, html)
+				},
+			},
+		},
+	}
+}
+
+// openBrowser writes the html page to a temporary file and opens it with the
+// default browser.
+func openBrowser(name, html string) error {
+	dir, err := ioutil.TempDir("", "sydent-go-preview")
+	if err != nil {
+		return err
+	}
+	file := filepath.Join(dir, name+".html")
+	if err := ioutil.WriteFile(file, []byte(html), 0600); err != nil {
+		return err
+	}
+	fmt.Println(file)
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", file)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", file)
+	default:
+		cmd = exec.Command("xdg-open", file)
+	}
+	return cmd.Start()
+}
+
+// eachEmail calls fn with the email ids passed as arguments.
+func eachEmail(ctx *cli.Context, fn func(context.Context, int64) error) error {
+	if ctx.NArg() == 0 {
+		return errors.New("missing email id")
+	}
+	for _, arg := range ctx.Args() {
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid email id %q", arg)
+		}
+		if err := fn(context.Background(), id); err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("email %d not found", id)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// withMigrator loads the configuration from the config flag and connects to
+// the database before calling fn. The schema version is printed when fn
+// succeeds.
+func withMigrator(fn func(*cli.Context, *schema.Migrator) error) cli.ActionFunc {
+	return func(ctx *cli.Context) error {
+		c, err := loadConfigFile(ctx.String("config"))
+		if err != nil {
+			return err
+		}
+		m, db, err := store.OpenMigrator(c.DB)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		if err := fn(ctx, m); err != nil {
+			return err
+		}
+		v, err := m.Version(context.Background())
+		if err != nil {
+			return err
+		}
+		fmt.Printf("schema version %d, latest is %d\n", v, m.Latest())
+		return nil
+	}
+}
+
+// cliContext returns the context of commands which change the store, the
+// changes are attributed to the user running the command in the audit log.
+func cliContext() context.Context {
+	name := os.Getenv("USER")
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	return models.WithActor(context.Background(), models.Actor{
+		Kind: models.ActorCLI,
+		ID:   name,
+	})
+}
+
+// withStore loads the configuration from the config flag and opens the
+// store before calling fn.
+func withStore(fn func(*cli.Context, *core.Ctx) error) cli.ActionFunc {
+	return func(ctx *cli.Context) error {
+		c, err := loadConfigFile(ctx.String("config"))
+		if err != nil {
+			return err
+		}
+		storage, db, err := openStore(c)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		canonical, err := c.Canonical.Canonicalizer()
+		if err != nil {
+			return err
+		}
+		return fn(ctx, &core.Ctx{
+			Config:    c,
+			Store:     storage,
+			Canonical: canonical,
+		})
+	}
+}
+
+func peerNotFound(ctx *cli.Context, err error) error {
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("peer %q not found", ctx.Args().First())
+	}
+	return err
+}
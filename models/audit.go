@@ -0,0 +1,115 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Kinds of actors recorded in the audit log, see Actor.
+const (
+	// ActorSession is a validation session, the id is the sid.
+	ActorSession = "session"
+
+	// ActorServer is a homeserver which signed the request, the id is its
+	// name.
+	ActorServer = "server"
+
+	// ActorPeer is a replication peer, the id is its name.
+	ActorPeer = "peer"
+
+	// ActorAccount is an account authenticated with an access token, the id
+	// is the user id.
+	ActorAccount = "account"
+
+	// ActorClient is an unauthenticated client.
+	ActorClient = "client"
+
+	// ActorAdmin is a request to the admin api.
+	ActorAdmin = "admin"
+
+	// ActorCLI is a command, the id is the user running it.
+	ActorCLI = "cli"
+
+	// ActorSystem is used when the context of a change has no actor.
+	ActorSystem = "system"
+)
+
+// Actions recorded in the audit log.
+const (
+	AuditBind         = "bind"
+	AuditUnbind       = "unbind"
+	AuditGlobalAdd    = "global_add"
+	AuditGlobalRemove = "global_remove"
+	AuditInviteStore  = "invite_store"
+	AuditInviteSent   = "invite_sent"
+	AuditPeerAdd      = "peer_add"
+	AuditPeerRemove   = "peer_remove"
+	AuditPeerKey      = "peer_key"
+	AuditPeerActive   = "peer_active"
+	AuditCanonicalize = "canonicalize"
+	AuditErase        = "erase"
+)
+
+// Actor is who made a change, it is recorded with the change in the audit log.
+type Actor struct {
+	Kind string `json:"kind"`
+	ID   string `json:"id,omitempty"`
+	IP   string `json:"ip,omitempty"`
+}
+
+type actorKey struct{}
+
+// WithActor returns a copy of ctx carrying a. Changes made by stores with the
+// returned context are attributed to a.
+func WithActor(ctx context.Context, a Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, a)
+}
+
+// ActorFrom returns the actor carried by ctx, ActorSystem is returned when
+// there is none.
+func ActorFrom(ctx context.Context) Actor {
+	if a, ok := ctx.Value(actorKey{}).(Actor); ok {
+		return a
+	}
+	return Actor{Kind: ActorSystem}
+}
+
+// AuditEntry is a change recorded in the audit log. Before and After are json
+// snapshots of what changed, Before is empty for additions and After for
+// removals.
+//
+// Medium, Address, MatrixID and Peer identify what changed, they are empty
+// when they don't apply. Entries of erased subjects are redacted, their
+// Address, MatrixID, snapshots and the ip of their Actor are empty.
+type AuditEntry struct {
+	ID       int64           `json:"id"`
+	TS       int64           `json:"ts"`
+	Action   string          `json:"action"`
+	Actor    Actor           `json:"actor"`
+	Medium   string          `json:"medium,omitempty"`
+	Address  string          `json:"address,omitempty"`
+	MatrixID string          `json:"mxid,omitempty"`
+	Peer     string          `json:"peer,omitempty"`
+	Before   json.RawMessage `json:"before,omitempty"`
+	After    json.RawMessage `json:"after,omitempty"`
+}
+
+// AuditQuery selects entries of the audit log, empty fields match all
+// entries.
+type AuditQuery struct {
+	Medium   string
+	Address  string
+	MatrixID string
+	Peer     string
+	Action   string
+
+	// Since and Until are timestamps in milliseconds, entries recorded at or
+	// after Since and before Until are returned.
+	Since int64
+	Until int64
+
+	// AfterID returns entries with id greater than AfterID, it is used to page
+	// through the log.
+	AfterID int64
+	Limit   int
+}
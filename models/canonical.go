@@ -0,0 +1,122 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/idna"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// ProviderRule describes how an email provider delivers addresses which
+// differ from the mailbox, for instance gmail ignores dots in the local part.
+type ProviderRule struct {
+	// Domains are the domains of the provider, the first one is canonical.
+	Domains []string
+
+	// IgnoreDots is true when dots in the local part are ignored.
+	IgnoreDots bool
+
+	// Subaddress is the separator of subaddresses, everything after it in the
+	// local part is ignored. Empty when the provider has none.
+	Subaddress string
+}
+
+// ProviderRules are the email provider rules Canonicalizer can apply.
+var ProviderRules = map[string]ProviderRule{
+	"gmail": {
+		Domains:    []string{"gmail.com", "googlemail.com"},
+		IgnoreDots: true,
+		Subaddress: "+",
+	},
+}
+
+// Canonicalizer returns the canonical form of addresses, which is the form
+// they are stored and looked up in.
+//
+// Email domains are converted to unicode with IDNA, so the punycode and
+// unicode forms of a domain are the same address. The address is then NFC
+// normalised and case folded. Msisdn addresses are converted to E.164 without
+// the leading +.
+//
+// A nil Canonicalizer applies no provider rules.
+type Canonicalizer struct {
+	domains map[string]ProviderRule
+}
+
+// NewCanonicalizer returns Canonicalizer applying the named ProviderRules.
+func NewCanonicalizer(providers ...string) (*Canonicalizer, error) {
+	c := &Canonicalizer{domains: make(map[string]ProviderRule)}
+	for _, name := range providers {
+		rule, ok := ProviderRules[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown email provider %q", name)
+		}
+		for _, d := range rule.Domains {
+			c.domains[d] = rule
+		}
+	}
+	return c, nil
+}
+
+// Providers returns the sorted names of ProviderRules.
+func Providers() []string {
+	var o []string
+	for k := range ProviderRules {
+		o = append(o, k)
+	}
+	sort.Strings(o)
+	return o
+}
+
+// Address returns the canonical form of address. Addresses which can not be
+// parsed are returned with surrounding spaces removed, and for email
+// lowercased, so they can still be matched.
+func (c *Canonicalizer) Address(medium, address string) string {
+	address = strings.TrimSpace(address)
+	switch medium {
+	case "email":
+		return c.email(address)
+	case "msisdn":
+		n, err := NormalizeMsisdn(address)
+		if err != nil {
+			return address
+		}
+		return n
+	default:
+		return address
+	}
+}
+
+var fold = cases.Fold()
+
+func (c *Canonicalizer) email(address string) string {
+	i := strings.LastIndexByte(address, '@')
+	if i < 0 {
+		return fold.String(norm.NFC.String(address))
+	}
+	local := fold.String(norm.NFC.String(address[:i]))
+	domain := strings.TrimSuffix(address[i+1:], ".")
+	if u, err := idna.Lookup.ToUnicode(domain); err == nil {
+		domain = u
+	} else {
+		domain = strings.ToLower(domain)
+	}
+	domain = norm.NFC.String(domain)
+	if c != nil {
+		if rule, ok := c.domains[domain]; ok {
+			if rule.Subaddress != "" {
+				if j := strings.Index(local, rule.Subaddress); j > 0 {
+					local = local[:j]
+				}
+			}
+			if rule.IgnoreDots {
+				local = strings.Replace(local, ".", "", -1)
+			}
+			domain = rule.Domains[0]
+		}
+	}
+	return local + "@" + domain
+}
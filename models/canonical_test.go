@@ -0,0 +1,37 @@
+package models
+
+import "testing"
+
+func TestCanonicalAddress(t *testing.T) {
+	gmail, err := NewCanonicalizer("gmail")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sample := []struct {
+		c               *Canonicalizer
+		medium, address string
+		canonical       string
+	}{
+		{nil, "email", " Alice@Example.COM ", "alice@example.com"},
+		{nil, "email", "alice@example.com.", "alice@example.com"},
+		{nil, "email", "STRASSE@bücher.de", "strasse@bücher.de"},
+		{nil, "email", "straße@XN--BCHER-KVA.de", "strasse@bücher.de"},
+		{nil, "email", "École@example.com", "école@example.com"},
+		{nil, "email", "john.smith+chat@gmail.com", "john.smith+chat@gmail.com"},
+		{gmail, "email", "John.Smith+chat@GoogleMail.com", "johnsmith@gmail.com"},
+		{gmail, "email", "john.smith+chat@example.com", "john.smith+chat@example.com"},
+		{gmail, "email", "+chat@gmail.com", "+chat@gmail.com"},
+		{nil, "msisdn", "+44 7700 900123", "447700900123"},
+		{nil, "msisdn", "447700900123", "447700900123"},
+		{nil, "msisdn", "not a number", "not a number"},
+		{nil, "fax", " 123 ", "123"},
+	}
+	for _, v := range sample {
+		if got := v.c.Address(v.medium, v.address); got != v.canonical {
+			t.Errorf("%s %q: expected %q got %q", v.medium, v.address, v.canonical, got)
+		}
+	}
+	if _, err := NewCanonicalizer("hotmail"); err == nil {
+		t.Error("expected unknown provider to fail")
+	}
+}
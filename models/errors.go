@@ -11,6 +11,7 @@ var ErrSessionNotValidated = errors.New("db: session not validated")
 var ErrIncorrectToken = errors.New("bad token")
 var ErrNoSignature = errors.New("no signatures found")
 var ErrNoMatchingSignature = errors.New("no matching signatures found")
+var ErrInvalidPhoneNumber = errors.New("invalid phone number")
 
 // matrix error codes
 const (
@@ -55,6 +56,9 @@ const (
 	ErrInvalidEmail                = "M_INVALID_EMAIL"
 	ErrEmailSendError              = "M_EMAIL_SEND_ERROR"
 	ErrIncorrectClientSecretCode   = "M_INCORRECT_CLIENT_SECRET"
+	ErrInvalidPhoneNumberCode      = "M_INVALID_PHONE_NUMBER"
+	ErrInvalidPepper               = "M_INVALID_PEPPER"
+	ErrTermsNotSigned              = "M_TERMS_NOT_SIGNED"
 )
 
 // Error implements error interface, that wraps around the response from the
@@ -77,3 +81,17 @@ func (e Error) Error() string {
 	b, _ := json.Marshal(e)
 	return string(b)
 }
+
+// LimitExceeded is the M_LIMIT_EXCEEDED error, RetryAfterMS is how long the
+// client must wait before trying again.
+type LimitExceeded struct {
+	Error
+	RetryAfterMS int64 `json:"retry_after_ms"`
+}
+
+// CaptchaError is the M_CAPTCHA_NEEDED and M_CAPTCHA_INVALID error, Challenge
+// is what the client needs to solve a new challenge.
+type CaptchaError struct {
+	Error
+	Challenge map[string]interface{} `json:"captcha"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+// Lookup algorithms supported by the v2 lookup api.
+const (
+	LookupAlgorithmNone   = "none"
+	LookupAlgorithmSHA256 = "sha256"
+)
+
+// LookupAlgorithms lists the supported lookup algorithms in the order they are
+// advertised to clients.
+var LookupAlgorithms = []string{LookupAlgorithmNone, LookupAlgorithmSHA256}
+
+// LookupHash returns the hash clients use to look up the 3pid with the sha256
+// algorithm. This is the unpadded url safe base64 encoding of the sha256 digest
+// of "address medium pepper".
+//
+// address must be in its canonical form, see Canonicalizer.Address, which is
+// how addresses are stored.
+func LookupHash(medium, address, pepper string) string {
+	h := sha256.Sum256([]byte(address + " " + medium + " " + pepper))
+	return base64.RawURLEncoding.EncodeToString(h[:])
+}
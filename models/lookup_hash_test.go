@@ -0,0 +1,14 @@
+package models
+
+import "testing"
+
+func TestLookupHash(t *testing.T) {
+	expect := "4kenr7N9drpCJ4AfalmlGQVsOn3o2RHjkADUpXJWZUc"
+	var c *Canonicalizer
+	for _, v := range []string{"alice@example.com", "Alice@Example.com"} {
+		got := LookupHash("email", c.Address("email", v), "matrixrocks")
+		if got != expect {
+			t.Errorf("%s: expected %s got %s", v, expect, got)
+		}
+	}
+}
@@ -61,8 +61,8 @@ type Association struct {
 	Address     string                 `json:"address"`
 	MatrixID    string                 `json:"mxid"`
 	Timestamp   int64                  `json:"ts"`
-	NotBefore   int64                  `json:"notBefore"`
-	NotAfter    int64                  `json:"notAfter"`
+	NotBefore   int64                  `json:"not_before"`
+	NotAfter    int64                  `json:"not_after"`
 	ExtraFields map[string]interface{} `json:"-"`
 }
 
@@ -139,3 +139,58 @@ type Profile struct {
 	AvatarURL   string `json:"avatar_url,omitempty"`
 	LastChecked int64  `json:"last_check,omitempty"`
 }
+
+// Account is a user registered with the identity server through the v2 api.
+type Account struct {
+	UserID     string `json:"user_id"`
+	CreationTS int64  `json:"-"`
+}
+
+// OpenIDToken is the token a homeserver issues to its users for proving their
+// identity to third parties.
+type OpenIDToken struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	MatrixServerName string `json:"matrix_server_name"`
+	ExpiresIn        int64  `json:"expires_in"`
+}
+
+// AcceptedTerms records that a user accepted the policy document at URL.
+type AcceptedTerms struct {
+	Policy  string
+	Version string
+	URL     string
+}
+
+// OnbindNotification is a signed association waiting to be delivered to the
+// homeserver of the bound mxid.
+type OnbindNotification struct {
+	ID          int64
+	Destination string
+	Payload     string
+	Attempts    int64
+	NextAttempt int64
+}
+
+// Outbox email states.
+const (
+	EmailPending = "pending"
+
+	// EmailDead is the state of emails which were not sent after all
+	// attempts, they stay in the outbox until they are requeued.
+	EmailDead = "dead"
+)
+
+// OutboxEmail is a rendered email waiting in the outbox.
+type OutboxEmail struct {
+	ID          int64    `json:"id"`
+	From        string   `json:"from"`
+	To          []string `json:"to"`
+	Domain      string   `json:"domain"`
+	Message     string   `json:"-"`
+	State       string   `json:"state"`
+	Attempts    int64    `json:"attempts"`
+	NextAttempt int64    `json:"next_attempt_ts"`
+	Created     int64    `json:"creation_ts"`
+	LastError   string   `json:"last_error,omitempty"`
+}
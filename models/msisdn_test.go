@@ -0,0 +1,58 @@
+package models
+
+import "testing"
+
+func TestParsePhoneNumber(t *testing.T) {
+	sample := []struct {
+		country, number string
+		msisdn, intl    string
+	}{
+		{"GB", "07700 900123", "447700900123", "+44 7700900123"},
+		{"gb", "+44 7700 900123", "447700900123", "+44 7700900123"},
+		{"", "0044 (7700) 900-123", "447700900123", "+44 7700900123"},
+		{"US", "1 (201) 555-0123", "12015550123", "+1 2015550123"},
+		{"US", "201.555.0123", "12015550123", "+1 2015550123"},
+		{"IT", "06 1234 5678", "390612345678", "+39 0612345678"},
+		{"TZ", "0754 123 456", "255754123456", "+255 754123456"},
+	}
+	for _, v := range sample {
+		p, err := ParsePhoneNumber(v.country, v.number)
+		if err != nil {
+			t.Errorf("%s %s: %v", v.country, v.number, err)
+			continue
+		}
+		if p.MSISDN() != v.msisdn {
+			t.Errorf("%s %s: expected %s got %s", v.country, v.number, v.msisdn, p.MSISDN())
+		}
+		if p.International() != v.intl {
+			t.Errorf("%s %s: expected %s got %s", v.country, v.number, v.intl, p.International())
+		}
+	}
+	invalid := []struct {
+		country, number string
+	}{
+		{"GB", ""},
+		{"GB", "07700 9OO123"},
+		{"ZZ", "07700 900123"},
+		{"", "+999 123456"},
+		{"GB", "+44 7700 900123 123456"},
+		{"GB", "012"},
+	}
+	for _, v := range invalid {
+		if _, err := ParsePhoneNumber(v.country, v.number); err != ErrInvalidPhoneNumber {
+			t.Errorf("%s %s: expected ErrInvalidPhoneNumber got %v", v.country, v.number, err)
+		}
+	}
+}
+
+func TestNormalizeMsisdn(t *testing.T) {
+	for _, v := range []string{"447700900123", "+447700900123", "+44 7700 900123"} {
+		n, err := NormalizeMsisdn(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != "447700900123" {
+			t.Errorf("%s: expected 447700900123 got %s", v, n)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	crand "crypto/rand"
 	"math/rand"
 	"time"
 )
@@ -32,3 +33,23 @@ func GenerateToken(medium string) string {
 	}
 	return randomWith(num, 6)
 }
+
+// SecureRandomString is like RandomString but uses crypto/rand, use this for
+// values that must not be guessable.
+func SecureRandomString(length int) string {
+	// bytes above max are rejected so that every character is equally likely.
+	max := 256 - 256%len(alphaNum)
+	out := make([]byte, 0, length)
+	buf := make([]byte, length)
+	for len(out) < length {
+		if _, err := crand.Read(buf); err != nil {
+			panic(err)
+		}
+		for _, c := range buf {
+			if int(c) < max && len(out) < length {
+				out = append(out, alphaNum[int(c)%len(alphaNum)])
+			}
+		}
+	}
+	return string(out)
+}
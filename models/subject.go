@@ -0,0 +1,100 @@
+package models
+
+// Subject is the person whose data is exported or erased, identified either by
+// an address of a medium or by a matrix id.
+type Subject struct {
+	Medium   string `json:"medium,omitempty"`
+	Address  string `json:"address,omitempty"`
+	MatrixID string `json:"mxid,omitempty"`
+}
+
+func (s Subject) String() string {
+	if s.MatrixID != "" {
+		return s.MatrixID
+	}
+	return s.Medium + ":" + s.Address
+}
+
+// SubjectAssociation is an association of a SubjectExport, the origin is only
+// set for global associations. MatrixID is empty for removed local
+// associations.
+type SubjectAssociation struct {
+	ID           int64  `json:"id"`
+	Medium       string `json:"medium"`
+	Address      string `json:"address"`
+	MatrixID     string `json:"mxid,omitempty"`
+	Timestamp    int64  `json:"ts"`
+	NotBefore    int64  `json:"not_before,omitempty"`
+	NotAfter     int64  `json:"not_after,omitempty"`
+	OriginServer string `json:"origin_server,omitempty"`
+	OriginID     int64  `json:"origin_id,omitempty"`
+}
+
+// SubjectInvite is an invite token of a SubjectExport. The token itself is
+// left out, it is a secret of the invited user.
+type SubjectInvite struct {
+	Medium     string `json:"medium"`
+	Address    string `json:"address"`
+	RoomID     string `json:"room_id"`
+	Sender     string `json:"sender"`
+	ReceivedTS int64  `json:"received_ts,omitempty"`
+	SentTS     int64  `json:"sent_ts,omitempty"`
+}
+
+// SubjectSession is a validation session of a SubjectExport.
+type SubjectSession struct {
+	ID        int64  `json:"sid"`
+	Medium    string `json:"medium"`
+	Address   string `json:"address"`
+	Validated bool   `json:"validated"`
+	Mtime     int64  `json:"mtime"`
+}
+
+// SubjectEmail is an email of a SubjectExport waiting in the outbox.
+type SubjectEmail struct {
+	ID      int64    `json:"id"`
+	From    string   `json:"from"`
+	To      []string `json:"to"`
+	State   string   `json:"state"`
+	Created int64    `json:"creation_ts"`
+	Message string   `json:"message"`
+}
+
+// SubjectExport is everything stored about a Subject.
+//
+// For a matrix id it has the associations bound to it, and the invite tokens,
+// validation sessions, queued emails and audit log entries of their addresses.
+type SubjectExport struct {
+	Subject            Subject              `json:"subject"`
+	LocalAssociations  []SubjectAssociation `json:"local_associations"`
+	GlobalAssociations []SubjectAssociation `json:"global_associations"`
+	InviteTokens       []SubjectInvite      `json:"invite_tokens"`
+	ValidationSessions []SubjectSession     `json:"validation_sessions"`
+	Emails             []SubjectEmail       `json:"emails"`
+	Audit              []AuditEntry         `json:"audit"`
+}
+
+// SubjectErasure is the outcome of erasing a Subject.
+type SubjectErasure struct {
+	Subject Subject `json:"subject"`
+
+	// Erased are the addresses whose data was erased.
+	Erased []Subject `json:"erased"`
+
+	// Skipped are addresses bound to the erased matrix id which are bound to
+	// other matrix ids too. They are left alone, they must be erased by
+	// address.
+	Skipped []Subject `json:"skipped,omitempty"`
+
+	// LocalAssociations is the number of local associations replaced with a
+	// removal, which is replicated to peers.
+	LocalAssociations  int64 `json:"local_associations"`
+	GlobalAssociations int64 `json:"global_associations"`
+	InviteTokens       int64 `json:"invite_tokens"`
+	ValidationSessions int64 `json:"validation_sessions"`
+	Emails             int64 `json:"emails"`
+
+	// Audit is the number of audit log entries about the subject which were
+	// redacted.
+	Audit int64 `json:"audit"`
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gernest/sydent-go/clients"
+	"github.com/gernest/sydent-go/config"
+	"github.com/gernest/sydent-go/core"
+	"github.com/gernest/sydent-go/models"
+	"github.com/labstack/echo"
+)
+
+// accountKey is the key under which the authenticated account is stored in the
+// echo context.
+const accountKey = "account"
+
+// accessTokenLength is the length of access tokens issued by the identity server.
+const accessTokenLength = 64
+
+var errInvalidOpenID = errors.New("invalid openid token")
+
+// RegisterAccount returns a handler which exchanges an openid token issued by a
+// homeserver for an access token of the identity server. The token is verified
+// by asking the homeserver named in matrix_server_name which user it belongs
+// to.
+func RegisterAccount(coreContext *core.Ctx, fedClient config.HTTPClient, m Metric) echo.HandlerFunc {
+	count := m.CountError("register_account")
+	return func(ctx echo.Context) error {
+		req := ctx.Request()
+		var token models.OpenIDToken
+		err := json.NewDecoder(req.Body).Decode(&token)
+		if err != nil {
+			count.Inc()
+			RequestError(coreContext.Log, req, err)
+			return ctx.JSON(http.StatusBadRequest, models.NewError(
+				models.ErrBadJSON,
+				"Malformed JSON",
+			))
+		}
+		var missing []string
+		if token.AccessToken == "" {
+			missing = append(missing, "access_token")
+		}
+		if token.MatrixServerName == "" {
+			missing = append(missing, "matrix_server_name")
+		}
+		if len(missing) > 0 {
+			count.Inc()
+			return ctx.JSON(http.StatusBadRequest, models.NewError(
+				models.ErrMissingParam,
+				fmt.Sprintf("Missing parameters: %s", strings.Join(missing, ",")),
+			))
+		}
+		if !clients.ValidServerName(token.MatrixServerName) {
+			count.Inc()
+			return ctx.JSON(http.StatusBadRequest, models.NewError(
+				models.ErrInvalidParam,
+				"matrix_server_name is not a valid server name",
+			))
+		}
+		userID, err := verifyOpenID(req.Context(), fedClient, &token)
+		if err != nil {
+			count.Inc()
+			RequestError(coreContext.Log, req, err)
+			return ctx.JSON(http.StatusUnauthorized, models.NewError(
+				models.ErrUnauthorized,
+				"Unable to verify openid token",
+			))
+		}
+		db := coreContext.Store
+		err = db.AddAccount(req.Context(), userID)
+		if err != nil {
+			count.Inc()
+			RequestError(coreContext.Log, req, err)
+			return InternalError(ctx)
+		}
+		issued := models.SecureRandomString(accessTokenLength)
+		err = db.AddAccountToken(req.Context(), userID, issued)
+		if err != nil {
+			count.Inc()
+			RequestError(coreContext.Log, req, err)
+			return InternalError(ctx)
+		}
+		return ctx.JSON(http.StatusOK, map[string]string{
+			"token":        issued,
+			"access_token": issued,
+		})
+	}
+}
+
+// verifyOpenID asks the homeserver which issued the openid token for the user
+// it belongs to. The user must be on the same homeserver.
+func verifyOpenID(ctx context.Context, fedClient config.HTTPClient, token *models.OpenIDToken) (string, error) {
+	u := &url.URL{
+		Scheme:   "matrix",
+		Host:     token.MatrixServerName,
+		Path:     "/_matrix/federation/v1/openid/userinfo",
+		RawQuery: url.Values{"access_token": {token.AccessToken}}.Encode(),
+	}
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	res, err := fedClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	b, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return "", err
+	}
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("openid userinfo: %s: %s", res.Status, string(b))
+	}
+	var info struct {
+		Sub string `json:"sub"`
+	}
+	err = json.Unmarshal(b, &info)
+	if err != nil {
+		return "", err
+	}
+	if info.Sub == "" || !strings.HasSuffix(info.Sub, ":"+token.MatrixServerName) {
+		return "", errInvalidOpenID
+	}
+	return info.Sub, nil
+}
+
+// Account returns a handler which returns the user id of the authenticated
+// account.
+func Account(ctx echo.Context) error {
+	a := AccountFrom(ctx)
+	if a == nil {
+		return unauthorized(ctx)
+	}
+	return ctx.JSON(http.StatusOK, map[string]string{
+		"user_id": a.UserID,
+	})
+}
+
+// Logout returns a handler which revokes the access token used to authenticate
+// the request.
+func Logout(coreContext *core.Ctx, m Metric) echo.HandlerFunc {
+	count := m.CountError("logout")
+	return func(ctx echo.Context) error {
+		req := ctx.Request()
+		err := coreContext.Store.DeleteAccountToken(req.Context(), accessToken(req))
+		if err != nil {
+			count.Inc()
+			RequestError(coreContext.Log, req, err)
+			return InternalError(ctx)
+		}
+		return ctx.JSON(http.StatusOK, map[string]interface{}{})
+	}
+}
+
+// Authenticate returns a middleware which requires requests to carry an access
+// token issued by RegisterAccount. The token is read from the Authorization
+// header or the access_token query parameter.
+//
+// The account which owns the token is available to the handlers through
+// AccountFrom.
+func Authenticate(coreContext *core.Ctx, m Metric) echo.MiddlewareFunc {
+	count := m.CountError("authenticate")
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			req := ctx.Request()
+			token := accessToken(req)
+			if token == "" {
+				count.Inc()
+				return unauthorized(ctx)
+			}
+			a, err := coreContext.Store.GetAccountByToken(req.Context(), token)
+			if err != nil {
+				count.Inc()
+				RequestError(coreContext.Log, req, err)
+				return unauthorized(ctx)
+			}
+			ctx.Set(accountKey, a)
+			setActor(coreContext, ctx, models.ActorAccount, a.UserID)
+			return next(ctx)
+		}
+	}
+}
+
+// AccountFrom returns the account that was authenticated for the request or nil
+// if the request was not authenticated.
+func AccountFrom(ctx echo.Context) *models.Account {
+	if a, ok := ctx.Get(accountKey).(*models.Account); ok {
+		return a
+	}
+	return nil
+}
+
+func accessToken(req *http.Request) string {
+	if h := req.Header.Get(echo.HeaderAuthorization); h != "" {
+		if strings.HasPrefix(h, "Bearer ") {
+			return strings.TrimSpace(strings.TrimPrefix(h, "Bearer "))
+		}
+		return ""
+	}
+	return req.URL.Query().Get("access_token")
+}
+
+func unauthorized(ctx echo.Context) error {
+	return ctx.JSON(http.StatusUnauthorized, models.NewError(
+		models.ErrUnauthorized,
+		"Unauthorized",
+	))
+}
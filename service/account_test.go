@@ -0,0 +1,103 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gernest/sydent-go/clients"
+	"github.com/labstack/echo"
+)
+
+func TestRegisterAccount(t *testing.T) {
+	hs := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/_matrix/federation/v1/openid/userinfo" ||
+			r.URL.Query().Get("access_token") != "openid-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{
+			"sub": "@alice:" + r.Host,
+		})
+	}))
+	defer hs.Close()
+	u, err := url.Parse(hs.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fed := &http.Client{
+		Transport: clients.NewFederatedTripperWith(0, hs.Client().Transport, nil),
+	}
+	e := echo.New()
+	register := func(body string) (int, map[string]string) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		err := RegisterAccount(mainContext, fed, &TestMetric{})(e.NewContext(req, rec))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var res map[string]string
+		json.Unmarshal(rec.Body.Bytes(), &res)
+		return rec.Code, res
+	}
+
+	code, res := register(`{"access_token":"bad-token","token_type":"Bearer","matrix_server_name":"` + u.Host + `"}`)
+	if code != http.StatusUnauthorized || res["errcode"] != "M_UNAUTHORIZED" {
+		t.Fatalf("expected 401 M_UNAUTHORIZED got %d %v", code, res)
+	}
+	code, res = register(`{"access_token":"openid-token","matrix_server_name":"evil.com/path"}`)
+	if code != http.StatusBadRequest {
+		t.Fatalf("expected 400 got %d %v", code, res)
+	}
+	code, res = register(`{"access_token":"openid-token","token_type":"Bearer","matrix_server_name":"` + u.Host + `"}`)
+	if code != http.StatusOK {
+		t.Fatalf("expected 200 got %d %v", code, res)
+	}
+	token := res["token"]
+	if token == "" || res["access_token"] != token {
+		t.Fatalf("expected access token got %v", res)
+	}
+
+	auth := Authenticate(mainContext, &TestMetric{})
+	account := func(token string) (int, map[string]string) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if token != "" {
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		err := auth(Account)(e.NewContext(req, rec))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var res map[string]string
+		json.Unmarshal(rec.Body.Bytes(), &res)
+		return rec.Code, res
+	}
+	code, res = account("")
+	if code != http.StatusUnauthorized || res["errcode"] != "M_UNAUTHORIZED" {
+		t.Errorf("expected 401 M_UNAUTHORIZED got %d %v", code, res)
+	}
+	code, res = account(token)
+	if expect := "@alice:" + u.Host; code != http.StatusOK || res["user_id"] != expect {
+		t.Errorf("expected %s got %d %v", expect, code, res)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	rec := httptest.NewRecorder()
+	err = auth(Logout(mainContext, &TestMetric{}))(e.NewContext(req, rec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d %s", rec.Code, rec.Body.String())
+	}
+	code, _ = account(token)
+	if code != http.StatusUnauthorized {
+		t.Errorf("expected token to be revoked got %d", code)
+	}
+}
@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gernest/signedjson"
+	"github.com/gernest/sydent-go/clients"
+	"github.com/gernest/sydent-go/core"
+	"github.com/gernest/sydent-go/models"
+	"github.com/labstack/echo"
+	"golang.org/x/crypto/ed25519"
+)
+
+// AdminAuth returns a middleware which requires the admin token configured in
+// config.Admin as bearer token.
+func AdminAuth(coreContext *core.Ctx) echo.MiddlewareFunc {
+	token := []byte(coreContext.Config.Admin.Token)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			given := []byte(accessToken(ctx.Request()))
+			if len(token) == 0 || subtle.ConstantTimeCompare(given, token) != 1 {
+				return unauthorized(ctx)
+			}
+			setActor(coreContext, ctx, models.ActorAdmin, "")
+			return next(ctx)
+		}
+	}
+}
+
+// PeerStatus is a peer together with its replication state.
+type PeerStatus struct {
+	Name                string            `json:"name"`
+	Port                int64             `json:"port,omitempty"`
+	Active              bool              `json:"active"`
+	PublicKeys          map[string]string `json:"public_keys"`
+	LastSentVersion     int64             `json:"last_sent_version"`
+	LastPokeSucceededAt int64             `json:"last_poke_succeeded_at,omitempty"`
+
+	// Pending is the number of local associations which were not pushed to
+	// the peer yet, this is the replication lag of the peer.
+	Pending int64 `json:"pending"`
+}
+
+// PeerStatuses returns all peers with their replication lag.
+func PeerStatuses(ctx context.Context, coreContext *core.Ctx) ([]PeerStatus, error) {
+	db := coreContext.Store
+	peers, err := db.ListPeers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	o := make([]PeerStatus, 0, len(peers))
+	for _, p := range peers {
+		lastID := int64(-1)
+		if p.LastSentVersion.Valid {
+			lastID = p.LastSentVersion.Int64
+		}
+		pending, err := db.CountAssociationsAfterID(ctx, lastID)
+		if err != nil {
+			return nil, err
+		}
+		o = append(o, PeerStatus{
+			Name:                p.Name,
+			Port:                p.Port.Int64,
+			Active:              p.Active == 1,
+			PublicKeys:          p.PublicKeys,
+			LastSentVersion:     p.LastSentVersion.Int64,
+			LastPokeSucceededAt: p.LastPokeSucceededAt.Int64,
+			Pending:             pending,
+		})
+	}
+	return o, nil
+}
+
+// ValidPeerName returns an error if name can't be used as peer name.
+func ValidPeerName(name string) error {
+	if !clients.ValidServerName(name) {
+		return fmt.Errorf("%q is not a valid server name", name)
+	}
+	return nil
+}
+
+// ValidPeerKey returns an error if key is not a valid public key for alg.
+func ValidPeerKey(alg, key string) error {
+	if alg != SIGNING_KEY_ALGORITHM {
+		return fmt.Errorf("unsupported key algorithm %q", alg)
+	}
+	b, err := signedjson.DecodeBase64(key)
+	if err != nil || len(b) != ed25519.PublicKeySize {
+		return errors.New("key is not a valid base64 encoded public key")
+	}
+	return nil
+}
+
+// AdminListPeers returns a handler which lists all peers with their
+// replication lag.
+func AdminListPeers(coreContext *core.Ctx, m Metric) echo.HandlerFunc {
+	count := m.CountError("admin_list_peers")
+	return func(ctx echo.Context) error {
+		req := ctx.Request()
+		peers, err := PeerStatuses(req.Context(), coreContext)
+		if err != nil {
+			count.Inc()
+			RequestError(coreContext.Log, req, err)
+			return InternalError(ctx)
+		}
+		return ctx.JSON(http.StatusOK, map[string]interface{}{
+			"peers": peers,
+		})
+	}
+}
+
+// AddPeerRequest is the body of the request for adding a peer.
+type AddPeerRequest struct {
+	Name string `json:"name"`
+	Port int64  `json:"port,omitempty"`
+}
+
+// AdminAddPeer returns a handler which adds an inactive peer.
+func AdminAddPeer(coreContext *core.Ctx, m Metric) echo.HandlerFunc {
+	count := m.CountError("admin_add_peer")
+	return func(ctx echo.Context) error {
+		req := ctx.Request()
+		var o AddPeerRequest
+		if err := json.NewDecoder(req.Body).Decode(&o); err != nil {
+			count.Inc()
+			return ctx.JSON(http.StatusBadRequest, models.NewError(
+				models.ErrBadJSON,
+				"Malformed JSON",
+			))
+		}
+		if err := ValidPeerName(o.Name); err != nil {
+			count.Inc()
+			return ctx.JSON(http.StatusBadRequest, models.NewError(
+				models.ErrInvalidParam, err.Error(),
+			))
+		}
+		if o.Port < 0 || o.Port > 65535 {
+			count.Inc()
+			return ctx.JSON(http.StatusBadRequest, models.NewError(
+				models.ErrInvalidParam, "port is out of range",
+			))
+		}
+		port := sql.NullInt64{Int64: o.Port, Valid: o.Port != 0}
+		err := coreContext.Store.AddPeer(req.Context(), o.Name, port)
+		if err != nil {
+			count.Inc()
+			RequestError(coreContext.Log, req, err)
+			return InternalError(ctx)
+		}
+		return ctx.JSON(http.StatusOK, map[string]interface{}{})
+	}
+}
+
+// AdminRemovePeer returns a handler which removes the peer :name.
+func AdminRemovePeer(coreContext *core.Ctx, m Metric) echo.HandlerFunc {
+	count := m.CountError("admin_remove_peer")
+	return func(ctx echo.Context) error {
+		req := ctx.Request()
+		err := coreContext.Store.RemovePeer(req.Context(), ctx.Param("name"))
+		if err != nil {
+			count.Inc()
+			return peerError(coreContext, ctx, err)
+		}
+		return ctx.JSON(http.StatusOK, map[string]interface{}{})
+	}
+}
+
+// SetPeerKeyRequest is the body of the request for setting a peer key.
+type SetPeerKeyRequest struct {
+	Key string `json:"key"`
+}
+
+// AdminSetPeerKey returns a handler which sets the public key of the peer
+// :name for the algorithm :alg.
+func AdminSetPeerKey(coreContext *core.Ctx, m Metric) echo.HandlerFunc {
+	count := m.CountError("admin_set_peer_key")
+	return func(ctx echo.Context) error {
+		req := ctx.Request()
+		var o SetPeerKeyRequest
+		if err := json.NewDecoder(req.Body).Decode(&o); err != nil {
+			count.Inc()
+			return ctx.JSON(http.StatusBadRequest, models.NewError(
+				models.ErrBadJSON,
+				"Malformed JSON",
+			))
+		}
+		alg := ctx.Param("alg")
+		if err := ValidPeerKey(alg, o.Key); err != nil {
+			count.Inc()
+			return ctx.JSON(http.StatusBadRequest, models.NewError(
+				models.ErrInvalidParam, err.Error(),
+			))
+		}
+		err := coreContext.Store.SetPeerKey(req.Context(), ctx.Param("name"), alg, o.Key)
+		if err != nil {
+			count.Inc()
+			return peerError(coreContext, ctx, err)
+		}
+		return ctx.JSON(http.StatusOK, map[string]interface{}{})
+	}
+}
+
+// SetPeerActiveRequest is the body of the request for enabling or disabling
+// replication with a peer.
+type SetPeerActiveRequest struct {
+	Active bool `json:"active"`
+}
+
+// AdminSetPeerActive returns a handler which enables or disables replication
+// with the peer :name.
+func AdminSetPeerActive(coreContext *core.Ctx, m Metric) echo.HandlerFunc {
+	count := m.CountError("admin_set_peer_active")
+	return func(ctx echo.Context) error {
+		req := ctx.Request()
+		var o SetPeerActiveRequest
+		if err := json.NewDecoder(req.Body).Decode(&o); err != nil {
+			count.Inc()
+			return ctx.JSON(http.StatusBadRequest, models.NewError(
+				models.ErrBadJSON,
+				"Malformed JSON",
+			))
+		}
+		err := coreContext.Store.SetPeerActive(req.Context(), ctx.Param("name"), o.Active)
+		if err != nil {
+			count.Inc()
+			return peerError(coreContext, ctx, err)
+		}
+		if o.Active {
+			coreContext.Replication.Notify()
+		}
+		return ctx.JSON(http.StatusOK, map[string]interface{}{})
+	}
+}
+
+func peerError(coreContext *core.Ctx, ctx echo.Context, err error) error {
+	if err == sql.ErrNoRows {
+		return ctx.JSON(http.StatusNotFound, models.NewError(
+			models.ErrNotFound,
+			"Peer not found",
+		))
+	}
+	RequestError(coreContext.Log, ctx.Request(), err)
+	return InternalError(ctx)
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gernest/sydent-go/core"
+	"github.com/gernest/sydent-go/models"
+	"github.com/labstack/echo"
+)
+
+func TestAdminPeers(t *testing.T) {
+	cfg := *mainContext.Config
+	cfg.Admin.Token = "admin-secret"
+	tctx := *mainContext
+	tctx.Config = &cfg
+
+	m := &TestMetric{}
+	admin := AdminAuth(&tctx)
+	e := echo.New()
+	e.GET("/peers", AdminListPeers(&tctx, m), admin)
+	e.POST("/peers", AdminAddPeer(&tctx, m), admin)
+	e.DELETE("/peers/:name", AdminRemovePeer(&tctx, m), admin)
+	e.PUT("/peers/:name/keys/:alg", AdminSetPeerKey(&tctx, m), admin)
+	e.PUT("/peers/:name/active", AdminSetPeerActive(&tctx, m), admin)
+
+	call := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Set(echo.HeaderXRealIP, "10.0.0.99")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	for _, token := range []string{"", "wrong"} {
+		rec := call(http.MethodGet, "/peers", token, "")
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("token %q: expected 401 got %d", token, rec.Code)
+		}
+	}
+
+	const token = "admin-secret"
+	steps := []struct {
+		method, path, body string
+		code               int
+	}{
+		{http.MethodPost, "/peers", `{"name":"not a server name"}`, http.StatusBadRequest},
+		{http.MethodPost, "/peers", `{"name":"admin.example.com","port":8448}`, http.StatusOK},
+		{http.MethodPut, "/peers/admin.example.com/keys/ed25519", `{"key":"not base64!"}`, http.StatusBadRequest},
+		{http.MethodPut, "/peers/admin.example.com/keys/ed25519", `{"key":"Pm5n0Bz9Sp4dH+dJlKJ8tDxuBaTiDdy4wUPrgEXgGdA"}`, http.StatusOK},
+		{http.MethodPut, "/peers/missing.example.com/keys/ed25519", `{"key":"Pm5n0Bz9Sp4dH+dJlKJ8tDxuBaTiDdy4wUPrgEXgGdA"}`, http.StatusNotFound},
+		{http.MethodPut, "/peers/admin.example.com/active", `{"active":true}`, http.StatusOK},
+		{http.MethodPut, "/peers/missing.example.com/active", `{"active":true}`, http.StatusNotFound},
+	}
+	for _, s := range steps {
+		rec := call(s.method, s.path, token, s.body)
+		if rec.Code != s.code {
+			t.Fatalf("%s %s %s: expected %d got %d %s", s.method, s.path, s.body, s.code, rec.Code, rec.Body.String())
+		}
+	}
+
+	rec := call(http.MethodGet, "/peers", token, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d %s", rec.Code, rec.Body.String())
+	}
+	var list struct {
+		Peers []PeerStatus `json:"peers"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatal(err)
+	}
+	var found *PeerStatus
+	for i := range list.Peers {
+		if list.Peers[i].Name == "admin.example.com" {
+			found = &list.Peers[i]
+		}
+	}
+	if found == nil {
+		t.Fatalf("expected peer in %s", rec.Body.String())
+	}
+	if !found.Active || found.Port != 8448 || found.PublicKeys[SIGNING_KEY_ALGORITHM] == "" {
+		t.Errorf("unexpected peer %+v", found)
+	}
+
+	rec = call(http.MethodDelete, "/peers/admin.example.com", token, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d %s", rec.Code, rec.Body.String())
+	}
+	rec = call(http.MethodDelete, "/peers/admin.example.com", token, "")
+	if rec.Code != http.StatusNotFound || !strings.Contains(rec.Body.String(), models.ErrNotFound) {
+		t.Errorf("expected 404 got %d %s", rec.Code, rec.Body.String())
+	}
+
+	entries, err := tctx.Store.AuditLog(context.Background(), models.AuditQuery{
+		Peer:  "admin.example.com",
+		Limit: 100,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("expected add, key, active and remove to be audited got %d entries", len(entries))
+	}
+	for _, e := range entries {
+		if e.Actor.Kind != models.ActorAdmin || e.Actor.IP != "10.0.0.1" {
+			t.Errorf("expected the admin actor from the peer ip got %+v", e.Actor)
+		}
+	}
+}
+
+func TestAdminSubjects(t *testing.T) {
+	cfg := *mainContext.Config
+	cfg.Admin.Token = "admin-secret"
+	tctx := *mainContext
+	tctx.Config = &cfg
+	tctx.Replication = core.NewSignal()
+
+	m := &TestMetric{}
+	admin := AdminAuth(&tctx)
+	e := echo.New()
+	e.POST("/gdpr/export", AdminExportSubject(&tctx, m), admin)
+	e.POST("/gdpr/erase", AdminEraseSubject(&tctx, m), admin)
+
+	call := func(path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	if rec := call("/gdpr/export", "", `{"mxid":"@subject:example.com"}`); rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 got %d", rec.Code)
+	}
+	const token = "admin-secret"
+	for _, body := range []string{
+		`not json`,
+		`{}`,
+		`{"mxid":"subject"}`,
+		`{"medium":"email"}`,
+		`{"medium":"fax","address":"123"}`,
+		`{"medium":"email","address":"subject@example.com","mxid":"@subject:example.com"}`,
+	} {
+		for _, path := range []string{"/gdpr/export", "/gdpr/erase"} {
+			if rec := call(path, token, body); rec.Code != http.StatusBadRequest {
+				t.Errorf("%s %s: expected 400 got %d %s", path, body, rec.Code, rec.Body.String())
+			}
+		}
+	}
+
+	ctx := context.Background()
+	now := models.Time()
+	err := tctx.Store.LocalAddOrUpdateAssociation(ctx, &models.Association{
+		Medium:    "email",
+		Address:   "subject@example.com",
+		MatrixID:  "@subject:example.com",
+		Timestamp: now,
+		NotBefore: now,
+		NotAfter:  now + associationLifetime,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LocalPusher(&tctx)(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := call("/gdpr/export", token, `{"mxid":"@subject:example.com"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d %s", rec.Code, rec.Body.String())
+	}
+	var export models.SubjectExport
+	if err := json.Unmarshal(rec.Body.Bytes(), &export); err != nil {
+		t.Fatal(err)
+	}
+	if len(export.LocalAssociations) != 1 || len(export.GlobalAssociations) != 1 ||
+		export.GlobalAssociations[0].OriginServer != cfg.Server.Name {
+		t.Errorf("unexpected export %s", rec.Body.String())
+	}
+
+	rec = call("/gdpr/erase", token, `{"medium":"email","address":"Subject@example.com"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d %s", rec.Code, rec.Body.String())
+	}
+	var erasure models.SubjectErasure
+	if err := json.Unmarshal(rec.Body.Bytes(), &erasure); err != nil {
+		t.Fatal(err)
+	}
+	if len(erasure.Erased) != 1 || erasure.LocalAssociations != 1 || erasure.GlobalAssociations != 1 {
+		t.Errorf("unexpected erasure %s", rec.Body.String())
+	}
+	select {
+	case <-tctx.Replication:
+	default:
+		t.Error("expected replication to peers to be notified")
+	}
+	if mxid, err := tctx.Store.GlobalGetMxid(ctx, "email", "subject@example.com"); err != sql.ErrNoRows {
+		t.Errorf("expected the global association to be removed got %q %v", mxid, err)
+	}
+	entries, err := tctx.Store.AuditLog(ctx, models.AuditQuery{
+		Action: models.AuditErase,
+		Limit:  1 << 20,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) == 0 {
+		t.Fatal("expected the erasure to be audited")
+	}
+	if e := entries[len(entries)-1]; e.Actor.Kind != models.ActorAdmin || e.Address != "" || e.MatrixID != "" {
+		t.Errorf("expected the erasure to be audited without the subject got %+v", e)
+	}
+	for _, aq := range []models.AuditQuery{
+		{Medium: "email", Address: "subject@example.com", Limit: 100},
+		{MatrixID: "@subject:example.com", Limit: 100},
+	} {
+		entries, err = tctx.Store.AuditLog(ctx, aq)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 0 {
+			t.Errorf("expected the entries of the subject to be redacted got %+v", entries)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package service
+
+import (
+	"context"
+
+	"github.com/gernest/sydent-go/core"
+	"github.com/gernest/sydent-go/models"
+	"github.com/labstack/echo"
+)
+
+// setActor attributes the changes made while handling the request of ctx to
+// the actor of kind and id, see models.WithActor. The ip of the client, read
+// through the trusted proxies of coreContext, is recorded with it. The returned
+// context is the new context of the request.
+func setActor(coreContext *core.Ctx, ctx echo.Context, kind, id string) context.Context {
+	req := ctx.Request()
+	c := models.WithActor(req.Context(), models.Actor{
+		Kind: kind,
+		ID:   id,
+		IP:   coreContext.Proxies.ClientIP(req),
+	})
+	ctx.SetRequest(req.WithContext(c))
+	return c
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/gernest/signedjson"
 	"github.com/gernest/sydent-go/core"
 	"github.com/gernest/sydent-go/models"
+	"github.com/gernest/sydent-go/store"
 )
 
 const associationLifetime = 100 * 365 * 24 * 60 * 60 * 1000
@@ -15,7 +16,9 @@ func AddBinding(coreContext *core.Ctx) func(context.Context, string, string, str
 	key := coreContext.Config.Server.Crypto.Key()
 	serverName := coreContext.Config.Server.Name
 	sign := Signer(coreContext)
+	push := LocalPusher(coreContext)
 	return func(ctx context.Context, medium, address, mxid string) (signedjson.Message, error) {
+		address = coreContext.Canonical.Address(medium, address)
 		createdAt := models.Time()
 		expiresAt := createdAt + associationLifetime
 		as := &models.Association{
@@ -53,15 +56,31 @@ func AddBinding(coreContext *core.Ctx) func(context.Context, string, string, str
 			as.ExtraFields = map[string]interface{}{
 				"invites": invites,
 			}
-			err := coreContext.Store.MarkTokensAsSent(ctx, medium, address)
-			if err != nil {
-				return nil, err
-			}
 		}
 		a, err := sign(as)
 		if err != nil {
 			return nil, err
 		}
+		// the invites are marked as sent in the transaction queueing the
+		// notification which delivers them, so they are never lost.
+		err = coreContext.Store.Tx(ctx, func(tx store.Store) error {
+			if len(invites) > 0 {
+				err := tx.MarkTokensAsSent(ctx, medium, address)
+				if err != nil {
+					return err
+				}
+			}
+			return addOnbind(ctx, tx, mxid, a)
+		})
+		if err != nil {
+			return nil, err
+		}
+		coreContext.OnBind.Notify()
+		err = push(ctx)
+		if err != nil {
+			return nil, err
+		}
+		coreContext.Replication.Notify()
 		return a, nil
 	}
 }
@@ -73,10 +92,17 @@ func RemoveBinding(coreContext *core.Ctx) func(context.Context, *models.Associat
 		if err != nil {
 			return err
 		}
-		return push(ctx)
+		err = push(ctx)
+		if err != nil {
+			return err
+		}
+		coreContext.Replication.Notify()
+		return nil
 	}
 }
 
+// LocalPusher returns a function which copies local associations that were
+// not copied yet to the global association table.
 func LocalPusher(coreContext *core.Ctx) func(context.Context) error {
 	push := PushLocal(coreContext)
 	name := coreContext.Config.Server.Name
@@ -90,23 +116,40 @@ func LocalPusher(coreContext *core.Ctx) func(context.Context) error {
 			}
 			lastID = -1
 		}
-		as, err := db.GetAssociationsAfterID(ctx, lastID, 0)
-		if err != nil {
-			return err
-		}
-		out := make([]Association, len(as))
-		for k, v := range as {
-			a, err := sign(&v)
+		for {
+			as, err := db.GetAssociationsAfterID(ctx, lastID, replicationBatch)
+			if err != nil {
+				return err
+			}
+			out, err := signAssociations(sign, as)
+			if err != nil {
+				return err
+			}
+			err = push(ctx, out)
 			if err != nil {
 				return err
 			}
-			out[k] = Association{
-				OriginID:          v.ID,
-				SignedAssociation: a,
+			if len(as) < replicationBatch {
+				return nil
 			}
+			lastID = as[len(as)-1].ID
+		}
+	}
+}
+
+func signAssociations(sign func(*models.Association) (signedjson.Message, error), as []models.Association) ([]Association, error) {
+	out := make([]Association, len(as))
+	for k, v := range as {
+		a, err := sign(&v)
+		if err != nil {
+			return nil, err
+		}
+		out[k] = Association{
+			OriginID:          v.ID,
+			SignedAssociation: a,
 		}
-		return push(ctx, out)
 	}
+	return out, nil
 }
 
 func Signer(coreContext *core.Ctx) func(*models.Association) (signedjson.Message, error) {
@@ -45,11 +45,23 @@ func BulkLookup(coreContext *core.Ctx, m Metric) echo.HandlerFunc {
 				RequestError(lg, req, err)
 				return ctx.JSON(http.StatusOK, map[string]interface{}{})
 			}
+			// answer with the addresses as they were given, the store has
+			// them in canonical form.
+			canonical := coreContext.Canonical
+			given := make(map[string][]string)
+			for _, v := range o.Threepids {
+				if len(v) == 2 {
+					k := v[0] + " " + canonical.Address(v[0], v[1])
+					given[k] = append(given[k], v[1])
+				}
+			}
 			var result models.BulkLookupRequest
 			for _, v := range a {
-				result.Threepids = append(result.Threepids, []string{
-					v.Medium, v.Address, v.MatrixID,
-				})
+				for _, address := range given[v.Medium+" "+canonical.Address(v.Medium, v.Address)] {
+					result.Threepids = append(result.Threepids, []string{
+						v.Medium, address, v.MatrixID,
+					})
+				}
 			}
 			return ctx.JSON(http.StatusOK, result)
 		}
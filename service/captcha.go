@@ -0,0 +1,117 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"time"
+
+	"github.com/gernest/sydent-go/config"
+	"github.com/gernest/sydent-go/core"
+	"github.com/gernest/sydent-go/models"
+	"github.com/labstack/echo"
+)
+
+// captchaParam is the request parameter with the response to a challenge.
+const captchaParam = "captcha_response"
+
+// captchaReplay is how long a solved challenge is remembered so it can not be
+// used twice.
+const captchaReplay = config.PowChallengeTTL + time.Minute
+
+// Captcha returns a middleware requiring clients to solve a challenge once they
+// made more than config.Captcha.Threshold requests, or for all requests when
+// there is no threshold. Clients get M_CAPTCHA_NEEDED with the challenge, and
+// retry with the response in the captcha_response parameter.
+//
+// Requests are counted per client ip, read through the trusted proxies of
+// coreContext, in the rate limit buckets, see config.RateLimit.Backend. A
+// response can only be used once.
+func Captcha(coreContext *core.Ctx, m Metric) echo.MiddlewareFunc {
+	c := coreContext.Config.Captcha
+	count := m.CountError("captcha")
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		verifier := coreContext.Captcha
+		if verifier == nil {
+			return next
+		}
+		return func(ctx echo.Context) error {
+			req := ctx.Request()
+			ip := coreContext.Proxies.ClientIP(req)
+			limits := rateLimitStore(coreContext)
+			response := peekParams(req)[captchaParam]
+			if response == "" {
+				if n := c.Limit(); n > 0 {
+					interval := int64(c.Every()/time.Millisecond) / int64(n)
+					if interval < 1 {
+						interval = 1
+					}
+					retry, err := limits.TakeRateLimitToken(req.Context(),
+						"captcha:ip:"+ip, float64(n), interval, models.Time(),
+					)
+					if err != nil {
+						RequestError(coreContext.Log, req, err)
+						return next(ctx)
+					}
+					if retry == 0 {
+						return next(ctx)
+					}
+				}
+				count.Inc()
+				return captchaError(coreContext, ctx, models.ErrCaptchaNeeded, "Solve the captcha to continue")
+			}
+			err := verifier.Verify(req.Context(), response, ip)
+			if err == config.ErrCaptchaInvalid {
+				count.Inc()
+				return captchaError(coreContext, ctx, models.ErrCaptchaInvalid, "Invalid captcha response")
+			}
+			if err != nil {
+				count.Inc()
+				RequestError(coreContext.Log, req, err)
+				return InternalError(ctx)
+			}
+			sum := sha256.Sum256([]byte(response))
+			retry, err := limits.TakeRateLimitToken(req.Context(),
+				"captcha:response:"+base64.RawStdEncoding.EncodeToString(sum[:]),
+				1, int64(captchaReplay/time.Millisecond), models.Time(),
+			)
+			if err != nil {
+				count.Inc()
+				RequestError(coreContext.Log, req, err)
+				return InternalError(ctx)
+			}
+			if retry > 0 {
+				count.Inc()
+				return captchaError(coreContext, ctx, models.ErrCaptchaInvalid, "Captcha response was already used")
+			}
+			return next(ctx)
+		}
+	}
+}
+
+func captchaError(coreContext *core.Ctx, ctx echo.Context, code, msg string) error {
+	challenge, err := coreContext.Captcha.Challenge()
+	if err != nil {
+		RequestError(coreContext.Log, ctx.Request(), err)
+		return InternalError(ctx)
+	}
+	return ctx.JSON(http.StatusBadRequest, models.CaptchaError{
+		Error:     models.NewError(code, msg),
+		Challenge: challenge,
+	})
+}
+
+// bucketRetention returns how long the rate limit buckets must be kept, 0 when
+// there are none.
+func bucketRetention(c *config.Matrix) time.Duration {
+	d := c.RateLimit.Retention()
+	if c.Captcha.Enabled() {
+		if c.Captcha.Every() > d {
+			d = c.Captcha.Every()
+		}
+		if captchaReplay > d {
+			d = captchaReplay
+		}
+	}
+	return d
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gernest/sydent-go/config"
+	"github.com/gernest/sydent-go/models"
+	"github.com/gernest/sydent-go/store"
+	"github.com/labstack/echo"
+)
+
+func TestCaptcha(t *testing.T) {
+	cfg := *mainContext.Config
+	cfg.Captcha = config.Captcha{
+		Verifier:   config.CaptchaProofOfWork,
+		Threshold:  "2",
+		Window:     "1h",
+		Difficulty: "8",
+		Secret:     "secret",
+	}
+	tctx := *mainContext
+	tctx.Config = &cfg
+	tctx.Limits = store.NewMemory()
+	var err error
+	tctx.Captcha, err = cfg.Captcha.CaptchaVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	e := echo.New()
+	e.POST("/requestToken", func(ctx echo.Context) error {
+		m, err := models.EnsureParams(ctx.Request(), "email")
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, err)
+		}
+		got = append(got, m["email"])
+		return ctx.JSON(http.StatusOK, map[string]interface{}{})
+	}, Captcha(&tctx, &TestMetric{}))
+
+	type result struct {
+		Code    string `json:"errcode"`
+		Captcha struct {
+			Type       string `json:"type"`
+			Challenge  string `json:"challenge"`
+			Difficulty int    `json:"difficulty"`
+		} `json:"captcha"`
+	}
+	var calls int
+	call := func(ip, email, response string) (int, result) {
+		params := map[string]string{"email": email}
+		if response != "" {
+			params[captchaParam] = response
+		}
+		body, _ := json.Marshal(params)
+		req := httptest.NewRequest(http.MethodPost, "/requestToken", bytes.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.RemoteAddr = ip + ":1234"
+		// clients can not pick their ip with headers
+		calls++
+		req.Header.Set(echo.HeaderXRealIP, fmt.Sprintf("10.1.0.%d", calls))
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		var res result
+		if rec.Code != http.StatusOK {
+			if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return rec.Code, res
+	}
+	expectError := func(code int, res result, errcode string) {
+		t.Helper()
+		if code != http.StatusBadRequest || res.Code != errcode {
+			t.Fatalf("expected %s got %d %s", errcode, code, res.Code)
+		}
+		if res.Captcha.Type != config.CaptchaProofOfWork || res.Captcha.Challenge == "" || res.Captcha.Difficulty != 8 {
+			t.Fatalf("unexpected challenge %+v", res.Captcha)
+		}
+	}
+
+	for _, email := range []string{"alice@example.com", "bob@example.com"} {
+		if code, res := call("10.0.0.1", email, ""); code != http.StatusOK {
+			t.Fatalf("%s: expected no challenge got %d %s", email, code, res.Code)
+		}
+	}
+	code, res := call("10.0.0.1", "carol@example.com", "")
+	expectError(code, res, models.ErrCaptchaNeeded)
+
+	// other clients are not challenged yet
+	if code, res := call("10.0.0.2", "dave@example.com", ""); code != http.StatusOK {
+		t.Fatalf("expected no challenge got %d %s", code, res.Code)
+	}
+
+	code, res = call("10.0.0.1", "carol@example.com", res.Captcha.Challenge+":x")
+	expectError(code, res, models.ErrCaptchaInvalid)
+
+	response := config.SolvePow(res.Captcha.Challenge, res.Captcha.Difficulty)
+	if code, res := call("10.0.0.1", "carol@example.com", response); code != http.StatusOK {
+		t.Fatalf("expected solved challenge to pass got %d %s", code, res.Code)
+	}
+	code, res = call("10.0.0.1", "erin@example.com", response)
+	expectError(code, res, models.ErrCaptchaInvalid)
+
+	expect := []string{"alice@example.com", "bob@example.com", "dave@example.com", "carol@example.com"}
+	if strings.Join(got, ",") != strings.Join(expect, ",") {
+		t.Errorf("expected requests %v got %v", expect, got)
+	}
+}
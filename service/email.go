@@ -2,6 +2,7 @@ package service
 
 import (
 	"bytes"
+	"net"
 	"net/http"
 	"strconv"
 	"strings"
@@ -44,10 +45,16 @@ func EmailRequestCode(coreContext *core.Ctx, m Metric) echo.HandlerFunc {
 				"Invalid email address",
 			))
 		}
+		if !addressAllowed(coreContext, ctx, config.EndpointRequestToken, "email", email) {
+			count.Inc()
+			return addressDenied(ctx)
+		}
 		var tr TokenRequest
 		tr.Email = email
 		tr.ClientSecret = clientSecret
 		tr.SendAttempt = sendAttempt
+		tr.IP = net.ParseIP(coreContext.Proxies.ClientIP(req))
+		tr.Locales = requestLocales(req, m[localeParam])
 		if n, ok := m["next_link"]; ok {
 			if !strings.HasPrefix(n, "file:///") {
 				tr.NextLink = n
@@ -83,7 +90,7 @@ func GetEmailValidatedCode(coreContext *core.Ctx, m Metric) echo.HandlerFunc {
 		req := ctx.Request()
 		msg := "Verification successful! Please return to your Matrix client to continue."
 		status := http.StatusOK
-		err := validateEmailRequest(coreContext, req)
+		err := validateTokenRequest(coreContext, req)
 		if err != nil {
 			count.Inc()
 			msg = "Verification failed: you may need to request another verification email"
@@ -94,8 +101,11 @@ func GetEmailValidatedCode(coreContext *core.Ctx, m Metric) echo.HandlerFunc {
 				req.Header.Set("Location", nextLink)
 			}
 		}
+		page, _ := coreContext.Config.LocalizedTemplate(v,
+			requestLocales(req, req.URL.Query().Get(localeParam))...,
+		)
 		var buf bytes.Buffer
-		err = tpl.ExecuteTemplate(&buf, v, map[string]interface{}{
+		err = tpl.ExecuteTemplate(&buf, page, map[string]interface{}{
 			"message": msg,
 		})
 		if err != nil {
@@ -112,7 +122,7 @@ func GetEmailValidatedCode(coreContext *core.Ctx, m Metric) echo.HandlerFunc {
 func PostEmailValidatedCode(coreContext *core.Ctx, m Metric) echo.HandlerFunc {
 	count := m.CountError("post_email_validated_code")
 	return func(ctx echo.Context) error {
-		err := validateEmailRequest(coreContext, ctx.Request())
+		err := validateTokenRequest(coreContext, ctx.Request())
 		if err != nil {
 			count.Inc()
 			return ctx.JSON(http.StatusOK, models.Success{
@@ -125,7 +135,7 @@ func PostEmailValidatedCode(coreContext *core.Ctx, m Metric) echo.HandlerFunc {
 	}
 }
 
-func validateEmailRequest(coreContext *core.Ctx, req *http.Request) error {
+func validateTokenRequest(coreContext *core.Ctx, req *http.Request) error {
 	m, merr := models.EnsureParams(req, "token", "sid", "client_secret")
 	if merr != nil {
 		return merr
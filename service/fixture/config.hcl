@@ -10,7 +10,7 @@ server {
   crypto {
     algorithm   = "ed25519"
     version     = "0"
-    signing_key = "Je3daK6jsLslp585i/jt/2ioP563Sn3py5VTuyrEYfXjf2GI&#43;oBs0c&#43;rochHDZewd30ws8UfHzzw0DB4trbuYQ"
+    signing_key = "Je3daK6jsLslp585i/jt/2ioP563Sn3py5VTuyrEYfXjf2GI+oBs0c+rochHDZewd30ws8UfHzzw0DB4trbuYQ"
     verify_key  = "439hiPqAbNHPq6HIRw2XsHd9MLPFHx888NAweLa27mE"
   }
 }
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gernest/sydent-go/core"
+	"github.com/gernest/sydent-go/models"
+	"github.com/labstack/echo"
+)
+
+// ValidSubject returns an error if s is neither an address of a supported
+// medium nor a matrix id.
+func ValidSubject(s models.Subject) error {
+	if s.MatrixID != "" {
+		if s.Medium != "" || s.Address != "" {
+			return errors.New("either an address or a matrix id is required, not both")
+		}
+		if matrixIDServer(s.MatrixID) == "" {
+			return fmt.Errorf("%q is not a valid matrix id", s.MatrixID)
+		}
+		return nil
+	}
+	switch s.Medium {
+	case "email", "msisdn":
+	case "":
+		return errors.New("medium and address, or a matrix id are required")
+	default:
+		return fmt.Errorf("unsupported medium %q", s.Medium)
+	}
+	if s.Address == "" {
+		return errors.New("address is required")
+	}
+	return nil
+}
+
+// EraseSubject erases the data of s, see store.EraseSubject. The local
+// associations replaced with removals are pushed to peers like unbinds, signed
+// by this server, and peers remove the addresses when they receive them.
+func EraseSubject(ctx context.Context, coreContext *core.Ctx, s models.Subject) (*models.SubjectErasure, error) {
+	o, err := coreContext.Store.EraseSubject(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+	if o.LocalAssociations > 0 {
+		coreContext.Replication.Notify()
+	}
+	return o, nil
+}
+
+// AdminExportSubject returns a handler which exports everything stored about
+// the address or matrix id given in the body.
+func AdminExportSubject(coreContext *core.Ctx, m Metric) echo.HandlerFunc {
+	count := m.CountError("admin_export_subject")
+	return func(ctx echo.Context) error {
+		req := ctx.Request()
+		var s models.Subject
+		if err := json.NewDecoder(req.Body).Decode(&s); err != nil {
+			count.Inc()
+			return ctx.JSON(http.StatusBadRequest, models.NewError(
+				models.ErrBadJSON,
+				"Malformed JSON",
+			))
+		}
+		if err := ValidSubject(s); err != nil {
+			count.Inc()
+			return ctx.JSON(http.StatusBadRequest, models.NewError(
+				models.ErrInvalidParam, err.Error(),
+			))
+		}
+		o, err := coreContext.Store.ExportSubject(req.Context(), s)
+		if err != nil {
+			count.Inc()
+			RequestError(coreContext.Log, req, err)
+			return InternalError(ctx)
+		}
+		return ctx.JSON(http.StatusOK, o)
+	}
+}
+
+// AdminEraseSubject returns a handler which erases the address or matrix id
+// given in the body, see EraseSubject.
+func AdminEraseSubject(coreContext *core.Ctx, m Metric) echo.HandlerFunc {
+	count := m.CountError("admin_erase_subject")
+	return func(ctx echo.Context) error {
+		req := ctx.Request()
+		var s models.Subject
+		if err := json.NewDecoder(req.Body).Decode(&s); err != nil {
+			count.Inc()
+			return ctx.JSON(http.StatusBadRequest, models.NewError(
+				models.ErrBadJSON,
+				"Malformed JSON",
+			))
+		}
+		if err := ValidSubject(s); err != nil {
+			count.Inc()
+			return ctx.JSON(http.StatusBadRequest, models.NewError(
+				models.ErrInvalidParam, err.Error(),
+			))
+		}
+		o, err := EraseSubject(req.Context(), coreContext, s)
+		if err != nil {
+			count.Inc()
+			RequestError(coreContext.Log, req, err)
+			return InternalError(ctx)
+		}
+		return ctx.JSON(http.StatusOK, o)
+	}
+}
@@ -29,20 +29,93 @@ func main() {
 		"GetPeerByName",
 		"GetAllPeers",
 		"SetLastSentVersionAndPokeSucceeded",
+		"ListPeers",
+		"AddPeer",
+		"CountPeers",
+		"DeletePeerKeys",
+		"DeletePeer",
+		"SetPeerKey",
+		"SetPeerActive",
+		"CountAssociationsAfterID",
 		"SetSendAttemptNumber",
 		"SetValidated",
 		"SetMtime",
 		"GetSessionByID",
 		"GetTokenSessionByID",
 		"GlobalLastIDFromServer",
-		"LocalAddOrUpdateAssociation",
+		"LocalAddAssociation",
 		"GetTokenSession",
 		"CreateTokenSession",
 		"AddValidationSession",
 		"GetAssociationsAfterId",
-		"GetLocal3pid",
-		"LocalRemoveAssociation",
+		"LocalDeleteAssociation",
 		"CreateTMPMxid",
+		"GetLookupPepper",
+		"DeleteLookupPepper",
+		"SetLookupPepper",
+		"GlobalAssociationsAfterID",
+		"SetLookupHash",
+		"LocalAssociationAddresses",
+		"SetLocalAssociationAddress",
+		"GlobalAssociationAddresses",
+		"SetGlobalAssociationAddress",
+		"ValidationSessionAddresses",
+		"SetValidationSessionAddress",
+		"InviteTokenAddresses",
+		"SetInviteTokenAddress",
+		"GetLocalAssociation",
+		"GlobalAssociationsFor",
+		"AddAuditEntry",
+		"QueryAuditLog",
+		"LocalAssociationsForMxid",
+		"GlobalAssociationsForMxid",
+		"ValidationSessionsFor",
+		"DeleteInviteTokensFor",
+		"DeleteTokenAuthsFor",
+		"DeleteValidationSessionsFor",
+		"EmailsAfter",
+		"RedactAuditAddress",
+		"RedactAuditMxid",
+		"GlobalGetMxidsByHash",
+		"AddAccount",
+		"AddAccountToken",
+		"GetAccountByToken",
+		"DeleteAccountToken",
+		"AddAcceptedTerms",
+		"GetAcceptedTerms",
+		"AddOnbindNotification",
+		"GetDueOnbindNotifications",
+		"NextOnbindNotificationTS",
+		"RetryOnbindNotification",
+		"DeleteOnbindNotification",
+		"ImportInviteToken",
+		"ImportEphemeralPublicKey",
+		"ImportPeer",
+		"ImportPeerPubkey",
+		"ImportLocalAssociation",
+		"ImportGlobalAssociation",
+		"ImportValidationSession",
+		"ImportTokenAuth",
+		"GetImportCheckpoint",
+		"SetImportCheckpoint",
+		"ResetSequence",
+		"GetExpiredSessions",
+		"DeleteSessionTokenAuths",
+		"DeleteSessions",
+		"ReapInviteTokens",
+		"ReapEphemeralPublicKeys",
+		"AddEmail",
+		"GetDueEmails",
+		"ListEmails",
+		"ClaimEmail",
+		"RetryEmail",
+		"DeadEmail",
+		"RequeueEmail",
+		"DeleteEmail",
+		"NextEmailTS",
+		"TakeRateLimitToken",
+		"GetRateLimit",
+		"ReapRateLimits",
 	}
 
 	tpl, err := template.New("yay").Funcs(template.FuncMap{
@@ -54,7 +127,7 @@ func main() {
 	var buf bytes.Buffer
 	err = tpl.Execute(&buf, map[string]interface{}{
 		"names": names,
-		"pkg":   []string{"postgres"},
+		"pkg":   []string{"postgres", "sqlite3"},
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -73,8 +146,19 @@ import(
 	"fmt"
 	{{- range .pkg}}
 	"github.com/gernest/sydent-go/store/drivers/{{.}}"
-	{{end}}
+	{{- end}}
 )
+// Driver returns the queries of a database dialect.
+type Driver interface{
+	{{- range .names}}
+	{{.}}()string
+	{{- end}}
+	Name()string
+	Param(int)string
+}
+
+// DriverHandle implements Driver with the queries of a package in
+// store/drivers.
 type DriverHandle struct{
 	{{- range .names -}}
 	{{.|low}} string
@@ -83,6 +167,7 @@ type DriverHandle struct{
 	param func(int)string
 }
 
+// NewDriver returns the Driver for the database/sql driver name.
 func NewDriver(name string)(Driver,error){
 	switch name{
 		{{- range .pkg -}}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gernest/sydent-go/core"
+	"github.com/gernest/sydent-go/models"
+	"github.com/labstack/echo"
+	"go.uber.org/zap"
+)
+
+// lookupPepperLength is the length of generated lookup peppers.
+const lookupPepperLength = 32
+
+// HashedLookupRequest is the body of v2 /lookup requests.
+type HashedLookupRequest struct {
+	Addresses []string `json:"addresses"`
+	Algorithm string   `json:"algorithm"`
+	Pepper    string   `json:"pepper"`
+}
+
+// HashDetails returns the pepper and algorithms clients must use to hash 3pids
+// for v2 lookups.
+func HashDetails(coreContext *core.Ctx, m Metric) echo.HandlerFunc {
+	count := m.CountError("hash_details")
+	db := coreContext.Store
+	return func(ctx echo.Context) error {
+		req := ctx.Request()
+		pepper, err := db.GetLookupPepper(req.Context())
+		if err != nil {
+			count.Inc()
+			RequestError(coreContext.Log, req, err)
+			return InternalError(ctx)
+		}
+		return ctx.JSON(http.StatusOK, map[string]interface{}{
+			"lookup_pepper": pepper,
+			"algorithms":    models.LookupAlgorithms,
+		})
+	}
+}
+
+// HashedLookup looks up 3pids in bulk using the v2 lookup api. With the sha256
+// algorithm addresses are lookup hashes, with none they are plain
+// "address medium" pairs.
+func HashedLookup(coreContext *core.Ctx, m Metric) echo.HandlerFunc {
+	count := m.CountError("hashed_lookup")
+	db := coreContext.Store
+	lg := coreContext.Log
+	return func(ctx echo.Context) error {
+		req := ctx.Request()
+		b, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			count.Inc()
+			RequestError(lg, req, err)
+			return ctx.JSON(http.StatusBadRequest, models.NewError(
+				models.ErrBadJSON,
+				"Malformed JSON",
+			))
+		}
+		var o HashedLookupRequest
+		err = json.Unmarshal(b, &o)
+		if err != nil {
+			count.Inc()
+			RequestError(lg, req, err)
+			return ctx.JSON(http.StatusBadRequest, models.NewError(
+				models.ErrBadJSON,
+				"Malformed JSON",
+			))
+		}
+		var missing []string
+		if o.Addresses == nil {
+			missing = append(missing, "addresses")
+		}
+		if o.Algorithm == "" {
+			missing = append(missing, "algorithm")
+		}
+		if o.Pepper == "" {
+			missing = append(missing, "pepper")
+		}
+		if len(missing) > 0 {
+			return ctx.JSON(http.StatusBadRequest, models.NewError(
+				models.ErrMissingParam,
+				fmt.Sprintf("Missing parameters: %s", strings.Join(missing, ",")),
+			))
+		}
+		if !in(o.Algorithm, models.LookupAlgorithms...) {
+			return ctx.JSON(http.StatusBadRequest, models.NewError(
+				models.ErrInvalidParam,
+				"algorithm is not supported",
+			))
+		}
+		requestContext := req.Context()
+		pepper, err := db.GetLookupPepper(requestContext)
+		if err != nil {
+			count.Inc()
+			RequestError(lg, req, err)
+			return InternalError(ctx)
+		}
+		if o.Pepper != pepper {
+			return ctx.JSON(http.StatusBadRequest, models.NewError(
+				models.ErrInvalidPepper,
+				fmt.Sprintf("pepper does not match '%s'", pepper),
+			))
+		}
+		var mappings map[string]string
+		if o.Algorithm == models.LookupAlgorithmSHA256 {
+			mappings, err = db.GlobalGetMxidsByHash(requestContext, o.Addresses)
+		} else {
+			mappings, err = plainLookup(requestContext, coreContext, o.Addresses)
+		}
+		if err != nil {
+			count.Inc()
+			RequestError(lg, req, err)
+			return InternalError(ctx)
+		}
+		return ctx.JSON(http.StatusOK, map[string]interface{}{
+			"mappings": mappings,
+		})
+	}
+}
+
+// plainLookup resolves "address medium" pairs with the same store path as v1
+// bulk lookups. The returned map is keyed by the pairs as they were given,
+// pairs with the same canonical address get the same mxid.
+func plainLookup(ctx context.Context, coreContext *core.Ctx, addresses []string) (map[string]string, error) {
+	mappings := make(map[string]string)
+	keys := make(map[string][]string)
+	var ids [][]string
+	for _, v := range addresses {
+		i := strings.LastIndex(v, " ")
+		if i == -1 {
+			continue
+		}
+		medium, address := v[i+1:], v[:i]
+		k := medium + " " + coreContext.Canonical.Address(medium, address)
+		keys[k] = append(keys[k], v)
+		ids = append(ids, []string{medium, address})
+	}
+	if len(ids) == 0 {
+		return mappings, nil
+	}
+	as, err := coreContext.Store.GlobalGetMxids(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range as {
+		// rows stored before addresses were canonical are matched too.
+		for _, k := range keys[a.Medium+" "+coreContext.Canonical.Address(a.Medium, a.Address)] {
+			// results are ordered by ts descending, keep the newest.
+			if _, ok := mappings[k]; !ok {
+				mappings[k] = a.MatrixID
+			}
+		}
+	}
+	return mappings, nil
+}
+
+// EnsureLookupPepper generates the lookup pepper if it does not exist yet.
+func EnsureLookupPepper(ctx context.Context, coreContext *core.Ctx) error {
+	_, err := coreContext.Store.GetLookupPepper(ctx)
+	if err == nil {
+		return nil
+	}
+	if err != sql.ErrNoRows {
+		return err
+	}
+	_, err = RotateLookupPepper(ctx, coreContext)
+	return err
+}
+
+// RotateLookupPepper replaces the lookup pepper with a new random one and
+// rehashes all associations. The new pepper is returned.
+func RotateLookupPepper(ctx context.Context, coreContext *core.Ctx) (string, error) {
+	pepper := models.SecureRandomString(lookupPepperLength)
+	err := coreContext.Store.RotateLookupPepper(ctx, pepper)
+	if err != nil {
+		return "", err
+	}
+	return pepper, nil
+}
+
+// PepperRotator rotates the lookup pepper every interval until ctx is done.
+func PepperRotator(coreContext *core.Ctx, interval time.Duration) func(context.Context) {
+	lg := coreContext.Log
+	return func(ctx context.Context) {
+		tick := time.NewTicker(interval)
+		defer tick.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-tick.C:
+				_, err := RotateLookupPepper(ctx, coreContext)
+				if err != nil {
+					lg.Error("failed to rotate lookup pepper", zap.Error(err))
+					continue
+				}
+				lg.Info("rotated lookup pepper")
+			}
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gernest/sydent-go/models"
+	"github.com/labstack/echo"
+)
+
+func TestHashedLookup(t *testing.T) {
+	bctx := context.Background()
+	now := models.Time()
+	err := mainContext.Store.GlobalAddAssociation(bctx, &models.Association{
+		Medium:    "email",
+		Address:   "alice@example.com",
+		MatrixID:  "@alice:example.com",
+		Timestamp: now,
+		NotBefore: now - 1000,
+		NotAfter:  now + 100000,
+	}, "example.com", 100, "{}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pepper, err := RotateLookupPepper(bctx, mainContext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := echo.New()
+	lookup := func(body string) (int, map[string]interface{}) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		err := HashedLookup(mainContext, &TestMetric{})(e.NewContext(req, rec))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var o map[string]interface{}
+		json.Unmarshal(rec.Body.Bytes(), &o)
+		return rec.Code, o
+	}
+
+	hash := models.LookupHash("email", "alice@example.com", pepper)
+	code, o := lookup(`{"algorithm":"sha256","pepper":"` + pepper + `","addresses":["` + hash + `"]}`)
+	if code != http.StatusOK {
+		t.Fatalf("expected 200 got %d %v", code, o)
+	}
+	mappings := o["mappings"].(map[string]interface{})
+	if mappings[hash] != "@alice:example.com" {
+		t.Errorf("expected alice got %v", mappings)
+	}
+
+	code, o = lookup(`{"algorithm":"none","pepper":"` + pepper + `","addresses":["Alice@example.com email"]}`)
+	if code != http.StatusOK {
+		t.Fatalf("expected 200 got %d %v", code, o)
+	}
+	mappings = o["mappings"].(map[string]interface{})
+	if mappings["Alice@example.com email"] != "@alice:example.com" {
+		t.Errorf("expected alice got %v", mappings)
+	}
+
+	code, o = lookup(`{"algorithm":"sha256","pepper":"stale","addresses":["` + hash + `"]}`)
+	if code != http.StatusBadRequest || o["errcode"] != models.ErrInvalidPepper {
+		t.Errorf("expected M_INVALID_PEPPER got %d %v", code, o)
+	}
+	code, o = lookup(`{"algorithm":"md5","pepper":"` + pepper + `","addresses":[]}`)
+	if code != http.StatusBadRequest || o["errcode"] != models.ErrInvalidParam {
+		t.Errorf("expected M_INVALID_PARAM got %d %v", code, o)
+	}
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/gernest/sydent-go/core"
+	"github.com/gernest/sydent-go/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// TableLabel is the label of the table rows were deleted from.
+const TableLabel = "table"
+
+// JanitorMetric counts the rows deleted by the janitor.
+type JanitorMetric struct {
+	Reaped *prometheus.CounterVec
+}
+
+// NewJanitorMetric returns a registered JanitorMetric.
+func NewJanitorMetric() JanitorMetric {
+	m := newJanitorMetric()
+	prometheus.MustRegister(m.Reaped)
+	return m
+}
+
+func newJanitorMetric() JanitorMetric {
+	return JanitorMetric{
+		Reaped: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "matrix",
+				Subsystem: "janitor",
+				Name:      "reaped_rows_total",
+				Help:      "counts number of expired rows deleted by the janitor",
+			},
+			[]string{TableLabel},
+		),
+	}
+}
+
+func (m JanitorMetric) add(table string, n int64) {
+	m.Reaped.With(prometheus.Labels{TableLabel: table}).Add(float64(n))
+}
+
+// Janitor returns a function which deletes expired rows every
+// config.Janitor.Every until ctx is cancelled.
+func Janitor(coreContext *core.Ctx, m JanitorMetric) func(context.Context) {
+	lg := coreContext.Log
+	interval := coreContext.Config.Janitor.Every()
+	return func(ctx context.Context) {
+		tick := time.NewTicker(interval)
+		defer tick.Stop()
+		for {
+			if err := Reap(ctx, coreContext, m); err != nil {
+				lg.Error("failed to delete expired rows", zap.Error(err))
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-tick.C:
+			}
+		}
+	}
+}
+
+// Reap deletes all rows which are older than their retention period. Rows are
+// deleted in batches of config.Janitor.Limit so the tables are never locked
+// for long.
+func Reap(ctx context.Context, coreContext *core.Ctx, m JanitorMetric) error {
+	c := coreContext.Config.Janitor
+	db := coreContext.Store
+	limit := c.Limit()
+	if d := c.SessionRetention(); d > 0 {
+		before := models.Time() - int64(d/time.Millisecond)
+		for {
+			sessions, tokenAuths, err := db.ReapValidationSessions(ctx, before, limit)
+			if err != nil {
+				return err
+			}
+			m.add("threepid_validation_sessions", sessions)
+			m.add("threepid_token_auths", tokenAuths)
+			if sessions < int64(limit) {
+				break
+			}
+		}
+	}
+	type table struct {
+		name      string
+		retention time.Duration
+		reap      func(context.Context, int64, int) (int64, error)
+	}
+	tables := []table{
+		{"invite_tokens", c.InviteTokenRetention(), db.ReapInviteTokens},
+		{"ephemeral_public_keys", c.EphemeralKeyRetention(), db.ReapEphemeralPublicKeys},
+	}
+	if d := bucketRetention(coreContext.Config); d > 0 {
+		// buckets which filled up again are the same as missing ones.
+		tables = append(tables, table{
+			"rate_limits", d, rateLimitStore(coreContext).ReapRateLimits,
+		})
+	}
+	for _, t := range tables {
+		if t.retention <= 0 {
+			continue
+		}
+		before := models.Time() - int64(t.retention/time.Millisecond)
+		for {
+			n, err := t.reap(ctx, before, limit)
+			if err != nil {
+				return err
+			}
+			m.add(t.name, n)
+			if n < int64(limit) {
+				break
+			}
+		}
+	}
+	return nil
+}
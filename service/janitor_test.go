@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/gernest/sydent-go/config"
+	"github.com/gernest/sydent-go/core"
+	"github.com/gernest/sydent-go/models"
+	"github.com/gernest/sydent-go/store"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestReap(t *testing.T) {
+	ctx := context.Background()
+	db := store.NewMemory()
+	tctx := &core.Ctx{
+		Config: &config.Matrix{
+			Janitor: config.Janitor{Batch: "2"},
+		},
+		Store: db,
+	}
+	var ids []int64
+	for _, address := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		s, err := db.GetOrCreateTokenSession(ctx, "email", address, "secret")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, s.ID)
+	}
+	old := models.Time() - int64(config.SessionLifetime/time.Millisecond) - 1000
+	for _, id := range ids[1:] {
+		if err := db.SetMtime(ctx, id, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m := newJanitorMetric()
+	if err := Reap(ctx, tctx, m); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.GetSessionByID(ctx, ids[0]); err != nil {
+		t.Errorf("expected session %d to be kept got %v", ids[0], err)
+	}
+	for _, id := range ids[1:] {
+		if _, err := db.GetSessionByID(ctx, id); err != sql.ErrNoRows {
+			t.Errorf("expected session %d to be deleted got %v", id, err)
+		}
+	}
+	for table, expect := range map[string]float64{
+		"threepid_validation_sessions": 2,
+		"threepid_token_auths":         2,
+		"invite_tokens":                0,
+	} {
+		var o dto.Metric
+		m.Reaped.With(prometheus.Labels{TableLabel: table}).Write(&o)
+		if v := o.GetCounter().GetValue(); v != expect {
+			t.Errorf("%s: expected %v reaped rows got %v", table, expect, v)
+		}
+	}
+}
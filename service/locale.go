@@ -0,0 +1,58 @@
+package service
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// localeParam is the request parameter choosing the locale of emails and
+// pages, it takes precedence over the Accept-Language header.
+const localeParam = "locale"
+
+// requestLocales returns the locales preferred by req, param first followed by
+// the languages of the Accept-Language header in order of preference.
+func requestLocales(req *http.Request, param string) []string {
+	var o []string
+	if param != "" {
+		o = append(o, param)
+	}
+	return append(o, acceptLanguages(req.Header.Get("Accept-Language"))...)
+}
+
+// acceptLanguages returns the languages of the Accept-Language header h sorted
+// by their quality, languages with a quality of 0 and * are left out.
+func acceptLanguages(h string) []string {
+	type lang struct {
+		tag string
+		q   float64
+	}
+	var langs []lang
+	for _, part := range strings.Split(h, ",") {
+		fields := strings.Split(part, ";")
+		l := lang{tag: strings.TrimSpace(fields[0]), q: 1}
+		for _, f := range fields[1:] {
+			f = strings.TrimSpace(f)
+			if strings.HasPrefix(f, "q=") {
+				q, err := strconv.ParseFloat(f[2:], 64)
+				if err != nil {
+					q = 0
+				}
+				l.q = q
+			}
+		}
+		if l.tag == "" || l.tag == "*" || l.q <= 0 {
+			continue
+		}
+		langs = append(langs, l)
+	}
+	sort.SliceStable(langs, func(i, j int) bool {
+		return langs[i].q > langs[j].q
+	})
+	o := make([]string, len(langs))
+	for i, l := range langs {
+		o[i] = l.tag
+	}
+	return o
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestRequestLocales(t *testing.T) {
+	sample := []struct {
+		param  string
+		header string
+		expect []string
+	}{
+		{"", "", nil},
+		{"fr", "", []string{"fr"}},
+		{"", "de-CH, fr;q=0.9, en;q=0.8, *;q=0.5", []string{"de-CH", "fr", "en"}},
+		{"", "en;q=0.3, fr, pt-BR;q=0.7, es;q=0", []string{"fr", "pt-BR", "en"}},
+		{"de", "fr, en;q=bad", []string{"de", "fr"}},
+	}
+	for _, v := range sample {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if v.header != "" {
+			req.Header.Set("Accept-Language", v.header)
+		}
+		got := requestLocales(req, v.param)
+		if len(got) == 0 && len(v.expect) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(got, v.expect) {
+			t.Errorf("%q %q: expected %v got %v", v.param, v.header, v.expect, got)
+		}
+	}
+}
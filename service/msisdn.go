@@ -0,0 +1,86 @@
+package service
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gernest/sydent-go/config"
+	"github.com/gernest/sydent-go/core"
+	"github.com/gernest/sydent-go/models"
+	"github.com/labstack/echo"
+)
+
+// MsisdnRequestCode returns a handler which sends a validation token to a phone
+// number. The number is given as phone_number in the national format of
+// country or in international format.
+func MsisdnRequestCode(coreContext *core.Ctx, m Metric) echo.HandlerFunc {
+	count := m.CountError("msisdn_request_code")
+	return func(ctx echo.Context) error {
+		req := ctx.Request()
+		m, merr := models.EnsureParams(req, "phone_number", "country", "client_secret", "send_attempt")
+		if merr != nil {
+			count.Inc()
+			RequestError(coreContext.Log, req, merr)
+			return ctx.JSON(http.StatusBadRequest, merr)
+		}
+		sendAttempt, err := strconv.ParseInt(m["send_attempt"], 10, 64)
+		if err != nil {
+			count.Inc()
+			RequestError(coreContext.Log, req, err)
+			return ctx.JSON(http.StatusBadRequest, models.NewError(
+				models.ErrInvalidParam,
+				"send_attempt is not a valid integer",
+			))
+		}
+		phone, err := models.ParsePhoneNumber(m["country"], m["phone_number"])
+		if err != nil {
+			count.Inc()
+			RequestError(coreContext.Log, req, err)
+			return ctx.JSON(http.StatusBadRequest, models.NewError(
+				models.ErrInvalidPhoneNumberCode,
+				"Unable to parse number",
+			))
+		}
+		if !addressAllowed(coreContext, ctx, config.EndpointRequestToken, "msisdn", phone.MSISDN()) {
+			count.Inc()
+			return addressDenied(ctx)
+		}
+		sid, err := RequestMsisdnToken(req.Context(), coreContext, &MsisdnTokenRequest{
+			Msisdn:       phone.MSISDN(),
+			ClientSecret: m["client_secret"],
+			SendAttempt:  sendAttempt,
+		})
+		if err != nil {
+			count.Inc()
+			RequestError(coreContext.Log, req, err)
+			return ctx.JSON(http.StatusInternalServerError, models.NewError(
+				models.ErrUnknown,
+				"Failed to send SMS",
+			))
+		}
+		return ctx.JSON(http.StatusOK, map[string]interface{}{
+			"success":  true,
+			"sid":      sid,
+			"msisdn":   phone.MSISDN(),
+			"intl_fmt": phone.International(),
+		})
+	}
+}
+
+// MsisdnValidatedCode returns a handler which validates the token that was sent
+// to a phone number. It serves both GET and POST requests.
+func MsisdnValidatedCode(coreContext *core.Ctx, m Metric) echo.HandlerFunc {
+	count := m.CountError("msisdn_validated_code")
+	return func(ctx echo.Context) error {
+		err := validateTokenRequest(coreContext, ctx.Request())
+		if err != nil {
+			count.Inc()
+			return ctx.JSON(http.StatusOK, models.Success{
+				Success: false,
+			})
+		}
+		return ctx.JSON(http.StatusOK, models.Success{
+			Success: true,
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gernest/sydent-go/config"
+	"github.com/labstack/echo"
+)
+
+type TestSMSClient struct {
+	send func(originator, to string, body []byte) error
+}
+
+func (n TestSMSClient) Send(ctx context.Context, originator, to string, body []byte) error {
+	if n.send != nil {
+		return n.send(originator, to, body)
+	}
+	return nil
+}
+
+func (n TestSMSClient) Valid() *config.Validation {
+	return &config.Validation{Namespace: "noop"}
+}
+
+func TestMsisdnValidation(t *testing.T) {
+	var to, body string
+	tctx := *mainContext
+	tctx.SMS = config.NewSMS(TestSMSClient{
+		send: func(originator, t string, b []byte) error {
+			to = t
+			body = string(b)
+			return nil
+		},
+	}, mainContext.Config.GetTemplate())
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{
+		"client_secret": "msisdn-secret",
+		"country": "GB",
+		"phone_number": "07700 900123",
+		"send_attempt": 1
+	}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	err := MsisdnRequestCode(&tctx, &TestMetric{})(e.NewContext(req, rec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d %s", rec.Code, rec.Body.String())
+	}
+	var res struct {
+		SID     int64  `json:"sid"`
+		Msisdn  string `json:"msisdn"`
+		IntlFmt string `json:"intl_fmt"`
+	}
+	err = json.Unmarshal(rec.Body.Bytes(), &res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Msisdn != "447700900123" || to != res.Msisdn {
+		t.Errorf("expected sms to 447700900123 got %q %q", res.Msisdn, to)
+	}
+	token := strings.TrimPrefix(body, "Your code is ")
+	if len(token) != 6 {
+		t.Fatalf("expected 6 digit token got %q", body)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(fmt.Sprintf(`{
+		"client_secret": "msisdn-secret",
+		"sid": %d,
+		"token": %q
+	}`, res.SID, token)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	err = MsisdnValidatedCode(&tctx, &TestMetric{})(e.NewContext(req, rec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `{"success":true}`
+	if got := strings.TrimSpace(rec.Body.String()); got != expect {
+		t.Errorf("expected %s got %s", expect, got)
+	}
+	_, err = tctx.Store.GetValidatedSession(context.Background(), res.SID, "msisdn-secret")
+	if err != nil {
+		t.Errorf("expected validated session got %v", err)
+	}
+}
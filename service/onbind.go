@@ -0,0 +1,211 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gernest/signedjson"
+	"github.com/gernest/sydent-go/config"
+	"github.com/gernest/sydent-go/core"
+	"github.com/gernest/sydent-go/models"
+	"github.com/gernest/sydent-go/store"
+	"go.uber.org/zap"
+)
+
+// OnbindPath is the federation endpoint of homeservers which receives signed
+// associations of newly bound third party ids.
+const OnbindPath = "/_matrix/federation/v1/3pid/onbind"
+
+const (
+	onbindBatch       = 100
+	onbindMaxAttempts = config.MaxRetries
+	onbindMinDelay    = 10 * time.Second
+	onbindMaxDelay    = time.Hour
+
+	// onbindPoll is how long the notifier sleeps when the queue is empty. The
+	// notifier is woken up by core.Ctx.OnBind as soon as something is queued.
+	onbindPoll = 5 * time.Minute
+)
+
+var errInvalidMatrixID = errors.New("invalid mxid")
+
+// QueueOnbind stores the signed association for delivery to the homeserver of
+// mxid and wakes up the notifier. The queue is kept in the database so pending
+// notifications survive restarts.
+func QueueOnbind(ctx context.Context, coreContext *core.Ctx, mxid string, sgAssoc signedjson.Message) error {
+	err := addOnbind(ctx, coreContext.Store, mxid, sgAssoc)
+	if err != nil {
+		return err
+	}
+	coreContext.OnBind.Notify()
+	return nil
+}
+
+// addOnbind stores the signed association in db for delivery to the
+// homeserver of mxid, without waking up the notifier.
+func addOnbind(ctx context.Context, db store.Store, mxid string, sgAssoc signedjson.Message) error {
+	destination := matrixIDServer(mxid)
+	if destination == "" {
+		return errInvalidMatrixID
+	}
+	b, err := json.Marshal(sgAssoc)
+	if err != nil {
+		return err
+	}
+	_, err = db.AddOnbindNotification(ctx, destination, string(b))
+	return err
+}
+
+// matrixIDServer returns the server name part of mxid.
+func matrixIDServer(mxid string) string {
+	i := strings.IndexByte(mxid, ':')
+	if i == -1 || !strings.HasPrefix(mxid, "@") {
+		return ""
+	}
+	return mxid[i+1:]
+}
+
+// OnbindNotifier returns a function which delivers queued onbind notifications
+// until ctx is cancelled. client is expected to resolve matrix:// urls, see
+// clients.FederatedTripper.
+func OnbindNotifier(coreContext *core.Ctx, client config.HTTPClient) func(context.Context) {
+	lg := coreContext.Log
+	return func(ctx context.Context) {
+		timer := time.NewTimer(0)
+		defer timer.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-coreContext.OnBind:
+			case <-timer.C:
+			}
+			wait, err := DeliverOnbind(ctx, coreContext, client)
+			if err != nil {
+				lg.Error("failed to deliver onbind notifications", zap.Error(err))
+				wait = onbindMinDelay
+			}
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(wait)
+		}
+	}
+}
+
+// DeliverOnbind sends all notifications which are due and returns how long to
+// wait until the next one is due.
+//
+// Failed deliveries are retried with exponential backoff. Notifications are
+// dropped when the homeserver rejects them or after onbindMaxAttempts.
+func DeliverOnbind(ctx context.Context, coreContext *core.Ctx, client config.HTTPClient) (time.Duration, error) {
+	db := coreContext.Store
+	lg := coreContext.Log
+	for {
+		due, err := db.GetDueOnbindNotifications(ctx, models.Time(), onbindBatch)
+		if err != nil {
+			return 0, err
+		}
+		for _, n := range due {
+			retry, err := sendOnbind(ctx, client, &n)
+			if err == nil {
+				err = db.DeleteOnbindNotification(ctx, n.ID)
+				if err != nil {
+					return 0, err
+				}
+				continue
+			}
+			if !retry || n.Attempts+1 >= onbindMaxAttempts {
+				lg.Error("dropping onbind notification",
+					zap.String("destination", n.Destination),
+					zap.Int64("attempts", n.Attempts+1),
+					zap.Error(err),
+				)
+				err = db.DeleteOnbindNotification(ctx, n.ID)
+				if err != nil {
+					return 0, err
+				}
+				continue
+			}
+			lg.Info("failed to deliver onbind notification",
+				zap.String("destination", n.Destination),
+				zap.Int64("attempts", n.Attempts+1),
+				zap.Error(err),
+			)
+			next := time.Now().Add(onbindDelay(n.Attempts + 1))
+			err = db.RetryOnbindNotification(ctx, n.ID, models.MS(&next), err.Error())
+			if err != nil {
+				return 0, err
+			}
+		}
+		if len(due) < onbindBatch {
+			break
+		}
+	}
+	ts, err := db.NextOnbindNotificationTS(ctx)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return onbindPoll, nil
+		}
+		return 0, err
+	}
+	wait := time.Until(models.FromMS(ts))
+	if wait < 0 {
+		wait = 0
+	}
+	if wait > onbindPoll {
+		wait = onbindPoll
+	}
+	return wait, nil
+}
+
+// onbindDelay returns the delay before the next delivery attempt after
+// attempts failures.
+func onbindDelay(attempts int64) time.Duration {
+	d := onbindMinDelay
+	for i := int64(1); i < attempts && d < onbindMaxDelay; i++ {
+		d *= 2
+	}
+	if d > onbindMaxDelay {
+		d = onbindMaxDelay
+	}
+	return d
+}
+
+// sendOnbind posts the notification to its homeserver. retry is false when
+// the homeserver rejected the notification and sending it again won't help.
+func sendOnbind(ctx context.Context, client config.HTTPClient, n *models.OnbindNotification) (retry bool, err error) {
+	u := fmt.Sprintf("matrix://%s%s", n.Destination, OnbindPath)
+	req, err := http.NewRequest(http.MethodPost, u, bytes.NewReader([]byte(n.Payload)))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", config.AgentName)
+	res, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return true, err
+	}
+	defer res.Body.Close()
+	io.Copy(ioutil.Discard, res.Body)
+	switch {
+	case res.StatusCode >= 200 && res.StatusCode < 300:
+		return false, nil
+	case res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500:
+		return true, fmt.Errorf("onbind: %s responded with %s", n.Destination, res.Status)
+	default:
+		return false, fmt.Errorf("onbind: %s responded with %s", n.Destination, res.Status)
+	}
+}
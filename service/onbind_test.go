@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gernest/signedjson"
+	"github.com/gernest/sydent-go/clients"
+	"github.com/gernest/sydent-go/models"
+	"github.com/gernest/sydent-go/store"
+)
+
+func TestDeliverOnbind(t *testing.T) {
+	var fail int32 = 1
+	received := make(chan map[string]interface{}, 1)
+	hs := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != OnbindPath {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if atomic.LoadInt32(&fail) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var o map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&o)
+		received <- o
+	}))
+	defer hs.Close()
+	u, err := url.Parse(hs.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{
+		Transport: clients.NewFederatedTripperWith(0, hs.Client().Transport, nil),
+	}
+	bctx := context.Background()
+	err = QueueOnbind(bctx, mainContext, "@alice:"+u.Host, signedjson.Message{
+		"medium":  "email",
+		"address": "alice@example.com",
+		"mxid":    "@alice:" + u.Host,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wait, err := DeliverOnbind(bctx, mainContext, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wait <= 0 || wait > onbindMinDelay {
+		t.Errorf("expected retry within %v got %v", onbindMinDelay, wait)
+	}
+	due, err := mainContext.Store.GetDueOnbindNotifications(bctx, time.Now().Add(time.Hour).UnixNano()/int64(time.Millisecond), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(due) != 1 || due[0].Attempts != 1 {
+		t.Fatalf("expected one notification with a failed attempt got %#v", due)
+	}
+
+	atomic.StoreInt32(&fail, 0)
+	_, err = sendOnbind(bctx, client, &due[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case o := <-received:
+		if o["address"] != "alice@example.com" {
+			t.Errorf("unexpected payload %v", o)
+		}
+	default:
+		t.Fatal("expected homeserver to receive the notification")
+	}
+}
+
+func TestOnbindDelay(t *testing.T) {
+	sample := []struct {
+		attempts int64
+		delay    time.Duration
+	}{
+		{1, onbindMinDelay},
+		{2, 2 * onbindMinDelay},
+		{3, 4 * onbindMinDelay},
+		{100, onbindMaxDelay},
+	}
+	for _, v := range sample {
+		if got := onbindDelay(v.attempts); got != v.delay {
+			t.Errorf("%d: expected %v got %v", v.attempts, v.delay, got)
+		}
+	}
+}
+
+func TestAddBindingInvites(t *testing.T) {
+	tctx := *mainContext
+	tctx.Store = store.NewMemory()
+	bctx := context.Background()
+	err := tctx.Store.StoreToken(bctx, models.InviteToken{
+		Medium:  "email",
+		Address: "invited@example.com",
+		RoomID:  "!room:example.com",
+		Sender:  "@sender:example.com",
+		Token:   "invite-token",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sent := func() bool {
+		t.Helper()
+		tokens, err := tctx.Store.GetTokens(bctx, "email", "invited@example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(tokens) != 1 {
+			t.Fatalf("expected 1 invite got %d", len(tokens))
+		}
+		return !tokens[0].SentAt.IsZero()
+	}
+	bind := AddBinding(&tctx)
+
+	// the notification can not be queued for an mxid without a server
+	if _, err := bind(bctx, "email", "invited@example.com", "@invited"); err != errInvalidMatrixID {
+		t.Fatalf("expected %v got %v", errInvalidMatrixID, err)
+	}
+	if sent() {
+		t.Error("expected the invite to be kept unsent")
+	}
+
+	if _, err := bind(bctx, "email", "invited@example.com", "@invited:example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if !sent() {
+		t.Error("expected the invite to be sent")
+	}
+	due, err := tctx.Store.GetDueOnbindNotifications(bctx, models.Time()+1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(due) != 1 || due[0].Destination != "example.com" || !strings.Contains(due[0].Payload, "invite-token") {
+		t.Errorf("expected the invite to be queued for delivery got %+v", due)
+	}
+}
@@ -0,0 +1,279 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/textproto"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gernest/sydent-go/config"
+	"github.com/gernest/sydent-go/core"
+	"github.com/gernest/sydent-go/models"
+	"github.com/gernest/sydent-go/store"
+	"go.uber.org/zap"
+)
+
+const (
+	outboxMinDelay = 30 * time.Second
+	outboxMaxDelay = 6 * time.Hour
+
+	// outboxPoll is how long the workers sleep when nothing is due. They are
+	// woken up by core.Ctx.Outbox as soon as an email is queued.
+	outboxPoll = 5 * time.Minute
+
+	// outboxLease is how long a claimed email is left alone by other workers,
+	// sending an email must take less than this.
+	outboxLease = 10 * time.Minute
+
+	// outboxSendTimeout is how long a single delivery attempt may take.
+	outboxSendTimeout = 5 * time.Minute
+)
+
+var _ config.Client = (*Outbox)(nil)
+
+// Outbox is a config.Client which queues emails in the database instead of
+// sending them, OutboxWorker sends them in the background with client.
+type Outbox struct {
+	db     store.Store
+	signal core.Signal
+	client config.Client
+}
+
+// NewOutbox returns an Outbox which queues emails in db and wakes up the
+// workers with signal.
+func NewOutbox(db store.Store, signal core.Signal, client config.Client) *Outbox {
+	return &Outbox{db: db, signal: signal, client: client}
+}
+
+// Send queues msg for delivery to the recipients to.
+func (o *Outbox) Send(ctx context.Context, from string, to []string, msg []byte) error {
+	if len(to) == 0 {
+		return errors.New("outbox: no recipients")
+	}
+	_, err := o.db.AddEmail(ctx, from, to, emailDomain(to[0]), string(msg))
+	if err != nil {
+		return err
+	}
+	o.signal.Notify()
+	return nil
+}
+
+// Host returns the host of the client sending the emails.
+func (o *Outbox) Host() string {
+	return o.client.Host()
+}
+
+// Valid validates the client sending the emails.
+func (o *Outbox) Valid() *config.Validation {
+	return o.client.Valid()
+}
+
+// emailDomain returns the lower cased domain of address.
+func emailDomain(address string) string {
+	return strings.ToLower(address[strings.LastIndexByte(address, '@')+1:])
+}
+
+// OutboxWorker returns a function which sends queued emails with client until
+// ctx is cancelled.
+//
+// At most config.Outbox.WorkerCount emails are sent at the same time, and at
+// most config.Outbox.PerDomain to the same recipient domain.
+func OutboxWorker(coreContext *core.Ctx, client config.Client) func(context.Context) {
+	lg := coreContext.Log
+	return func(ctx context.Context) {
+		d := newEmailDispatcher(coreContext, client)
+		defer d.wg.Wait()
+		timer := time.NewTimer(0)
+		defer timer.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-coreContext.Outbox:
+			case <-timer.C:
+			case domain := <-d.done:
+				d.release(domain)
+			}
+			wait, err := d.dispatch(ctx)
+			if err != nil {
+				lg.Error("failed to send queued emails", zap.Error(err))
+				wait = outboxMinDelay
+			}
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(wait)
+		}
+	}
+}
+
+// emailDispatcher hands due emails to sending goroutines. Only the worker loop
+// calls dispatch and release, the sending goroutines report back through done.
+type emailDispatcher struct {
+	coreContext *core.Ctx
+	client      config.Client
+	workers     int
+	perDomain   int
+	maxAttempts int64
+
+	inflight int
+	domains  map[string]int
+	done     chan string
+	wg       sync.WaitGroup
+}
+
+func newEmailDispatcher(coreContext *core.Ctx, client config.Client) *emailDispatcher {
+	c := coreContext.Config.Email.Outbox
+	return &emailDispatcher{
+		coreContext: coreContext,
+		client:      client,
+		workers:     c.WorkerCount(),
+		perDomain:   c.PerDomain(),
+		maxAttempts: c.Attempts(),
+		domains:     make(map[string]int),
+		// there are never more than workers sends in flight, so sending
+		// goroutines never block.
+		done: make(chan string, c.WorkerCount()),
+	}
+}
+
+// dispatch starts sending due emails while there are free workers and returns
+// how long to wait until the next email is due.
+func (d *emailDispatcher) dispatch(ctx context.Context) (time.Duration, error) {
+	db := d.coreContext.Store
+	limit := d.workers * 4
+	var blocked bool
+	for d.inflight < d.workers {
+		due, err := db.GetDueEmails(ctx, models.Time(), limit)
+		if err != nil {
+			return 0, err
+		}
+		var started int
+		for _, e := range due {
+			if d.inflight >= d.workers {
+				break
+			}
+			if d.domains[e.Domain] >= d.perDomain {
+				blocked = true
+				continue
+			}
+			until := time.Now().Add(outboxLease)
+			err = db.ClaimEmail(ctx, e.ID, e.NextAttempt, models.MS(&until))
+			if err != nil {
+				if err == sql.ErrNoRows {
+					// another instance is sending it
+					continue
+				}
+				return 0, err
+			}
+			d.start(e)
+			started++
+		}
+		if started == 0 || len(due) < limit {
+			break
+		}
+	}
+	if blocked || d.inflight >= d.workers {
+		// woken up through done when a send finishes
+		return outboxPoll, nil
+	}
+	ts, err := db.NextEmailTS(ctx)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return outboxPoll, nil
+		}
+		return 0, err
+	}
+	wait := time.Until(models.FromMS(ts))
+	if wait < 0 {
+		wait = 0
+	}
+	if wait > outboxPoll {
+		wait = outboxPoll
+	}
+	return wait, nil
+}
+
+func (d *emailDispatcher) start(e models.OutboxEmail) {
+	d.inflight++
+	d.domains[e.Domain]++
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		d.send(e)
+		d.done <- e.Domain
+	}()
+}
+
+func (d *emailDispatcher) release(domain string) {
+	d.inflight--
+	if d.domains[domain]--; d.domains[domain] <= 0 {
+		delete(d.domains, domain)
+	}
+}
+
+// send delivers e and records the outcome. The outcome is recorded even when
+// the worker is stopping, so an email which was sent is not sent again.
+func (d *emailDispatcher) send(e models.OutboxEmail) {
+	db := d.coreContext.Store
+	lg := d.coreContext.Log
+	sendCtx, cancel := context.WithTimeout(context.Background(), outboxSendTimeout)
+	err := d.client.Send(sendCtx, e.From, e.To, []byte(e.Message))
+	cancel()
+	ctx := context.Background()
+	if err == nil {
+		if err := db.DeleteEmail(ctx, e.ID); err != nil && err != sql.ErrNoRows {
+			lg.Error("failed to remove sent email", zap.Int64("id", e.ID), zap.Error(err))
+		}
+		return
+	}
+	attempts := e.Attempts + 1
+	if permanentEmailError(err) || attempts >= d.maxAttempts {
+		lg.Error("giving up sending email",
+			zap.Int64("id", e.ID),
+			zap.String("domain", e.Domain),
+			zap.Int64("attempts", attempts),
+			zap.Error(err),
+		)
+		if err := db.DeadEmail(ctx, e.ID, err.Error()); err != nil {
+			lg.Error("failed to move email to dead letters", zap.Int64("id", e.ID), zap.Error(err))
+		}
+		return
+	}
+	lg.Info("failed to send email",
+		zap.Int64("id", e.ID),
+		zap.String("domain", e.Domain),
+		zap.Int64("attempts", attempts),
+		zap.Error(err),
+	)
+	next := time.Now().Add(outboxDelay(attempts))
+	if err := db.RetryEmail(ctx, e.ID, models.MS(&next), err.Error()); err != nil {
+		lg.Error("failed to schedule email", zap.Int64("id", e.ID), zap.Error(err))
+	}
+}
+
+// permanentEmailError returns true when the mail server rejected the email and
+// sending it again won't help.
+func permanentEmailError(err error) bool {
+	e, ok := err.(*textproto.Error)
+	return ok && e.Code >= 500
+}
+
+// outboxDelay returns the delay before the next delivery attempt after
+// attempts failures.
+func outboxDelay(attempts int64) time.Duration {
+	d := outboxMinDelay
+	for i := int64(1); i < attempts && d < outboxMaxDelay; i++ {
+		d *= 2
+	}
+	if d > outboxMaxDelay {
+		d = outboxMaxDelay
+	}
+	return d
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/textproto"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gernest/sydent-go/config"
+	"github.com/gernest/sydent-go/core"
+	"github.com/gernest/sydent-go/models"
+	"github.com/gernest/sydent-go/store"
+)
+
+func TestOutbox(t *testing.T) {
+	ctx := context.Background()
+	db := store.NewMemory()
+	tctx := &core.Ctx{
+		Config: &config.Matrix{
+			Email: config.Email{
+				Outbox: config.Outbox{
+					Workers:           "2",
+					DomainConcurrency: "1",
+					MaxAttempts:       "2",
+				},
+			},
+		},
+		Store:  db,
+		Log:    &TestLogger{},
+		Outbox: core.NewSignal(),
+	}
+	var mu sync.Mutex
+	var sent []string
+	gate := make(chan struct{})
+	client := TestEmailClient{
+		send: func(from string, to []string, msg []byte) error {
+			<-gate
+			switch {
+			case strings.HasPrefix(to[0], "dead@"):
+				return &textproto.Error{Code: 550, Msg: "no such user"}
+			case strings.HasPrefix(to[0], "retry@"):
+				return errors.New("connection refused")
+			}
+			mu.Lock()
+			sent = append(sent, to[0])
+			mu.Unlock()
+			return nil
+		},
+	}
+	outbox := NewOutbox(db, tctx.Outbox, client)
+	d := newEmailDispatcher(tctx, client)
+
+	// finish lets n sends complete and waits for them like the worker loop.
+	finish := func(n int) {
+		t.Helper()
+		for i := 0; i < n; i++ {
+			gate <- struct{}{}
+		}
+		for i := 0; i < n; i++ {
+			select {
+			case domain := <-d.done:
+				d.release(domain)
+			case <-time.After(time.Second):
+				t.Fatal("timed out waiting for sends to finish")
+			}
+		}
+	}
+
+	for _, to := range []string{"a1@a.example", "a2@A.example", "b@b.example"} {
+		if err := outbox.Send(ctx, "sydent@example.org", []string{to}, []byte("hello")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	select {
+	case <-tctx.Outbox:
+	default:
+		t.Error("expected queuing an email to notify the workers")
+	}
+	wait, err := d.dispatch(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.inflight != 2 || d.domains["a.example"] != 1 || d.domains["b.example"] != 1 {
+		t.Errorf("expected one send per domain got %d in flight %v", d.inflight, d.domains)
+	}
+	if wait != outboxPoll {
+		t.Errorf("expected to wait for a free worker got %v", wait)
+	}
+	finish(2)
+	if _, err := d.dispatch(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if d.inflight != 1 {
+		t.Errorf("expected the second email to a.example to be sent got %d in flight", d.inflight)
+	}
+	finish(1)
+	sort.Strings(sent)
+	if strings.Join(sent, ",") != "a1@a.example,a2@A.example,b@b.example" {
+		t.Errorf("unexpected sent emails %v", sent)
+	}
+	pending, err := db.ListEmails(ctx, models.EmailPending, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("expected sent emails to be removed got %+v", pending)
+	}
+
+	for _, to := range []string{"dead@example.com", "retry@example.net"} {
+		if err := outbox.Send(ctx, "sydent@example.org", []string{to}, []byte("hello")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := d.dispatch(ctx); err != nil {
+		t.Fatal(err)
+	}
+	finish(2)
+	dead, err := db.ListEmails(ctx, models.EmailDead, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dead) != 1 || dead[0].To[0] != "dead@example.com" || dead[0].Attempts != 1 {
+		t.Fatalf("expected permanent failures to be dead letters got %+v", dead)
+	}
+	pending, err = db.ListEmails(ctx, models.EmailPending, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 || pending[0].Attempts != 1 || pending[0].LastError != "connection refused" {
+		t.Fatalf("expected temporary failures to be retried got %+v", pending)
+	}
+	if next := models.FromMS(pending[0].NextAttempt); time.Until(next) < outboxMinDelay-time.Second {
+		t.Errorf("expected retry to be delayed got %v", next)
+	}
+	wait, err = d.dispatch(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.inflight != 0 || wait <= 0 {
+		t.Errorf("expected nothing to be due got %d in flight and wait %v", d.inflight, wait)
+	}
+
+	// make the retry due, the next failure reaches the attempts limit.
+	err = db.RetryEmail(ctx, pending[0].ID, models.Time(), "connection refused")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.dispatch(ctx); err != nil {
+		t.Fatal(err)
+	}
+	finish(1)
+	dead, err = db.ListEmails(ctx, models.EmailDead, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dead) != 2 || dead[1].Attempts != 3 {
+		t.Errorf("expected the email to be dead after too many attempts got %+v", dead)
+	}
+}
+
+func TestOutboxDelay(t *testing.T) {
+	for attempts, expect := range map[int64]time.Duration{
+		1:  outboxMinDelay,
+		2:  2 * outboxMinDelay,
+		3:  4 * outboxMinDelay,
+		50: outboxMaxDelay,
+	} {
+		if d := outboxDelay(attempts); d != expect {
+			t.Errorf("%d: expected %v got %v", attempts, expect, d)
+		}
+	}
+}
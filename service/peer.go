@@ -7,6 +7,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
 
 	"github.com/gernest/sydent-go/config"
@@ -165,7 +167,7 @@ func in(key string, v ...string) bool {
 // PushToRemotePeer pushes signed associations to a remote identity service peer.
 //
 // replica is the client used for replication.
-func PushToRemotePeer(cfg *config.Matrix, peer *models.Peer, replica config.HTTPClient, as []Association) error {
+func PushToRemotePeer(ctx context.Context, cfg *config.Matrix, peer *models.Peer, replica config.HTTPClient, as []Association) error {
 	m := Payload{SignedAssociations: as}
 	b, err := json.Marshal(m)
 	if err != nil {
@@ -178,9 +180,14 @@ func PushToRemotePeer(cfg *config.Matrix, peer *models.Peer, replica config.HTTP
 	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", config.AgentName)
-	res, err := replica.Do(req)
+	res, err := replica.Do(req.WithContext(ctx))
 	if err != nil {
 		return err
 	}
-	return res.Body.Close()
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(io.LimitReader(res.Body, 1<<10))
+		return fmt.Errorf("replication: %s responded with %s: %s", peer.Name, res.Status, string(body))
+	}
+	return nil
 }
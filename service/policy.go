@@ -0,0 +1,30 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/gernest/sydent-go/core"
+	"github.com/gernest/sydent-go/models"
+	"github.com/labstack/echo"
+)
+
+// addressAllowed returns true if the address policies allow the canonical
+// form of address of medium to be used with endpoint.
+//
+// Errors reloading the list files of the policies are logged, and the address
+// is checked against the entries the files had before.
+func addressAllowed(coreContext *core.Ctx, ctx echo.Context, endpoint, medium, address string) bool {
+	address = coreContext.Canonical.Address(medium, address)
+	name, err := coreContext.Policy.Check(endpoint, medium, address)
+	if err != nil {
+		RequestError(coreContext.Log, ctx.Request(), err)
+	}
+	return name == ""
+}
+
+func addressDenied(ctx echo.Context) error {
+	return ctx.JSON(http.StatusForbidden, models.NewError(
+		models.ErrThreepidDenied,
+		"Third party identifier is not allowed",
+	))
+}
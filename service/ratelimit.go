@@ -0,0 +1,129 @@
+package service
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gernest/sydent-go/config"
+	"github.com/gernest/sydent-go/core"
+	"github.com/gernest/sydent-go/models"
+	"github.com/gernest/sydent-go/store"
+	"github.com/labstack/echo"
+	"go.uber.org/zap"
+)
+
+// RateLimit returns a middleware limiting requests per client ip, per
+// requested address and per domain of the requested email address as
+// configured in config.RateLimit. Requests over a limit get M_LIMIT_EXCEEDED.
+// The client ip is read through the trusted proxies of coreContext. A request
+// takes a token from every bucket or from none, see store.TakeRateLimitTokens.
+//
+// The address is read from the email, address or phone_number parameters.
+// Requests are let through when the buckets can not be read.
+func RateLimit(coreContext *core.Ctx, m Metric) echo.MiddlewareFunc {
+	c := coreContext.Config.RateLimit
+	count := m.CountError("rate_limit")
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if !c.Enabled() {
+			return next
+		}
+		return func(ctx echo.Context) error {
+			req := ctx.Request()
+			ip := coreContext.Proxies.ClientIP(req)
+			buckets := rateLimitBuckets(c, coreContext.Canonical, ip, peekParams(req))
+			retry, err := rateLimitStore(coreContext).TakeRateLimitTokens(req.Context(), buckets, models.Time())
+			if err != nil {
+				RequestError(coreContext.Log, req, err)
+				return next(ctx)
+			}
+			if retry == 0 {
+				return next(ctx)
+			}
+			count.Inc()
+			coreContext.Log.Info("rate limit exceeded",
+				zap.String("path", req.URL.Path),
+				zap.String("ip", ip),
+			)
+			ctx.Response().Header().Set("Retry-After", strconv.FormatInt((retry+999)/1000, 10))
+			return ctx.JSON(http.StatusTooManyRequests, models.LimitExceeded{
+				Error:        models.NewError(models.ErrLimitExceeded, "Too many requests"),
+				RetryAfterMS: retry,
+			})
+		}
+	}
+}
+
+// rateLimitStore returns the store keeping the buckets of the rate limits.
+func rateLimitStore(coreContext *core.Ctx) store.Store {
+	if coreContext.Limits != nil {
+		return coreContext.Limits
+	}
+	return coreContext.Store
+}
+
+// rateLimitBucket returns the bucket key of limit.
+func rateLimitBucket(key string, limit config.Limit) store.RateLimitBucket {
+	return store.RateLimitBucket{
+		Key:      key,
+		Burst:    float64(limit.Size()),
+		Interval: int64(limit.Every() / time.Millisecond),
+	}
+}
+
+// rateLimitBuckets returns the buckets a request from ip with params takes a
+// token from. Addresses are counted in their canonical form, so variants of an
+// address share a bucket.
+func rateLimitBuckets(c config.RateLimit, canonical *models.Canonicalizer, ip string, params map[string]string) []store.RateLimitBucket {
+	var o []store.RateLimitBucket
+	if c.IP.Enabled() {
+		o = append(o, rateLimitBucket("ip:"+ip, c.IP))
+	}
+	var medium, address string
+	switch {
+	case params["email"] != "":
+		medium, address = "email", params["email"]
+	case params["address"] != "":
+		medium, address = params["medium"], params["address"]
+	case params["phone_number"] != "":
+		medium, address = "msisdn", params["country"]+":"+params["phone_number"]
+		if p, err := models.ParsePhoneNumber(params["country"], params["phone_number"]); err == nil {
+			address = p.MSISDN()
+		}
+	}
+	if address == "" {
+		return o
+	}
+	address = canonical.Address(medium, address)
+	if c.Address.Enabled() {
+		o = append(o, rateLimitBucket("address:"+medium+":"+address, c.Address))
+	}
+	if i := strings.LastIndexByte(address, '@'); i >= 0 && medium == "email" && c.Domain.Enabled() {
+		o = append(o, rateLimitBucket("domain:"+address[i+1:], c.Domain))
+	}
+	return o
+}
+
+// peekParams returns the parameters of req without consuming its body.
+func peekParams(req *http.Request) map[string]string {
+	var body []byte
+	if req.Body != nil {
+		b, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return nil
+		}
+		body = b
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	params, err := models.EnsureParams(req)
+	if req.Body != nil {
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	if err != nil {
+		return nil
+	}
+	return params
+}
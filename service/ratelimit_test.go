@@ -0,0 +1,110 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gernest/sydent-go/config"
+	"github.com/gernest/sydent-go/models"
+	"github.com/gernest/sydent-go/store"
+	"github.com/labstack/echo"
+)
+
+func TestRateLimit(t *testing.T) {
+	cfg := *mainContext.Config
+	cfg.RateLimit = config.RateLimit{
+		IP:      config.Limit{Burst: "4", Period: "1h"},
+		Address: config.Limit{Burst: "2", Period: "1h"},
+		Domain:  config.Limit{Burst: "3", Period: "1h"},
+	}
+	tctx := *mainContext
+	tctx.Config = &cfg
+	tctx.Limits = store.NewMemory()
+	tctx.Proxies, _ = config.Server{TrustedProxies: []string{"192.168.0.0/16"}}.Proxies()
+
+	var got []string
+	e := echo.New()
+	e.POST("/requestToken", func(ctx echo.Context) error {
+		m, err := models.EnsureParams(ctx.Request(), "email")
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, err)
+		}
+		got = append(got, m["email"])
+		return ctx.JSON(http.StatusOK, map[string]interface{}{})
+	}, RateLimit(&tctx, &TestMetric{}))
+
+	call := func(ip, email string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/requestToken", strings.NewReader(`{"email":"`+email+`"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if i := strings.IndexByte(ip, ','); i >= 0 {
+			// a request forwarded by a proxy
+			req.Header.Set(echo.HeaderXForwardedFor, ip[i+1:])
+			ip = ip[:i]
+		} else {
+			// clients can not pick their ip with headers
+			req.Header.Set(echo.HeaderXForwardedFor, "10.0.0.99")
+			req.Header.Set(echo.HeaderXRealIP, "10.0.0.99")
+		}
+		req.RemoteAddr = ip + ":1234"
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	steps := []struct {
+		ip, email string
+		code      int
+	}{
+		{"10.0.0.1", "alice@example.com", http.StatusOK},
+		{"10.0.0.2", "Alice@example.com", http.StatusOK},
+		// address limit
+		{"10.0.0.3", "alice@example.com", http.StatusTooManyRequests},
+		// the denied request took no domain token
+		{"10.0.0.4", "bob@example.com", http.StatusOK},
+		// domain limit
+		{"10.0.0.4", "carol@example.com", http.StatusTooManyRequests},
+		{"10.0.0.5", "bob@example.org", http.StatusOK},
+		{"10.0.0.5", "carol@example.net", http.StatusOK},
+		{"10.0.0.5", "dave@example.net", http.StatusOK},
+		{"10.0.0.5", "erin@example.net", http.StatusOK},
+		// ip limit, forwarded by a trusted proxy too
+		{"10.0.0.5", "frank@example.de", http.StatusTooManyRequests},
+		{"192.168.0.1,10.0.0.5", "frank@example.de", http.StatusTooManyRequests},
+		{"192.168.0.1,10.0.0.6, 192.168.0.2", "frank@example.de", http.StatusOK},
+		// the denied requests took no address token
+		{"10.0.0.7", "frank@example.de", http.StatusOK},
+		{"10.0.0.8", "frank@example.de", http.StatusTooManyRequests},
+	}
+	for i, s := range steps {
+		rec := call(s.ip, s.email)
+		if rec.Code != s.code {
+			t.Fatalf("%d %s %s: expected %d got %d %s", i, s.ip, s.email, s.code, rec.Code, rec.Body)
+		}
+		if s.code != http.StatusTooManyRequests {
+			continue
+		}
+		var res struct {
+			Code       string `json:"errcode"`
+			RetryAfter int64  `json:"retry_after_ms"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+			t.Fatal(err)
+		}
+		if res.Code != models.ErrLimitExceeded || res.RetryAfter <= 0 || res.RetryAfter > 3600000 {
+			t.Errorf("unexpected response %s", rec.Body)
+		}
+		if rec.Header().Get("Retry-After") == "" {
+			t.Error("expected Retry-After header")
+		}
+	}
+	expect := []string{
+		"alice@example.com", "Alice@example.com", "bob@example.com",
+		"bob@example.org", "carol@example.net", "dave@example.net",
+		"erin@example.net", "frank@example.de", "frank@example.de",
+	}
+	if strings.Join(got, ",") != strings.Join(expect, ",") {
+		t.Errorf("expected requests %v got %v", expect, got)
+	}
+}
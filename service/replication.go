@@ -17,9 +17,15 @@ func Replicate(coreContext *core.Ctx, m Metric) echo.HandlerFunc {
 	db := coreContext.Store
 	return func(ctx echo.Context) error {
 		req := ctx.Request()
-		tls := req.TLS
-		name := tls.PeerCertificates[0].Subject.CommonName
-		requestContext := req.Context()
+		name, ok := peerName(req)
+		if !ok {
+			count.Inc()
+			return ctx.JSON(http.StatusForbidden, models.NewError(
+				models.ErrForbidden,
+				"A verified client certificate is required",
+			))
+		}
+		requestContext := setActor(coreContext, ctx, models.ActorPeer, name)
 		activePeer, err := db.GetPeerByName(requestContext, name)
 		if err != nil {
 			count.Inc()
@@ -88,45 +94,26 @@ func Replicate(coreContext *core.Ctx, m Metric) echo.HandlerFunc {
 			RequestError(coreContext.Log, req, m)
 			return ctx.JSON(http.StatusBadRequest, m)
 		}
-		tx, err := db.DB().BeginTx(requestContext, nil)
-		if err != nil {
-			count.Inc()
-			RequestError(coreContext.Log, req, err)
-			return ctx.JSON(http.StatusInternalServerError, models.NewError(
-				models.ErrUnknown, "Ooops something went wrong please try again",
-			))
-		}
-		idStore := store.New(tx, db.Metric())
-		for _, a := range o.SignedAssociations {
-			as, err := AssociationFromMap(a.SignedAssociation)
-			if err != nil {
-				count.Inc()
-				RequestError(coreContext.Log, req, err)
-				tx.Rollback()
-				return internalError(ctx)
-			}
-			if as.MatrixID != "" {
-				b, _ := json.Marshal(as)
-				err = idStore.GlobalAddAssociation(requestContext, as,
-					activePeer.Name, a.OriginID, string(b),
-				)
+		err = db.Tx(requestContext, func(tx store.Store) error {
+			for _, a := range o.SignedAssociations {
+				as, err := AssociationFromMap(a.SignedAssociation)
 				if err != nil {
-					count.Inc()
-					RequestError(coreContext.Log, req, err)
-					tx.Rollback()
-					return internalError(ctx)
+					return err
+				}
+				if as.MatrixID != "" {
+					b, _ := json.Marshal(a.SignedAssociation)
+					err = tx.GlobalAddAssociation(requestContext, as,
+						activePeer.Name, a.OriginID, string(b),
+					)
+				} else {
+					err = tx.GlobalRemoveAssociation(requestContext, as.Medium, as.Address)
 				}
-			} else {
-				err = idStore.GlobalRemoveAssociation(requestContext, as.Medium, as.Address)
 				if err != nil {
-					count.Inc()
-					RequestError(coreContext.Log, req, err)
-					tx.Rollback()
-					return internalError(ctx)
+					return err
 				}
 			}
-		}
-		err = tx.Commit()
+			return nil
+		})
 		if err != nil {
 			count.Inc()
 			RequestError(coreContext.Log, req, err)
@@ -144,3 +131,12 @@ func internalError(ctx echo.Context) error {
 		"Having issue processing the request",
 	))
 }
+
+// peerName returns the common name of the verified client certificate of req.
+func peerName(req *http.Request) (string, bool) {
+	if req.TLS == nil || len(req.TLS.VerifiedChains) == 0 || len(req.TLS.VerifiedChains[0]) == 0 {
+		return "", false
+	}
+	name := req.TLS.VerifiedChains[0][0].Subject.CommonName
+	return name, name != ""
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/cenkalti/backoff"
+	"github.com/gernest/sydent-go/config"
+	"github.com/gernest/sydent-go/core"
+	"github.com/gernest/sydent-go/models"
+	"go.uber.org/zap"
+)
+
+const (
+	// replicationBatch is the maximum number of associations sent to a peer in
+	// a single push.
+	replicationBatch = 100
+
+	// replicationPoll is how often peers are checked for pending associations
+	// when nothing wakes up the pusher.
+	replicationPoll = time.Minute
+)
+
+// ReplicationPusher returns a function which pushes local associations to all
+// active peers until ctx is cancelled. Pushes happen every replicationPoll and
+// immediately after core.Ctx.Replication is notified.
+//
+// Each peer has its own exponential backoff, a peer that is down doesn't delay
+// replication to the others.
+func ReplicationPusher(coreContext *core.Ctx, client config.HTTPClient) func(context.Context) {
+	lg := coreContext.Log
+	db := coreContext.Store
+	return func(ctx context.Context) {
+		retries := make(map[string]*peerRetry)
+		timer := time.NewTimer(0)
+		defer timer.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-coreContext.Replication:
+			case <-timer.C:
+			}
+			wait := replicationPoll
+			peers, err := db.GetAllPeers(ctx)
+			if err != nil {
+				lg.Error("failed to load peers", zap.Error(err))
+				peers = nil
+			}
+			now := time.Now()
+			for i := range peers {
+				p := &peers[i]
+				r, ok := retries[p.Name]
+				if !ok {
+					r = newPeerRetry()
+					retries[p.Name] = r
+				}
+				if now.Before(r.next) {
+					if d := r.next.Sub(now); d < wait {
+						wait = d
+					}
+					continue
+				}
+				err := PushToPeer(ctx, coreContext, client, p)
+				if err != nil {
+					d := r.b.NextBackOff()
+					r.next = now.Add(d)
+					if d < wait {
+						wait = d
+					}
+					lg.Error("failed to push associations to peer",
+						zap.String("peer", p.Name),
+						zap.Duration("retry_in", d),
+						zap.Error(err),
+					)
+					continue
+				}
+				r.b.Reset()
+				r.next = time.Time{}
+			}
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(wait)
+		}
+	}
+}
+
+type peerRetry struct {
+	b    *backoff.ExponentialBackOff
+	next time.Time
+}
+
+func newPeerRetry() *peerRetry {
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = 5 * time.Second
+	b.MaxInterval = 30 * time.Minute
+	// never give up on a peer.
+	b.MaxElapsedTime = 0
+	b.Reset()
+	return &peerRetry{b: b}
+}
+
+// PushToPeer sends all local associations which peer has not seen yet in
+// batches of replicationBatch. The last sent version of the peer is advanced
+// after every successful push.
+func PushToPeer(ctx context.Context, coreContext *core.Ctx, client config.HTTPClient, peer *models.Peer) error {
+	db := coreContext.Store
+	sign := Signer(coreContext)
+	lastID := int64(-1)
+	if peer.LastSentVersion.Valid {
+		lastID = peer.LastSentVersion.Int64
+	}
+	for {
+		as, err := db.GetAssociationsAfterID(ctx, lastID, replicationBatch)
+		if err != nil {
+			return err
+		}
+		if len(as) == 0 {
+			return nil
+		}
+		out, err := signAssociations(sign, as)
+		if err != nil {
+			return err
+		}
+		err = PushToRemotePeer(ctx, coreContext.Config, peer, client, out)
+		if err != nil {
+			return err
+		}
+		lastID = as[len(as)-1].ID
+		err = db.SetLastSentVersionAndPokeSucceeded(ctx, peer.Name, lastID, models.Time())
+		if err != nil {
+			return err
+		}
+		peer.LastSentVersion.Int64 = lastID
+		peer.LastSentVersion.Valid = true
+		if len(as) < replicationBatch {
+			return nil
+		}
+	}
+}
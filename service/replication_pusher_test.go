@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gernest/sydent-go/config"
+	"github.com/gernest/sydent-go/models"
+)
+
+func TestPushToPeer(t *testing.T) {
+	bctx := context.Background()
+	var got []Payload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var o Payload
+		json.NewDecoder(r.Body).Decode(&o)
+		got = append(got, o)
+	}))
+	defer srv.Close()
+
+	_, err := sqlConn.Exec(`INSERT INTO peers (name, port, active) VALUES ('push.example.com', 1001, 1)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = sqlConn.Exec(`INSERT INTO peer_pubkeys (peername, alg, key) VALUES ('push.example.com', 'ed25519', 'key')`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := *mainContext.Config
+	cfg.Peers = []config.Peer{
+		{Name: "push.example.com", BaseReplicationURL: srv.URL},
+	}
+	tctx := *mainContext
+	tctx.Config = &cfg
+
+	now := models.Time()
+	err = tctx.Store.LocalAddOrUpdateAssociation(bctx, &models.Association{
+		Medium:    "email",
+		Address:   "push@example.com",
+		MatrixID:  "@push:example.com",
+		Timestamp: now,
+		NotBefore: now,
+		NotAfter:  now + 100000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	peers, err := tctx.Store.GetAllPeers(bctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var peer *models.Peer
+	for i := range peers {
+		if peers[i].Name == "push.example.com" {
+			peer = &peers[i]
+		}
+	}
+	if peer == nil {
+		t.Fatalf("expected peer push.example.com got %#v", peers)
+	}
+	err = PushToPeer(bctx, &tctx, http.DefaultClient, peer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || len(got[0].SignedAssociations) == 0 {
+		t.Fatalf("expected a push got %#v", got)
+	}
+	as := got[0].SignedAssociations
+	last := as[len(as)-1]
+	if last.SignedAssociation["address"] != "push@example.com" {
+		t.Errorf("expected push@example.com got %v", last.SignedAssociation)
+	}
+	if !peer.LastSentVersion.Valid || peer.LastSentVersion.Int64 != last.OriginID {
+		t.Errorf("expected last sent version %d got %v", last.OriginID, peer.LastSentVersion)
+	}
+
+	// nothing new to send.
+	err = PushToPeer(bctx, &tctx, http.DefaultClient, peer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 {
+		t.Errorf("expected no push got %d", len(got))
+	}
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gernest/sydent-go/config"
+	"github.com/gernest/sydent-go/models"
+)
+
+func TestReplicateClientCertificate(t *testing.T) {
+	dir := "fixture/certs/replication/"
+	r := config.Replication{
+		CertFile: dir + "localhost.pem",
+		KeyFile:  dir + "localhost-key.pem",
+		CAFile:   dir + "ca.pem",
+	}
+	serverTLS, err := r.ServerTLS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewUnstartedServer(ReplicationService(mainContext, &TestMetric{}))
+	srv.TLS = serverTLS
+	srv.StartTLS()
+	defer srv.Close()
+
+	push := func(clientTLS *tls.Config) (int, models.Error) {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: clientTLS}}
+		res, err := client.Post(srv.URL+IdentityReplicationPush, "application/json", strings.NewReader(`{"sgAssocs":[]}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+		var e models.Error
+		json.NewDecoder(res.Body).Decode(&e)
+		return res.StatusCode, e
+	}
+
+	peer := config.Replication{
+		CertFile: dir + "peer.example.com.pem",
+		KeyFile:  dir + "peer.example.com-key.pem",
+		CAFile:   dir + "ca.pem",
+	}
+	clientTLS, err := peer.ClientTLS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	anonymous := clientTLS.Clone()
+	anonymous.Certificates = nil
+	code, e := push(anonymous)
+	if code != http.StatusForbidden || e.Code != models.ErrForbidden {
+		t.Errorf("expected 403 %s got %d %v", models.ErrForbidden, code, e)
+	}
+	code, e = push(clientTLS)
+	if code != http.StatusForbidden || e.Code != models.ErrUnknownPeer {
+		t.Errorf("expected 403 %s got %d %v", models.ErrUnknownPeer, code, e)
+	}
+}
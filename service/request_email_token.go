@@ -6,6 +6,7 @@ import (
 	"net"
 	"net/url"
 
+	"github.com/gernest/sydent-go/config"
 	"github.com/gernest/sydent-go/core"
 	"github.com/gernest/sydent-go/models"
 )
@@ -16,6 +17,10 @@ type TokenRequest struct {
 	SendAttempt  int64
 	NextLink     string
 	IP           net.IP
+
+	// Locales are the locales preferred by the user, the verification email is
+	// sent in the first one with a localized template.
+	Locales []string
 }
 
 func RequestEmailToken(ctx context.Context, coreContext *core.Ctx, req *TokenRequest) (int64, error) {
@@ -34,8 +39,13 @@ func RequestEmailToken(ctx context.Context, coreContext *core.Ctx, req *TokenReq
 	if session.SendAttemptNumber >= req.SendAttempt {
 		return session.ID, nil
 	}
+	name := coreContext.Config.Email.Verification.Template
+	if name == "" {
+		name = verifyTpl
+	}
+	name, locale := coreContext.Config.LocalizedTemplate(name, req.Locales...)
 	clientHTTPBase := coreContext.Config.Server.ClientHTTPBase
-	link, err := makeValidateLink(clientHTTPBase, session, req.ClientSecret, req.NextLink)
+	link, err := makeValidateLink(clientHTTPBase, session, req.ClientSecret, req.NextLink, locale)
 	if err != nil {
 		return 0, err
 	}
@@ -44,7 +54,7 @@ func RequestEmailToken(ctx context.Context, coreContext *core.Ctx, req *TokenReq
 		"link":      link,
 		"token":     session.Token,
 	}
-	err = coreContext.Email.SendMail(ctx, verifyTpl,
+	err = coreContext.Email.SendMail(ctx, name,
 		coreContext.Config.Email.Verification.From,
 		[]string{req.Email}, data,
 	)
@@ -58,13 +68,18 @@ func RequestEmailToken(ctx context.Context, coreContext *core.Ctx, req *TokenReq
 	return session.ID, nil
 }
 
-func makeValidateLink(clientHTTPBase string, session *models.ValidationSession, clientSecret, nextLink string) (string, error) {
+// makeValidateLink returns the link to submit the token of session. The locale
+// is kept in the link so the response page is in the language of the email.
+func makeValidateLink(clientHTTPBase string, session *models.ValidationSession, clientSecret, nextLink, locale string) (string, error) {
 	link := fmt.Sprintf("%s/_matrix/identity/api/v1/validate/email/submitToken", clientHTTPBase)
 	q := make(url.Values)
 	q.Set("token", session.Token)
 	q.Set("client_secret", clientSecret)
 	sid := fmt.Sprint(session.ID)
 	q.Set("sid", sid)
+	if locale != "" && locale != config.DefaultLocale {
+		q.Set(localeParam, locale)
+	}
 	if nextLink != "" {
 		u, err := url.Parse(nextLink)
 		if err != nil {
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+
+	"github.com/gernest/sydent-go/core"
+	"github.com/gernest/sydent-go/models"
+)
+
+const smsVerifyTpl = "sms_verification"
+
+type MsisdnTokenRequest struct {
+	// Msisdn is the phone number in E.164 format without the leading +.
+	Msisdn       string
+	ClientSecret string
+	SendAttempt  int64
+}
+
+// RequestMsisdnToken creates a validation session for the phone number and
+// sends the token to it over sms. Like email, the message is only sent when
+// SendAttempt is greater than the last attempt seen for the session.
+func RequestMsisdnToken(ctx context.Context, coreContext *core.Ctx, req *MsisdnTokenRequest) (int64, error) {
+	db := coreContext.Store
+	session, err := db.GetOrCreateTokenSession(ctx,
+		"msisdn", req.Msisdn, req.ClientSecret,
+	)
+	if err != nil {
+		return 0, err
+	}
+	err = db.SetMtime(ctx, session.ID, models.Time())
+	if err != nil {
+		return 0, err
+	}
+	if session.SendAttemptNumber >= req.SendAttempt {
+		return session.ID, nil
+	}
+	cfg := coreContext.Config.Msisdn.Verification
+	tpl := cfg.Template
+	if tpl == "" {
+		tpl = smsVerifyTpl
+	}
+	err = coreContext.SMS.SendSMS(ctx, tpl, cfg.Originator, req.Msisdn,
+		map[string]string{
+			"token": session.Token,
+		},
+	)
+	if err != nil {
+		return 0, err
+	}
+	err = db.SetSendAttemptNumber(ctx, session.ID, req.SendAttempt)
+	if err != nil {
+		return 0, err
+	}
+	return session.ID, nil
+}
@@ -27,6 +27,8 @@ func Service(opts *core.Ctx, m Metric) *echo.Echo {
 	matrix := e.Group("/_matrix")
 	identityService := matrix.Group("/identity/api")
 	matrix.Use(middleware.CORSWithConfig(CORS()))
+	// endpoints which make us send emails or text messages.
+	guarded := []echo.MiddlewareFunc{RateLimit(opts, m), Captcha(opts, m)}
 	identityService.GET("/v1", Version)
 	identityService.OPTIONS("/v1", options)
 	identityService.GET("/v1/pubkey/ephemeral/isvalid", EphemeralIsValid(opts))
@@ -36,25 +38,84 @@ func Service(opts *core.Ctx, m Metric) *echo.Echo {
 	identityService.GET("/v1/lookup", Lookup(opts, m))
 	identityService.POST("/v1/bulk_lookup", BulkLookup(opts, m))
 	identityService.OPTIONS("/v1/bulk_lookup", options)
-	identityService.POST("/v1/validate/email/requestToken", EmailRequestCode(opts, m))
+	identityService.POST("/v1/validate/email/requestToken", EmailRequestCode(opts, m), guarded...)
 	identityService.OPTIONS("/v1/validate/email/requestToken", options)
 	identityService.POST("/v1/validate/email/submitToken", PostEmailValidatedCode(opts, m))
 	identityService.GET("/v1/validate/email/submitToken", GetEmailValidatedCode(opts, m))
-	identityService.POST("/v1/validate/msisdn/requestToken", todo)
-	identityService.POST("/v1/validate/msisdn/submitToken", todo)
-	identityService.GET("/v1/validate/msisdn/submitToken", todo)
+	identityService.POST("/v1/validate/msisdn/requestToken", MsisdnRequestCode(opts, m), guarded...)
+	identityService.OPTIONS("/v1/validate/msisdn/requestToken", options)
+	msisdnValidated := MsisdnValidatedCode(opts, m)
+	identityService.POST("/v1/validate/msisdn/submitToken", msisdnValidated)
+	identityService.GET("/v1/validate/msisdn/submitToken", msisdnValidated)
 	identityService.GET("/v1/3pid/getValidated3pid", GetValidated3PID(opts, m))
 	identityService.OPTIONS("/v1/bind", options)
 	identityService.POST("/v1/bind", Bind(opts, m))
 	identityService.POST("/v1/unbind", Unbind(opts, clients.Fed))
-	identityService.POST("/v1/store-invite", StoreInvite(opts, m))
+	identityService.POST("/v1/store-invite", StoreInvite(opts, m), guarded...)
 	identityService.POST("/v1/sign-ed25519", SignED25519(opts, m))
 	identityService.OPTIONS("/v1/sign-ed25519", options)
-	matrix.POST("/identity/replicate/v1/push", Replicate(opts, m))
+
+	identityV2 := matrix.Group("/identity/v2")
+	auth := Authenticate(opts, m)
+	// endpoints which are only available to accounts that accepted the terms.
+	signed := []echo.MiddlewareFunc{auth, RequireTerms(opts, m)}
+	signedGuarded := append(signed[:len(signed):len(signed)], guarded...)
+	identityV2.GET("", Version)
+	identityV2.OPTIONS("", options)
+	identityV2.POST("/account/register", RegisterAccount(opts, clients.Fed, m))
+	identityV2.OPTIONS("/account/register", options)
+	identityV2.GET("/account", Account, auth)
+	identityV2.POST("/account/logout", Logout(opts, m), auth)
+	identityV2.OPTIONS("/account/logout", options)
+	identityV2.GET("/terms", GetTerms(opts))
+	identityV2.POST("/terms", AcceptTerms(opts, m), auth)
+	identityV2.OPTIONS("/terms", options)
+	identityV2.GET("/hash_details", HashDetails(opts, m), signed...)
+	identityV2.POST("/lookup", HashedLookup(opts, m), signed...)
+	identityV2.OPTIONS("/lookup", options)
+	identityV2.GET("/pubkey/ephemeral/isvalid", EphemeralIsValid(opts))
+	identityV2.GET("/pubkey/:keyId", GetPublicKey(opts))
+	identityV2.GET("/pubkey/isvalid", PublicKeyIsValid(opts))
+	identityV2.POST("/validate/email/requestToken", EmailRequestCode(opts, m), signedGuarded...)
+	identityV2.OPTIONS("/validate/email/requestToken", options)
+	identityV2.POST("/validate/email/submitToken", PostEmailValidatedCode(opts, m), signed...)
+	identityV2.GET("/validate/email/submitToken", GetEmailValidatedCode(opts, m))
+	identityV2.POST("/validate/msisdn/requestToken", MsisdnRequestCode(opts, m), signedGuarded...)
+	identityV2.OPTIONS("/validate/msisdn/requestToken", options)
+	identityV2.POST("/validate/msisdn/submitToken", msisdnValidated, signed...)
+	identityV2.GET("/validate/msisdn/submitToken", msisdnValidated)
+	identityV2.GET("/3pid/getValidated3pid", GetValidated3PID(opts, m), signed...)
+	identityV2.POST("/3pid/bind", Bind(opts, m), signed...)
+	identityV2.OPTIONS("/3pid/bind", options)
+	identityV2.POST("/3pid/unbind", Unbind(opts, clients.Fed))
+	identityV2.POST("/store-invite", StoreInvite(opts, m), signedGuarded...)
+	identityV2.POST("/sign-ed25519", SignED25519(opts, m), signed...)
+	identityV2.OPTIONS("/sign-ed25519", options)
+
+	if opts.Config.Admin.Enabled() {
+		admin := AdminAuth(opts)
+		adminV1 := e.Group("/admin/v1")
+		adminV1.GET("/peers", AdminListPeers(opts, m), admin)
+		adminV1.POST("/peers", AdminAddPeer(opts, m), admin)
+		adminV1.DELETE("/peers/:name", AdminRemovePeer(opts, m), admin)
+		adminV1.PUT("/peers/:name/keys/:alg", AdminSetPeerKey(opts, m), admin)
+		adminV1.PUT("/peers/:name/active", AdminSetPeerActive(opts, m), admin)
+		adminV1.POST("/gdpr/export", AdminExportSubject(opts, m), admin)
+		adminV1.POST("/gdpr/erase", AdminEraseSubject(opts, m), admin)
+	}
+
 	e.GET("/metrics", echo.WrapHandler(promhttp.Handler()))
 	return e
 }
 
+// ReplicationService returns the handler for the replication listener. It must
+// be served over mutual TLS, see config.Replication.
+func ReplicationService(opts *core.Ctx, m Metric) *echo.Echo {
+	e := echo.New()
+	e.POST(IdentityReplicationPush, Replicate(opts, m))
+	return e
+}
+
 func todo(ctx echo.Context) error {
 	return ctx.JSON(http.StatusNotImplemented, models.NewError(
 		models.ErrUnknown,
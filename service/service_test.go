@@ -7,6 +7,8 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
+	"sync"
 	"testing"
 
 	"github.com/gernest/sydent-go/config"
@@ -70,8 +72,10 @@ type logEntry struct {
 	Fields []zap.Field
 }
 
+// TestLogger records log entries, it is safe for concurrent use.
 type TestLogger struct {
 	with    []zap.Field
+	mu      sync.Mutex
 	entries []logEntry
 }
 
@@ -88,6 +92,8 @@ func (lg *TestLogger) Sync() error {
 }
 
 func (lg *TestLogger) add(level, msg string, fields ...zap.Field) {
+	lg.mu.Lock()
+	defer lg.mu.Unlock()
 	lg.entries = append(lg.entries, logEntry{
 		With:   lg.with,
 		Level:  level,
@@ -97,6 +103,8 @@ func (lg *TestLogger) add(level, msg string, fields ...zap.Field) {
 }
 
 func (lg *TestLogger) String() string {
+	lg.mu.Lock()
+	defer lg.mu.Unlock()
 	b, _ := json.Marshal(lg.entries)
 	return string(b)
 }
@@ -108,25 +116,50 @@ func (lg *TestLogger) With(fields ...zap.Field) logger.Logger {
 }
 
 var sqlConn *sql.DB
+var sqlDriver store.Driver
 var mainContext *core.Ctx
 
 func TestMain(m *testing.M) {
-	driverName := "postgres"
+	driverName := os.Getenv("MATRIXID_DB_DRIVER")
+	if driverName == "" {
+		driverName = config.Postgres
+	}
 	conn := os.Getenv("MATRIXID_DB_CONN")
+	if conn == "" {
+		// without a database server the tests use a temporary sqlite database.
+		dir, err := ioutil.TempDir("", "sydent-go")
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		driverName = config.SQLite
+		conn = filepath.Join(dir, "test.db")
+	}
+	os.Exit(run(m, driverName, conn))
+}
+
+func run(m *testing.M, driverName, conn string) int {
 	db, err := sql.Open(driverName, conn)
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer sqlConn.Close()
+	defer db.Close()
+	if driverName == config.SQLite {
+		db.SetMaxOpenConns(1)
+	}
 	sqlConn = db
+	sqlDriver, err = store.NewDriver(driverName)
+	if err != nil {
+		log.Fatal(err)
+	}
 	q := query.New(db)
 	fs := embed.New()
 	ctx := context.Background()
-	err = schema.IdentityDown(ctx, fs, q)
+	err = schema.IdentityDown(ctx, fs, driverName, q)
 	if err != nil {
 		log.Fatal(err)
 	}
-	err = schema.IdentityUp(ctx, fs, q)
+	err = schema.IdentityUp(ctx, fs, driverName, q)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -136,11 +169,11 @@ func TestMain(m *testing.M) {
 	}
 	mainContext = mctx
 	code := m.Run()
-	err = schema.IdentityUp(ctx, fs, q)
+	err = schema.IdentityUp(ctx, fs, driverName, q)
 	if err != nil {
 		log.Fatal(err)
 	}
-	os.Exit(code)
+	return code
 }
 
 func NewTestCtx(m store.Metric) (*core.Ctx, error) {
@@ -157,7 +190,7 @@ func NewTestCtx(m store.Metric) (*core.Ctx, error) {
 		return nil, err
 	}
 
-	db := store.NewStore(query.New(sqlConn), m)
+	db := store.NewStore(query.New(sqlConn), sqlDriver, m)
 	return &core.Ctx{
 		Config: c,
 		Store:  db,
@@ -171,7 +204,7 @@ type TestEmailClient struct {
 }
 
 // SendMail does nothing and always returns nil.
-func (n TestEmailClient) Send(from string, to []string, msg []byte) error {
+func (n TestEmailClient) Send(ctx context.Context, from string, to []string, msg []byte) error {
 	if n.send != nil {
 		return n.send(from, to, msg)
 	}
@@ -2,12 +2,15 @@ package service
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/gernest/sydent-go/config"
 	"github.com/gernest/sydent-go/core"
 	"github.com/gernest/sydent-go/models"
 )
 
+// SessionWithToken checks token against the one issued for the session sid and
+// marks the session as validated when they match.
 func SessionWithToken(ctx context.Context, coreContext *core.Ctx, sid int64, clientSecret, token string) error {
 	s, err := coreContext.Store.GetTokenSessionByID(ctx, sid)
 	if err != nil {
@@ -23,5 +26,9 @@ func SessionWithToken(ctx context.Context, coreContext *core.Ctx, sid int64, cli
 	if s.Token != token {
 		return models.ErrIncorrectToken
 	}
-	return nil
+	err = coreContext.Store.SetValidated(ctx, fmt.Sprint(s.ID), 1)
+	if err != nil {
+		return err
+	}
+	return coreContext.Store.SetMtime(ctx, s.ID, models.Time())
 }
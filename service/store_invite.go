@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/gernest/sydent-go/config"
 	"github.com/gernest/sydent-go/core"
 	"github.com/gernest/sydent-go/models"
 	"github.com/gernest/signedjson"
@@ -35,6 +36,9 @@ func StoreInvite(coreContext *core.Ctx, m Metric) echo.HandlerFunc {
 		roomID := m["room_id"]
 		sender := m["sender"]
 		requestContext := ctx.Request().Context()
+		if AccountFrom(ctx) == nil {
+			requestContext = setActor(coreContext, ctx, models.ActorClient, "")
+		}
 		mxid, err := db.GlobalGetMxid(requestContext, medium, address)
 		if err != nil && err != sql.ErrNoRows {
 			count.Inc()
@@ -57,6 +61,13 @@ func StoreInvite(coreContext *core.Ctx, m Metric) echo.HandlerFunc {
 			RequestError(coreContext.Log, ctx.Request(), err)
 			return ctx.JSON(http.StatusBadRequest, err)
 		}
+		if !addressAllowed(coreContext, ctx, config.EndpointInvite, medium, address) {
+			count.Inc()
+			return addressDenied(ctx)
+		}
+		tplName, _ := coreContext.Config.LocalizedTemplate(emailTplName,
+			requestLocales(ctx.Request(), m[localeParam])...,
+		)
 		token := models.RandomString(128)
 		keys, err := signedjson.New("0")
 		if err != nil {
@@ -94,7 +105,7 @@ func StoreInvite(coreContext *core.Ctx, m Metric) echo.HandlerFunc {
 		if substitution["room_name"] != "" {
 			substitution["bracketed_room_name"] = fmt.Sprintf("(%s)", substitution["room_name"])
 		}
-		err = send(requestContext, emailTplName, mail.Invite.From, []string{address}, substitution)
+		err = send(requestContext, tplName, mail.Invite.From, []string{address}, substitution)
 		if err != nil {
 			RequestError(coreContext.Log, ctx.Request(), err)
 			return InternalError(ctx)
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/gernest/sydent-go/config"
+	"github.com/gernest/sydent-go/models"
 
 	"github.com/labstack/echo"
 )
@@ -61,3 +62,46 @@ func TestStoreInvite(t *testing.T) {
 		t.Log(received.msg)
 	}
 }
+
+func TestStoreInviteDenied(t *testing.T) {
+	policies := config.AddressPolicies{{
+		Name:      "corporate",
+		Medium:    "email",
+		Endpoints: []string{config.EndpointInvite},
+		Allow:     []string{"example.com"},
+	}}
+	checker, err := policies.Checker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mail, err := config.New(TestEmailClient{
+		host: "localhost",
+		send: func(from string, to []string, msg []byte) error {
+			t.Errorf("unexpected email to %v", to)
+			return nil
+		},
+	}, mainContext.Config.GetTemplate())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tctx := *mainContext
+	tctx.Email = mail
+	tctx.Policy = checker
+	sr := `{
+		"medium": "email",
+		"address": "foo@bar.baz",
+		"room_id": "!something:example.tld",
+		"sender": "@bob:example.com"
+	  }`
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(sr))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	err = StoreInvite(&tctx, &TestMetric{})(e.NewContext(req, rec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), models.ErrThreepidDenied) {
+		t.Errorf("expected M_THREEPID_DENIED got %d %s", rec.Code, rec.Body)
+	}
+}
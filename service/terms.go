@@ -0,0 +1,142 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gernest/sydent-go/config"
+	"github.com/gernest/sydent-go/core"
+	"github.com/gernest/sydent-go/models"
+	"github.com/labstack/echo"
+)
+
+// AcceptTermsRequest is the body of POST /terms.
+type AcceptTermsRequest struct {
+	UserAccepts []string `json:"user_accepts"`
+}
+
+// GetTerms returns a handler which lists the policies users must accept before
+// using the v2 api.
+func GetTerms(coreContext *core.Ctx) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		return ctx.JSON(http.StatusOK, map[string]interface{}{
+			"policies": policies(coreContext.Config.Terms),
+		})
+	}
+}
+
+func policies(terms config.Terms) map[string]interface{} {
+	o := make(map[string]interface{})
+	for _, p := range terms.Policies {
+		m := map[string]interface{}{
+			"version": p.Version,
+		}
+		for _, d := range p.Documents {
+			m[d.Lang] = map[string]string{
+				"name": d.Name,
+				"url":  d.URL,
+			}
+		}
+		o[p.Name] = m
+	}
+	return o
+}
+
+// AcceptTerms returns a handler which records the policy documents the
+// authenticated account has accepted. Urls which don't belong to any of the
+// configured policies are ignored.
+func AcceptTerms(coreContext *core.Ctx, m Metric) echo.HandlerFunc {
+	count := m.CountError("accept_terms")
+	return func(ctx echo.Context) error {
+		req := ctx.Request()
+		a := AccountFrom(ctx)
+		if a == nil {
+			return unauthorized(ctx)
+		}
+		var body AcceptTermsRequest
+		err := json.NewDecoder(req.Body).Decode(&body)
+		if err != nil {
+			count.Inc()
+			RequestError(coreContext.Log, req, err)
+			return ctx.JSON(http.StatusBadRequest, models.NewError(
+				models.ErrBadJSON,
+				"Malformed JSON",
+			))
+		}
+		if body.UserAccepts == nil {
+			count.Inc()
+			return ctx.JSON(http.StatusBadRequest, models.NewError(
+				models.ErrMissingParam,
+				"Missing parameters: user_accepts",
+			))
+		}
+		var accepted []models.AcceptedTerms
+		for _, u := range body.UserAccepts {
+			p, ok := coreContext.Config.Terms.Policy(u)
+			if !ok {
+				continue
+			}
+			accepted = append(accepted, models.AcceptedTerms{
+				Policy:  p.Name,
+				Version: p.Version,
+				URL:     u,
+			})
+		}
+		err = coreContext.Store.AddAcceptedTerms(req.Context(), a.UserID, accepted)
+		if err != nil {
+			count.Inc()
+			RequestError(coreContext.Log, req, err)
+			return InternalError(ctx)
+		}
+		return ctx.JSON(http.StatusOK, map[string]interface{}{})
+	}
+}
+
+// RequireTerms returns a middleware which rejects requests from accounts that
+// have not accepted the current version of every configured policy. It must be
+// used after Authenticate.
+func RequireTerms(coreContext *core.Ctx, m Metric) echo.MiddlewareFunc {
+	count := m.CountError("require_terms")
+	terms := coreContext.Config.Terms
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if len(terms.Policies) == 0 {
+			return next
+		}
+		return func(ctx echo.Context) error {
+			req := ctx.Request()
+			a := AccountFrom(ctx)
+			if a == nil {
+				return unauthorized(ctx)
+			}
+			accepted, err := coreContext.Store.GetAcceptedTerms(req.Context(), a.UserID)
+			if err != nil {
+				count.Inc()
+				RequestError(coreContext.Log, req, err)
+				return InternalError(ctx)
+			}
+			if !signedTerms(terms, accepted) {
+				count.Inc()
+				return ctx.JSON(http.StatusForbidden, models.NewError(
+					models.ErrTermsNotSigned,
+					"Terms not signed",
+				))
+			}
+			return next(ctx)
+		}
+	}
+}
+
+// signedTerms returns true if accepted covers the current version of all
+// policies in terms.
+func signedTerms(terms config.Terms, accepted []models.AcceptedTerms) bool {
+	signed := make(map[string]bool)
+	for _, t := range accepted {
+		signed[t.Policy+"\x00"+t.Version] = true
+	}
+	for _, p := range terms.Policies {
+		if !signed[p.Name+"\x00"+p.Version] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gernest/sydent-go/config"
+	"github.com/gernest/sydent-go/models"
+	"github.com/labstack/echo"
+)
+
+func TestTerms(t *testing.T) {
+	cfg := *mainContext.Config
+	cfg.Terms = config.Terms{
+		Policies: []config.Policy{
+			{
+				Name:    "privacy_policy",
+				Version: "1.2",
+				Documents: []config.PolicyDocument{
+					{Lang: "en", Name: "Privacy Policy", URL: "https://example.org/privacy-1.2-en.html"},
+					{Lang: "fr", Name: "Politique de confidentialité", URL: "https://example.org/privacy-1.2-fr.html"},
+				},
+			},
+		},
+	}
+	tctx := *mainContext
+	tctx.Config = &cfg
+	account := &models.Account{UserID: "@terms:example.com"}
+	err := tctx.Store.AddAccount(context.Background(), account.UserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := echo.New()
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	err = GetTerms(&tctx)(e.NewContext(req, rec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `{"policies":{"privacy_policy":{"en":{"name":"Privacy Policy","url":"https://example.org/privacy-1.2-en.html"},"fr":{"name":"Politique de confidentialité","url":"https://example.org/privacy-1.2-fr.html"},"version":"1.2"}}}`
+	if got := strings.TrimSpace(rec.Body.String()); got != expect {
+		t.Errorf("expected %s got %s", expect, got)
+	}
+
+	protected := RequireTerms(&tctx, &TestMetric{})(options)
+	call := func(h echo.HandlerFunc, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set(accountKey, account)
+		if err := h(c); err != nil {
+			t.Fatal(err)
+		}
+		return rec
+	}
+	rec = call(protected, "")
+	if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), models.ErrTermsNotSigned) {
+		t.Fatalf("expected 403 %s got %d %s", models.ErrTermsNotSigned, rec.Code, rec.Body.String())
+	}
+	rec = call(AcceptTerms(&tctx, &TestMetric{}), `{"user_accepts":["https://example.org/privacy-1.2-fr.html","https://example.org/unknown.html"]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d %s", rec.Code, rec.Body.String())
+	}
+	rec = call(protected, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d %s", rec.Code, rec.Body.String())
+	}
+
+	// a new version of the policy must be accepted again.
+	cfg.Terms.Policies[0].Version = "1.3"
+	protected = RequireTerms(&tctx, &TestMetric{})(options)
+	rec = call(protected, "")
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected 403 got %d %s", rec.Code, rec.Body.String())
+	}
+}
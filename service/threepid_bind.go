@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/gernest/sydent-go/config"
 	"github.com/gernest/sydent-go/core"
 	"github.com/gernest/sydent-go/models"
 	"github.com/labstack/echo"
@@ -53,7 +54,14 @@ func Bind(coreContext *core.Ctx, m Metric) echo.HandlerFunc {
 		}
 		mxid := m["mxid"]
 		clientSecret := m["client_secret"]
-		requestContext := req.Context()
+		if a := AccountFrom(ctx); a != nil && a.UserID != mxid {
+			count.Inc()
+			return ctx.JSON(http.StatusForbidden, models.NewError(
+				models.ErrUnauthorized,
+				"This user is prohibited from binding to the mxid",
+			))
+		}
+		requestContext := setActor(coreContext, ctx, models.ActorSession, m["sid"])
 		noMatch := models.NewError(
 			models.ErrNoValidSession,
 			"No valid session was found matching that sid and client secret",
@@ -79,6 +87,10 @@ func Bind(coreContext *core.Ctx, m Metric) echo.HandlerFunc {
 				return ctx.JSON(http.StatusBadRequest, noMatch)
 			}
 		}
+		if !addressAllowed(coreContext, ctx, config.EndpointBind, s.Medium, s.Address) {
+			count.Inc()
+			return addressDenied(ctx)
+		}
 		sgAss, err := bind(requestContext, s.Medium, s.Address, mxid)
 		if err != nil {
 			RequestError(coreContext.Log, req, err)
@@ -202,7 +202,7 @@ func Unbind(coreContext *core.Ctx, fedClient config.HTTPClient) echo.HandlerFunc
 				"Origin server name does not match mxid",
 			))
 		}
-		err = unbind(req.Context(), opts.Threepid)
+		err = unbind(setActor(coreContext, ctx, models.ActorServer, origin), opts.Threepid)
 		if err != nil {
 			RequestError(lg, req, err)
 			return InternalError(ctx)
@@ -0,0 +1,39 @@
+package store
+
+import (
+	"context"
+
+	"github.com/gernest/sydent-go/models"
+)
+
+// AddAccount creates an account for userID if it does not exist yet.
+func AddAccount(ctx context.Context, db models.Query, q Driver, userID string) error {
+	_, err := db.ExecContext(ctx, q.AddAccount(), userID, models.Time())
+	return err
+}
+
+// AddAccountToken stores an access token issued to the account userID.
+func AddAccountToken(ctx context.Context, db models.Query, q Driver, userID, token string) error {
+	_, err := db.ExecContext(ctx, q.AddAccountToken(), token, userID)
+	return err
+}
+
+// GetAccountByToken returns the account which owns the access token.
+// sql.ErrNoRows is returned when the token is not known.
+func GetAccountByToken(ctx context.Context, db models.Query, q Driver, token string) (*models.Account, error) {
+	var a models.Account
+	err := db.QueryRowContext(ctx, q.GetAccountByToken(), token).Scan(
+		&a.UserID,
+		&a.CreationTS,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// DeleteAccountToken revokes the access token.
+func DeleteAccountToken(ctx context.Context, db models.Query, q Driver, token string) error {
+	_, err := db.ExecContext(ctx, q.DeleteAccountToken(), token)
+	return err
+}
@@ -2,6 +2,8 @@ package store
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
 
 	"github.com/gernest/sydent-go/models"
 )
@@ -25,9 +27,67 @@ type Store interface {
 	LocalRemoveAssociation(ctx context.Context, as *models.Association) error
 	GetAssociationsAfterID(ctx context.Context, afterID int64, limit int64) ([]models.Association, error)
 
+	GetLookupPepper(ctx context.Context) (string, error)
+	RotateLookupPepper(ctx context.Context, pepper string) error
+	GlobalGetMxidsByHash(ctx context.Context, hashes []string) (map[string]string, error)
+
+	AddAccount(ctx context.Context, userID string) error
+	AddAccountToken(ctx context.Context, userID, token string) error
+	GetAccountByToken(ctx context.Context, token string) (*models.Account, error)
+	DeleteAccountToken(ctx context.Context, token string) error
+	AddAcceptedTerms(ctx context.Context, userID string, terms []models.AcceptedTerms) error
+	GetAcceptedTerms(ctx context.Context, userID string) ([]models.AcceptedTerms, error)
+
+	AddOnbindNotification(ctx context.Context, destination, payload string) (int64, error)
+	GetDueOnbindNotifications(ctx context.Context, now int64, limit int) ([]models.OnbindNotification, error)
+	NextOnbindNotificationTS(ctx context.Context) (int64, error)
+	RetryOnbindNotification(ctx context.Context, id, next int64, lastErr string) error
+	DeleteOnbindNotification(ctx context.Context, id int64) error
+
+	ReapValidationSessions(ctx context.Context, before int64, limit int) (sessions, tokenAuths int64, err error)
+	ReapInviteTokens(ctx context.Context, before int64, limit int) (int64, error)
+	ReapEphemeralPublicKeys(ctx context.Context, before int64, limit int) (int64, error)
+
+	AddEmail(ctx context.Context, from string, to []string, domain, message string) (int64, error)
+	GetDueEmails(ctx context.Context, now int64, limit int) ([]models.OutboxEmail, error)
+	ListEmails(ctx context.Context, state string, limit int) ([]models.OutboxEmail, error)
+	ClaimEmail(ctx context.Context, id, due, until int64) error
+	RetryEmail(ctx context.Context, id, next int64, lastErr string) error
+	DeadEmail(ctx context.Context, id int64, lastErr string) error
+	RequeueEmail(ctx context.Context, id int64) error
+	DeleteEmail(ctx context.Context, id int64) error
+	NextEmailTS(ctx context.Context) (int64, error)
+
+	TakeRateLimitToken(ctx context.Context, key string, burst float64, interval, now int64) (int64, error)
+	TakeRateLimitTokens(ctx context.Context, buckets []RateLimitBucket, now int64) (int64, error)
+	ReapRateLimits(ctx context.Context, before int64, limit int) (int64, error)
+
+	// AuditLog returns the entries of the audit log matching q. Changes to
+	// associations, invite tokens and peers are recorded in the same
+	// transaction as the change, attributed to the actor of their context,
+	// see models.WithActor. Entries are never deleted, and only changed by
+	// EraseSubject, which redacts the entries of the erased subject.
+	AuditLog(ctx context.Context, q models.AuditQuery) ([]models.AuditEntry, error)
+
+	// ExportSubject returns everything stored about the address or matrix id
+	// of s, see ExportSubject.
+	ExportSubject(ctx context.Context, s models.Subject) (*models.SubjectExport, error)
+
+	// EraseSubject erases the associations, invite tokens, validation
+	// sessions and queued emails of s and redacts its audit log entries, see
+	// EraseSubject. The removals of local associations are replicated to peers
+	// like unbinds.
+	EraseSubject(ctx context.Context, s models.Subject) (*models.SubjectErasure, error)
+
 	GetPeerByName(ctx context.Context, name string) (*models.Peer, error)
 	GetAllPeers(ctx context.Context) ([]models.Peer, error)
-	SetLastSentVersionAndPokeSucceeded(ctx context.Context, peerName, lastSentVersion, lastPokeSucceeded string) error
+	SetLastSentVersionAndPokeSucceeded(ctx context.Context, peerName string, lastSentVersion, lastPokeSucceeded int64) error
+	ListPeers(ctx context.Context) ([]models.Peer, error)
+	AddPeer(ctx context.Context, name string, port sql.NullInt64) error
+	RemovePeer(ctx context.Context, name string) error
+	SetPeerKey(ctx context.Context, name, alg, key string) error
+	SetPeerActive(ctx context.Context, name string, active bool) error
+	CountAssociationsAfterID(ctx context.Context, afterID int64) (int64, error)
 
 	SetSendAttemptNumber(ctx context.Context, sid int64, attemptNo int64) error
 	SetValidated(ctx context.Context, sid string, validated int) error
@@ -38,8 +98,10 @@ type Store interface {
 	GetOrCreateTokenSession(ctx context.Context, medium, address, clientSecret string) (*models.ValidationSession, error)
 	GetValidatedSession(ctx context.Context, sid int64, clientSecret string) (*models.ValidationSession, error)
 
-	DB() models.SQL
-	Metric() Metric
+	// Tx calls fn with a Store whose changes are committed together when fn
+	// returns nil and discarded otherwise. Calling Tx on the Store passed to
+	// fn joins the same transaction.
+	Tx(ctx context.Context, fn func(Store) error) error
 }
 
 // Matrix universal database handler for the matrix services.
@@ -49,14 +111,24 @@ type Matrix struct {
 	metrics Metric
 }
 
-func NewStore(db models.SQL, m Metric) *Matrix {
+// NewStore returns a Store backed by db, driver provides the queries for the
+// dialect of db.
+func NewStore(db models.SQL, driver Driver, m Metric) *Matrix {
 	return &Matrix{
-		Identity: New(db, m),
+		Identity: New(db, driver, m),
 		db:       db,
 		metrics:  m,
 	}
 }
 
+// Canonical makes m store and look up addresses in the canonical form of c,
+// addresses of associations passed to m are replaced with it. c can be nil to
+// apply no provider rules.
+func (m *Matrix) Canonical(c *models.Canonicalizer) *Matrix {
+	m.canonical = c
+	return m
+}
+
 func (m *Matrix) DB() models.SQL {
 	return m.db
 }
@@ -64,3 +136,57 @@ func (m *Matrix) DB() models.SQL {
 func (m *Matrix) Metric() Metric {
 	return m.metrics
 }
+
+func (m *Matrix) Driver() Driver {
+	return m.driver
+}
+
+func (m *Matrix) Tx(ctx context.Context, fn func(Store) error) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	id := New(tx, m.driver, m.metrics)
+	id.canonical = m.canonical
+	err = fn(txStore{Identity: id})
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// txStore is the Store passed to the function given to Matrix.Tx.
+type txStore struct {
+	*Identity
+}
+
+func (s txStore) Tx(ctx context.Context, fn func(Store) error) error {
+	return fn(s)
+}
+
+// begin starts a transaction on db. When db is already a transaction the
+// returned transaction joins it, committing and rolling back is then left to
+// the owner of the outer transaction.
+func begin(ctx context.Context, db models.Query) (models.Tx, error) {
+	switch e := db.(type) {
+	case models.SQL:
+		return e.BeginTx(ctx, nil)
+	case models.Tx:
+		return joinedTx{Tx: e}, nil
+	default:
+		return nil, fmt.Errorf("store: %T does not support transactions", db)
+	}
+}
+
+type joinedTx struct {
+	models.Tx
+}
+
+func (joinedTx) Commit() error {
+	return nil
+}
+
+func (joinedTx) Rollback() error {
+	return nil
+}
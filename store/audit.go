@@ -0,0 +1,284 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/gernest/sydent-go/models"
+)
+
+// auditAssociation is the snapshot of an association recorded in the audit
+// log, the origin is only set for global associations.
+type auditAssociation struct {
+	ID           int64  `json:"id,omitempty"`
+	Medium       string `json:"medium"`
+	Address      string `json:"address"`
+	MatrixID     string `json:"mxid,omitempty"`
+	Timestamp    int64  `json:"ts"`
+	NotBefore    int64  `json:"not_before,omitempty"`
+	NotAfter     int64  `json:"not_after,omitempty"`
+	OriginServer string `json:"origin_server,omitempty"`
+	OriginID     int64  `json:"origin_id,omitempty"`
+}
+
+// newAuditAssociation returns the snapshot of as, nil is returned when as is
+// nil.
+func newAuditAssociation(as *models.Association) *auditAssociation {
+	if as == nil {
+		return nil
+	}
+	return &auditAssociation{
+		ID:        as.ID,
+		Medium:    as.Medium,
+		Address:   as.Address,
+		MatrixID:  as.MatrixID,
+		Timestamp: as.Timestamp,
+		NotBefore: as.NotBefore,
+		NotAfter:  as.NotAfter,
+	}
+}
+
+// auditInvite is the snapshot of an invite token recorded in the audit log.
+// The token is left out, it is a secret of the invited user.
+type auditInvite struct {
+	Medium     string `json:"medium"`
+	Address    string `json:"address"`
+	RoomID     string `json:"room_id"`
+	Sender     string `json:"sender"`
+	ReceivedTS int64  `json:"received_ts,omitempty"`
+	SentTS     int64  `json:"sent_ts,omitempty"`
+}
+
+// auditPeer is the snapshot of a peer recorded in the audit log.
+type auditPeer struct {
+	Name       string            `json:"name"`
+	Port       int64             `json:"port,omitempty"`
+	Active     bool              `json:"active"`
+	PublicKeys map[string]string `json:"public_keys,omitempty"`
+}
+
+func newAuditPeer(p models.Peer) *auditPeer {
+	a := &auditPeer{
+		Name:   p.Name,
+		Port:   p.Port.Int64,
+		Active: p.Active == 1,
+	}
+	if len(p.PublicKeys) > 0 {
+		a.PublicKeys = p.PublicKeys
+	}
+	return a
+}
+
+// auditAddress is the snapshot of an address rewritten by Canonicalize.
+type auditAddress struct {
+	Table   string `json:"table"`
+	ID      int64  `json:"id"`
+	Address string `json:"address"`
+}
+
+// newAuditEntry returns the entry recording action, made by the actor of ctx.
+// before and after are encoded to json, nil values are left empty.
+func newAuditEntry(ctx context.Context, action string, before, after interface{}) (models.AuditEntry, error) {
+	e := models.AuditEntry{
+		TS:     models.Time(),
+		Action: action,
+		Actor:  models.ActorFrom(ctx),
+	}
+	var err error
+	e.Before, err = auditJSON(before)
+	if err != nil {
+		return e, err
+	}
+	e.After, err = auditJSON(after)
+	return e, err
+}
+
+func auditJSON(v interface{}) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if string(b) == "null" {
+		return nil, nil
+	}
+	return b, nil
+}
+
+// audit appends e to the audit log. It must be called with the transaction of
+// the change e records.
+func audit(ctx context.Context, db models.Query, q Driver, e models.AuditEntry) error {
+	_, err := db.ExecContext(ctx, q.AddAuditEntry(),
+		e.TS, e.Action, e.Actor.Kind, e.Actor.ID, e.Actor.IP,
+		e.Medium, e.Address, e.MatrixID, e.Peer,
+		nullJSON(e.Before), nullJSON(e.After),
+	)
+	return err
+}
+
+func nullJSON(b json.RawMessage) sql.NullString {
+	return sql.NullString{String: string(b), Valid: len(b) > 0}
+}
+
+// auditChange records action on the association or invite of medium and
+// address.
+func auditChange(ctx context.Context, db models.Query, q Driver, action, medium, address, mxid string, before, after interface{}) error {
+	e, err := newAuditEntry(ctx, action, before, after)
+	if err != nil {
+		return err
+	}
+	e.Medium = medium
+	e.Address = address
+	e.MatrixID = mxid
+	return audit(ctx, db, q, e)
+}
+
+// auditPeerChange records action on the peer name.
+func auditPeerChange(ctx context.Context, db models.Query, q Driver, action, name string, before, after *auditPeer) error {
+	e, err := newAuditEntry(ctx, action, before, after)
+	if err != nil {
+		return err
+	}
+	e.Peer = name
+	return audit(ctx, db, q, e)
+}
+
+// AuditLog returns the entries of the audit log matching aq, ordered by id.
+func AuditLog(ctx context.Context, db models.Query, q Driver, aq models.AuditQuery) ([]models.AuditEntry, error) {
+	rows, err := db.QueryContext(ctx, q.QueryAuditLog(),
+		aq.AfterID, aq.Medium, aq.Address, aq.MatrixID, aq.Peer, aq.Action,
+		aq.Since, aq.Until, aq.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var o []models.AuditEntry
+	for rows.Next() {
+		var e models.AuditEntry
+		var before, after sql.NullString
+		err = rows.Scan(
+			&e.ID,
+			&e.TS,
+			&e.Action,
+			&e.Actor.Kind,
+			&e.Actor.ID,
+			&e.Actor.IP,
+			&e.Medium,
+			&e.Address,
+			&e.MatrixID,
+			&e.Peer,
+			&before,
+			&after,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if before.Valid {
+			e.Before = json.RawMessage(before.String)
+		}
+		if after.Valid {
+			e.After = json.RawMessage(after.String)
+		}
+		o = append(o, e)
+	}
+	return o, rows.Err()
+}
+
+// getLocalAssociation returns the local association of medium and address,
+// nil is returned when there is none.
+func getLocalAssociation(ctx context.Context, db models.Query, q Driver, medium, address string) (*models.Association, error) {
+	var as models.Association
+	var mxid sql.NullString
+	var notBefore, notAfter sql.NullInt64
+	err := db.QueryRowContext(ctx, q.GetLocalAssociation(), medium, address).Scan(
+		&as.ID,
+		&as.Medium,
+		&as.Address,
+		&mxid,
+		&as.Timestamp,
+		&notBefore,
+		&notAfter,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	as.MatrixID = mxid.String
+	as.NotBefore = notBefore.Int64
+	as.NotAfter = notAfter.Int64
+	return &as, nil
+}
+
+// globalAssociationsFor returns snapshots of the global associations of medium
+// and address.
+func globalAssociationsFor(ctx context.Context, db models.Query, q Driver, medium, address string) ([]*auditAssociation, error) {
+	rows, err := db.QueryContext(ctx, q.GlobalAssociationsFor(), medium, address)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var o []*auditAssociation
+	for rows.Next() {
+		var a auditAssociation
+		err = rows.Scan(
+			&a.ID,
+			&a.Medium,
+			&a.Address,
+			&a.MatrixID,
+			&a.Timestamp,
+			&a.NotBefore,
+			&a.NotAfter,
+			&a.OriginServer,
+			&a.OriginID,
+		)
+		if err != nil {
+			return nil, err
+		}
+		o = append(o, &a)
+	}
+	return o, rows.Err()
+}
+
+// unsentInvites returns snapshots of the invite tokens of medium and address
+// which were not sent yet.
+func unsentInvites(ctx context.Context, db models.Query, q Driver, medium, address string) ([]*auditInvite, error) {
+	tokens, err := GetTokens(ctx, db, q, medium, address)
+	if err != nil {
+		return nil, err
+	}
+	var o []*auditInvite
+	for _, t := range tokens {
+		if !t.SentAt.IsZero() {
+			continue
+		}
+		o = append(o, &auditInvite{
+			Medium:     t.Medium,
+			Address:    t.Address,
+			RoomID:     t.RoomID,
+			Sender:     t.Sender,
+			ReceivedTS: models.MS(&t.ReceivedAt),
+		})
+	}
+	return o, nil
+}
+
+// peerSnapshot returns the snapshot of the peer name, nil is returned when it
+// doesn't exist.
+func peerSnapshot(ctx context.Context, db models.Query, q Driver, name string) (*auditPeer, error) {
+	peers, err := ListPeers(ctx, db, q)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range peers {
+		if p.Name == name {
+			return newAuditPeer(p), nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,257 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+
+	"github.com/gernest/sydent-go/models"
+)
+
+// canonicalIDs returns a copy of the medium, address pairs ids with the
+// addresses in their canonical form.
+func canonicalIDs(c *models.Canonicalizer, ids [][]string) [][]string {
+	o := make([][]string, len(ids))
+	for i, v := range ids {
+		o[i] = append([]string(nil), v...)
+		if len(v) == 2 {
+			o[i][1] = c.Address(v[0], v[1])
+		}
+	}
+	return o
+}
+
+// CanonicalResult is the outcome of canonicalising the addresses of one table.
+type CanonicalResult struct {
+	Table string
+
+	// Checked is the number of rows in the table.
+	Checked int64
+
+	// Updated is the number of rows whose address was not canonical and was
+	// replaced.
+	Updated int64
+
+	Collisions []CanonicalCollision
+}
+
+// CanonicalCollision are rows with different addresses which have the same
+// canonical form, and which can not be merged silently.
+type CanonicalCollision struct {
+	Medium string
+
+	// Address is the canonical address of the rows.
+	Address string
+
+	Rows []CanonicalRow
+
+	// Updated is true when the addresses of Rows were replaced anyway.
+	Updated bool
+}
+
+// CanonicalRow is a row of a CanonicalCollision. MatrixID is empty for
+// removed local associations.
+type CanonicalRow struct {
+	ID       int64
+	Address  string
+	MatrixID string
+}
+
+// canonicalTable describes a table with addresses. The list query returns id,
+// medium, address and mxid, the mxid is empty for tables without one.
+type canonicalTable struct {
+	name   string
+	list   func(Driver) string
+	update func(Driver) string
+
+	// unique is true when medium and address are unique in the table, rows
+	// with the same canonical address are left as they are.
+	unique bool
+
+	// mxid is true when rows with the same canonical address collide if they
+	// are bound to different mxids.
+	mxid bool
+
+	// hash is true when the lookup hash is updated with the address.
+	hash bool
+}
+
+var canonicalTables = []canonicalTable{
+	{
+		name:   "local_threepid_associations",
+		list:   Driver.LocalAssociationAddresses,
+		update: Driver.SetLocalAssociationAddress,
+		unique: true,
+		mxid:   true,
+	},
+	{
+		name:   "global_threepid_associations",
+		list:   Driver.GlobalAssociationAddresses,
+		update: Driver.SetGlobalAssociationAddress,
+		mxid:   true,
+		hash:   true,
+	},
+	{
+		name:   "threepid_validation_sessions",
+		list:   Driver.ValidationSessionAddresses,
+		update: Driver.SetValidationSessionAddress,
+	},
+	{
+		name:   "invite_tokens",
+		list:   Driver.InviteTokenAddresses,
+		update: Driver.SetInviteTokenAddress,
+	},
+}
+
+type canonicalRow struct {
+	CanonicalRow
+	medium    string
+	canonical string
+}
+
+// Canonicalize replaces the addresses of associations, validation sessions and
+// invite tokens stored before addresses were canonical, or before the
+// canonical form of m changed, with their canonical form. Everything happens in
+// one transaction which is rolled back when dryRun is true.
+//
+// Local associations with the same canonical address are reported as
+// collisions and left alone since medium and address are unique, they must be
+// resolved by hand.
+// Global associations are updated, the ones bound to different mxids are
+// reported. Peers canonicalise the associations they replicated on their own.
+// Every rewritten address is recorded in the audit log.
+func Canonicalize(ctx context.Context, m *Matrix, dryRun bool) ([]CanonicalResult, error) {
+	tx, err := begin(ctx, m.DB())
+	if err != nil {
+		return nil, err
+	}
+	pepper, err := GetLookupPepper(ctx, tx, m.Driver())
+	if err != nil && err != sql.ErrNoRows {
+		tx.Rollback()
+		return nil, err
+	}
+	var o []CanonicalResult
+	for _, t := range canonicalTables {
+		r, err := canonicalizeTable(ctx, tx, m.Driver(), m.canonical, t, pepper)
+		if err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("canonicalizing %s: %v", t.name, err)
+		}
+		o = append(o, r)
+	}
+	if dryRun {
+		return o, tx.Rollback()
+	}
+	return o, tx.Commit()
+}
+
+func canonicalizeTable(ctx context.Context, tx models.Query, q Driver, c *models.Canonicalizer, t canonicalTable, pepper string) (CanonicalResult, error) {
+	r := CanonicalResult{Table: t.name}
+	groups := make(map[string][]canonicalRow)
+	var lastID int64
+	for {
+		rows, err := canonicalRows(ctx, tx, q, t, c, lastID)
+		if err != nil {
+			return r, err
+		}
+		for _, v := range rows {
+			k := v.medium + " " + v.canonical
+			groups[k] = append(groups[k], v)
+			lastID = v.ID
+		}
+		r.Checked += int64(len(rows))
+		if len(rows) < batch {
+			break
+		}
+	}
+	keys := make([]string, 0, len(groups))
+	for k := range groups {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		g := groups[k]
+		if collides(t, g) {
+			col := CanonicalCollision{
+				Medium:  g[0].medium,
+				Address: g[0].canonical,
+				Updated: !t.unique,
+			}
+			for _, v := range g {
+				col.Rows = append(col.Rows, v.CanonicalRow)
+			}
+			r.Collisions = append(r.Collisions, col)
+			if t.unique {
+				continue
+			}
+		}
+		for _, v := range g {
+			if v.Address == v.canonical {
+				continue
+			}
+			args := []interface{}{v.canonical, v.ID}
+			if t.hash {
+				hash := sql.NullString{}
+				if pepper != "" {
+					hash = sql.NullString{
+						String: models.LookupHash(v.medium, v.canonical, pepper),
+						Valid:  true,
+					}
+				}
+				args = []interface{}{v.canonical, hash, v.ID}
+			}
+			if _, err := tx.ExecContext(ctx, t.update(q), args...); err != nil {
+				return r, err
+			}
+			err := auditChange(ctx, tx, q, models.AuditCanonicalize, v.medium, v.canonical, v.MatrixID,
+				&auditAddress{Table: t.name, ID: v.ID, Address: v.Address},
+				&auditAddress{Table: t.name, ID: v.ID, Address: v.canonical},
+			)
+			if err != nil {
+				return r, err
+			}
+			r.Updated++
+		}
+	}
+	return r, nil
+}
+
+// collides returns true if the rows g with the same canonical address can not
+// be merged silently.
+func collides(t canonicalTable, g []canonicalRow) bool {
+	if len(g) < 2 {
+		return false
+	}
+	if t.unique {
+		return true
+	}
+	if !t.mxid {
+		return false
+	}
+	for _, v := range g[1:] {
+		if v.MatrixID != g[0].MatrixID {
+			return true
+		}
+	}
+	return false
+}
+
+func canonicalRows(ctx context.Context, db models.Query, q Driver, t canonicalTable, c *models.Canonicalizer, afterID int64) ([]canonicalRow, error) {
+	rows, err := db.QueryContext(ctx, t.list(q), afterID, batch)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var o []canonicalRow
+	for rows.Next() {
+		var v canonicalRow
+		err = rows.Scan(&v.ID, &v.medium, &v.Address, &v.MatrixID)
+		if err != nil {
+			return nil, err
+		}
+		v.canonical = c.Address(v.medium, v.Address)
+		o = append(o, v)
+	}
+	return o, rows.Err()
+}
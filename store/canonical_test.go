@@ -0,0 +1,143 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gernest/sydent-go/embed"
+	"github.com/gernest/sydent-go/models"
+	"github.com/gernest/sydent-go/store/query"
+	"github.com/gernest/sydent-go/store/schema"
+)
+
+const canonicalFixture = `
+INSERT INTO local_threepid_associations (medium, address, mxid, ts, notBefore, notAfter) VALUES ('email', 'John.Smith@gmail.com', '@john:example.com', 10, 0, 100000000000000);
+INSERT INTO local_threepid_associations (medium, address, mxid, ts, notBefore, notAfter) VALUES ('email', 'johnsmith+chat@googlemail.com', '@smith:example.com', 10, 0, 100000000000000);
+INSERT INTO local_threepid_associations (medium, address, mxid, ts, notBefore, notAfter) VALUES ('email', 'Alice@Example.com', '@alice:example.com', 10, 0, 100000000000000);
+INSERT INTO global_threepid_associations (medium, address, mxid, ts, notBefore, notAfter, originServer, originId, sgAssoc) VALUES ('email', 'Bob@Example.com', '@bob:example.com', 10, 0, 100000000000000, 'a.example.com', 1, '{}');
+INSERT INTO global_threepid_associations (medium, address, mxid, ts, notBefore, notAfter, originServer, originId, sgAssoc) VALUES ('email', 'bob@example.com', '@bob:example.com', 10, 0, 100000000000000, 'b.example.com', 1, '{}');
+INSERT INTO global_threepid_associations (medium, address, mxid, ts, notBefore, notAfter, originServer, originId, sgAssoc) VALUES ('email', 'Carol@Example.com', '@carol:example.com', 10, 0, 100000000000000, 'a.example.com', 2, '{}');
+INSERT INTO global_threepid_associations (medium, address, mxid, ts, notBefore, notAfter, originServer, originId, sgAssoc) VALUES ('email', 'carol@example.com', '@mallory:example.com', 10, 0, 100000000000000, 'b.example.com', 2, '{}');
+INSERT INTO threepid_validation_sessions (medium, address, clientSecret, validated, mtime) VALUES ('email', 'Dave@Example.com', 'secret', 1, 10);
+INSERT INTO invite_tokens (medium, address, room_id, sender, token, received_ts) VALUES ('msisdn', '+44 7700 900123', '!room:example.com', '@bob:example.com', 'token', 10);
+`
+
+func TestCanonicalize(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sydent-go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "identity.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	q := query.New(db)
+	if err := schema.IdentityUp(ctx, embed.New(), "sqlite3", q); err != nil {
+		t.Fatal(err)
+	}
+	driver, err := NewDriver("sqlite3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gmail, err := models.NewCanonicalizer("gmail")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewStore(q, driver, Metric{}).Canonical(gmail)
+	if err := m.RotateLookupPepper(ctx, "pepper"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ExecContext(ctx, canonicalFixture); err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(dryRun bool) {
+		t.Helper()
+		result, err := Canonicalize(ctx, m, dryRun)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expect := map[string][3]int64{
+			"local_threepid_associations":  {3, 1, 1},
+			"global_threepid_associations": {4, 2, 1},
+			"threepid_validation_sessions": {1, 1, 0},
+			"invite_tokens":                {1, 1, 0},
+		}
+		if len(result) != len(expect) {
+			t.Fatalf("expected %d tables got %#v", len(expect), result)
+		}
+		for _, r := range result {
+			e := expect[r.Table]
+			if r.Checked != e[0] || r.Updated != e[1] || int64(len(r.Collisions)) != e[2] {
+				t.Errorf("%s: expected %v got %#v", r.Table, e, r)
+			}
+		}
+		local := result[0].Collisions[0]
+		if local.Address != "johnsmith@gmail.com" || len(local.Rows) != 2 || local.Updated {
+			t.Errorf("unexpected local collision %#v", local)
+		}
+		global := result[1].Collisions[0]
+		if global.Address != "carol@example.com" || len(global.Rows) != 2 || !global.Updated {
+			t.Errorf("unexpected global collision %#v", global)
+		}
+	}
+
+	check(true)
+	if _, err := m.GlobalGetMxid(ctx, "email", "Bob@Example.com"); err != nil {
+		t.Errorf("expected lower case lookups to match before canonicalizing got %v", err)
+	}
+	var n int
+	err = db.QueryRowContext(ctx, `SELECT count(*) FROM threepid_validation_sessions WHERE address = 'Dave@Example.com'`).Scan(&n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Fatal("expected dry run to change nothing")
+	}
+
+	check(false)
+	// the dry run recorded nothing.
+	entries, err := m.AuditLog(ctx, models.AuditQuery{Action: models.AuditCanonicalize, Limit: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 5 {
+		t.Errorf("expected every rewritten address to be audited got %d entries", len(entries))
+	}
+	tokens, err := m.GetTokens(ctx, "msisdn", "447700900123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 1 {
+		t.Errorf("expected the invite token got %#v", tokens)
+	}
+	hashes, err := m.GlobalGetMxidsByHash(ctx, []string{
+		models.LookupHash("email", "bob@example.com", "pepper"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hashes) != 1 {
+		t.Errorf("expected the lookup hash to be updated got %v", hashes)
+	}
+	as, err := m.GetAssociationsAfterID(ctx, -1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, a := range as {
+		if a.MatrixID == "@alice:example.com" && a.Address != "alice@example.com" {
+			t.Errorf("expected alice to be canonical got %s", a.Address)
+		}
+		if a.MatrixID == "@john:example.com" && a.Address != "John.Smith@gmail.com" {
+			t.Errorf("expected colliding john to be left alone got %s", a.Address)
+		}
+	}
+}
@@ -0,0 +1,1225 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gernest/sydent-go/models"
+)
+
+func testLocalAssociations(t *testing.T, ctx TestContext) {
+	now := models.Time()
+	carol := &models.Association{
+		Medium:    "email",
+		Address:   "carol@example.com",
+		MatrixID:  "@carol:example.com",
+		Timestamp: now,
+		NotBefore: now,
+		NotAfter:  now + 1000,
+	}
+	err := ctx.Store.LocalAddOrUpdateAssociation(ctx.Ctx, carol)
+	if err != nil {
+		t.Fatal(err)
+	}
+	as, err := ctx.Store.GetAssociationsAfterID(ctx.Ctx, -1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(as) != 1 || as[0].MatrixID != carol.MatrixID || as[0].NotAfter != carol.NotAfter {
+		t.Fatalf("expected carol got %#v", as)
+	}
+	first := as[0].ID
+
+	// updates and removals get a new id so replication picks them up.
+	err = ctx.Store.LocalAddOrUpdateAssociation(ctx.Ctx, carol)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ctx.Store.LocalRemoveAssociation(ctx.Ctx, &models.Association{
+		Medium: carol.Medium, Address: carol.Address, MatrixID: "@someone:example.com",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := ctx.Store.CountAssociationsAfterID(ctx.Ctx, first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("expected one association after %d got %d", first, n)
+	}
+	err = ctx.Store.LocalRemoveAssociation(ctx.Ctx, carol)
+	if err != nil {
+		t.Fatal(err)
+	}
+	as, err = ctx.Store.GetAssociationsAfterID(ctx.Ctx, first, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(as) != 1 || as[0].ID <= first+1 || as[0].MatrixID != "" || as[0].NotAfter != 0 {
+		t.Errorf("expected removed association got %#v", as)
+	}
+}
+
+func testGlobalAssociations(t *testing.T, ctx TestContext) {
+	now := models.Time()
+	dave := &models.Association{
+		Medium:    "email",
+		Address:   "Dave@Example.com",
+		MatrixID:  "@dave:example.com",
+		Timestamp: now,
+		NotBefore: now - 1000,
+		NotAfter:  now + 100000,
+	}
+	const origin = "global.example.com"
+	_, err := ctx.Store.GlobalLastIDFromServer(ctx.Ctx, origin)
+	if err != sql.ErrNoRows {
+		t.Errorf("expected %v got %v", sql.ErrNoRows, err)
+	}
+	for _, id := range []int64{7, 7, 3} {
+		err = ctx.Store.GlobalAddAssociation(ctx.Ctx, dave, origin, id, `{"mxid":"@dave:example.com"}`)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	last, err := ctx.Store.GlobalLastIDFromServer(ctx.Ctx, origin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last != 7 {
+		t.Errorf("expected 7 got %d", last)
+	}
+	mxid, err := ctx.Store.GlobalGetMxid(ctx.Ctx, "email", "dave@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mxid != dave.MatrixID {
+		t.Errorf("expected %s got %s", dave.MatrixID, mxid)
+	}
+	signed, err := ctx.Store.SignedAssociationStringForThreepid(ctx.Ctx, "email", "DAVE@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signed != `{"mxid":"@dave:example.com"}` {
+		t.Errorf("unexpected signed association %s", signed)
+	}
+	found, err := ctx.Store.GlobalGetMxids(ctx.Ctx, [][]string{
+		{"email", "dave@example.com"},
+		{"email", "nobody@example.com"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 2 || found[0].MatrixID != dave.MatrixID || found[0].Address != dave.Address {
+		t.Errorf("expected two associations of dave got %#v", found)
+	}
+	err = ctx.Store.GlobalRemoveAssociation(ctx.Ctx, dave.Medium, dave.Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ctx.Store.GlobalGetMxid(ctx.Ctx, "email", "dave@example.com")
+	if err != sql.ErrNoRows {
+		t.Errorf("expected %v got %v", sql.ErrNoRows, err)
+	}
+}
+
+func testPeers(t *testing.T, ctx TestContext) {
+	const name = "peer.example.com"
+	err := ctx.Store.AddPeer(ctx.Ctx, name, sql.NullInt64{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ctx.Store.AddPeer(ctx.Ctx, name, sql.NullInt64{Int64: 1001, Valid: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// inactive peers and peers without keys don't replicate.
+	_, err = ctx.Store.GetPeerByName(ctx.Ctx, name)
+	if err != sql.ErrNoRows {
+		t.Errorf("expected %v got %v", sql.ErrNoRows, err)
+	}
+	for _, missing := range []error{
+		ctx.Store.SetPeerKey(ctx.Ctx, "missing.example.com", "ed25519", "key"),
+		ctx.Store.SetPeerActive(ctx.Ctx, "missing.example.com", true),
+		ctx.Store.RemovePeer(ctx.Ctx, "missing.example.com"),
+	} {
+		if missing != sql.ErrNoRows {
+			t.Errorf("expected %v got %v", sql.ErrNoRows, missing)
+		}
+	}
+	err = ctx.Store.SetPeerKey(ctx.Ctx, name, "ed25519", "old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ctx.Store.SetPeerKey(ctx.Ctx, name, "ed25519", "key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ctx.Store.SetPeerActive(ctx.Ctx, name, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ctx.Store.SetLastSentVersionAndPokeSucceeded(ctx.Ctx, name, 42, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	peer, err := ctx.Store.GetPeerByName(ctx.Ctx, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if peer.Port.Int64 != 1001 || peer.LastSentVersion.Int64 != 42 || peer.PublicKeys["ed25519"] != "key" {
+		t.Errorf("unexpected peer %#v", peer)
+	}
+	all, err := ctx.Store.GetAllPeers(ctx.Ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 1 || all[0].Name != name || all[0].Active != 1 {
+		t.Errorf("expected one active peer got %#v", all)
+	}
+	err = ctx.Store.AddPeer(ctx.Ctx, "another.example.com", sql.NullInt64{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	list, err := ctx.Store.ListPeers(ctx.Ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].Name != "another.example.com" || len(list[0].PublicKeys) != 0 ||
+		list[1].LastPokeSucceededAt.Int64 != 1000 {
+		t.Errorf("unexpected peers %#v", list)
+	}
+	err = ctx.Store.RemovePeer(ctx.Ctx, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	all, err = ctx.Store.GetAllPeers(ctx.Ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 0 {
+		t.Errorf("expected no peers got %#v", all)
+	}
+}
+
+func testAccounts(t *testing.T, ctx TestContext) {
+	const user = "@erin:example.com"
+	for i := 0; i < 2; i++ {
+		if err := ctx.Store.AddAccount(ctx.Ctx, user); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := ctx.Store.AddAccountToken(ctx.Ctx, user, "erin-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := ctx.Store.GetAccountByToken(ctx.Ctx, "erin-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.UserID != user || a.CreationTS == 0 {
+		t.Errorf("unexpected account %#v", a)
+	}
+	err = ctx.Store.DeleteAccountToken(ctx.Ctx, "erin-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ctx.Store.GetAccountByToken(ctx.Ctx, "erin-token")
+	if err != sql.ErrNoRows {
+		t.Errorf("expected %v got %v", sql.ErrNoRows, err)
+	}
+
+	terms := []models.AcceptedTerms{
+		{Policy: "privacy_policy", Version: "1.0", URL: "https://example.org/privacy-en.html"},
+		{Policy: "privacy_policy", Version: "1.0", URL: "https://example.org/privacy-en.html"},
+	}
+	err = ctx.Store.AddAcceptedTerms(ctx.Ctx, user, terms)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ctx.Store.AddAcceptedTerms(ctx.Ctx, user, terms[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	accepted, err := ctx.Store.GetAcceptedTerms(ctx.Ctx, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(accepted, terms[:1]) {
+		t.Errorf("expected %#v got %#v", terms[:1], accepted)
+	}
+}
+
+func testOnbind(t *testing.T, ctx TestContext) {
+	_, err := ctx.Store.NextOnbindNotificationTS(ctx.Ctx)
+	if err != sql.ErrNoRows {
+		t.Errorf("expected %v got %v", sql.ErrNoRows, err)
+	}
+	first, err := ctx.Store.AddOnbindNotification(ctx.Ctx, "a.example.com", "{}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := ctx.Store.AddOnbindNotification(ctx.Ctx, "b.example.com", "{}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second <= first {
+		t.Errorf("expected increasing ids got %d and %d", first, second)
+	}
+	later := models.Time() + 60000
+	err = ctx.Store.RetryOnbindNotification(ctx.Ctx, first, later, "unavailable")
+	if err != nil {
+		t.Fatal(err)
+	}
+	due, err := ctx.Store.GetDueOnbindNotifications(ctx.Ctx, models.Time(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(due) != 1 || due[0].ID != second {
+		t.Errorf("expected notification %d to be due got %#v", second, due)
+	}
+	err = ctx.Store.DeleteOnbindNotification(ctx.Ctx, second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next, err := ctx.Store.NextOnbindNotificationTS(ctx.Ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != later {
+		t.Errorf("expected %d got %d", later, next)
+	}
+	due, err = ctx.Store.GetDueOnbindNotifications(ctx.Ctx, later, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(due) != 1 || due[0].Attempts != 1 {
+		t.Errorf("expected one failed attempt got %#v", due)
+	}
+}
+
+func testSessions(t *testing.T, ctx TestContext) {
+	s, err := ctx.Store.GetOrCreateTokenSession(ctx.Ctx, "email", "frank@example.com", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.ID == 0 || s.Token == "" || s.SendAttemptNumber != -1 {
+		t.Fatalf("unexpected session %#v", s)
+	}
+	again, err := ctx.Store.GetOrCreateTokenSession(ctx.Ctx, "email", "frank@example.com", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.ID != s.ID || again.Token != s.Token {
+		t.Errorf("expected session %d got %d", s.ID, again.ID)
+	}
+	err = ctx.Store.SetSendAttemptNumber(ctx.Ctx, s.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts, err := ctx.Store.GetTokenSessionByID(ctx.Ctx, s.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ts.Token != s.Token || ts.SendAttemptNumber != 1 {
+		t.Errorf("unexpected token session %#v", ts)
+	}
+	_, err = ctx.Store.GetValidatedSession(ctx.Ctx, s.ID, "secret")
+	if err != models.ErrSessionNotValidated {
+		t.Errorf("expected %v got %v", models.ErrSessionNotValidated, err)
+	}
+	err = ctx.Store.SetValidated(ctx.Ctx, strconv.FormatInt(s.ID, 10), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ctx.Store.SetMtime(ctx.Ctx, s.ID, models.Time())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ctx.Store.GetValidatedSession(ctx.Ctx, s.ID, "wrong")
+	if err != models.ErrIncorrectClientSecret {
+		t.Errorf("expected %v got %v", models.ErrIncorrectClientSecret, err)
+	}
+	v, err := ctx.Store.GetValidatedSession(ctx.Ctx, s.ID, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Validated != 1 {
+		t.Errorf("expected validated session got %#v", v)
+	}
+	_, err = ctx.Store.GetSessionByID(ctx.Ctx, s.ID+1000)
+	if err != sql.ErrNoRows {
+		t.Errorf("expected %v got %v", sql.ErrNoRows, err)
+	}
+}
+
+func testTx(t *testing.T, ctx TestContext) {
+	fail := errors.New("rollback")
+	err := ctx.Store.Tx(ctx.Ctx, func(tx Store) error {
+		err := tx.AddPeer(ctx.Ctx, "rollback.example.com", sql.NullInt64{})
+		if err != nil {
+			return err
+		}
+		return fail
+	})
+	if err != fail {
+		t.Fatalf("expected %v got %v", fail, err)
+	}
+	err = ctx.Store.Tx(ctx.Ctx, func(tx Store) error {
+		err := tx.AddPeer(ctx.Ctx, "commit.example.com", sql.NullInt64{})
+		if err != nil {
+			return err
+		}
+		return tx.SetPeerKey(ctx.Ctx, "commit.example.com", "ed25519", "key")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// statements which need their own transaction join the outer one.
+	err = ctx.Store.Tx(ctx.Ctx, func(tx Store) error {
+		err := tx.RemovePeer(ctx.Ctx, "another.example.com")
+		if err != nil {
+			return err
+		}
+		return fail
+	})
+	if err != fail {
+		t.Fatalf("expected %v got %v", fail, err)
+	}
+	peers, err := ctx.Store.ListPeers(ctx.Ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, p := range peers {
+		names = append(names, p.Name)
+	}
+	expect := []string{"another.example.com", "commit.example.com"}
+	if !reflect.DeepEqual(names, expect) {
+		t.Errorf("expected %v got %v", expect, names)
+	}
+	if len(peers) == 2 && peers[1].PublicKeys["ed25519"] != "key" {
+		t.Errorf("expected the key to be committed got %#v", peers[1])
+	}
+}
+
+func testReap(t *testing.T, ctx TestContext) {
+	var ids []int64
+	for _, address := range []string{"old1@example.com", "old2@example.com", "old3@example.com"} {
+		s, err := ctx.Store.GetOrCreateTokenSession(ctx.Ctx, "email", address, "secret")
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ctx.Store.SetMtime(ctx.Ctx, s.ID, 1000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, s.ID)
+	}
+	fresh, err := ctx.Store.GetOrCreateTokenSession(ctx.Ctx, "email", "fresh@example.com", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := models.Time() - 60000
+	for _, expect := range []int64{2, 1, 0} {
+		sessions, tokenAuths, err := ctx.Store.ReapValidationSessions(ctx.Ctx, before, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sessions != expect || tokenAuths != expect {
+			t.Errorf("expected %d sessions and token auths got %d and %d", expect, sessions, tokenAuths)
+		}
+	}
+	for _, id := range ids {
+		_, err = ctx.Store.GetSessionByID(ctx.Ctx, id)
+		if err != sql.ErrNoRows {
+			t.Errorf("expected session %d to be deleted got %v", id, err)
+		}
+	}
+	_, err = ctx.Store.GetTokenSessionByID(ctx.Ctx, fresh.ID)
+	if err != nil {
+		t.Errorf("expected fresh session to be kept got %v", err)
+	}
+
+	err = ctx.Store.StoreToken(ctx.Ctx, models.InviteToken{
+		Medium:  "email",
+		Address: "reap@example.com",
+		RoomID:  "!room:example.com",
+		Sender:  "@alice:example.com",
+		Token:   "reap",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokens, err := ctx.Store.GetTokens(ctx.Ctx, "email", "reap@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 1 || time.Since(tokens[0].ReceivedAt) > time.Minute {
+		t.Errorf("expected a token received just now got %#v", tokens)
+	}
+	n, err := ctx.Store.ReapInviteTokens(ctx.Ctx, before, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 0 {
+		t.Errorf("expected no expired invite tokens got %d", n)
+	}
+	for {
+		n, err = ctx.Store.ReapInviteTokens(ctx.Ctx, models.Time()+60000, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n > 1 {
+			t.Fatalf("expected at most 1 invite token got %d", n)
+		}
+		if n == 0 {
+			break
+		}
+	}
+	tokens, err = ctx.Store.GetTokens(ctx.Ctx, "email", "reap@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 0 {
+		t.Errorf("expected invite tokens to be deleted got %#v", tokens)
+	}
+
+	err = ctx.Store.StoreEphemeralPublicKey(ctx.Ctx, "reap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err = ctx.Store.ReapEphemeralPublicKeys(ctx.Ctx, models.Time()+60000, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n == 0 {
+		t.Error("expected ephemeral public keys to be deleted")
+	}
+	err = ctx.Store.StoreEphemeralPublicKey(ctx.Ctx, "reap")
+	if err != nil {
+		t.Errorf("expected the deleted key to be stored again got %v", err)
+	}
+}
+
+func testOutbox(t *testing.T, ctx TestContext) {
+	_, err := ctx.Store.NextEmailTS(ctx.Ctx)
+	if err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows for an empty outbox got %v", err)
+	}
+	to := []string{"alice@example.com", "bob@example.com"}
+	id, err := ctx.Store.AddEmail(ctx.Ctx, "sydent@example.org", to, "example.com", "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := ctx.Store.AddEmail(ctx.Ctx, "sydent@example.org", []string{"carol@example.net"}, "example.net", "hi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := models.Time()
+	due, err := ctx.Store.GetDueEmails(ctx.Ctx, now, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(due) != 2 {
+		t.Fatalf("expected 2 due emails got %d", len(due))
+	}
+	e := due[0]
+	if e.ID != id || e.From != "sydent@example.org" || !reflect.DeepEqual(e.To, to) ||
+		e.Domain != "example.com" || e.Message != "hello" || e.State != models.EmailPending {
+		t.Errorf("unexpected email %+v", e)
+	}
+
+	until := now + 60000
+	err = ctx.Store.ClaimEmail(ctx.Ctx, e.ID, e.NextAttempt, until)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ctx.Store.ClaimEmail(ctx.Ctx, e.ID, e.NextAttempt, until)
+	if err != sql.ErrNoRows {
+		t.Errorf("expected claiming twice to fail got %v", err)
+	}
+	due, err = ctx.Store.GetDueEmails(ctx.Ctx, now, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(due) != 1 || due[0].ID != other {
+		t.Errorf("expected only the unclaimed email to be due got %+v", due)
+	}
+	ts, err := ctx.Store.NextEmailTS(ctx.Ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ts != due[0].NextAttempt {
+		t.Errorf("expected next email at %d got %d", due[0].NextAttempt, ts)
+	}
+
+	err = ctx.Store.RetryEmail(ctx.Ctx, id, until, "421 try again")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pending, err := ctx.Store.ListEmails(ctx.Ctx, models.EmailPending, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 2 || pending[0].Attempts != 1 || pending[0].LastError != "421 try again" || pending[0].NextAttempt != until {
+		t.Errorf("unexpected pending emails %+v", pending)
+	}
+
+	err = ctx.Store.DeadEmail(ctx.Ctx, id, "550 no such user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dead, err := ctx.Store.ListEmails(ctx.Ctx, models.EmailDead, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dead) != 1 || dead[0].ID != id || dead[0].Attempts != 2 || dead[0].LastError != "550 no such user" {
+		t.Errorf("unexpected dead letters %+v", dead)
+	}
+	due, err = ctx.Store.GetDueEmails(ctx.Ctx, until+1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(due) != 1 || due[0].ID != other {
+		t.Errorf("expected dead letters not to be due got %+v", due)
+	}
+
+	err = ctx.Store.RequeueEmail(ctx.Ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	due, err = ctx.Store.GetDueEmails(ctx.Ctx, models.Time(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(due) != 2 || due[0].Attempts != 0 || due[0].State != models.EmailPending {
+		t.Errorf("expected requeued email to be due got %+v", due)
+	}
+
+	for _, id := range []int64{id, other} {
+		err = ctx.Store.DeleteEmail(ctx.Ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ctx.Store.DeleteEmail(ctx.Ctx, id); err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows deleting a missing email got %v", err)
+	}
+	if err := ctx.Store.RequeueEmail(ctx.Ctx, id); err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows requeueing a missing email got %v", err)
+	}
+}
+
+func testRateLimits(t *testing.T, ctx TestContext) {
+	const burst, interval, now = 2, 1000, 1000000
+	take := func(key string, now, expect int64) {
+		t.Helper()
+		retry, err := ctx.Store.TakeRateLimitToken(ctx.Ctx, key, burst, interval, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if retry != expect {
+			t.Errorf("%s at %d: expected retry after %d got %d", key, now, expect, retry)
+		}
+	}
+	take("ip:127.0.0.1", now, 0)
+	take("ip:127.0.0.1", now, 0)
+	take("ip:127.0.0.1", now, 1000)
+	take("ip:127.0.0.1", now+400, 600)
+	take("address:alice@example.com", now, 0)
+	take("ip:127.0.0.1", now+1000, 0)
+	take("ip:127.0.0.1", now+1500, 500)
+
+	// a bucket never holds more than burst tokens
+	take("address:alice@example.com", now+100000, 0)
+	take("address:alice@example.com", now+100000, 0)
+	take("address:alice@example.com", now+100000, 1000)
+
+	n, err := ctx.Store.ReapRateLimits(ctx.Ctx, now+50000, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("expected 1 reaped bucket got %d", n)
+	}
+	take("ip:127.0.0.1", now+1500, 0)
+
+	takeAll := func(keys []string, now, expect int64) {
+		t.Helper()
+		var buckets []RateLimitBucket
+		for _, k := range keys {
+			buckets = append(buckets, RateLimitBucket{Key: k, Burst: burst, Interval: interval})
+		}
+		retry, err := ctx.Store.TakeRateLimitTokens(ctx.Ctx, buckets, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if retry != expect {
+			t.Errorf("%v at %d: expected retry after %d got %d", keys, now, expect, retry)
+		}
+	}
+	takeAll([]string{"ip:10.0.0.1", "address:bob@example.com"}, now, 0)
+	takeAll([]string{"ip:10.0.0.2", "address:bob@example.com"}, now, 0)
+	// a denied request takes no token from the other buckets
+	takeAll([]string{"ip:10.0.0.1", "address:bob@example.com"}, now, 1000)
+	takeAll([]string{"ip:10.0.0.1", "address:bob@example.com"}, now+400, 600)
+	take("ip:10.0.0.1", now, 0)
+	take("ip:10.0.0.1", now, 1000)
+
+	// concurrent requests never take more than burst tokens
+	const requests = 20
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var taken int
+	var denied []int
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			retry, err := ctx.Store.TakeRateLimitTokens(ctx.Ctx, []RateLimitBucket{
+				{Key: "ip:10.0.0.3", Burst: burst, Interval: interval},
+				{Key: fmt.Sprintf("address:%d@example.com", i), Burst: burst, Interval: interval},
+			}, now)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if retry == 0 {
+				taken++
+			} else {
+				denied = append(denied, i)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if taken != burst {
+		t.Errorf("expected %d concurrent requests to take tokens got %d", burst, taken)
+	}
+	for _, i := range denied {
+		key := fmt.Sprintf("address:%d@example.com", i)
+		take(key, now, 0)
+		take(key, now, 0)
+	}
+}
+
+// testCanonicalAddresses checks that addresses are matched in their canonical
+// form on every path, whatever form they were written in.
+func testCanonicalAddresses(t *testing.T, ctx TestContext) {
+	now := models.Time()
+	erin := &models.Association{
+		Medium:    "email",
+		Address:   "Erin@XN--BCHER-KVA.de",
+		MatrixID:  "@erin:example.com",
+		Timestamp: now,
+		NotBefore: now - 1000,
+		NotAfter:  now + 100000,
+	}
+	err := ctx.Store.LocalAddOrUpdateAssociation(ctx.Ctx, erin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if erin.Address != "erin@bücher.de" {
+		t.Errorf("expected canonical address got %s", erin.Address)
+	}
+	err = ctx.Store.GlobalAddAssociation(ctx.Ctx, &models.Association{
+		Medium:    "email",
+		Address:   "ERIN@Bücher.de",
+		MatrixID:  erin.MatrixID,
+		Timestamp: now,
+		NotBefore: now - 1000,
+		NotAfter:  now + 100000,
+	}, "canonical.example.com", 1, `{}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mxid, err := ctx.Store.GlobalGetMxid(ctx.Ctx, "email", "erin@xn--bcher-kva.de")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mxid != erin.MatrixID {
+		t.Errorf("expected %s got %s", erin.MatrixID, mxid)
+	}
+	found, err := ctx.Store.GlobalGetMxids(ctx.Ctx, [][]string{{"email", " Erin@bücher.DE"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0].Address != "erin@bücher.de" {
+		t.Errorf("expected the association of erin got %#v", found)
+	}
+	err = ctx.Store.GlobalRemoveAssociation(ctx.Ctx, "email", "ERIN@XN--BCHER-KVA.DE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ctx.Store.GlobalGetMxid(ctx.Ctx, "email", erin.Address)
+	if err != sql.ErrNoRows {
+		t.Errorf("expected %v got %v", sql.ErrNoRows, err)
+	}
+	err = ctx.Store.LocalRemoveAssociation(ctx.Ctx, &models.Association{
+		Medium: "email", Address: "erin@BÜCHER.de", MatrixID: erin.MatrixID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	as, err := ctx.Store.GetAssociationsAfterID(ctx.Ctx, -1, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := as[len(as)-1]; last.Address != erin.Address || last.MatrixID != "" {
+		t.Errorf("expected the removal of erin got %#v", last)
+	}
+
+	err = ctx.Store.StoreToken(ctx.Ctx, models.InviteToken{
+		Medium: "msisdn", Address: "+44 7700 900123", RoomID: "!room:example.com",
+		Sender: "@bob:example.com", Token: "canonical",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokens, err := ctx.Store.GetTokens(ctx.Ctx, "msisdn", "447700900123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 1 || tokens[0].Address != "447700900123" {
+		t.Errorf("expected the invite token got %#v", tokens)
+	}
+	s1, err := ctx.Store.GetOrCreateTokenSession(ctx.Ctx, "email", "Frank@Example.com", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s2, err := ctx.Store.GetOrCreateTokenSession(ctx.Ctx, "email", "frank@example.COM", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s1.ID != s2.ID || s2.Address != "frank@example.com" {
+		t.Errorf("expected the same session got %#v and %#v", s1, s2)
+	}
+}
+
+func testAudit(t *testing.T, ctx TestContext) {
+	all, err := ctx.Store.AuditLog(ctx.Ctx, models.AuditQuery{Limit: 1 << 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lastID int64
+	if len(all) > 0 {
+		lastID = all[len(all)-1].ID
+	}
+	// sids come from requests, the length of actor ids is not bounded
+	session := models.Actor{Kind: models.ActorSession, ID: strings.Repeat("42", 200), IP: "10.0.0.1"}
+	server := models.Actor{Kind: models.ActorServer, ID: "example.com"}
+	peer := models.Actor{Kind: models.ActorPeer, ID: "peer.example.com"}
+	admin := models.Actor{Kind: models.ActorAdmin, IP: "10.0.0.2"}
+	as := func() *models.Association {
+		return &models.Association{
+			Medium:    "email",
+			Address:   "audit@example.com",
+			MatrixID:  "@audit:example.com",
+			Timestamp: 1000,
+			NotBefore: 1000,
+			NotAfter:  2000,
+		}
+	}
+	steps := []struct {
+		actor models.Actor
+		fn    func(c context.Context) error
+	}{
+		{session, func(c context.Context) error {
+			return ctx.Store.LocalAddOrUpdateAssociation(c, as())
+		}},
+		// unbinding another mxid changes nothing.
+		{server, func(c context.Context) error {
+			a := as()
+			a.MatrixID = "@other:example.com"
+			return ctx.Store.LocalRemoveAssociation(c, a)
+		}},
+		{server, func(c context.Context) error {
+			return ctx.Store.LocalRemoveAssociation(c, as())
+		}},
+		{peer, func(c context.Context) error {
+			return ctx.Store.GlobalAddAssociation(c, as(), "peer.example.com", 7, "{}")
+		}},
+		// associations which were replicated already are ignored.
+		{peer, func(c context.Context) error {
+			return ctx.Store.GlobalAddAssociation(c, as(), "peer.example.com", 7, "{}")
+		}},
+		{peer, func(c context.Context) error {
+			return ctx.Store.GlobalRemoveAssociation(c, "email", "audit@example.com")
+		}},
+		{session, func(c context.Context) error {
+			return ctx.Store.StoreToken(c, models.InviteToken{
+				Medium:  "email",
+				Address: "audit@example.com",
+				RoomID:  "!room:example.com",
+				Sender:  "@sender:example.com",
+				Token:   "secret-invite-token",
+			})
+		}},
+		{session, func(c context.Context) error {
+			return ctx.Store.MarkTokensAsSent(c, "email", "audit@example.com")
+		}},
+		{session, func(c context.Context) error {
+			return ctx.Store.MarkTokensAsSent(c, "email", "audit@example.com")
+		}},
+		{admin, func(c context.Context) error {
+			return ctx.Store.AddPeer(c, "audit.example.com", sql.NullInt64{})
+		}},
+		{admin, func(c context.Context) error {
+			return ctx.Store.SetPeerActive(c, "audit.example.com", true)
+		}},
+		{admin, func(c context.Context) error {
+			return ctx.Store.RemovePeer(c, "audit.example.com")
+		}},
+		// failed and rolled back changes are not recorded.
+		{admin, func(c context.Context) error {
+			if err := ctx.Store.SetPeerActive(c, "audit.example.com", true); err != sql.ErrNoRows {
+				return fmt.Errorf("expected %v got %v", sql.ErrNoRows, err)
+			}
+			return nil
+		}},
+		{admin, func(c context.Context) error {
+			fail := errors.New("rollback")
+			err := ctx.Store.Tx(c, func(tx Store) error {
+				if err := tx.AddPeer(c, "rollback.example.com", sql.NullInt64{}); err != nil {
+					return err
+				}
+				return fail
+			})
+			if err != fail {
+				return fmt.Errorf("expected %v got %v", fail, err)
+			}
+			return nil
+		}},
+	}
+	for i, s := range steps {
+		if err := s.fn(models.WithActor(ctx.Ctx, s.actor)); err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+	}
+	entries, err := ctx.Store.AuditLog(ctx.Ctx, models.AuditQuery{AfterID: lastID, Limit: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actions []string
+	for _, e := range entries {
+		actions = append(actions, e.Action)
+	}
+	expect := []string{
+		models.AuditBind, models.AuditUnbind, models.AuditGlobalAdd, models.AuditGlobalRemove,
+		models.AuditInviteStore, models.AuditInviteSent,
+		models.AuditPeerAdd, models.AuditPeerActive, models.AuditPeerRemove,
+	}
+	if !reflect.DeepEqual(actions, expect) {
+		t.Fatalf("expected %v got %v", expect, actions)
+	}
+	bind := entries[0]
+	if bind.Actor != session || bind.MatrixID != "@audit:example.com" ||
+		len(bind.Before) != 0 || !strings.Contains(string(bind.After), `"mxid":"@audit:example.com"`) {
+		t.Errorf("unexpected bind entry %#v", bind)
+	}
+	unbind := entries[1]
+	if unbind.Actor != server || !strings.Contains(string(unbind.Before), `"mxid":"@audit:example.com"`) ||
+		strings.Contains(string(unbind.After), "mxid") {
+		t.Errorf("unexpected unbind entry %s %s", unbind.Before, unbind.After)
+	}
+	if remove := entries[3]; remove.Actor != peer ||
+		!strings.Contains(string(remove.Before), `"origin_server":"peer.example.com"`) || len(remove.After) != 0 {
+		t.Errorf("unexpected global remove entry %s %s", remove.Before, remove.After)
+	}
+	for _, e := range entries[4:6] {
+		if strings.Contains(string(e.Before)+string(e.After), "secret-invite-token") {
+			t.Errorf("expected the invite token to be left out got %s %s", e.Before, e.After)
+		}
+	}
+	if sent := entries[5]; !strings.Contains(string(sent.After), "sent_ts") ||
+		strings.Contains(string(sent.Before), "sent_ts") {
+		t.Errorf("unexpected invite sent entry %s %s", sent.Before, sent.After)
+	}
+	if removed := entries[8]; removed.Peer != "audit.example.com" ||
+		!strings.Contains(string(removed.Before), `"active":true`) || len(removed.After) != 0 {
+		t.Errorf("unexpected peer remove entry %#v", removed)
+	}
+
+	for _, v := range []struct {
+		q      models.AuditQuery
+		expect int
+	}{
+		{models.AuditQuery{Medium: "email", Address: "Audit@Example.com"}, 6},
+		{models.AuditQuery{MatrixID: "@audit:example.com"}, 4},
+		{models.AuditQuery{Peer: "audit.example.com"}, 3},
+		{models.AuditQuery{Action: models.AuditUnbind}, 1},
+		{models.AuditQuery{Since: entries[0].TS, Until: entries[0].TS}, 0},
+		{models.AuditQuery{Limit: 2}, 2},
+		{models.AuditQuery{AfterID: entries[7].ID}, 1},
+	} {
+		if v.q.AfterID == 0 {
+			v.q.AfterID = lastID
+		}
+		if v.q.Limit == 0 {
+			v.q.Limit = 100
+		}
+		o, err := ctx.Store.AuditLog(ctx.Ctx, v.q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(o) != v.expect {
+			t.Errorf("%+v: expected %d entries got %d", v.q, v.expect, len(o))
+		}
+	}
+}
+
+// erasedSubjects are the addresses, matrix id and client ip erased by
+// testSubjects. No row has them afterwards, but the removals of the local
+// associations which replicate the erasure.
+var erasedSubjects = []string{"gdpr@example.com", "gdpr-peer@example.com", "@gdpr:example.com", "10.0.0.7"}
+
+func testSubjects(t *testing.T, ctx TestContext) {
+	mxid := "@gdpr:example.com"
+	bind := func(address, mxid, ip string) {
+		c := models.WithActor(ctx.Ctx, models.Actor{Kind: models.ActorSession, ID: "1", IP: ip})
+		err := ctx.Store.LocalAddOrUpdateAssociation(c, &models.Association{
+			Medium:    "email",
+			Address:   address,
+			MatrixID:  mxid,
+			Timestamp: 1000,
+			NotBefore: 1000,
+			NotAfter:  2000,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	replicate := func(address, mxid, origin string, originID int64) {
+		err := ctx.Store.GlobalAddAssociation(ctx.Ctx, &models.Association{
+			Medium:    "email",
+			Address:   address,
+			MatrixID:  mxid,
+			Timestamp: 1000,
+			NotBefore: 1000,
+			NotAfter:  2000,
+		}, origin, originID, "{}")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	bind("gdpr@example.com", mxid, "10.0.0.7")
+	replicate("gdpr@example.com", mxid, "gdpr.example.com", 1)
+	replicate("gdpr-peer@example.com", mxid, "peer.example.com", 101)
+	// shared@example.com is bound to another mxid on a peer, erasing mxid
+	// leaves it alone.
+	bind("shared@example.com", mxid, "10.0.0.7")
+	replicate("shared@example.com", "@other:example.com", "peer.example.com", 102)
+	bind("other@example.com", "@other:example.com", "10.0.0.8")
+	// an invite for someone else stored by the erased mxid
+	account := models.WithActor(ctx.Ctx, models.Actor{Kind: models.ActorAccount, ID: mxid, IP: "10.0.0.7"})
+	err := ctx.Store.StoreToken(account, models.InviteToken{
+		Medium:  "email",
+		Address: "invitee@example.com",
+		RoomID:  "!room:example.com",
+		Sender:  "@sender:example.com",
+		Token:   "gdpr-invitee-token",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ctx.Store.StoreToken(ctx.Ctx, models.InviteToken{
+		Medium:  "email",
+		Address: "gdpr@example.com",
+		RoomID:  "!room:example.com",
+		Sender:  "@sender:example.com",
+		Token:   "gdpr-invite-token",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	session, err := ctx.Store.GetOrCreateTokenSession(ctx.Ctx, "email", "gdpr@example.com", "gdpr-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ctx.Store.SetValidated(ctx.Ctx, strconv.FormatInt(session.ID, 10), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, to := range []string{"GDPR@example.com", "other@example.com"} {
+		_, err = ctx.Store.AddEmail(ctx.Ctx, "noreply@example.com", []string{to}, "example.com", "To: "+to+"\r\n\r\nhello")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := ctx.Store.ExportSubject(ctx.Ctx, models.Subject{Medium: "email"}); err == nil {
+		t.Error("expected an error for a subject without address")
+	}
+	count := func(e *models.SubjectExport) []int {
+		return []int{
+			len(e.LocalAssociations), len(e.GlobalAssociations),
+			len(e.InviteTokens), len(e.ValidationSessions), len(e.Emails),
+		}
+	}
+	export, err := ctx.Store.ExportSubject(ctx.Ctx, models.Subject{MatrixID: mxid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := count(export); !reflect.DeepEqual(c, []int{2, 2, 1, 1, 1}) {
+		t.Errorf("expected 2 local, 2 global associations, 1 invite, 1 session and 1 email got %v", c)
+	}
+	if m := export.Emails[0]; m.To[0] != "GDPR@example.com" || !strings.Contains(m.Message, "hello") {
+		t.Errorf("unexpected email %+v", m)
+	}
+	if s := export.ValidationSessions[0]; s.ID != session.ID || !s.Validated {
+		t.Errorf("unexpected session %+v", s)
+	}
+	if len(export.Audit) == 0 {
+		t.Error("expected audit log entries")
+	}
+	for _, e := range export.Audit {
+		if e.Address == "other@example.com" {
+			t.Errorf("expected entries of other subjects to be left out got %+v", e)
+		}
+	}
+	b, err := json.Marshal(export)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "gdpr-invite-token") || strings.Contains(string(b), "gdpr-secret") {
+		t.Errorf("expected secrets to be left out got %s", b)
+	}
+	export, err = ctx.Store.ExportSubject(ctx.Ctx, models.Subject{Medium: "email", Address: "GDPR@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := count(export); !reflect.DeepEqual(c, []int{1, 1, 1, 1, 1}) {
+		t.Errorf("expected 1 local, 1 global association, 1 invite, 1 session and 1 email got %v", c)
+	}
+
+	admin := models.WithActor(ctx.Ctx, models.Actor{Kind: models.ActorAdmin})
+	erasure, err := ctx.Store.EraseSubject(admin, models.Subject{MatrixID: mxid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := &models.SubjectErasure{
+		Subject: models.Subject{MatrixID: mxid},
+		Erased: []models.Subject{
+			{Medium: "email", Address: "gdpr-peer@example.com"},
+			{Medium: "email", Address: "gdpr@example.com"},
+		},
+		Skipped: []models.Subject{
+			{Medium: "email", Address: "shared@example.com"},
+		},
+		LocalAssociations:  2,
+		GlobalAssociations: 2,
+		InviteTokens:       1,
+		ValidationSessions: 1,
+		Emails:             1,
+	}
+	if erasure.Audit == 0 {
+		t.Error("expected audit log entries to be redacted")
+	}
+	expect.Audit = erasure.Audit
+	if !reflect.DeepEqual(erasure, expect) {
+		t.Errorf("expected %+v got %+v", expect, erasure)
+	}
+	for _, address := range []string{"gdpr@example.com", "gdpr-peer@example.com"} {
+		export, err = ctx.Store.ExportSubject(ctx.Ctx, models.Subject{Medium: "email", Address: address})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c := count(export); !reflect.DeepEqual(c, []int{1, 0, 0, 0, 0}) || len(export.Audit) != 0 {
+			t.Errorf("%s: expected only the removal of the local association got %v %+v", address, c, export.Audit)
+		}
+		if a := export.LocalAssociations[0]; a.MatrixID != "" {
+			t.Errorf("%s: expected a removal got %+v", address, a)
+		}
+	}
+	// the removals are replicated like unbinds.
+	local, err := ctx.Store.GetAssociationsAfterID(ctx.Ctx, 0, 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	removed := 0
+	for _, a := range local[len(local)-2:] {
+		if strings.HasPrefix(a.Address, "gdpr") && a.MatrixID == "" {
+			removed++
+		}
+	}
+	if removed != 2 {
+		t.Errorf("expected the last local associations to be the removals got %+v", local[len(local)-2:])
+	}
+	for _, v := range []struct {
+		address string
+		expect  []int
+	}{
+		{"shared@example.com", []int{1, 1, 0, 0, 0}},
+		{"other@example.com", []int{1, 0, 0, 0, 1}},
+	} {
+		export, err = ctx.Store.ExportSubject(ctx.Ctx, models.Subject{Medium: "email", Address: v.address})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c := count(export); !reflect.DeepEqual(c, v.expect) || export.LocalAssociations[0].MatrixID == "" {
+			t.Errorf("%s: expected the associations to be kept got %v %+v", v.address, c, export.LocalAssociations)
+		}
+	}
+	entries, err := ctx.Store.AuditLog(ctx.Ctx, models.AuditQuery{Action: models.AuditErase, Limit: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 erase entries got %d", len(entries))
+	}
+	for _, e := range entries {
+		if e.Actor.Kind != models.ActorAdmin || e.Medium != "email" || e.Address != "" || e.MatrixID != "" ||
+			!strings.Contains(string(e.Before), `"global_associations":1`) || len(e.After) != 0 {
+			t.Errorf("unexpected erase entry %+v %s", e, e.Before)
+		}
+	}
+	all, err := ctx.Store.AuditLog(ctx.Ctx, models.AuditQuery{Limit: 1 << 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	emails, err := ctx.Store.ListEmails(ctx.Ctx, models.EmailPending, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []interface{}{all, emails} {
+		b, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, erased := range erasedSubjects {
+			if strings.Contains(strings.ToLower(string(b)), erased) {
+				t.Errorf("expected %s to be erased got %s", erased, b)
+			}
+		}
+	}
+	if len(emails) != 1 || emails[0].To[0] != "other@example.com" {
+		t.Errorf("expected the email to other@example.com to be kept got %+v", emails)
+	}
+
+	// erasing again changes nothing.
+	erasure, err = ctx.Store.EraseSubject(admin, models.Subject{Medium: "email", Address: "gdpr@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(erasure.Erased) != 0 {
+		t.Errorf("expected nothing to be erased got %+v", erasure)
+	}
+	erasure, err = ctx.Store.EraseSubject(admin, models.Subject{Medium: "email", Address: "Shared@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(erasure.Erased) != 1 || erasure.LocalAssociations != 1 || erasure.GlobalAssociations != 1 {
+		t.Errorf("expected the address to be erased got %+v", erasure)
+	}
+}
@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/gernest/sydent-go/models"
@@ -43,186 +44,472 @@ func (m Metric) observe(labelStr string, fn func()) {
 }
 
 // Identity contains all identity service database facing routines.
+//
+// Addresses are converted to their canonical form before they are stored or
+// looked up, see Canonical.
 type Identity struct {
-	db      models.Query
-	metrics Metric
+	db        models.Query
+	driver    Driver
+	metrics   Metric
+	canonical *models.Canonicalizer
 }
 
-func New(db models.Query, m Metric) *Identity {
-	return &Identity{db: db, metrics: m}
+func New(db models.Query, driver Driver, m Metric) *Identity {
+	return &Identity{db: db, driver: driver, metrics: m}
 }
 
 func (id *Identity) StoreToken(ctx context.Context, token models.InviteToken) (err error) {
 	id.metrics.observe("store_token", func() {
-		err = StoreToken(ctx, id.db, token)
+		token.Address = id.canonical.Address(token.Medium, token.Address)
+		err = StoreToken(ctx, id.db, id.driver, token)
 	})
 	return
 }
 
 func (id *Identity) GetTokens(ctx context.Context, medium, address string) (tokens []models.InviteToken, err error) {
+	address = id.canonical.Address(medium, address)
 	id.metrics.observe("get_tokens", func() {
-		tokens, err = GetTokens(ctx, id.db, medium, address)
+		tokens, err = GetTokens(ctx, id.db, id.driver, medium, address)
 	})
 	return
 }
 
 func (id *Identity) MarkTokensAsSent(ctx context.Context, medium, address string) (err error) {
+	address = id.canonical.Address(medium, address)
 	id.metrics.observe("mark_tokens_as_sent", func() {
-		err = MarkTokensAsSent(ctx, id.db, medium, address)
+		err = MarkTokensAsSent(ctx, id.db, id.driver, medium, address)
 	})
 	return
 }
 
 func (id *Identity) StoreEphemeralPublicKey(ctx context.Context, publicKey string) (err error) {
 	id.metrics.observe("store_ephemeral_public_key", func() {
-		err = StoreEphemeralPublicKey(ctx, id.db, publicKey)
+		err = StoreEphemeralPublicKey(ctx, id.db, id.driver, publicKey)
 	})
 	return
 }
 
 func (id *Identity) ValidateEphemeralPublicKey(ctx context.Context, publicKey string) (err error) {
 	id.metrics.observe("store_ephemeral_public_key", func() {
-		err = ValidateEphemeralPublicKey(ctx, id.db, publicKey)
+		err = ValidateEphemeralPublicKey(ctx, id.db, id.driver, publicKey)
 	})
 	return
 }
 
 func (id *Identity) GetSenderForToken(ctx context.Context, token string) (tokenInfo string, err error) {
 	id.metrics.observe("get_sender_for_token", func() {
-		tokenInfo, err = GetSenderForToken(ctx, id.db, token)
+		tokenInfo, err = GetSenderForToken(ctx, id.db, id.driver, token)
 	})
 	return
 }
 
 func (id *Identity) SignedAssociationStringForThreepid(ctx context.Context, medium, address string) (ass string, err error) {
+	address = id.canonical.Address(medium, address)
 	id.metrics.observe("signed_association_string_for_threepid", func() {
-		ass, err = SignedAssociationStringForThreepid(ctx, id.db, medium, address)
+		ass, err = SignedAssociationStringForThreepid(ctx, id.db, id.driver, medium, address)
 	})
 	return
 }
 
 func (id *Identity) GlobalGetMxid(ctx context.Context, medium, address string) (mxid string, err error) {
+	address = id.canonical.Address(medium, address)
 	id.metrics.observe("global_get_mxid", func() {
-		mxid, err = GlobalGetMxid(ctx, id.db, medium, address)
+		mxid, err = GlobalGetMxid(ctx, id.db, id.driver, medium, address)
 	})
 	return
 }
 
 func (id *Identity) GetPeerByName(ctx context.Context, name string) (peer *models.Peer, err error) {
 	id.metrics.observe("get_peer_by_name", func() {
-		peer, err = GetPeerByName(ctx, id.db, name)
+		peer, err = GetPeerByName(ctx, id.db, id.driver, name)
 	})
 	return
 }
 
 func (id *Identity) GetAllPeers(ctx context.Context) (peers []models.Peer, err error) {
 	id.metrics.observe("get_all_peers", func() {
-		peers, err = GetAllPeers(ctx, id.db)
+		peers, err = GetAllPeers(ctx, id.db, id.driver)
 	})
 	return
 }
 
-func (id *Identity) SetLastSentVersionAndPokeSucceeded(ctx context.Context, peerName, lastSentVersion, lastPokeSucceeded string) (err error) {
+func (id *Identity) SetLastSentVersionAndPokeSucceeded(ctx context.Context, peerName string, lastSentVersion, lastPokeSucceeded int64) (err error) {
 	id.metrics.observe("set_last_sent_version_and_poke_succeeded", func() {
-		err = SetLastSentVersionAndPokeSucceeded(ctx, id.db, peerName, lastSentVersion, lastPokeSucceeded)
+		err = SetLastSentVersionAndPokeSucceeded(ctx, id.db, id.driver, peerName, lastSentVersion, lastPokeSucceeded)
+	})
+	return
+}
+
+func (id *Identity) ListPeers(ctx context.Context) (peers []models.Peer, err error) {
+	id.metrics.observe("list_peers", func() {
+		peers, err = ListPeers(ctx, id.db, id.driver)
+	})
+	return
+}
+
+func (id *Identity) AddPeer(ctx context.Context, name string, port sql.NullInt64) (err error) {
+	id.metrics.observe("add_peer", func() {
+		err = AddPeer(ctx, id.db, id.driver, name, port)
+	})
+	return
+}
+
+func (id *Identity) RemovePeer(ctx context.Context, name string) (err error) {
+	id.metrics.observe("remove_peer", func() {
+		err = RemovePeer(ctx, id.db, id.driver, name)
+	})
+	return
+}
+
+func (id *Identity) SetPeerKey(ctx context.Context, name, alg, key string) (err error) {
+	id.metrics.observe("set_peer_key", func() {
+		err = SetPeerKey(ctx, id.db, id.driver, name, alg, key)
+	})
+	return
+}
+
+func (id *Identity) SetPeerActive(ctx context.Context, name string, active bool) (err error) {
+	id.metrics.observe("set_peer_active", func() {
+		err = SetPeerActive(ctx, id.db, id.driver, name, active)
+	})
+	return
+}
+
+func (id *Identity) CountAssociationsAfterID(ctx context.Context, afterID int64) (n int64, err error) {
+	id.metrics.observe("count_associations_after_id", func() {
+		n, err = CountAssociationsAfterID(ctx, id.db, id.driver, afterID)
 	})
 	return
 }
 
 func (id *Identity) SetSendAttemptNumber(ctx context.Context, sid int64, attemptNo int64) (err error) {
 	id.metrics.observe("set_send_attempt_number", func() {
-		err = SetSendAttemptNumber(ctx, id.db, sid, attemptNo)
+		err = SetSendAttemptNumber(ctx, id.db, id.driver, sid, attemptNo)
 	})
 	return
 }
 
 func (id *Identity) SetValidated(ctx context.Context, sid string, validated int) (err error) {
 	id.metrics.observe("set_validated", func() {
-		err = SetValidated(ctx, id.db, sid, validated)
+		err = SetValidated(ctx, id.db, id.driver, sid, validated)
 	})
 	return
 }
 
 func (id *Identity) SetMtime(ctx context.Context, sid int64, mtime int64) (err error) {
 	id.metrics.observe("set_mtime", func() {
-		err = SetMtime(ctx, id.db, sid, mtime)
+		err = SetMtime(ctx, id.db, id.driver, sid, mtime)
 	})
 	return
 }
 
 func (id *Identity) GetSessionByID(ctx context.Context, sid int64) (session *models.ValidationSession, err error) {
 	id.metrics.observe("get_session_by_id", func() {
-		session, err = GetSessionByID(ctx, id.db, sid)
+		session, err = GetSessionByID(ctx, id.db, id.driver, sid)
 	})
 	return
 }
 
 func (id *Identity) GetValidatedSession(ctx context.Context, sid int64, clientSecret string) (session *models.ValidationSession, err error) {
 	id.metrics.observe("get_validated_session", func() {
-		session, err = GetValidatedSession(ctx, id.db, sid, clientSecret)
+		session, err = GetValidatedSession(ctx, id.db, id.driver, sid, clientSecret)
 	})
 	return
 }
 
 func (id *Identity) GetTokenSessionByID(ctx context.Context, sid int64) (tokenSession *models.TokenSession, err error) {
 	id.metrics.observe("get_token_session_by_id", func() {
-		tokenSession, err = GetTokenSessionByID(ctx, id.db, sid)
+		tokenSession, err = GetTokenSessionByID(ctx, id.db, id.driver, sid)
 	})
 	return
 }
 
 func (id *Identity) GlobalGetMxids(ctx context.Context, ids [][]string) (mxids []models.Association, err error) {
 	id.metrics.observe("global_get_mxids", func() {
-		mxids, err = GlobalGetMxids(ctx, id.db, ids)
+		mxids, err = GlobalGetMxids(ctx, id.db, id.driver, canonicalIDs(id.canonical, ids))
 	})
 	return
 }
 
 func (id *Identity) GlobalLastIDFromServer(ctx context.Context, originServer string) (lastID int64, err error) {
 	id.metrics.observe("global_last_id_from_server", func() {
-		lastID, err = GlobalLastIDFromServer(ctx, id.db, originServer)
+		lastID, err = GlobalLastIDFromServer(ctx, id.db, id.driver, originServer)
 	})
 	return
 }
 
 func (id *Identity) GlobalAddAssociation(ctx context.Context, as *models.Association, originServer string, originID int64, rawSgnAssoc string) (err error) {
+	as.Address = id.canonical.Address(as.Medium, as.Address)
 	id.metrics.observe("global_add_association", func() {
-		err = GlobalAddAssociation(ctx, id.db, as, originServer, originID, rawSgnAssoc)
+		err = GlobalAddAssociation(ctx, id.db, id.driver, as, originServer, originID, rawSgnAssoc)
 	})
 	return
 }
 
 func (id *Identity) GlobalRemoveAssociation(ctx context.Context, medium, address string) (err error) {
+	address = id.canonical.Address(medium, address)
 	id.metrics.observe("global_remove_association", func() {
-		err = GlobalRemoveAssociation(ctx, id.db, medium, address)
+		err = GlobalRemoveAssociation(ctx, id.db, id.driver, medium, address)
 	})
 	return
 }
 
 func (id *Identity) LocalAddOrUpdateAssociation(ctx context.Context, as *models.Association) (err error) {
+	as.Address = id.canonical.Address(as.Medium, as.Address)
 	id.metrics.observe("local_add_or_update_association", func() {
-		err = LocalAddOrUpdateAssociation(ctx, id.db, as)
+		err = LocalAddOrUpdateAssociation(ctx, id.db, id.driver, as)
 	})
 	return
 }
 
 func (id *Identity) LocalRemoveAssociation(ctx context.Context, as *models.Association) (err error) {
+	as.Address = id.canonical.Address(as.Medium, as.Address)
 	id.metrics.observe("local_remove_association", func() {
-		err = LocalRemoveAssociation(ctx, id.db, as)
+		err = LocalRemoveAssociation(ctx, id.db, id.driver, as)
 	})
 	return
 }
 
 func (id *Identity) GetAssociationsAfterID(ctx context.Context, afterID int64, limit int64) (as []models.Association, err error) {
 	id.metrics.observe("local_get_association_after_id", func() {
-		as, err = GetAssociationsAfterID(ctx, id.db, afterID, limit)
+		as, err = GetAssociationsAfterID(ctx, id.db, id.driver, afterID, limit)
 	})
 	return
 }
 
 func (id *Identity) GetOrCreateTokenSession(ctx context.Context, medium, address, clientSecret string) (session *models.ValidationSession, err error) {
+	address = id.canonical.Address(medium, address)
 	id.metrics.observe("get_or_create_token_session", func() {
-		session, err = GetOrCreateTokenSession(ctx, id.db, medium, address, clientSecret)
+		session, err = GetOrCreateTokenSession(ctx, id.db, id.driver, medium, address, clientSecret)
+	})
+	return
+}
+
+func (id *Identity) GetLookupPepper(ctx context.Context) (pepper string, err error) {
+	id.metrics.observe("get_lookup_pepper", func() {
+		pepper, err = GetLookupPepper(ctx, id.db, id.driver)
+	})
+	return
+}
+
+func (id *Identity) RotateLookupPepper(ctx context.Context, pepper string) (err error) {
+	id.metrics.observe("rotate_lookup_pepper", func() {
+		err = RotateLookupPepper(ctx, id.db, id.driver, pepper)
+	})
+	return
+}
+
+func (id *Identity) GlobalGetMxidsByHash(ctx context.Context, hashes []string) (mxids map[string]string, err error) {
+	id.metrics.observe("global_get_mxids_by_hash", func() {
+		mxids, err = GlobalGetMxidsByHash(ctx, id.db, id.driver, hashes)
+	})
+	return
+}
+
+func (id *Identity) AddAccount(ctx context.Context, userID string) (err error) {
+	id.metrics.observe("add_account", func() {
+		err = AddAccount(ctx, id.db, id.driver, userID)
+	})
+	return
+}
+
+func (id *Identity) AddAccountToken(ctx context.Context, userID, token string) (err error) {
+	id.metrics.observe("add_account_token", func() {
+		err = AddAccountToken(ctx, id.db, id.driver, userID, token)
+	})
+	return
+}
+
+func (id *Identity) GetAccountByToken(ctx context.Context, token string) (account *models.Account, err error) {
+	id.metrics.observe("get_account_by_token", func() {
+		account, err = GetAccountByToken(ctx, id.db, id.driver, token)
+	})
+	return
+}
+
+func (id *Identity) DeleteAccountToken(ctx context.Context, token string) (err error) {
+	id.metrics.observe("delete_account_token", func() {
+		err = DeleteAccountToken(ctx, id.db, id.driver, token)
+	})
+	return
+}
+
+func (id *Identity) AddAcceptedTerms(ctx context.Context, userID string, terms []models.AcceptedTerms) (err error) {
+	id.metrics.observe("add_accepted_terms", func() {
+		err = AddAcceptedTerms(ctx, id.db, id.driver, userID, terms)
+	})
+	return
+}
+
+func (id *Identity) GetAcceptedTerms(ctx context.Context, userID string) (terms []models.AcceptedTerms, err error) {
+	id.metrics.observe("get_accepted_terms", func() {
+		terms, err = GetAcceptedTerms(ctx, id.db, id.driver, userID)
+	})
+	return
+}
+
+func (id *Identity) AddOnbindNotification(ctx context.Context, destination, payload string) (n int64, err error) {
+	id.metrics.observe("add_onbind_notification", func() {
+		n, err = AddOnbindNotification(ctx, id.db, id.driver, destination, payload)
+	})
+	return
+}
+
+func (id *Identity) GetDueOnbindNotifications(ctx context.Context, now int64, limit int) (o []models.OnbindNotification, err error) {
+	id.metrics.observe("get_due_onbind_notifications", func() {
+		o, err = GetDueOnbindNotifications(ctx, id.db, id.driver, now, limit)
+	})
+	return
+}
+
+func (id *Identity) NextOnbindNotificationTS(ctx context.Context) (ts int64, err error) {
+	id.metrics.observe("next_onbind_notification_ts", func() {
+		ts, err = NextOnbindNotificationTS(ctx, id.db, id.driver)
+	})
+	return
+}
+
+func (id *Identity) RetryOnbindNotification(ctx context.Context, n, next int64, lastErr string) (err error) {
+	id.metrics.observe("retry_onbind_notification", func() {
+		err = RetryOnbindNotification(ctx, id.db, id.driver, n, next, lastErr)
+	})
+	return
+}
+
+func (id *Identity) DeleteOnbindNotification(ctx context.Context, n int64) (err error) {
+	id.metrics.observe("delete_onbind_notification", func() {
+		err = DeleteOnbindNotification(ctx, id.db, id.driver, n)
+	})
+	return
+}
+
+func (id *Identity) ReapValidationSessions(ctx context.Context, before int64, limit int) (sessions, tokenAuths int64, err error) {
+	id.metrics.observe("reap_validation_sessions", func() {
+		sessions, tokenAuths, err = ReapValidationSessions(ctx, id.db, id.driver, before, limit)
+	})
+	return
+}
+
+func (id *Identity) ReapInviteTokens(ctx context.Context, before int64, limit int) (n int64, err error) {
+	id.metrics.observe("reap_invite_tokens", func() {
+		n, err = ReapInviteTokens(ctx, id.db, id.driver, before, limit)
+	})
+	return
+}
+
+func (id *Identity) ReapEphemeralPublicKeys(ctx context.Context, before int64, limit int) (n int64, err error) {
+	id.metrics.observe("reap_ephemeral_public_keys", func() {
+		n, err = ReapEphemeralPublicKeys(ctx, id.db, id.driver, before, limit)
+	})
+	return
+}
+
+func (id *Identity) AddEmail(ctx context.Context, from string, to []string, domain, message string) (n int64, err error) {
+	id.metrics.observe("add_email", func() {
+		n, err = AddEmail(ctx, id.db, id.driver, from, to, domain, message)
+	})
+	return
+}
+
+func (id *Identity) GetDueEmails(ctx context.Context, now int64, limit int) (o []models.OutboxEmail, err error) {
+	id.metrics.observe("get_due_emails", func() {
+		o, err = GetDueEmails(ctx, id.db, id.driver, now, limit)
+	})
+	return
+}
+
+func (id *Identity) ListEmails(ctx context.Context, state string, limit int) (o []models.OutboxEmail, err error) {
+	id.metrics.observe("list_emails", func() {
+		o, err = ListEmails(ctx, id.db, id.driver, state, limit)
+	})
+	return
+}
+
+func (id *Identity) ClaimEmail(ctx context.Context, n, due, until int64) (err error) {
+	id.metrics.observe("claim_email", func() {
+		err = ClaimEmail(ctx, id.db, id.driver, n, due, until)
+	})
+	return
+}
+
+func (id *Identity) RetryEmail(ctx context.Context, n, next int64, lastErr string) (err error) {
+	id.metrics.observe("retry_email", func() {
+		err = RetryEmail(ctx, id.db, id.driver, n, next, lastErr)
+	})
+	return
+}
+
+func (id *Identity) DeadEmail(ctx context.Context, n int64, lastErr string) (err error) {
+	id.metrics.observe("dead_email", func() {
+		err = DeadEmail(ctx, id.db, id.driver, n, lastErr)
+	})
+	return
+}
+
+func (id *Identity) RequeueEmail(ctx context.Context, n int64) (err error) {
+	id.metrics.observe("requeue_email", func() {
+		err = RequeueEmail(ctx, id.db, id.driver, n)
+	})
+	return
+}
+
+func (id *Identity) DeleteEmail(ctx context.Context, n int64) (err error) {
+	id.metrics.observe("delete_email", func() {
+		err = DeleteEmail(ctx, id.db, id.driver, n)
+	})
+	return
+}
+
+func (id *Identity) NextEmailTS(ctx context.Context) (ts int64, err error) {
+	id.metrics.observe("next_email_ts", func() {
+		ts, err = NextEmailTS(ctx, id.db, id.driver)
+	})
+	return
+}
+
+func (id *Identity) TakeRateLimitToken(ctx context.Context, key string, burst float64, interval, now int64) (retry int64, err error) {
+	id.metrics.observe("take_rate_limit_token", func() {
+		retry, err = TakeRateLimitToken(ctx, id.db, id.driver, key, burst, interval, now)
+	})
+	return
+}
+
+func (id *Identity) TakeRateLimitTokens(ctx context.Context, buckets []RateLimitBucket, now int64) (retry int64, err error) {
+	id.metrics.observe("take_rate_limit_tokens", func() {
+		retry, err = TakeRateLimitTokens(ctx, id.db, id.driver, buckets, now)
+	})
+	return
+}
+
+func (id *Identity) ReapRateLimits(ctx context.Context, before int64, limit int) (n int64, err error) {
+	id.metrics.observe("reap_rate_limits", func() {
+		n, err = ReapRateLimits(ctx, id.db, id.driver, before, limit)
+	})
+	return
+}
+
+func (id *Identity) AuditLog(ctx context.Context, q models.AuditQuery) (o []models.AuditEntry, err error) {
+	if q.Medium != "" {
+		q.Address = id.canonical.Address(q.Medium, q.Address)
+	}
+	id.metrics.observe("audit_log", func() {
+		o, err = AuditLog(ctx, id.db, id.driver, q)
+	})
+	return
+}
+
+func (id *Identity) ExportSubject(ctx context.Context, s models.Subject) (o *models.SubjectExport, err error) {
+	s = canonicalSubject(id.canonical, s)
+	id.metrics.observe("export_subject", func() {
+		o, err = ExportSubject(ctx, id.db, id.driver, id.canonical, s)
+	})
+	return
+}
+
+func (id *Identity) EraseSubject(ctx context.Context, s models.Subject) (o *models.SubjectErasure, err error) {
+	s = canonicalSubject(id.canonical, s)
+	id.metrics.observe("erase_subject", func() {
+		o, err = EraseSubject(ctx, id.db, id.driver, id.canonical, s)
 	})
 	return
 }
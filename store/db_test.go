@@ -3,20 +3,24 @@ package store
 import (
 	"context"
 	"database/sql"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/gernest/sydent-go/embed"
-	"github.com/gernest/sydent-go/models"
+	"github.com/gernest/sydent-go/store/drivers/postgres"
 	"github.com/gernest/sydent-go/store/query"
 	"github.com/gernest/sydent-go/store/schema"
 	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
 )
 
 type TestContext struct {
 	Ctx   context.Context
-	Query models.Query
+	Store Store
 }
 
 func TestGenValues(t *testing.T) {
@@ -25,7 +29,7 @@ func TestGenValues(t *testing.T) {
 		[]string{"c", "d"},
 		[]string{"e", "f"},
 	}
-	x, a := genValues(ids)
+	x, a := genValues(postgres.Param, ids)
 	ex := "($1,$2),($3,$4),($5,$6)"
 	if x != ex {
 		t.Errorf("expected %s got %s", ex, x)
@@ -36,29 +40,136 @@ func TestGenValues(t *testing.T) {
 	}
 }
 
+// TestIdentity runs the conformance suite against every backend. Postgres is
+// only tested when MATRIXID_DB_CONN is set.
 func TestIdentity(t *testing.T) {
-	driverName := "postgres"
-	conn := os.Getenv("MATRIXID_DB_CONN")
+	t.Run("memory", func(t *testing.T) {
+		testStore(t, NewMemory())
+	})
+	t.Run("sqlite3", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "sydent-go")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		testSQL(t, "sqlite3", filepath.Join(dir, "identity.db"))
+	})
+	t.Run("postgres", func(t *testing.T) {
+		conn := os.Getenv("MATRIXID_DB_CONN")
+		if conn == "" {
+			t.Skip("MATRIXID_DB_CONN is not set")
+		}
+		testSQL(t, "postgres", conn)
+	})
+}
+
+func testSQL(t *testing.T, driverName, conn string) {
 	db, err := sql.Open(driverName, conn)
 	if err != nil {
 		t.Fatal(err)
 	}
 	defer db.Close()
-
-	tctx := TestContext{
-		Ctx:   context.Background(),
-		Query: query.New(db),
+	if driverName == "sqlite3" {
+		db.SetMaxOpenConns(1)
+	}
+	driver, err := NewDriver(driverName)
+	if err != nil {
+		t.Fatal(err)
 	}
+	ctx := context.Background()
+	q := query.New(db)
 	fs := embed.New()
-
-	err = schema.IdentityDown(tctx.Ctx, fs, tctx.Query)
+	err = schema.IdentityDown(ctx, fs, driverName, q)
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = schema.IdentityUp(tctx.Ctx, fs, tctx.Query)
+	err = schema.IdentityUp(ctx, fs, driverName, q)
 	if err != nil {
 		t.Fatal(err)
 	}
+	testStore(t, NewStore(q, driver, Metric{}))
+	testErasedRows(t, db, driverName)
+}
 
+// testErasedRows checks that no row of any table has the erasedSubjects, but
+// the removals of local associations.
+func testErasedRows(t *testing.T, db *sql.DB, driverName string) {
+	list := "SELECT table_name FROM information_schema.tables WHERE table_schema = current_schema()"
+	if driverName == "sqlite3" {
+		list = "SELECT name FROM sqlite_master WHERE type = 'table'"
+	}
+	var tables []string
+	rows, err := db.Query(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			t.Fatal(err)
+		}
+		tables = append(tables, name)
+	}
+	if err := rows.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for _, table := range tables {
+		rows, err := db.Query("SELECT * FROM " + table)
+		if err != nil {
+			t.Fatal(err)
+		}
+		columns, err := rows.Columns()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for rows.Next() {
+			values := make([]sql.NullString, len(columns))
+			dest := make([]interface{}, len(columns))
+			for i := range values {
+				dest[i] = &values[i]
+			}
+			if err := rows.Scan(dest...); err != nil {
+				t.Fatal(err)
+			}
+			row := make(map[string]sql.NullString)
+			for i, c := range columns {
+				row[c] = values[i]
+			}
+			if table == "local_threepid_associations" && !row["mxid"].Valid {
+				continue
+			}
+			for _, v := range values {
+				for _, erased := range erasedSubjects {
+					if strings.Contains(strings.ToLower(v.String), erased) {
+						t.Errorf("%s: expected %s to be erased got %v", table, erased, row)
+					}
+				}
+			}
+		}
+		if err := rows.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func testStore(t *testing.T, s Store) {
+	tctx := TestContext{
+		Ctx:   context.Background(),
+		Store: s,
+	}
 	testInvites(t, tctx)
+	testHashedLookup(t, tctx)
+	testLocalAssociations(t, tctx)
+	testGlobalAssociations(t, tctx)
+	testPeers(t, tctx)
+	testAccounts(t, tctx)
+	testOnbind(t, tctx)
+	testSessions(t, tctx)
+	testReap(t, tctx)
+	testOutbox(t, tctx)
+	testRateLimits(t, tctx)
+	testCanonicalAddresses(t, tctx)
+	testAudit(t, tctx)
+	testSubjects(t, tctx)
+	testTx(t, tctx)
 }
@@ -0,0 +1,862 @@
+// Code generated by generate_store_driver.go. DO NOT EDIT.
+
+package store
+
+import (
+	"fmt"
+	"github.com/gernest/sydent-go/store/drivers/postgres"
+	"github.com/gernest/sydent-go/store/drivers/sqlite3"
+)
+
+// Driver returns the queries of a database dialect.
+type Driver interface {
+	StoreToken() string
+	GetTokens() string
+	MarkTokensAsSent() string
+	StoreEphemeralPublicKey() string
+	ValidateEphemeralPublicKey() string
+	GetSenderForToken() string
+	SignedAssociationStringForThreepid() string
+	GlobalGetMxid() string
+	GlobalGetMxids() string
+	GlobalRemoveAssociation() string
+	GlobalAddAssociation() string
+	GetPeerByName() string
+	GetAllPeers() string
+	SetLastSentVersionAndPokeSucceeded() string
+	ListPeers() string
+	AddPeer() string
+	CountPeers() string
+	DeletePeerKeys() string
+	DeletePeer() string
+	SetPeerKey() string
+	SetPeerActive() string
+	CountAssociationsAfterID() string
+	SetSendAttemptNumber() string
+	SetValidated() string
+	SetMtime() string
+	GetSessionByID() string
+	GetTokenSessionByID() string
+	GlobalLastIDFromServer() string
+	LocalAddAssociation() string
+	GetTokenSession() string
+	CreateTokenSession() string
+	AddValidationSession() string
+	GetAssociationsAfterId() string
+	LocalDeleteAssociation() string
+	CreateTMPMxid() string
+	GetLookupPepper() string
+	DeleteLookupPepper() string
+	SetLookupPepper() string
+	GlobalAssociationsAfterID() string
+	SetLookupHash() string
+	LocalAssociationAddresses() string
+	SetLocalAssociationAddress() string
+	GlobalAssociationAddresses() string
+	SetGlobalAssociationAddress() string
+	ValidationSessionAddresses() string
+	SetValidationSessionAddress() string
+	InviteTokenAddresses() string
+	SetInviteTokenAddress() string
+	GetLocalAssociation() string
+	GlobalAssociationsFor() string
+	AddAuditEntry() string
+	QueryAuditLog() string
+	LocalAssociationsForMxid() string
+	GlobalAssociationsForMxid() string
+	ValidationSessionsFor() string
+	DeleteInviteTokensFor() string
+	DeleteTokenAuthsFor() string
+	DeleteValidationSessionsFor() string
+	EmailsAfter() string
+	RedactAuditAddress() string
+	RedactAuditMxid() string
+	GlobalGetMxidsByHash() string
+	AddAccount() string
+	AddAccountToken() string
+	GetAccountByToken() string
+	DeleteAccountToken() string
+	AddAcceptedTerms() string
+	GetAcceptedTerms() string
+	AddOnbindNotification() string
+	GetDueOnbindNotifications() string
+	NextOnbindNotificationTS() string
+	RetryOnbindNotification() string
+	DeleteOnbindNotification() string
+	ImportInviteToken() string
+	ImportEphemeralPublicKey() string
+	ImportPeer() string
+	ImportPeerPubkey() string
+	ImportLocalAssociation() string
+	ImportGlobalAssociation() string
+	ImportValidationSession() string
+	ImportTokenAuth() string
+	GetImportCheckpoint() string
+	SetImportCheckpoint() string
+	ResetSequence() string
+	GetExpiredSessions() string
+	DeleteSessionTokenAuths() string
+	DeleteSessions() string
+	ReapInviteTokens() string
+	ReapEphemeralPublicKeys() string
+	AddEmail() string
+	GetDueEmails() string
+	ListEmails() string
+	ClaimEmail() string
+	RetryEmail() string
+	DeadEmail() string
+	RequeueEmail() string
+	DeleteEmail() string
+	NextEmailTS() string
+	TakeRateLimitToken() string
+	GetRateLimit() string
+	ReapRateLimits() string
+	Name() string
+	Param(int) string
+}
+
+// DriverHandle implements Driver with the queries of a package in
+// store/drivers.
+type DriverHandle struct {
+	storetoken                         string
+	gettokens                          string
+	marktokensassent                   string
+	storeephemeralpublickey            string
+	validateephemeralpublickey         string
+	getsenderfortoken                  string
+	signedassociationstringforthreepid string
+	globalgetmxid                      string
+	globalgetmxids                     string
+	globalremoveassociation            string
+	globaladdassociation               string
+	getpeerbyname                      string
+	getallpeers                        string
+	setlastsentversionandpokesucceeded string
+	listpeers                          string
+	addpeer                            string
+	countpeers                         string
+	deletepeerkeys                     string
+	deletepeer                         string
+	setpeerkey                         string
+	setpeeractive                      string
+	countassociationsafterid           string
+	setsendattemptnumber               string
+	setvalidated                       string
+	setmtime                           string
+	getsessionbyid                     string
+	gettokensessionbyid                string
+	globallastidfromserver             string
+	localaddassociation                string
+	gettokensession                    string
+	createtokensession                 string
+	addvalidationsession               string
+	getassociationsafterid             string
+	localdeleteassociation             string
+	createtmpmxid                      string
+	getlookuppepper                    string
+	deletelookuppepper                 string
+	setlookuppepper                    string
+	globalassociationsafterid          string
+	setlookuphash                      string
+	localassociationaddresses          string
+	setlocalassociationaddress         string
+	globalassociationaddresses         string
+	setglobalassociationaddress        string
+	validationsessionaddresses         string
+	setvalidationsessionaddress        string
+	invitetokenaddresses               string
+	setinvitetokenaddress              string
+	getlocalassociation                string
+	globalassociationsfor              string
+	addauditentry                      string
+	queryauditlog                      string
+	localassociationsformxid           string
+	globalassociationsformxid          string
+	validationsessionsfor              string
+	deleteinvitetokensfor              string
+	deletetokenauthsfor                string
+	deletevalidationsessionsfor        string
+	emailsafter                        string
+	redactauditaddress                 string
+	redactauditmxid                    string
+	globalgetmxidsbyhash               string
+	addaccount                         string
+	addaccounttoken                    string
+	getaccountbytoken                  string
+	deleteaccounttoken                 string
+	addacceptedterms                   string
+	getacceptedterms                   string
+	addonbindnotification              string
+	getdueonbindnotifications          string
+	nextonbindnotificationts           string
+	retryonbindnotification            string
+	deleteonbindnotification           string
+	importinvitetoken                  string
+	importephemeralpublickey           string
+	importpeer                         string
+	importpeerpubkey                   string
+	importlocalassociation             string
+	importglobalassociation            string
+	importvalidationsession            string
+	importtokenauth                    string
+	getimportcheckpoint                string
+	setimportcheckpoint                string
+	resetsequence                      string
+	getexpiredsessions                 string
+	deletesessiontokenauths            string
+	deletesessions                     string
+	reapinvitetokens                   string
+	reapephemeralpublickeys            string
+	addemail                           string
+	getdueemails                       string
+	listemails                         string
+	claimemail                         string
+	retryemail                         string
+	deademail                          string
+	requeueemail                       string
+	deleteemail                        string
+	nextemailts                        string
+	takeratelimittoken                 string
+	getratelimit                       string
+	reapratelimits                     string
+	name                               string
+	param                              func(int) string
+}
+
+// NewDriver returns the Driver for the database/sql driver name.
+func NewDriver(name string) (Driver, error) {
+	switch name {
+	case "postgres":
+		return postgresImpl(), nil
+	case "sqlite3":
+		return sqlite3Impl(), nil
+	default:
+		return nil, fmt.Errorf("%s is not supported", name)
+	}
+}
+
+func (h DriverHandle) StoreToken() string {
+	return h.storetoken
+}
+
+func (h DriverHandle) GetTokens() string {
+	return h.gettokens
+}
+
+func (h DriverHandle) MarkTokensAsSent() string {
+	return h.marktokensassent
+}
+
+func (h DriverHandle) StoreEphemeralPublicKey() string {
+	return h.storeephemeralpublickey
+}
+
+func (h DriverHandle) ValidateEphemeralPublicKey() string {
+	return h.validateephemeralpublickey
+}
+
+func (h DriverHandle) GetSenderForToken() string {
+	return h.getsenderfortoken
+}
+
+func (h DriverHandle) SignedAssociationStringForThreepid() string {
+	return h.signedassociationstringforthreepid
+}
+
+func (h DriverHandle) GlobalGetMxid() string {
+	return h.globalgetmxid
+}
+
+func (h DriverHandle) GlobalGetMxids() string {
+	return h.globalgetmxids
+}
+
+func (h DriverHandle) GlobalRemoveAssociation() string {
+	return h.globalremoveassociation
+}
+
+func (h DriverHandle) GlobalAddAssociation() string {
+	return h.globaladdassociation
+}
+
+func (h DriverHandle) GetPeerByName() string {
+	return h.getpeerbyname
+}
+
+func (h DriverHandle) GetAllPeers() string {
+	return h.getallpeers
+}
+
+func (h DriverHandle) SetLastSentVersionAndPokeSucceeded() string {
+	return h.setlastsentversionandpokesucceeded
+}
+
+func (h DriverHandle) ListPeers() string {
+	return h.listpeers
+}
+
+func (h DriverHandle) AddPeer() string {
+	return h.addpeer
+}
+
+func (h DriverHandle) CountPeers() string {
+	return h.countpeers
+}
+
+func (h DriverHandle) DeletePeerKeys() string {
+	return h.deletepeerkeys
+}
+
+func (h DriverHandle) DeletePeer() string {
+	return h.deletepeer
+}
+
+func (h DriverHandle) SetPeerKey() string {
+	return h.setpeerkey
+}
+
+func (h DriverHandle) SetPeerActive() string {
+	return h.setpeeractive
+}
+
+func (h DriverHandle) CountAssociationsAfterID() string {
+	return h.countassociationsafterid
+}
+
+func (h DriverHandle) SetSendAttemptNumber() string {
+	return h.setsendattemptnumber
+}
+
+func (h DriverHandle) SetValidated() string {
+	return h.setvalidated
+}
+
+func (h DriverHandle) SetMtime() string {
+	return h.setmtime
+}
+
+func (h DriverHandle) GetSessionByID() string {
+	return h.getsessionbyid
+}
+
+func (h DriverHandle) GetTokenSessionByID() string {
+	return h.gettokensessionbyid
+}
+
+func (h DriverHandle) GlobalLastIDFromServer() string {
+	return h.globallastidfromserver
+}
+
+func (h DriverHandle) LocalAddAssociation() string {
+	return h.localaddassociation
+}
+
+func (h DriverHandle) GetTokenSession() string {
+	return h.gettokensession
+}
+
+func (h DriverHandle) CreateTokenSession() string {
+	return h.createtokensession
+}
+
+func (h DriverHandle) AddValidationSession() string {
+	return h.addvalidationsession
+}
+
+func (h DriverHandle) GetAssociationsAfterId() string {
+	return h.getassociationsafterid
+}
+
+func (h DriverHandle) LocalDeleteAssociation() string {
+	return h.localdeleteassociation
+}
+
+func (h DriverHandle) CreateTMPMxid() string {
+	return h.createtmpmxid
+}
+
+func (h DriverHandle) GetLookupPepper() string {
+	return h.getlookuppepper
+}
+
+func (h DriverHandle) DeleteLookupPepper() string {
+	return h.deletelookuppepper
+}
+
+func (h DriverHandle) SetLookupPepper() string {
+	return h.setlookuppepper
+}
+
+func (h DriverHandle) GlobalAssociationsAfterID() string {
+	return h.globalassociationsafterid
+}
+
+func (h DriverHandle) SetLookupHash() string {
+	return h.setlookuphash
+}
+
+func (h DriverHandle) LocalAssociationAddresses() string {
+	return h.localassociationaddresses
+}
+
+func (h DriverHandle) SetLocalAssociationAddress() string {
+	return h.setlocalassociationaddress
+}
+
+func (h DriverHandle) GlobalAssociationAddresses() string {
+	return h.globalassociationaddresses
+}
+
+func (h DriverHandle) SetGlobalAssociationAddress() string {
+	return h.setglobalassociationaddress
+}
+
+func (h DriverHandle) ValidationSessionAddresses() string {
+	return h.validationsessionaddresses
+}
+
+func (h DriverHandle) SetValidationSessionAddress() string {
+	return h.setvalidationsessionaddress
+}
+
+func (h DriverHandle) InviteTokenAddresses() string {
+	return h.invitetokenaddresses
+}
+
+func (h DriverHandle) SetInviteTokenAddress() string {
+	return h.setinvitetokenaddress
+}
+
+func (h DriverHandle) GetLocalAssociation() string {
+	return h.getlocalassociation
+}
+
+func (h DriverHandle) GlobalAssociationsFor() string {
+	return h.globalassociationsfor
+}
+
+func (h DriverHandle) AddAuditEntry() string {
+	return h.addauditentry
+}
+
+func (h DriverHandle) QueryAuditLog() string {
+	return h.queryauditlog
+}
+
+func (h DriverHandle) LocalAssociationsForMxid() string {
+	return h.localassociationsformxid
+}
+
+func (h DriverHandle) GlobalAssociationsForMxid() string {
+	return h.globalassociationsformxid
+}
+
+func (h DriverHandle) ValidationSessionsFor() string {
+	return h.validationsessionsfor
+}
+
+func (h DriverHandle) DeleteInviteTokensFor() string {
+	return h.deleteinvitetokensfor
+}
+
+func (h DriverHandle) DeleteTokenAuthsFor() string {
+	return h.deletetokenauthsfor
+}
+
+func (h DriverHandle) DeleteValidationSessionsFor() string {
+	return h.deletevalidationsessionsfor
+}
+
+func (h DriverHandle) EmailsAfter() string {
+	return h.emailsafter
+}
+
+func (h DriverHandle) RedactAuditAddress() string {
+	return h.redactauditaddress
+}
+
+func (h DriverHandle) RedactAuditMxid() string {
+	return h.redactauditmxid
+}
+
+func (h DriverHandle) GlobalGetMxidsByHash() string {
+	return h.globalgetmxidsbyhash
+}
+
+func (h DriverHandle) AddAccount() string {
+	return h.addaccount
+}
+
+func (h DriverHandle) AddAccountToken() string {
+	return h.addaccounttoken
+}
+
+func (h DriverHandle) GetAccountByToken() string {
+	return h.getaccountbytoken
+}
+
+func (h DriverHandle) DeleteAccountToken() string {
+	return h.deleteaccounttoken
+}
+
+func (h DriverHandle) AddAcceptedTerms() string {
+	return h.addacceptedterms
+}
+
+func (h DriverHandle) GetAcceptedTerms() string {
+	return h.getacceptedterms
+}
+
+func (h DriverHandle) AddOnbindNotification() string {
+	return h.addonbindnotification
+}
+
+func (h DriverHandle) GetDueOnbindNotifications() string {
+	return h.getdueonbindnotifications
+}
+
+func (h DriverHandle) NextOnbindNotificationTS() string {
+	return h.nextonbindnotificationts
+}
+
+func (h DriverHandle) RetryOnbindNotification() string {
+	return h.retryonbindnotification
+}
+
+func (h DriverHandle) DeleteOnbindNotification() string {
+	return h.deleteonbindnotification
+}
+
+func (h DriverHandle) ImportInviteToken() string {
+	return h.importinvitetoken
+}
+
+func (h DriverHandle) ImportEphemeralPublicKey() string {
+	return h.importephemeralpublickey
+}
+
+func (h DriverHandle) ImportPeer() string {
+	return h.importpeer
+}
+
+func (h DriverHandle) ImportPeerPubkey() string {
+	return h.importpeerpubkey
+}
+
+func (h DriverHandle) ImportLocalAssociation() string {
+	return h.importlocalassociation
+}
+
+func (h DriverHandle) ImportGlobalAssociation() string {
+	return h.importglobalassociation
+}
+
+func (h DriverHandle) ImportValidationSession() string {
+	return h.importvalidationsession
+}
+
+func (h DriverHandle) ImportTokenAuth() string {
+	return h.importtokenauth
+}
+
+func (h DriverHandle) GetImportCheckpoint() string {
+	return h.getimportcheckpoint
+}
+
+func (h DriverHandle) SetImportCheckpoint() string {
+	return h.setimportcheckpoint
+}
+
+func (h DriverHandle) ResetSequence() string {
+	return h.resetsequence
+}
+
+func (h DriverHandle) GetExpiredSessions() string {
+	return h.getexpiredsessions
+}
+
+func (h DriverHandle) DeleteSessionTokenAuths() string {
+	return h.deletesessiontokenauths
+}
+
+func (h DriverHandle) DeleteSessions() string {
+	return h.deletesessions
+}
+
+func (h DriverHandle) ReapInviteTokens() string {
+	return h.reapinvitetokens
+}
+
+func (h DriverHandle) ReapEphemeralPublicKeys() string {
+	return h.reapephemeralpublickeys
+}
+
+func (h DriverHandle) AddEmail() string {
+	return h.addemail
+}
+
+func (h DriverHandle) GetDueEmails() string {
+	return h.getdueemails
+}
+
+func (h DriverHandle) ListEmails() string {
+	return h.listemails
+}
+
+func (h DriverHandle) ClaimEmail() string {
+	return h.claimemail
+}
+
+func (h DriverHandle) RetryEmail() string {
+	return h.retryemail
+}
+
+func (h DriverHandle) DeadEmail() string {
+	return h.deademail
+}
+
+func (h DriverHandle) RequeueEmail() string {
+	return h.requeueemail
+}
+
+func (h DriverHandle) DeleteEmail() string {
+	return h.deleteemail
+}
+
+func (h DriverHandle) NextEmailTS() string {
+	return h.nextemailts
+}
+
+func (h DriverHandle) TakeRateLimitToken() string {
+	return h.takeratelimittoken
+}
+
+func (h DriverHandle) GetRateLimit() string {
+	return h.getratelimit
+}
+
+func (h DriverHandle) ReapRateLimits() string {
+	return h.reapratelimits
+}
+
+func (h DriverHandle) Name() string {
+	return h.name
+}
+
+func (h DriverHandle) Param(idx int) string {
+	return h.param(idx)
+}
+
+func postgresImpl() *DriverHandle {
+	return &DriverHandle{storetoken: postgres.StoreToken,
+		gettokens:                          postgres.GetTokens,
+		marktokensassent:                   postgres.MarkTokensAsSent,
+		storeephemeralpublickey:            postgres.StoreEphemeralPublicKey,
+		validateephemeralpublickey:         postgres.ValidateEphemeralPublicKey,
+		getsenderfortoken:                  postgres.GetSenderForToken,
+		signedassociationstringforthreepid: postgres.SignedAssociationStringForThreepid,
+		globalgetmxid:                      postgres.GlobalGetMxid,
+		globalgetmxids:                     postgres.GlobalGetMxids,
+		globalremoveassociation:            postgres.GlobalRemoveAssociation,
+		globaladdassociation:               postgres.GlobalAddAssociation,
+		getpeerbyname:                      postgres.GetPeerByName,
+		getallpeers:                        postgres.GetAllPeers,
+		setlastsentversionandpokesucceeded: postgres.SetLastSentVersionAndPokeSucceeded,
+		listpeers:                          postgres.ListPeers,
+		addpeer:                            postgres.AddPeer,
+		countpeers:                         postgres.CountPeers,
+		deletepeerkeys:                     postgres.DeletePeerKeys,
+		deletepeer:                         postgres.DeletePeer,
+		setpeerkey:                         postgres.SetPeerKey,
+		setpeeractive:                      postgres.SetPeerActive,
+		countassociationsafterid:           postgres.CountAssociationsAfterID,
+		setsendattemptnumber:               postgres.SetSendAttemptNumber,
+		setvalidated:                       postgres.SetValidated,
+		setmtime:                           postgres.SetMtime,
+		getsessionbyid:                     postgres.GetSessionByID,
+		gettokensessionbyid:                postgres.GetTokenSessionByID,
+		globallastidfromserver:             postgres.GlobalLastIDFromServer,
+		localaddassociation:                postgres.LocalAddAssociation,
+		gettokensession:                    postgres.GetTokenSession,
+		createtokensession:                 postgres.CreateTokenSession,
+		addvalidationsession:               postgres.AddValidationSession,
+		getassociationsafterid:             postgres.GetAssociationsAfterId,
+		localdeleteassociation:             postgres.LocalDeleteAssociation,
+		createtmpmxid:                      postgres.CreateTMPMxid,
+		getlookuppepper:                    postgres.GetLookupPepper,
+		deletelookuppepper:                 postgres.DeleteLookupPepper,
+		setlookuppepper:                    postgres.SetLookupPepper,
+		globalassociationsafterid:          postgres.GlobalAssociationsAfterID,
+		setlookuphash:                      postgres.SetLookupHash,
+		localassociationaddresses:          postgres.LocalAssociationAddresses,
+		setlocalassociationaddress:         postgres.SetLocalAssociationAddress,
+		globalassociationaddresses:         postgres.GlobalAssociationAddresses,
+		setglobalassociationaddress:        postgres.SetGlobalAssociationAddress,
+		validationsessionaddresses:         postgres.ValidationSessionAddresses,
+		setvalidationsessionaddress:        postgres.SetValidationSessionAddress,
+		invitetokenaddresses:               postgres.InviteTokenAddresses,
+		setinvitetokenaddress:              postgres.SetInviteTokenAddress,
+		getlocalassociation:                postgres.GetLocalAssociation,
+		globalassociationsfor:              postgres.GlobalAssociationsFor,
+		addauditentry:                      postgres.AddAuditEntry,
+		queryauditlog:                      postgres.QueryAuditLog,
+		localassociationsformxid:           postgres.LocalAssociationsForMxid,
+		globalassociationsformxid:          postgres.GlobalAssociationsForMxid,
+		validationsessionsfor:              postgres.ValidationSessionsFor,
+		deleteinvitetokensfor:              postgres.DeleteInviteTokensFor,
+		deletetokenauthsfor:                postgres.DeleteTokenAuthsFor,
+		deletevalidationsessionsfor:        postgres.DeleteValidationSessionsFor,
+		emailsafter:                        postgres.EmailsAfter,
+		redactauditaddress:                 postgres.RedactAuditAddress,
+		redactauditmxid:                    postgres.RedactAuditMxid,
+		globalgetmxidsbyhash:               postgres.GlobalGetMxidsByHash,
+		addaccount:                         postgres.AddAccount,
+		addaccounttoken:                    postgres.AddAccountToken,
+		getaccountbytoken:                  postgres.GetAccountByToken,
+		deleteaccounttoken:                 postgres.DeleteAccountToken,
+		addacceptedterms:                   postgres.AddAcceptedTerms,
+		getacceptedterms:                   postgres.GetAcceptedTerms,
+		addonbindnotification:              postgres.AddOnbindNotification,
+		getdueonbindnotifications:          postgres.GetDueOnbindNotifications,
+		nextonbindnotificationts:           postgres.NextOnbindNotificationTS,
+		retryonbindnotification:            postgres.RetryOnbindNotification,
+		deleteonbindnotification:           postgres.DeleteOnbindNotification,
+		importinvitetoken:                  postgres.ImportInviteToken,
+		importephemeralpublickey:           postgres.ImportEphemeralPublicKey,
+		importpeer:                         postgres.ImportPeer,
+		importpeerpubkey:                   postgres.ImportPeerPubkey,
+		importlocalassociation:             postgres.ImportLocalAssociation,
+		importglobalassociation:            postgres.ImportGlobalAssociation,
+		importvalidationsession:            postgres.ImportValidationSession,
+		importtokenauth:                    postgres.ImportTokenAuth,
+		getimportcheckpoint:                postgres.GetImportCheckpoint,
+		setimportcheckpoint:                postgres.SetImportCheckpoint,
+		resetsequence:                      postgres.ResetSequence,
+		getexpiredsessions:                 postgres.GetExpiredSessions,
+		deletesessiontokenauths:            postgres.DeleteSessionTokenAuths,
+		deletesessions:                     postgres.DeleteSessions,
+		reapinvitetokens:                   postgres.ReapInviteTokens,
+		reapephemeralpublickeys:            postgres.ReapEphemeralPublicKeys,
+		addemail:                           postgres.AddEmail,
+		getdueemails:                       postgres.GetDueEmails,
+		listemails:                         postgres.ListEmails,
+		claimemail:                         postgres.ClaimEmail,
+		retryemail:                         postgres.RetryEmail,
+		deademail:                          postgres.DeadEmail,
+		requeueemail:                       postgres.RequeueEmail,
+		deleteemail:                        postgres.DeleteEmail,
+		nextemailts:                        postgres.NextEmailTS,
+		takeratelimittoken:                 postgres.TakeRateLimitToken,
+		getratelimit:                       postgres.GetRateLimit,
+		reapratelimits:                     postgres.ReapRateLimits,
+		name:                               "postgres",
+		param:                              postgres.Param,
+	}
+}
+
+func sqlite3Impl() *DriverHandle {
+	return &DriverHandle{storetoken: sqlite3.StoreToken,
+		gettokens:                          sqlite3.GetTokens,
+		marktokensassent:                   sqlite3.MarkTokensAsSent,
+		storeephemeralpublickey:            sqlite3.StoreEphemeralPublicKey,
+		validateephemeralpublickey:         sqlite3.ValidateEphemeralPublicKey,
+		getsenderfortoken:                  sqlite3.GetSenderForToken,
+		signedassociationstringforthreepid: sqlite3.SignedAssociationStringForThreepid,
+		globalgetmxid:                      sqlite3.GlobalGetMxid,
+		globalgetmxids:                     sqlite3.GlobalGetMxids,
+		globalremoveassociation:            sqlite3.GlobalRemoveAssociation,
+		globaladdassociation:               sqlite3.GlobalAddAssociation,
+		getpeerbyname:                      sqlite3.GetPeerByName,
+		getallpeers:                        sqlite3.GetAllPeers,
+		setlastsentversionandpokesucceeded: sqlite3.SetLastSentVersionAndPokeSucceeded,
+		listpeers:                          sqlite3.ListPeers,
+		addpeer:                            sqlite3.AddPeer,
+		countpeers:                         sqlite3.CountPeers,
+		deletepeerkeys:                     sqlite3.DeletePeerKeys,
+		deletepeer:                         sqlite3.DeletePeer,
+		setpeerkey:                         sqlite3.SetPeerKey,
+		setpeeractive:                      sqlite3.SetPeerActive,
+		countassociationsafterid:           sqlite3.CountAssociationsAfterID,
+		setsendattemptnumber:               sqlite3.SetSendAttemptNumber,
+		setvalidated:                       sqlite3.SetValidated,
+		setmtime:                           sqlite3.SetMtime,
+		getsessionbyid:                     sqlite3.GetSessionByID,
+		gettokensessionbyid:                sqlite3.GetTokenSessionByID,
+		globallastidfromserver:             sqlite3.GlobalLastIDFromServer,
+		localaddassociation:                sqlite3.LocalAddAssociation,
+		gettokensession:                    sqlite3.GetTokenSession,
+		createtokensession:                 sqlite3.CreateTokenSession,
+		addvalidationsession:               sqlite3.AddValidationSession,
+		getassociationsafterid:             sqlite3.GetAssociationsAfterId,
+		localdeleteassociation:             sqlite3.LocalDeleteAssociation,
+		createtmpmxid:                      sqlite3.CreateTMPMxid,
+		getlookuppepper:                    sqlite3.GetLookupPepper,
+		deletelookuppepper:                 sqlite3.DeleteLookupPepper,
+		setlookuppepper:                    sqlite3.SetLookupPepper,
+		globalassociationsafterid:          sqlite3.GlobalAssociationsAfterID,
+		setlookuphash:                      sqlite3.SetLookupHash,
+		localassociationaddresses:          sqlite3.LocalAssociationAddresses,
+		setlocalassociationaddress:         sqlite3.SetLocalAssociationAddress,
+		globalassociationaddresses:         sqlite3.GlobalAssociationAddresses,
+		setglobalassociationaddress:        sqlite3.SetGlobalAssociationAddress,
+		validationsessionaddresses:         sqlite3.ValidationSessionAddresses,
+		setvalidationsessionaddress:        sqlite3.SetValidationSessionAddress,
+		invitetokenaddresses:               sqlite3.InviteTokenAddresses,
+		setinvitetokenaddress:              sqlite3.SetInviteTokenAddress,
+		getlocalassociation:                sqlite3.GetLocalAssociation,
+		globalassociationsfor:              sqlite3.GlobalAssociationsFor,
+		addauditentry:                      sqlite3.AddAuditEntry,
+		queryauditlog:                      sqlite3.QueryAuditLog,
+		localassociationsformxid:           sqlite3.LocalAssociationsForMxid,
+		globalassociationsformxid:          sqlite3.GlobalAssociationsForMxid,
+		validationsessionsfor:              sqlite3.ValidationSessionsFor,
+		deleteinvitetokensfor:              sqlite3.DeleteInviteTokensFor,
+		deletetokenauthsfor:                sqlite3.DeleteTokenAuthsFor,
+		deletevalidationsessionsfor:        sqlite3.DeleteValidationSessionsFor,
+		emailsafter:                        sqlite3.EmailsAfter,
+		redactauditaddress:                 sqlite3.RedactAuditAddress,
+		redactauditmxid:                    sqlite3.RedactAuditMxid,
+		globalgetmxidsbyhash:               sqlite3.GlobalGetMxidsByHash,
+		addaccount:                         sqlite3.AddAccount,
+		addaccounttoken:                    sqlite3.AddAccountToken,
+		getaccountbytoken:                  sqlite3.GetAccountByToken,
+		deleteaccounttoken:                 sqlite3.DeleteAccountToken,
+		addacceptedterms:                   sqlite3.AddAcceptedTerms,
+		getacceptedterms:                   sqlite3.GetAcceptedTerms,
+		addonbindnotification:              sqlite3.AddOnbindNotification,
+		getdueonbindnotifications:          sqlite3.GetDueOnbindNotifications,
+		nextonbindnotificationts:           sqlite3.NextOnbindNotificationTS,
+		retryonbindnotification:            sqlite3.RetryOnbindNotification,
+		deleteonbindnotification:           sqlite3.DeleteOnbindNotification,
+		importinvitetoken:                  sqlite3.ImportInviteToken,
+		importephemeralpublickey:           sqlite3.ImportEphemeralPublicKey,
+		importpeer:                         sqlite3.ImportPeer,
+		importpeerpubkey:                   sqlite3.ImportPeerPubkey,
+		importlocalassociation:             sqlite3.ImportLocalAssociation,
+		importglobalassociation:            sqlite3.ImportGlobalAssociation,
+		importvalidationsession:            sqlite3.ImportValidationSession,
+		importtokenauth:                    sqlite3.ImportTokenAuth,
+		getimportcheckpoint:                sqlite3.GetImportCheckpoint,
+		setimportcheckpoint:                sqlite3.SetImportCheckpoint,
+		resetsequence:                      sqlite3.ResetSequence,
+		getexpiredsessions:                 sqlite3.GetExpiredSessions,
+		deletesessiontokenauths:            sqlite3.DeleteSessionTokenAuths,
+		deletesessions:                     sqlite3.DeleteSessions,
+		reapinvitetokens:                   sqlite3.ReapInviteTokens,
+		reapephemeralpublickeys:            sqlite3.ReapEphemeralPublicKeys,
+		addemail:                           sqlite3.AddEmail,
+		getdueemails:                       sqlite3.GetDueEmails,
+		listemails:                         sqlite3.ListEmails,
+		claimemail:                         sqlite3.ClaimEmail,
+		retryemail:                         sqlite3.RetryEmail,
+		deademail:                          sqlite3.DeadEmail,
+		requeueemail:                       sqlite3.RequeueEmail,
+		deleteemail:                        sqlite3.DeleteEmail,
+		nextemailts:                        sqlite3.NextEmailTS,
+		takeratelimittoken:                 sqlite3.TakeRateLimitToken,
+		getratelimit:                       sqlite3.GetRateLimit,
+		reapratelimits:                     sqlite3.ReapRateLimits,
+		name:                               "sqlite3",
+		param:                              sqlite3.Param,
+	}
+}
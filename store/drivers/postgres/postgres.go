@@ -0,0 +1,1080 @@
+// Package postgres contains the queries used with the postgres database.
+package postgres
+
+import (
+	"fmt"
+)
+
+const StoreToken = `INSERT INTO
+invite_tokens (
+	medium,
+	address,
+	room_id,
+	sender,
+	token,
+	received_ts
+)
+VALUES
+($1, $2, $3, $4, $5, $6);`
+
+const GetTokens = `SELECT
+    medium,
+    address,
+    room_id,
+    sender,
+    token,
+    received_ts,
+    sent_ts
+FROM
+    invite_tokens
+WHERE
+    medium = $1
+    AND address = $2;`
+
+const MarkTokensAsSent = `UPDATE
+    invite_tokens
+SET
+    sent_ts = $1
+WHERE
+    medium = $2
+    AND address = $3;`
+
+const StoreEphemeralPublicKey = `INSERT INTO
+    ephemeral_public_keys (public_key, persistence_ts)
+VALUES
+    ($1, $2);`
+
+const ValidateEphemeralPublicKey = `UPDATE
+    ephemeral_public_keys
+SET
+    verify_count = verify_count + 1
+WHERE
+    public_key = $1;`
+
+const GetSenderForToken = `SELECT
+    sender
+FROM
+    invite_tokens
+WHERE
+    token = $1;`
+
+const SignedAssociationStringForThreepid = `SELECT
+    sgAssoc
+FROM
+    global_threepid_associations
+WHERE
+    medium = $1
+    and lower(address) = lower($2)
+    and notBefore < $3
+    and notAfter > $4
+ORDER by
+    ts desc
+LIMIT
+    1;`
+
+const GlobalGetMxid = `SELECT
+    mxid
+FROM
+    global_threepid_associations
+WHERE
+    medium = $1
+    and lower(address) = lower($2)
+    and notBefore < $3
+    and notAfter > $4
+ORDER by
+    ts desc
+LIMIT
+    1;`
+
+const CreateTMPMxid = `CREATE TEMPORARY TABLE tmp_getmxids (medium VARCHAR(16), address VARCHAR(256)) ON COMMIT DROP;CREATE INDEX tmp_getmxids_medium_lower_address ON tmp_getmxids (medium, lower(address));`
+
+const GlobalGetMxids = `SELECT
+    gte.medium,
+    gte.address,
+    gte.ts,
+    gte.mxid
+FROM
+    global_threepid_associations gte
+    JOIN tmp_getmxids ON gte.medium = tmp_getmxids.medium
+    AND lower(gte.address) = lower(tmp_getmxids.address)
+WHERE
+    gte.notBefore < $1
+    AND gte.notAfter > $2
+ORDER BY
+    gte.medium,
+    gte.address,
+	gte.ts DESC;`
+
+const GlobalLastIDFromServer = `SELECT
+    max(originId),
+    count(originId)
+FROM
+    global_threepid_associations
+WHERE
+    originServer = $1;`
+
+const GlobalRemoveAssociation = `DELETE FROM
+    global_threepid_associations
+WHERE
+    medium = $1
+    AND address = $2`
+
+const GlobalAddAssociation = `insert into
+    global_threepid_associations (
+        medium,
+        address,
+        mxid,
+        ts,
+        notBefore,
+        notAfter,
+        originServer,
+        originId,
+        sgAssoc,
+        lookup_hash
+    )
+VALUES
+    ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) ON CONFLICT DO NOTHING;`
+
+const GetLookupPepper = `SELECT
+    lookup_pepper
+FROM
+    hashing_metadata
+LIMIT
+    1;`
+
+const DeleteLookupPepper = `DELETE FROM
+    hashing_metadata;`
+
+const SetLookupPepper = `INSERT INTO
+    hashing_metadata (lookup_pepper)
+VALUES
+    ($1);`
+
+const GlobalAssociationsAfterID = `SELECT
+    id,
+    medium,
+    address
+FROM
+    global_threepid_associations
+WHERE
+    id > $1
+ORDER by
+    id asc
+LIMIT
+    $2;`
+
+const SetLookupHash = `UPDATE
+    global_threepid_associations
+SET
+    lookup_hash = $1
+WHERE
+    id = $2;`
+
+// GlobalGetMxidsByHash is a format string, %s is replaced by the placeholders
+// of the hashes starting at $3.
+const GlobalGetMxidsByHash = `SELECT
+    lookup_hash,
+    mxid
+FROM
+    global_threepid_associations
+WHERE
+    notBefore < $1
+    AND notAfter > $2
+    AND lookup_hash IN (%s)
+ORDER BY
+    ts DESC;`
+
+const GetPeerByName = `SELECT
+    p.name,
+    p.port,
+    p.lastSentVersion,
+    pk.alg,
+    pk.key
+FROM
+    peers p,
+    peer_pubkeys pk
+WHERE
+    p.name = $1
+    and pk.peername = p.name
+    and p.active = 1;`
+
+const GetAllPeers = `SELECT
+    p.name,
+    p.port,
+    p.lastSentVersion,
+    pk.alg,
+    pk.key
+FROM
+    peers p,
+    peer_pubkeys pk
+WHERE
+    pk.peername = p.name
+    and p.active = 1
+ORDER BY
+    p.name;`
+
+const SetLastSentVersionAndPokeSucceeded = `update
+    peers
+set
+    lastSentVersion = $1,
+    lastPokeSucceededAt = $2
+WHERE
+    name = $3;`
+
+const ListPeers = `SELECT
+    p.name,
+    p.port,
+    p.lastSentVersion,
+    p.lastPokeSucceededAt,
+    p.active,
+    pk.alg,
+    pk.key
+FROM
+    peers p
+    LEFT JOIN peer_pubkeys pk ON pk.peername = p.name
+ORDER BY
+    p.name;`
+
+const AddPeer = `INSERT INTO
+    peers (name, port, active)
+VALUES
+    ($1, $2, 0) ON CONFLICT (name) DO
+UPDATE
+SET
+    port = EXCLUDED.port;`
+
+const CountPeers = `SELECT
+    COUNT(*)
+FROM
+    peers
+WHERE
+    name = $1;`
+
+const DeletePeerKeys = `DELETE FROM
+    peer_pubkeys
+WHERE
+    peername = $1;`
+
+const DeletePeer = `DELETE FROM
+    peers
+WHERE
+    name = $1;`
+
+const SetPeerKey = `INSERT INTO
+    peer_pubkeys (peername, alg, key)
+VALUES
+    ($1, $2, $3) ON CONFLICT (peername, alg) DO
+UPDATE
+SET
+    key = EXCLUDED.key;`
+
+const SetPeerActive = `UPDATE
+    peers
+SET
+    active = $1
+WHERE
+    name = $2;`
+
+const CountAssociationsAfterID = `SELECT
+    COUNT(*)
+FROM
+    local_threepid_associations
+WHERE
+    id > $1;`
+
+const LocalAddAssociation = `INSERT INTO
+    local_threepid_associations (
+        medium,
+        address,
+        mxid,
+        ts,
+        notBefore,
+        notAfter
+    )
+VALUES
+    ($1, $2, $3, $4, $5, $6);`
+
+// LocalDeleteAssociation is used to replace associations. Replaced associations
+// are deleted and inserted again so that they get a new id, which is what
+// replication uses to find changes.
+const LocalDeleteAssociation = `DELETE FROM
+    local_threepid_associations
+WHERE
+    medium = $1
+    AND address = $2;`
+
+const GetAssociationsAfterId = `SELECT
+    id,
+    medium,
+    address,
+    mxid,
+    ts,
+    notBefore,
+    notAfter
+FROM
+    local_threepid_associations
+WHERE
+    id > $1
+ORDER by
+    id asc
+LIMIT
+    $2;`
+
+const GetTokenSession = `SELECT
+    s.id,
+    s.medium,
+    s.address,
+    s.clientSecret,
+    s.validated,
+    s.mtime,
+    t.token,
+    t.sendAttemptNumber
+FROM
+    threepid_validation_sessions s,
+    threepid_token_auths t
+WHERE
+    s.medium = $1
+    and s.address = $2
+    and s.clientSecret = $3
+	and t.validationSession = s.id;`
+
+const CreateTokenSession = `insert into
+    threepid_token_auths (validationSession, token, sendAttemptNumber)
+values
+    ($1, $2, $3)`
+
+const AddValidationSession = `insert into
+    threepid_validation_sessions (medium, address, clientSecret, mtime)
+values
+    ($1, $2, $3, $4) RETURNING id;`
+
+const SetSendAttemptNumber = `update
+    threepid_token_auths
+set
+    sendAttemptNumber = $1
+WHERE
+    id = $2`
+
+const SetValidated = `update
+    threepid_validation_sessions
+set
+    validated = $1
+WHERE
+    id = $2;`
+
+const SetMtime = `update
+    threepid_validation_sessions
+set
+    mtime = $1
+WHERE
+    id = $2;`
+
+const GetSessionByID = `SELECT
+    id,
+    medium,
+    address,
+    clientSecret,
+    validated,
+    mtime
+FROM
+    threepid_validation_sessions
+WHERE
+    id = $1`
+
+const GetTokenSessionByID = `SELECT
+    s.id,
+    s.medium,
+    s.address,
+    s.clientSecret,
+    s.validated,
+    s.mtime,
+    t.token,
+    t.sendAttemptNumber
+FROM
+    threepid_validation_sessions s,
+    threepid_token_auths t
+WHERE
+    s.id = $1
+    and t.validationSession = s.id;`
+
+const AddAccount = `INSERT INTO
+    accounts (user_id, creation_ts)
+VALUES
+    ($1, $2) ON CONFLICT DO NOTHING;`
+
+const AddAccountToken = `INSERT INTO
+    tokens (token, user_id)
+VALUES
+    ($1, $2);`
+
+const GetAccountByToken = `SELECT
+    a.user_id,
+    a.creation_ts
+FROM
+    accounts a,
+    tokens t
+WHERE
+    t.token = $1
+    AND t.user_id = a.user_id;`
+
+const DeleteAccountToken = `DELETE FROM
+    tokens
+WHERE
+    token = $1;`
+
+const AddAcceptedTerms = `INSERT INTO
+    accepted_terms_urls (user_id, policy, version, url, creation_ts)
+VALUES
+    ($1, $2, $3, $4, $5) ON CONFLICT DO NOTHING;`
+
+const GetAcceptedTerms = `SELECT
+    policy,
+    version,
+    url
+FROM
+    accepted_terms_urls
+WHERE
+    user_id = $1;`
+
+const AddOnbindNotification = `INSERT INTO
+    onbind_notifications (destination, payload, next_attempt_ts, creation_ts)
+VALUES
+    ($1, $2, $3, $3) RETURNING id;`
+
+const GetDueOnbindNotifications = `SELECT
+    id,
+    destination,
+    payload,
+    attempts,
+    next_attempt_ts
+FROM
+    onbind_notifications
+WHERE
+    next_attempt_ts <= $1
+ORDER BY
+    next_attempt_ts,
+    id
+LIMIT
+    $2;`
+
+const NextOnbindNotificationTS = `SELECT
+    MIN(next_attempt_ts)
+FROM
+    onbind_notifications;`
+
+const RetryOnbindNotification = `UPDATE
+    onbind_notifications
+SET
+    attempts = attempts + 1,
+    next_attempt_ts = $2,
+    last_error = $3
+WHERE
+    id = $1;`
+
+const DeleteOnbindNotification = `DELETE FROM
+    onbind_notifications
+WHERE
+    id = $1;`
+
+const ImportInviteToken = `INSERT INTO
+    invite_tokens (
+        id,
+        medium,
+        address,
+        room_id,
+        sender,
+        token,
+        received_ts,
+        sent_ts
+    )
+VALUES
+    ($1, $2, $3, $4, $5, $6, $7, $8) ON CONFLICT DO NOTHING;`
+
+const ImportEphemeralPublicKey = `INSERT INTO
+    ephemeral_public_keys (id, public_key, verify_count, persistence_ts)
+VALUES
+    ($1, $2, $3, $4) ON CONFLICT DO NOTHING;`
+
+const ImportPeer = `INSERT INTO
+    peers (
+        id,
+        name,
+        port,
+        lastSentVersion,
+        lastPokeSucceededAt,
+        active
+    )
+VALUES
+    ($1, $2, $3, $4, $5, $6) ON CONFLICT DO NOTHING;`
+
+const ImportPeerPubkey = `INSERT INTO
+    peer_pubkeys (id, peername, alg, key)
+VALUES
+    ($1, $2, $3, $4) ON CONFLICT DO NOTHING;`
+
+const ImportLocalAssociation = `INSERT INTO
+    local_threepid_associations (
+        id,
+        medium,
+        address,
+        mxid,
+        ts,
+        notBefore,
+        notAfter
+    )
+VALUES
+    ($1, $2, $3, $4, $5, $6, $7) ON CONFLICT DO NOTHING;`
+
+const ImportGlobalAssociation = `INSERT INTO
+    global_threepid_associations (
+        id,
+        medium,
+        address,
+        mxid,
+        ts,
+        notBefore,
+        notAfter,
+        originServer,
+        originId,
+        sgAssoc,
+        lookup_hash
+    )
+VALUES
+    ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) ON CONFLICT DO NOTHING;`
+
+const ImportValidationSession = `INSERT INTO
+    threepid_validation_sessions (
+        id,
+        medium,
+        address,
+        clientSecret,
+        validated,
+        mtime
+    )
+VALUES
+    ($1, $2, $3, $4, $5, $6) ON CONFLICT DO NOTHING;`
+
+const ImportTokenAuth = `INSERT INTO
+    threepid_token_auths (
+        id,
+        validationSession,
+        token,
+        sendAttemptNumber
+    )
+VALUES
+    ($1, $2, $3, $4) ON CONFLICT DO NOTHING;`
+
+const GetImportCheckpoint = `SELECT
+    last_id
+FROM
+    sydent_import
+WHERE
+    table_name = $1;`
+
+const SetImportCheckpoint = `INSERT INTO
+    sydent_import (table_name, last_id)
+VALUES
+    ($1, $2) ON CONFLICT (table_name) DO
+UPDATE
+SET
+    last_id = excluded.last_id;`
+
+// ResetSequence is a format string, %[1]s is replaced by a table name. It moves
+// the id sequence of the table past rows which were inserted with explicit ids.
+const ResetSequence = `SELECT
+    setval(
+        pg_get_serial_sequence('%[1]s', 'id'),
+        coalesce(max(id), 0) + 1,
+        false
+    )
+FROM
+    %[1]s;`
+
+const GetExpiredSessions = `SELECT
+    id
+FROM
+    threepid_validation_sessions
+WHERE
+    mtime < $1
+ORDER BY
+    id
+LIMIT
+    $2;`
+
+// DeleteSessionTokenAuths is a format string, %s is replaced by the
+// placeholders of the session ids.
+const DeleteSessionTokenAuths = `DELETE FROM
+    threepid_token_auths
+WHERE
+    validationSession IN (%s);`
+
+// DeleteSessions is a format string, %s is replaced by the placeholders of the
+// session ids.
+const DeleteSessions = `DELETE FROM
+    threepid_validation_sessions
+WHERE
+    id IN (%s);`
+
+const ReapInviteTokens = `DELETE FROM
+    invite_tokens
+WHERE
+    id IN (
+        SELECT
+            id
+        FROM
+            invite_tokens
+        WHERE
+            received_ts < $1
+        ORDER BY
+            id
+        LIMIT
+            $2
+    );`
+
+const ReapEphemeralPublicKeys = `DELETE FROM
+    ephemeral_public_keys
+WHERE
+    id IN (
+        SELECT
+            id
+        FROM
+            ephemeral_public_keys
+        WHERE
+            persistence_ts < $1
+        ORDER BY
+            id
+        LIMIT
+            $2
+    );`
+
+const AddEmail = `INSERT INTO
+    email_outbox (
+        sender,
+        recipients,
+        domain,
+        message,
+        next_attempt_ts,
+        creation_ts
+    )
+VALUES
+    ($1, $2, $3, $4, $5, $5) RETURNING id;`
+
+const GetDueEmails = `SELECT
+    id,
+    sender,
+    recipients,
+    domain,
+    message,
+    state,
+    attempts,
+    next_attempt_ts,
+    creation_ts,
+    last_error
+FROM
+    email_outbox
+WHERE
+    state = 'pending'
+    AND next_attempt_ts <= $1
+ORDER BY
+    next_attempt_ts
+LIMIT
+    $2;`
+
+const ListEmails = `SELECT
+    id,
+    sender,
+    recipients,
+    domain,
+    message,
+    state,
+    attempts,
+    next_attempt_ts,
+    creation_ts,
+    last_error
+FROM
+    email_outbox
+WHERE
+    state = $1
+ORDER BY
+    id
+LIMIT
+    $2;`
+
+// ClaimEmail moves next_attempt_ts of a due email past the time it takes to
+// send it, no rows are updated when another worker claimed it first.
+const ClaimEmail = `UPDATE
+    email_outbox
+SET
+    next_attempt_ts = $3
+WHERE
+    id = $1
+    AND next_attempt_ts = $2
+    AND state = 'pending';`
+
+const RetryEmail = `UPDATE
+    email_outbox
+SET
+    attempts = attempts + 1,
+    next_attempt_ts = $2,
+    last_error = $3
+WHERE
+    id = $1;`
+
+const DeadEmail = `UPDATE
+    email_outbox
+SET
+    attempts = attempts + 1,
+    state = 'dead',
+    last_error = $2
+WHERE
+    id = $1;`
+
+const RequeueEmail = `UPDATE
+    email_outbox
+SET
+    attempts = 0,
+    state = 'pending',
+    next_attempt_ts = $2
+WHERE
+    id = $1;`
+
+const DeleteEmail = `DELETE FROM
+    email_outbox
+WHERE
+    id = $1;`
+
+const NextEmailTS = `SELECT
+    MIN(next_attempt_ts)
+FROM
+    email_outbox
+WHERE
+    state = 'pending';`
+
+const TakeRateLimitToken = `INSERT INTO rate_limits
+    (key, tokens, updated_ts)
+VALUES
+    ($1, CAST($2 AS DOUBLE PRECISION) - 1, CAST($3 AS BIGINT)) ON CONFLICT (key) DO
+UPDATE
+SET
+    tokens = LEAST(
+        CAST($2 AS DOUBLE PRECISION),
+        rate_limits.tokens + GREATEST(CAST($3 AS BIGINT) - rate_limits.updated_ts, 0) / CAST($4 AS DOUBLE PRECISION)
+    ) - 1,
+    updated_ts = CAST($3 AS BIGINT)
+WHERE
+    LEAST(
+        CAST($2 AS DOUBLE PRECISION),
+        rate_limits.tokens + GREATEST(CAST($3 AS BIGINT) - rate_limits.updated_ts, 0) / CAST($4 AS DOUBLE PRECISION)
+    ) >= 1;`
+
+const GetRateLimit = `SELECT
+    tokens,
+    updated_ts
+FROM
+    rate_limits
+WHERE
+    key = $1;`
+
+const ReapRateLimits = `DELETE FROM
+    rate_limits
+WHERE
+    key IN (
+        SELECT
+            key
+        FROM
+            rate_limits
+        WHERE
+            updated_ts < $1
+        ORDER BY
+            updated_ts
+        LIMIT
+            $2
+    );`
+
+const LocalAssociationAddresses = `SELECT
+    id,
+    medium,
+    address,
+    COALESCE(mxid, '')
+FROM
+    local_threepid_associations
+WHERE
+    id > $1
+ORDER BY
+    id
+LIMIT
+    $2;`
+
+const SetLocalAssociationAddress = `UPDATE
+    local_threepid_associations
+SET
+    address = $1
+WHERE
+    id = $2;`
+
+const GlobalAssociationAddresses = `SELECT
+    id,
+    medium,
+    address,
+    mxid
+FROM
+    global_threepid_associations
+WHERE
+    id > $1
+ORDER BY
+    id
+LIMIT
+    $2;`
+
+const SetGlobalAssociationAddress = `UPDATE
+    global_threepid_associations
+SET
+    address = $1,
+    lookup_hash = $2
+WHERE
+    id = $3;`
+
+const ValidationSessionAddresses = `SELECT
+    id,
+    medium,
+    address,
+    ''
+FROM
+    threepid_validation_sessions
+WHERE
+    id > $1
+ORDER BY
+    id
+LIMIT
+    $2;`
+
+const SetValidationSessionAddress = `UPDATE
+    threepid_validation_sessions
+SET
+    address = $1
+WHERE
+    id = $2;`
+
+const InviteTokenAddresses = `SELECT
+    id,
+    medium,
+    address,
+    ''
+FROM
+    invite_tokens
+WHERE
+    id > $1
+ORDER BY
+    id
+LIMIT
+    $2;`
+
+const SetInviteTokenAddress = `UPDATE
+    invite_tokens
+SET
+    address = $1
+WHERE
+    id = $2;`
+
+const GetLocalAssociation = `SELECT
+    id,
+    medium,
+    address,
+    mxid,
+    ts,
+    notBefore,
+    notAfter
+FROM
+    local_threepid_associations
+WHERE
+    medium = $1
+    AND address = $2;`
+
+const GlobalAssociationsFor = `SELECT
+    id,
+    medium,
+    address,
+    mxid,
+    ts,
+    notBefore,
+    notAfter,
+    originServer,
+    originId
+FROM
+    global_threepid_associations
+WHERE
+    medium = $1
+    AND address = $2
+ORDER BY
+    id;`
+
+const AddAuditEntry = `INSERT INTO
+    audit_log (
+        ts,
+        action,
+        actor_kind,
+        actor,
+        ip,
+        medium,
+        address,
+        mxid,
+        peer,
+        before,
+        after
+    )
+VALUES
+    ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11);`
+
+const QueryAuditLog = `SELECT
+    id,
+    ts,
+    action,
+    actor_kind,
+    actor,
+    ip,
+    medium,
+    address,
+    mxid,
+    peer,
+    before,
+    after
+FROM
+    audit_log
+WHERE
+    id > $1
+    AND ($2::text = '' OR medium = $2)
+    AND ($3::text = '' OR address = $3)
+    AND ($4::text = '' OR mxid = $4)
+    AND ($5::text = '' OR peer = $5)
+    AND ($6::text = '' OR action = $6)
+    AND ts >= $7::bigint
+    AND ($8::bigint = 0 OR ts < $8)
+ORDER BY
+    id
+LIMIT
+    $9;`
+
+const LocalAssociationsForMxid = `SELECT
+    id,
+    medium,
+    address,
+    mxid,
+    ts,
+    notBefore,
+    notAfter
+FROM
+    local_threepid_associations
+WHERE
+    mxid = $1
+ORDER BY
+    id;`
+
+const GlobalAssociationsForMxid = `SELECT
+    id,
+    medium,
+    address,
+    mxid,
+    ts,
+    notBefore,
+    notAfter,
+    originServer,
+    originId
+FROM
+    global_threepid_associations
+WHERE
+    mxid = $1
+ORDER BY
+    id;`
+
+const ValidationSessionsFor = `SELECT
+    id,
+    medium,
+    address,
+    validated,
+    mtime
+FROM
+    threepid_validation_sessions
+WHERE
+    medium = $1
+    AND address = $2
+ORDER BY
+    id;`
+
+const DeleteInviteTokensFor = `DELETE FROM
+    invite_tokens
+WHERE
+    medium = $1
+    AND address = $2;`
+
+const DeleteTokenAuthsFor = `DELETE FROM
+    threepid_token_auths
+WHERE
+    validationSession IN (
+        SELECT
+            id
+        FROM
+            threepid_validation_sessions
+        WHERE
+            medium = $1
+            AND address = $2
+    );`
+
+const DeleteValidationSessionsFor = `DELETE FROM
+    threepid_validation_sessions
+WHERE
+    medium = $1
+    AND address = $2;`
+
+const EmailsAfter = `SELECT
+    id,
+    sender,
+    recipients,
+    domain,
+    message,
+    state,
+    attempts,
+    next_attempt_ts,
+    creation_ts,
+    last_error
+FROM
+    email_outbox
+WHERE
+    id > $1
+ORDER BY
+    id
+LIMIT
+    $2;`
+
+// RedactAuditAddress blanks the address, matrix id, ip and snapshots of the
+// audit log entries about an erased address.
+const RedactAuditAddress = `UPDATE
+    audit_log
+SET
+    address = '',
+    mxid = '',
+    ip = '',
+    before = NULL,
+    after = NULL
+WHERE
+    medium = $1
+    AND address = $2;`
+
+// RedactAuditMxid blanks the matrix id, ip and snapshots of the audit log
+// entries about an erased matrix id, and the actor of the entries it made as
+// an account.
+const RedactAuditMxid = `UPDATE
+    audit_log
+SET
+    mxid = CASE WHEN mxid = $1 THEN '' ELSE mxid END,
+    actor = CASE WHEN actor_kind = $2 AND actor = $1 THEN '' ELSE actor END,
+    ip = '',
+    before = NULL,
+    after = NULL
+WHERE
+    mxid = $1
+    OR (actor_kind = $2 AND actor = $1);`
+
+// Param returns the placeholder of the query argument at idx, starting at 1.
+func Param(idx int) string {
+	return fmt.Sprintf("$%d", idx)
+}
@@ -0,0 +1,153 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gernest/sydent-go/models"
+)
+
+// batch is the maximum number of rows or parameters handled by a single
+// statement when working on many rows.
+const batch = 500
+
+// GetLookupPepper returns the pepper used for hashing 3pids for v2 lookups.
+// sql.ErrNoRows is returned when no pepper has been set yet.
+func GetLookupPepper(ctx context.Context, db models.Query, q Driver) (string, error) {
+	var pepper string
+	err := db.QueryRowContext(ctx, q.GetLookupPepper()).Scan(&pepper)
+	if err != nil {
+		return "", err
+	}
+	return pepper, nil
+}
+
+// RotateLookupPepper replaces the lookup pepper with pepper and recomputes the
+// lookup hashes of all global associations. This happens in one transaction so
+// lookups never see hashes computed with a different pepper than the one
+// advertised.
+func RotateLookupPepper(ctx context.Context, db models.Query, q Driver, pepper string) error {
+	tx, err := begin(ctx, db)
+	if err != nil {
+		return err
+	}
+	err = rotateLookupPepper(ctx, tx, q, pepper)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func rotateLookupPepper(ctx context.Context, tx models.Query, q Driver, pepper string) error {
+	_, err := tx.ExecContext(ctx, q.DeleteLookupPepper())
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, q.SetLookupPepper(), pepper)
+	if err != nil {
+		return err
+	}
+	var lastID int64
+	for {
+		as, err := globalAssociationsAfterID(ctx, tx, q, lastID, batch)
+		if err != nil {
+			return err
+		}
+		for _, a := range as {
+			_, err = tx.ExecContext(ctx, q.SetLookupHash(),
+				models.LookupHash(a.Medium, a.Address, pepper), a.ID,
+			)
+			if err != nil {
+				return err
+			}
+			lastID = a.ID
+		}
+		if len(as) < batch {
+			return nil
+		}
+	}
+}
+
+func globalAssociationsAfterID(ctx context.Context, db models.Query, q Driver, afterID, limit int64) ([]models.Association, error) {
+	rows, err := db.QueryContext(ctx, q.GlobalAssociationsAfterID(), afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var as []models.Association
+	for rows.Next() {
+		var a models.Association
+		err = rows.Scan(&a.ID, &a.Medium, &a.Address)
+		if err != nil {
+			return nil, err
+		}
+		as = append(as, a)
+	}
+	return as, rows.Err()
+}
+
+// GlobalGetMxidsByHash returns a map of lookup hash to the mxid of the most
+// recent valid association matching the hash. Hashes without associations are
+// not included.
+func GlobalGetMxidsByHash(ctx context.Context, db models.Query, q Driver, hashes []string) (map[string]string, error) {
+	result := make(map[string]string)
+	now := time.Now()
+	ts := models.MS(&now)
+	for len(hashes) > 0 {
+		n := len(hashes)
+		if n > batch {
+			n = batch
+		}
+		args := []interface{}{ts, ts}
+		params := make([]string, n)
+		for i, h := range hashes[:n] {
+			params[i] = q.Param(i + 3)
+			args = append(args, h)
+		}
+		rows, err := db.QueryContext(ctx,
+			fmt.Sprintf(q.GlobalGetMxidsByHash(), strings.Join(params, ",")), args...,
+		)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var hash, mxid string
+			err = rows.Scan(&hash, &mxid)
+			if err != nil {
+				rows.Close()
+				return nil, err
+			}
+			if _, ok := result[hash]; !ok {
+				result[hash] = mxid
+			}
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+		hashes = hashes[n:]
+	}
+	return result, nil
+}
+
+// lookupHash returns the lookup hash of the 3pid using the current pepper. The
+// hash is not valid when there is no pepper yet, it will be computed when the
+// first pepper is set.
+func lookupHash(ctx context.Context, db models.Query, q Driver, medium, address string) (sql.NullString, error) {
+	pepper, err := GetLookupPepper(ctx, db, q)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return sql.NullString{}, nil
+		}
+		return sql.NullString{}, err
+	}
+	return sql.NullString{
+		String: models.LookupHash(medium, address, pepper),
+		Valid:  true,
+	}, nil
+}
@@ -0,0 +1,90 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/gernest/sydent-go/models"
+)
+
+func testHashedLookup(t *testing.T, ctx TestContext) {
+	now := models.Time()
+	alice := &models.Association{
+		Medium:    "email",
+		Address:   "Alice@Example.com",
+		MatrixID:  "@alice:example.com",
+		Timestamp: now,
+		NotBefore: now - 1000,
+		NotAfter:  now + 100000,
+	}
+	bob := &models.Association{
+		Medium:    "email",
+		Address:   "bob@example.com",
+		MatrixID:  "@bob:example.com",
+		Timestamp: now,
+		NotBefore: now - 1000,
+		NotAfter:  now + 100000,
+	}
+	t.Run("RotateLookupPepper", func(ts *testing.T) {
+		err := ctx.Store.GlobalAddAssociation(ctx.Ctx, alice, "example.com", 1, "{}")
+		if err != nil {
+			ts.Fatal(err)
+		}
+		err = ctx.Store.RotateLookupPepper(ctx.Ctx, "matrixrocks")
+		if err != nil {
+			ts.Fatal(err)
+		}
+		pepper, err := ctx.Store.GetLookupPepper(ctx.Ctx)
+		if err != nil {
+			ts.Fatal(err)
+		}
+		if pepper != "matrixrocks" {
+			ts.Errorf("expected matrixrocks got %s", pepper)
+		}
+	})
+	t.Run("GlobalGetMxidsByHash", func(ts *testing.T) {
+		err := ctx.Store.GlobalAddAssociation(ctx.Ctx, bob, "example.com", 2, "{}")
+		if err != nil {
+			ts.Fatal(err)
+		}
+		aliceHash := "4kenr7N9drpCJ4AfalmlGQVsOn3o2RHjkADUpXJWZUc"
+		bobHash := models.LookupHash(bob.Medium, bob.Address, "matrixrocks")
+		m, err := ctx.Store.GlobalGetMxidsByHash(ctx.Ctx, []string{aliceHash, bobHash, "unknown"})
+		if err != nil {
+			ts.Fatal(err)
+		}
+		expect := map[string]string{
+			aliceHash: alice.MatrixID,
+			bobHash:   bob.MatrixID,
+		}
+		if len(m) != len(expect) || m[aliceHash] != alice.MatrixID || m[bobHash] != bob.MatrixID {
+			ts.Errorf("expected %v got %v", expect, m)
+		}
+	})
+	t.Run("canonical", func(ts *testing.T) {
+		carol := &models.Association{
+			Medium:    "email",
+			Address:   "Straße@xn--bcher-kva.Example",
+			MatrixID:  "@carol:example.com",
+			Timestamp: now,
+			NotBefore: now - 1000,
+			NotAfter:  now + 100000,
+		}
+		err := ctx.Store.GlobalAddAssociation(ctx.Ctx, carol, "example.com", 3, "{}")
+		if err != nil {
+			ts.Fatal(err)
+		}
+		// the hashes are set again from the stored addresses.
+		err = ctx.Store.RotateLookupPepper(ctx.Ctx, "matrixrocks")
+		if err != nil {
+			ts.Fatal(err)
+		}
+		hash := models.LookupHash("email", "strasse@bücher.example", "matrixrocks")
+		m, err := ctx.Store.GlobalGetMxidsByHash(ctx.Ctx, []string{hash})
+		if err != nil {
+			ts.Fatal(err)
+		}
+		if m[hash] != carol.MatrixID {
+			ts.Errorf("expected the canonical address to match got %v", m)
+		}
+	})
+}
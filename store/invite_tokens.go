@@ -6,19 +6,38 @@ import (
 	"time"
 
 	"github.com/gernest/sydent-go/models"
-	"github.com/gernest/sydent-go/store/query"
 )
 
-func StoreToken(ctx context.Context, db models.Query, token models.InviteToken) error {
-	_, err := db.ExecContext(ctx, query.StoreToken,
+func StoreToken(ctx context.Context, db models.Query, q Driver, token models.InviteToken) error {
+	tx, err := begin(ctx, db)
+	if err != nil {
+		return err
+	}
+	ts := models.Time()
+	_, err = tx.ExecContext(ctx, q.StoreToken(),
 		token.Medium, token.Address, token.RoomID, token.Sender, token.Token,
-		time.Now().UTC().Unix(),
+		ts,
 	)
-	return err
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	err = auditChange(ctx, tx, q, models.AuditInviteStore, token.Medium, token.Address, "", nil, &auditInvite{
+		Medium:     token.Medium,
+		Address:    token.Address,
+		RoomID:     token.RoomID,
+		Sender:     token.Sender,
+		ReceivedTS: ts,
+	})
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
 }
 
-func GetTokens(ctx context.Context, db models.Query, medium, address string) ([]models.InviteToken, error) {
-	rows, err := db.QueryContext(ctx, query.GetTokens, medium, address)
+func GetTokens(ctx context.Context, db models.Query, q Driver, medium, address string) ([]models.InviteToken, error) {
+	rows, err := db.QueryContext(ctx, q.GetTokens(), medium, address)
 	if err != nil {
 		return nil, err
 	}
@@ -51,28 +70,52 @@ func GetTokens(ctx context.Context, db models.Query, medium, address string) ([]
 	return result, nil
 }
 
-func MarkTokensAsSent(ctx context.Context, db models.Query, medium, address string) error {
+// MarkTokensAsSent records that the invite tokens of medium and address were
+// sent, each token which was not sent before is recorded in the audit log.
+func MarkTokensAsSent(ctx context.Context, db models.Query, q Driver, medium, address string) error {
+	tx, err := begin(ctx, db)
+	if err != nil {
+		return err
+	}
+	before, err := unsentInvites(ctx, tx, q, medium, address)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
 	now := time.Now()
 	ts := models.MS(&now)
-	_, err := db.ExecContext(ctx, query.MarkTokensAsSent, ts, medium, address)
-	return err
+	_, err = tx.ExecContext(ctx, q.MarkTokensAsSent(), ts, medium, address)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	for _, b := range before {
+		after := *b
+		after.SentTS = ts
+		err = auditChange(ctx, tx, q, models.AuditInviteSent, medium, address, "", b, &after)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
 }
 
-func StoreEphemeralPublicKey(ctx context.Context, db models.Query, publicKey string) error {
+func StoreEphemeralPublicKey(ctx context.Context, db models.Query, q Driver, publicKey string) error {
 	now := time.Now()
 	ts := models.MS(&now)
-	_, err := db.ExecContext(ctx, query.StoreEphemeralPublicKey, publicKey, ts)
+	_, err := db.ExecContext(ctx, q.StoreEphemeralPublicKey(), publicKey, ts)
 	return err
 }
 
-func ValidateEphemeralPublicKey(ctx context.Context, db models.Query, publicKey string) error {
-	_, err := db.ExecContext(ctx, query.ValidateEphemeralPublicKey, publicKey)
+func ValidateEphemeralPublicKey(ctx context.Context, db models.Query, q Driver, publicKey string) error {
+	_, err := db.ExecContext(ctx, q.ValidateEphemeralPublicKey(), publicKey)
 	return err
 }
 
-func GetSenderForToken(ctx context.Context, db models.Query, token string) (string, error) {
+func GetSenderForToken(ctx context.Context, db models.Query, q Driver, token string) (string, error) {
 	var sender string
-	err := db.QueryRowContext(ctx, query.GetSenderForToken, token).Scan(&sender)
+	err := db.QueryRowContext(ctx, q.GetSenderForToken(), token).Scan(&sender)
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,88 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gernest/sydent-go/models"
+)
+
+// ReapValidationSessions deletes at most limit validation sessions which were
+// last modified before the unix time in milliseconds before, together with
+// their token auths. It returns the number of deleted sessions and token auths.
+func ReapValidationSessions(ctx context.Context, db models.Query, q Driver, before int64, limit int) (sessions, tokenAuths int64, err error) {
+	ids, err := expiredSessions(ctx, db, q, before, limit)
+	if err != nil || len(ids) == 0 {
+		return 0, 0, err
+	}
+	params := make([]string, len(ids))
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		params[i] = q.Param(i + 1)
+		args[i] = id
+	}
+	in := strings.Join(params, ",")
+	tx, err := begin(ctx, db)
+	if err != nil {
+		return 0, 0, err
+	}
+	r, err := tx.ExecContext(ctx, fmt.Sprintf(q.DeleteSessionTokenAuths(), in), args...)
+	if err != nil {
+		tx.Rollback()
+		return 0, 0, err
+	}
+	tokenAuths, err = r.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return 0, 0, err
+	}
+	r, err = tx.ExecContext(ctx, fmt.Sprintf(q.DeleteSessions(), in), args...)
+	if err != nil {
+		tx.Rollback()
+		return 0, 0, err
+	}
+	sessions, err = r.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return 0, 0, err
+	}
+	return sessions, tokenAuths, tx.Commit()
+}
+
+func expiredSessions(ctx context.Context, db models.Query, q Driver, before int64, limit int) ([]int64, error) {
+	rows, err := db.QueryContext(ctx, q.GetExpiredSessions(), before, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// ReapInviteTokens deletes at most limit invite tokens received before the unix
+// time in milliseconds before.
+func ReapInviteTokens(ctx context.Context, db models.Query, q Driver, before int64, limit int) (int64, error) {
+	return execCount(ctx, db, q.ReapInviteTokens(), before, limit)
+}
+
+// ReapEphemeralPublicKeys deletes at most limit ephemeral public keys stored
+// before the unix time in milliseconds before.
+func ReapEphemeralPublicKeys(ctx context.Context, db models.Query, q Driver, before int64, limit int) (int64, error) {
+	return execCount(ctx, db, q.ReapEphemeralPublicKeys(), before, limit)
+}
+
+func execCount(ctx context.Context, db models.Query, stmt string, args ...interface{}) (int64, error) {
+	r, err := db.ExecContext(ctx, stmt, args...)
+	if err != nil {
+		return 0, err
+	}
+	return r.RowsAffected()
+}
@@ -0,0 +1,1578 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gernest/sydent-go/models"
+)
+
+var _ Store = (*Memory)(nil)
+
+// Memory is a Store which keeps everything in memory. Nothing survives a
+// restart, it is meant for tests and small deployments which don't replicate.
+//
+// Addresses are converted to their canonical form like Identity does, see
+// Canonical.
+type Memory struct {
+	mu        sync.Locker
+	d         *memoryData
+	canonical *models.Canonicalizer
+}
+
+// NewMemory returns an empty Memory store.
+func NewMemory() *Memory {
+	return &Memory{mu: &sync.Mutex{}, d: newMemoryData()}
+}
+
+// Canonical makes m store and look up addresses in the canonical form of c,
+// see Matrix.Canonical.
+func (m *Memory) Canonical(c *models.Canonicalizer) *Memory {
+	m.canonical = c
+	return m
+}
+
+type memoryData struct {
+	inviteTokens  []memoryInviteToken
+	ephemeralKeys map[string]memoryEphemeralKey
+	peers         map[string]memoryPeer
+	local         []models.Association
+	localID       int64
+	global        []memoryGlobalAssociation
+	globalID      int64
+	sessions      map[int64]models.ValidationSession
+	sessionID     int64
+	tokenAuths    map[int64]memoryTokenAuth
+	tokenAuthID   int64
+	pepper        string
+	hasPepper     bool
+	accounts      map[string]int64
+	tokens        map[string]string
+	terms         map[string][]models.AcceptedTerms
+	onbind        []memoryOnbind
+	onbindID      int64
+	outbox        []models.OutboxEmail
+	outboxID      int64
+	buckets       map[string]memoryBucket
+	audit         []models.AuditEntry
+	auditID       int64
+}
+
+type memoryInviteToken struct {
+	models.InviteToken
+	receivedTS int64
+	sentTS     sql.NullInt64
+}
+
+type memoryPeer struct {
+	models.Peer
+	keys map[string]string
+}
+
+type memoryGlobalAssociation struct {
+	models.Association
+	originServer string
+	originID     int64
+	sgAssoc      string
+	lookupHash   sql.NullString
+}
+
+type memoryEphemeralKey struct {
+	verifyCount   int64
+	persistenceTS int64
+}
+
+type memoryTokenAuth struct {
+	session           int64
+	token             string
+	sendAttemptNumber int64
+}
+
+type memoryBucket struct {
+	tokens  float64
+	updated int64
+}
+
+type memoryOnbind struct {
+	models.OnbindNotification
+	lastError string
+}
+
+func newMemoryData() *memoryData {
+	return &memoryData{
+		ephemeralKeys: make(map[string]memoryEphemeralKey),
+		peers:         make(map[string]memoryPeer),
+		sessions:      make(map[int64]models.ValidationSession),
+		tokenAuths:    make(map[int64]memoryTokenAuth),
+		accounts:      make(map[string]int64),
+		tokens:        make(map[string]string),
+		terms:         make(map[string][]models.AcceptedTerms),
+		buckets:       make(map[string]memoryBucket),
+	}
+}
+
+func (d *memoryData) clone() *memoryData {
+	c := *d
+	c.inviteTokens = append([]memoryInviteToken(nil), d.inviteTokens...)
+	c.ephemeralKeys = make(map[string]memoryEphemeralKey, len(d.ephemeralKeys))
+	for k, v := range d.ephemeralKeys {
+		c.ephemeralKeys[k] = v
+	}
+	c.peers = make(map[string]memoryPeer, len(d.peers))
+	for k, v := range d.peers {
+		keys := make(map[string]string, len(v.keys))
+		for alg, key := range v.keys {
+			keys[alg] = key
+		}
+		v.keys = keys
+		c.peers[k] = v
+	}
+	c.local = append([]models.Association(nil), d.local...)
+	c.global = append([]memoryGlobalAssociation(nil), d.global...)
+	c.sessions = make(map[int64]models.ValidationSession, len(d.sessions))
+	for k, v := range d.sessions {
+		c.sessions[k] = v
+	}
+	c.tokenAuths = make(map[int64]memoryTokenAuth, len(d.tokenAuths))
+	for k, v := range d.tokenAuths {
+		c.tokenAuths[k] = v
+	}
+	c.accounts = make(map[string]int64, len(d.accounts))
+	for k, v := range d.accounts {
+		c.accounts[k] = v
+	}
+	c.tokens = make(map[string]string, len(d.tokens))
+	for k, v := range d.tokens {
+		c.tokens[k] = v
+	}
+	c.terms = make(map[string][]models.AcceptedTerms, len(d.terms))
+	for k, v := range d.terms {
+		c.terms[k] = append([]models.AcceptedTerms(nil), v...)
+	}
+	c.onbind = append([]memoryOnbind(nil), d.onbind...)
+	c.outbox = append([]models.OutboxEmail(nil), d.outbox...)
+	c.buckets = make(map[string]memoryBucket, len(d.buckets))
+	for k, v := range d.buckets {
+		c.buckets[k] = v
+	}
+	c.audit = append([]models.AuditEntry(nil), d.audit...)
+	return &c
+}
+
+// Tx runs fn on a copy of the data which replaces the data when fn succeeds.
+// The store is locked until fn returns.
+func (m *Memory) Tx(ctx context.Context, fn func(Store) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	tx := &Memory{mu: noLock{}, d: m.d.clone(), canonical: m.canonical}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	m.d = tx.d
+	return nil
+}
+
+// noLock is used by stores passed to Tx, the parent store is already locked.
+type noLock struct{}
+
+func (noLock) Lock()   {}
+func (noLock) Unlock() {}
+
+func (m *Memory) StoreToken(ctx context.Context, token models.InviteToken) error {
+	token.Address = m.canonical.Address(token.Medium, token.Address)
+	ts := models.Time()
+	e, err := newAuditEntry(ctx, models.AuditInviteStore, nil, &auditInvite{
+		Medium:     token.Medium,
+		Address:    token.Address,
+		RoomID:     token.RoomID,
+		Sender:     token.Sender,
+		ReceivedTS: ts,
+	})
+	if err != nil {
+		return err
+	}
+	e.Medium = token.Medium
+	e.Address = token.Address
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.d.inviteTokens = append(m.d.inviteTokens, memoryInviteToken{
+		InviteToken: token,
+		receivedTS:  ts,
+	})
+	m.d.addAudit(e)
+	return nil
+}
+
+func (m *Memory) GetTokens(ctx context.Context, medium, address string) ([]models.InviteToken, error) {
+	address = m.canonical.Address(medium, address)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []models.InviteToken
+	for _, t := range m.d.inviteTokens {
+		if t.Medium != medium || t.Address != address {
+			continue
+		}
+		token := models.InviteToken{
+			Medium:     t.Medium,
+			Address:    t.Address,
+			RoomID:     t.RoomID,
+			Sender:     t.Sender,
+			Token:      t.Token,
+			ReceivedAt: models.FromMS(t.receivedTS),
+		}
+		if t.sentTS.Valid {
+			token.SentAt = models.FromMS(t.sentTS.Int64)
+		}
+		result = append(result, token)
+	}
+	return result, nil
+}
+
+func (m *Memory) MarkTokensAsSent(ctx context.Context, medium, address string) error {
+	address = m.canonical.Address(medium, address)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ts := models.Time()
+	var entries []models.AuditEntry
+	for _, t := range m.d.inviteTokens {
+		if t.Medium != medium || t.Address != address || t.sentTS.Valid {
+			continue
+		}
+		before := auditInvite{
+			Medium:     t.Medium,
+			Address:    t.Address,
+			RoomID:     t.RoomID,
+			Sender:     t.Sender,
+			ReceivedTS: t.receivedTS,
+		}
+		after := before
+		after.SentTS = ts
+		e, err := newAuditEntry(ctx, models.AuditInviteSent, &before, &after)
+		if err != nil {
+			return err
+		}
+		e.Medium = medium
+		e.Address = address
+		entries = append(entries, e)
+	}
+	for i, t := range m.d.inviteTokens {
+		if t.Medium == medium && t.Address == address {
+			m.d.inviteTokens[i].sentTS = sql.NullInt64{Int64: ts, Valid: true}
+		}
+	}
+	for _, e := range entries {
+		m.d.addAudit(e)
+	}
+	return nil
+}
+
+func (m *Memory) StoreEphemeralPublicKey(ctx context.Context, publicKey string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.d.ephemeralKeys[publicKey]; ok {
+		return errors.New("store: ephemeral public key already exists")
+	}
+	m.d.ephemeralKeys[publicKey] = memoryEphemeralKey{persistenceTS: models.Time()}
+	return nil
+}
+
+func (m *Memory) ValidateEphemeralPublicKey(ctx context.Context, publicKey string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if k, ok := m.d.ephemeralKeys[publicKey]; ok {
+		k.verifyCount++
+		m.d.ephemeralKeys[publicKey] = k
+	}
+	return nil
+}
+
+func (m *Memory) GetSenderForToken(ctx context.Context, token string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, t := range m.d.inviteTokens {
+		if t.Token == token {
+			return t.Sender, nil
+		}
+	}
+	return "", sql.ErrNoRows
+}
+
+// validGlobal returns valid global associations for the 3pid, most recent
+// first.
+func (d *memoryData) validGlobal(medium, address string) []memoryGlobalAssociation {
+	ts := models.Time()
+	var o []memoryGlobalAssociation
+	for _, a := range d.global {
+		if a.Medium == medium && strings.EqualFold(a.Address, address) &&
+			a.NotBefore < ts && a.NotAfter > ts {
+			o = append(o, a)
+		}
+	}
+	sort.SliceStable(o, func(i, j int) bool {
+		return o[i].Timestamp > o[j].Timestamp
+	})
+	return o
+}
+
+func (m *Memory) SignedAssociationStringForThreepid(ctx context.Context, medium, address string) (string, error) {
+	address = m.canonical.Address(medium, address)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if as := m.d.validGlobal(medium, address); len(as) > 0 {
+		return as[0].sgAssoc, nil
+	}
+	return "", sql.ErrNoRows
+}
+
+func (m *Memory) GlobalGetMxid(ctx context.Context, medium, address string) (string, error) {
+	address = m.canonical.Address(medium, address)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if as := m.d.validGlobal(medium, address); len(as) > 0 {
+		return as[0].MatrixID, nil
+	}
+	return "", sql.ErrNoRows
+}
+
+func (m *Memory) GlobalGetMxids(ctx context.Context, ids [][]string) ([]models.Association, error) {
+	ids = canonicalIDs(m.canonical, ids)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var results []models.Association
+	for _, id := range ids {
+		if len(id) != 2 {
+			continue
+		}
+		for _, a := range m.d.validGlobal(id[0], id[1]) {
+			results = append(results, models.Association{
+				Medium:    a.Medium,
+				Address:   a.Address,
+				Timestamp: a.Timestamp,
+				MatrixID:  a.MatrixID,
+			})
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Medium != b.Medium {
+			return a.Medium < b.Medium
+		}
+		if a.Address != b.Address {
+			return a.Address < b.Address
+		}
+		return a.Timestamp > b.Timestamp
+	})
+	return results, nil
+}
+
+func (m *Memory) GlobalRemoveAssociation(ctx context.Context, medium, address string) error {
+	address = m.canonical.Address(medium, address)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.d.removeGlobal(ctx, medium, address)
+}
+
+// removeGlobal deletes the global associations of the 3pid, each of them is
+// recorded in the audit log.
+func (d *memoryData) removeGlobal(ctx context.Context, medium, address string) error {
+	var entries []models.AuditEntry
+	for _, a := range d.global {
+		if a.Medium != medium || a.Address != address {
+			continue
+		}
+		e, err := newAuditEntry(ctx, models.AuditGlobalRemove, a.snapshot(), nil)
+		if err != nil {
+			return err
+		}
+		e.Medium = medium
+		e.Address = address
+		e.MatrixID = a.MatrixID
+		entries = append(entries, e)
+	}
+	o := d.global[:0]
+	for _, a := range d.global {
+		if a.Medium != medium || a.Address != address {
+			o = append(o, a)
+		}
+	}
+	d.global = o
+	for _, e := range entries {
+		d.addAudit(e)
+	}
+	return nil
+}
+
+func (a memoryGlobalAssociation) snapshot() *auditAssociation {
+	s := newAuditAssociation(&a.Association)
+	s.OriginServer = a.originServer
+	s.OriginID = a.originID
+	return s
+}
+
+func (m *Memory) GlobalAddAssociation(ctx context.Context, as *models.Association, originServer string, originID int64, rawSgnAssoc string) error {
+	as.Address = m.canonical.Address(as.Medium, as.Address)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, a := range m.d.global {
+		if a.originServer == originServer && a.originID == originID {
+			return nil
+		}
+	}
+	a := memoryGlobalAssociation{
+		Association: models.Association{
+			Medium:    as.Medium,
+			Address:   as.Address,
+			MatrixID:  as.MatrixID,
+			Timestamp: as.Timestamp,
+			NotBefore: as.NotBefore,
+			NotAfter:  as.NotAfter,
+		},
+		originServer: originServer,
+		originID:     originID,
+		sgAssoc:      rawSgnAssoc,
+	}
+	if m.d.hasPepper {
+		a.lookupHash = sql.NullString{
+			String: models.LookupHash(as.Medium, as.Address, m.d.pepper),
+			Valid:  true,
+		}
+	}
+	e, err := newAuditEntry(ctx, models.AuditGlobalAdd, nil, a.snapshot())
+	if err != nil {
+		return err
+	}
+	e.Medium = as.Medium
+	e.Address = as.Address
+	e.MatrixID = as.MatrixID
+	m.d.globalID++
+	a.ID = m.d.globalID
+	m.d.global = append(m.d.global, a)
+	m.d.addAudit(e)
+	return nil
+}
+
+func (m *Memory) LocalAddOrUpdateAssociation(ctx context.Context, as *models.Association) error {
+	as.Address = m.canonical.Address(as.Medium, as.Address)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	after := models.Association{
+		Medium:    as.Medium,
+		Address:   as.Address,
+		MatrixID:  as.MatrixID,
+		Timestamp: as.Timestamp,
+		NotBefore: as.NotBefore,
+		NotAfter:  as.NotAfter,
+	}
+	e, err := newAuditEntry(ctx, models.AuditBind,
+		newAuditAssociation(m.d.getLocal(as.Medium, as.Address)), newAuditAssociation(&after),
+	)
+	if err != nil {
+		return err
+	}
+	e.Medium = as.Medium
+	e.Address = as.Address
+	e.MatrixID = as.MatrixID
+	m.d.replaceLocal(after)
+	m.d.addAudit(e)
+	return nil
+}
+
+// getLocal returns a copy of the local association of the 3pid, nil is
+// returned when there is none.
+func (d *memoryData) getLocal(medium, address string) *models.Association {
+	for _, a := range d.local {
+		if a.Medium == medium && a.Address == address {
+			return &a
+		}
+	}
+	return nil
+}
+
+// addAudit appends e to the audit log with the next id.
+func (d *memoryData) addAudit(e models.AuditEntry) {
+	d.auditID++
+	e.ID = d.auditID
+	d.audit = append(d.audit, e)
+}
+
+// replaceLocal deletes the local association of the 3pid and appends as with a
+// new id.
+func (d *memoryData) replaceLocal(as models.Association) {
+	o := d.local[:0]
+	for _, a := range d.local {
+		if a.Medium != as.Medium || a.Address != as.Address {
+			o = append(o, a)
+		}
+	}
+	d.localID++
+	as.ID = d.localID
+	d.local = append(o, as)
+}
+
+func (m *Memory) LocalRemoveAssociation(ctx context.Context, as *models.Association) error {
+	as.Address = m.canonical.Address(as.Medium, as.Address)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	before := m.d.getLocal(as.Medium, as.Address)
+	if before == nil || before.MatrixID == "" || before.MatrixID != as.MatrixID {
+		return nil
+	}
+	removed := models.Association{
+		Medium:    as.Medium,
+		Address:   as.Address,
+		Timestamp: models.Time(),
+	}
+	e, err := newAuditEntry(ctx, models.AuditUnbind,
+		newAuditAssociation(before), newAuditAssociation(&removed),
+	)
+	if err != nil {
+		return err
+	}
+	e.Medium = as.Medium
+	e.Address = as.Address
+	e.MatrixID = before.MatrixID
+	m.d.replaceLocal(removed)
+	m.d.addAudit(e)
+	return nil
+}
+
+func (m *Memory) GetAssociationsAfterID(ctx context.Context, afterID int64, limit int64) ([]models.Association, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var o []models.Association
+	for _, a := range m.d.local {
+		if int64(len(o)) >= limit {
+			break
+		}
+		if a.ID > afterID {
+			o = append(o, a)
+		}
+	}
+	return o, nil
+}
+
+func (m *Memory) GetLookupPepper(ctx context.Context) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.d.hasPepper {
+		return "", sql.ErrNoRows
+	}
+	return m.d.pepper, nil
+}
+
+func (m *Memory) RotateLookupPepper(ctx context.Context, pepper string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.d.pepper = pepper
+	m.d.hasPepper = true
+	for i, a := range m.d.global {
+		m.d.global[i].lookupHash = sql.NullString{
+			String: models.LookupHash(a.Medium, a.Address, pepper),
+			Valid:  true,
+		}
+	}
+	return nil
+}
+
+func (m *Memory) GlobalGetMxidsByHash(ctx context.Context, hashes []string) (map[string]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	want := make(map[string]bool, len(hashes))
+	for _, h := range hashes {
+		want[h] = true
+	}
+	ts := models.Time()
+	var as []memoryGlobalAssociation
+	for _, a := range m.d.global {
+		if a.lookupHash.Valid && want[a.lookupHash.String] &&
+			a.NotBefore < ts && a.NotAfter > ts {
+			as = append(as, a)
+		}
+	}
+	sort.SliceStable(as, func(i, j int) bool {
+		return as[i].Timestamp > as[j].Timestamp
+	})
+	result := make(map[string]string)
+	for _, a := range as {
+		if _, ok := result[a.lookupHash.String]; !ok {
+			result[a.lookupHash.String] = a.MatrixID
+		}
+	}
+	return result, nil
+}
+
+func (m *Memory) AddAccount(ctx context.Context, userID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.d.accounts[userID]; !ok {
+		m.d.accounts[userID] = models.Time()
+	}
+	return nil
+}
+
+func (m *Memory) AddAccountToken(ctx context.Context, userID, token string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.d.tokens[token]; ok {
+		return errors.New("store: token already exists")
+	}
+	m.d.tokens[token] = userID
+	return nil
+}
+
+func (m *Memory) GetAccountByToken(ctx context.Context, token string) (*models.Account, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	userID, ok := m.d.tokens[token]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	ts, ok := m.d.accounts[userID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &models.Account{UserID: userID, CreationTS: ts}, nil
+}
+
+func (m *Memory) DeleteAccountToken(ctx context.Context, token string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.d.tokens, token)
+	return nil
+}
+
+func (m *Memory) AddAcceptedTerms(ctx context.Context, userID string, terms []models.AcceptedTerms) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	accepted := m.d.terms[userID]
+next:
+	for _, t := range terms {
+		for _, a := range accepted {
+			if a.URL == t.URL && a.Version == t.Version {
+				continue next
+			}
+		}
+		accepted = append(accepted, t)
+	}
+	m.d.terms[userID] = accepted
+	return nil
+}
+
+func (m *Memory) GetAcceptedTerms(ctx context.Context, userID string) ([]models.AcceptedTerms, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]models.AcceptedTerms(nil), m.d.terms[userID]...), nil
+}
+
+func (m *Memory) AddOnbindNotification(ctx context.Context, destination, payload string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.d.onbindID++
+	m.d.onbind = append(m.d.onbind, memoryOnbind{
+		OnbindNotification: models.OnbindNotification{
+			ID:          m.d.onbindID,
+			Destination: destination,
+			Payload:     payload,
+			NextAttempt: models.Time(),
+		},
+	})
+	return m.d.onbindID, nil
+}
+
+func (m *Memory) GetDueOnbindNotifications(ctx context.Context, now int64, limit int) ([]models.OnbindNotification, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var o []models.OnbindNotification
+	for _, n := range m.d.onbind {
+		if n.NextAttempt <= now {
+			o = append(o, n.OnbindNotification)
+		}
+	}
+	sort.SliceStable(o, func(i, j int) bool {
+		if o[i].NextAttempt != o[j].NextAttempt {
+			return o[i].NextAttempt < o[j].NextAttempt
+		}
+		return o[i].ID < o[j].ID
+	})
+	if len(o) > limit {
+		o = o[:limit]
+	}
+	return o, nil
+}
+
+func (m *Memory) NextOnbindNotificationTS(ctx context.Context) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.d.onbind) == 0 {
+		return 0, sql.ErrNoRows
+	}
+	ts := m.d.onbind[0].NextAttempt
+	for _, n := range m.d.onbind[1:] {
+		if n.NextAttempt < ts {
+			ts = n.NextAttempt
+		}
+	}
+	return ts, nil
+}
+
+func (m *Memory) RetryOnbindNotification(ctx context.Context, id, next int64, lastErr string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, n := range m.d.onbind {
+		if n.ID == id {
+			m.d.onbind[i].Attempts++
+			m.d.onbind[i].NextAttempt = next
+			m.d.onbind[i].lastError = lastErr
+		}
+	}
+	return nil
+}
+
+func (m *Memory) DeleteOnbindNotification(ctx context.Context, id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	o := m.d.onbind[:0]
+	for _, n := range m.d.onbind {
+		if n.ID != id {
+			o = append(o, n)
+		}
+	}
+	m.d.onbind = o
+	return nil
+}
+
+// sortedPeers returns peers ordered by name.
+func (d *memoryData) sortedPeers() []memoryPeer {
+	o := make([]memoryPeer, 0, len(d.peers))
+	for _, p := range d.peers {
+		o = append(o, p)
+	}
+	sort.Slice(o, func(i, j int) bool {
+		return o[i].Name < o[j].Name
+	})
+	return o
+}
+
+func (p memoryPeer) publicKeys() map[string]string {
+	keys := make(map[string]string, len(p.keys))
+	for alg, key := range p.keys {
+		keys[alg] = key
+	}
+	return keys
+}
+
+func (m *Memory) GetPeerByName(ctx context.Context, name string) (*models.Peer, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, ok := m.d.peers[name]
+	if !ok || p.Active != 1 || len(p.keys) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return &models.Peer{
+		Name:            p.Name,
+		Port:            p.Port,
+		LastSentVersion: p.LastSentVersion,
+		PublicKeys:      p.publicKeys(),
+	}, nil
+}
+
+func (m *Memory) GetAllPeers(ctx context.Context) ([]models.Peer, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var peers []models.Peer
+	for _, p := range m.d.sortedPeers() {
+		if p.Active != 1 || len(p.keys) == 0 {
+			continue
+		}
+		peers = append(peers, models.Peer{
+			Name:            p.Name,
+			Port:            p.Port,
+			LastSentVersion: p.LastSentVersion,
+			Active:          1,
+			PublicKeys:      p.publicKeys(),
+		})
+	}
+	return peers, nil
+}
+
+func (m *Memory) SetLastSentVersionAndPokeSucceeded(ctx context.Context, peerName string, lastSentVersion, lastPokeSucceeded int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if p, ok := m.d.peers[peerName]; ok {
+		p.LastSentVersion = sql.NullInt64{Int64: lastSentVersion, Valid: true}
+		p.LastPokeSucceededAt = sql.NullInt64{Int64: lastPokeSucceeded, Valid: true}
+		m.d.peers[peerName] = p
+	}
+	return nil
+}
+
+func (m *Memory) ListPeers(ctx context.Context) ([]models.Peer, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var peers []models.Peer
+	for _, p := range m.d.sortedPeers() {
+		peer := p.Peer
+		peer.PublicKeys = p.publicKeys()
+		peers = append(peers, peer)
+	}
+	return peers, nil
+}
+
+func (m *Memory) AddPeer(ctx context.Context, name string, port sql.NullInt64) error {
+	return m.changePeer(ctx, models.AuditPeerAdd, name, func(d *memoryData) error {
+		p, ok := d.peers[name]
+		if !ok {
+			p = memoryPeer{
+				Peer: models.Peer{Name: name},
+				keys: make(map[string]string),
+			}
+		}
+		p.Port = port
+		d.peers[name] = p
+		return nil
+	})
+}
+
+func (m *Memory) RemovePeer(ctx context.Context, name string) error {
+	return m.changePeer(ctx, models.AuditPeerRemove, name, func(d *memoryData) error {
+		if _, ok := d.peers[name]; !ok {
+			return sql.ErrNoRows
+		}
+		delete(d.peers, name)
+		return nil
+	})
+}
+
+func (m *Memory) SetPeerKey(ctx context.Context, name, alg, key string) error {
+	return m.changePeer(ctx, models.AuditPeerKey, name, func(d *memoryData) error {
+		p, ok := d.peers[name]
+		if !ok {
+			return sql.ErrNoRows
+		}
+		p.keys[alg] = key
+		return nil
+	})
+}
+
+func (m *Memory) SetPeerActive(ctx context.Context, name string, active bool) error {
+	return m.changePeer(ctx, models.AuditPeerActive, name, func(d *memoryData) error {
+		p, ok := d.peers[name]
+		if !ok {
+			return sql.ErrNoRows
+		}
+		p.Active = 0
+		if active {
+			p.Active = 1
+		}
+		d.peers[name] = p
+		return nil
+	})
+}
+
+// changePeer calls fn on a copy of the data which replaces the data when fn
+// succeeds, the peer name before and after fn is recorded in the audit log.
+func (m *Memory) changePeer(ctx context.Context, action, name string, fn func(*memoryData) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	d := m.d.clone()
+	before := d.peerSnapshot(name)
+	if err := fn(d); err != nil {
+		return err
+	}
+	e, err := newAuditEntry(ctx, action, before, d.peerSnapshot(name))
+	if err != nil {
+		return err
+	}
+	e.Peer = name
+	d.addAudit(e)
+	m.d = d
+	return nil
+}
+
+// peerSnapshot returns the snapshot of the peer name, nil is returned when it
+// doesn't exist.
+func (d *memoryData) peerSnapshot(name string) *auditPeer {
+	p, ok := d.peers[name]
+	if !ok {
+		return nil
+	}
+	peer := p.Peer
+	peer.PublicKeys = p.publicKeys()
+	return newAuditPeer(peer)
+}
+
+func (m *Memory) CountAssociationsAfterID(ctx context.Context, afterID int64) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var n int64
+	for _, a := range m.d.local {
+		if a.ID > afterID {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *Memory) SetSendAttemptNumber(ctx context.Context, sid int64, attemptNo int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if t, ok := m.d.tokenAuths[sid]; ok {
+		t.sendAttemptNumber = attemptNo
+		m.d.tokenAuths[sid] = t
+	}
+	return nil
+}
+
+func (m *Memory) SetValidated(ctx context.Context, sid string, validated int) error {
+	id, err := strconv.ParseInt(sid, 10, 64)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if s, ok := m.d.sessions[id]; ok {
+		s.Validated = validated
+		m.d.sessions[id] = s
+	}
+	return nil
+}
+
+func (m *Memory) SetMtime(ctx context.Context, sid int64, mtime int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if s, ok := m.d.sessions[sid]; ok {
+		s.Mtime = mtime
+		m.d.sessions[sid] = s
+	}
+	return nil
+}
+
+func (m *Memory) GetSessionByID(ctx context.Context, sid int64) (*models.ValidationSession, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.d.sessions[sid]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &s, nil
+}
+
+// tokenAuth returns the token of the session sid.
+func (d *memoryData) tokenAuth(sid int64) (memoryTokenAuth, bool) {
+	for id := int64(1); id <= d.tokenAuthID; id++ {
+		if t, ok := d.tokenAuths[id]; ok && t.session == sid {
+			return t, true
+		}
+	}
+	return memoryTokenAuth{}, false
+}
+
+func (m *Memory) GetTokenSessionByID(ctx context.Context, sid int64) (*models.TokenSession, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.d.sessions[sid]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	t, ok := m.d.tokenAuth(sid)
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	s.Token = t.token
+	return &models.TokenSession{
+		ValidationSession: s,
+		SendAttemptNumber: t.sendAttemptNumber,
+	}, nil
+}
+
+func (m *Memory) GlobalLastIDFromServer(ctx context.Context, originServer string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var id int64
+	found := false
+	for _, a := range m.d.global {
+		if a.originServer == originServer && (!found || a.originID > id) {
+			id = a.originID
+			found = true
+		}
+	}
+	if !found {
+		return 0, sql.ErrNoRows
+	}
+	return id, nil
+}
+
+func (m *Memory) GetOrCreateTokenSession(ctx context.Context, medium, address, clientSecret string) (*models.ValidationSession, error) {
+	address = m.canonical.Address(medium, address)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id := int64(1); id <= m.d.sessionID; id++ {
+		s, ok := m.d.sessions[id]
+		if !ok || s.Medium != medium || s.Address != address || s.ClientSecret != clientSecret {
+			continue
+		}
+		if t, ok := m.d.tokenAuth(id); ok {
+			s.Token = t.token
+			s.SendAttemptNumber = t.sendAttemptNumber
+			return &s, nil
+		}
+	}
+	m.d.sessionID++
+	s := models.ValidationSession{
+		ID:           m.d.sessionID,
+		Medium:       medium,
+		Address:      address,
+		ClientSecret: clientSecret,
+		Mtime:        models.Time(),
+	}
+	m.d.sessions[s.ID] = s
+	m.d.tokenAuthID++
+	t := memoryTokenAuth{
+		session:           s.ID,
+		token:             models.GenerateToken(medium),
+		sendAttemptNumber: -1,
+	}
+	m.d.tokenAuths[m.d.tokenAuthID] = t
+	s.Token = t.token
+	s.SendAttemptNumber = t.sendAttemptNumber
+	return &s, nil
+}
+
+func (m *Memory) GetValidatedSession(ctx context.Context, sid int64, clientSecret string) (*models.ValidationSession, error) {
+	sess, err := m.GetSessionByID(ctx, sid)
+	if err != nil {
+		return nil, err
+	}
+	return validatedSession(sess, clientSecret)
+}
+
+func (m *Memory) ReapValidationSessions(ctx context.Context, before int64, limit int) (sessions, tokenAuths int64, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id := int64(1); id <= m.d.sessionID && sessions < int64(limit); id++ {
+		s, ok := m.d.sessions[id]
+		if !ok || s.Mtime >= before {
+			continue
+		}
+		for tid, t := range m.d.tokenAuths {
+			if t.session == id {
+				delete(m.d.tokenAuths, tid)
+				tokenAuths++
+			}
+		}
+		delete(m.d.sessions, id)
+		sessions++
+	}
+	return sessions, tokenAuths, nil
+}
+
+func (m *Memory) ReapInviteTokens(ctx context.Context, before int64, limit int) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var n int64
+	o := m.d.inviteTokens[:0]
+	for _, t := range m.d.inviteTokens {
+		if t.receivedTS < before && n < int64(limit) {
+			n++
+			continue
+		}
+		o = append(o, t)
+	}
+	m.d.inviteTokens = o
+	return n, nil
+}
+
+func (m *Memory) ReapEphemeralPublicKeys(ctx context.Context, before int64, limit int) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var n int64
+	for k, v := range m.d.ephemeralKeys {
+		if n >= int64(limit) {
+			break
+		}
+		if v.persistenceTS < before {
+			delete(m.d.ephemeralKeys, k)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *Memory) AddEmail(ctx context.Context, from string, to []string, domain, message string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.d.outboxID++
+	now := models.Time()
+	m.d.outbox = append(m.d.outbox, models.OutboxEmail{
+		ID:          m.d.outboxID,
+		From:        from,
+		To:          append([]string(nil), to...),
+		Domain:      domain,
+		Message:     message,
+		State:       models.EmailPending,
+		NextAttempt: now,
+		Created:     now,
+	})
+	return m.d.outboxID, nil
+}
+
+func (m *Memory) GetDueEmails(ctx context.Context, now int64, limit int) ([]models.OutboxEmail, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var o []models.OutboxEmail
+	for _, e := range m.d.outbox {
+		if e.State == models.EmailPending && e.NextAttempt <= now {
+			o = append(o, e)
+		}
+	}
+	sort.SliceStable(o, func(i, j int) bool {
+		if o[i].NextAttempt != o[j].NextAttempt {
+			return o[i].NextAttempt < o[j].NextAttempt
+		}
+		return o[i].ID < o[j].ID
+	})
+	if len(o) > limit {
+		o = o[:limit]
+	}
+	return o, nil
+}
+
+func (m *Memory) ListEmails(ctx context.Context, state string, limit int) ([]models.OutboxEmail, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var o []models.OutboxEmail
+	for _, e := range m.d.outbox {
+		if len(o) == limit {
+			break
+		}
+		if e.State == state {
+			o = append(o, e)
+		}
+	}
+	return o, nil
+}
+
+// email calls fn with the email id, sql.ErrNoRows is returned when there is
+// no such email.
+func (m *Memory) email(id int64, fn func(*models.OutboxEmail) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.d.outbox {
+		if m.d.outbox[i].ID == id {
+			return fn(&m.d.outbox[i])
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (m *Memory) ClaimEmail(ctx context.Context, id, due, until int64) error {
+	return m.email(id, func(e *models.OutboxEmail) error {
+		if e.State != models.EmailPending || e.NextAttempt != due {
+			return sql.ErrNoRows
+		}
+		e.NextAttempt = until
+		return nil
+	})
+}
+
+func (m *Memory) RetryEmail(ctx context.Context, id, next int64, lastErr string) error {
+	err := m.email(id, func(e *models.OutboxEmail) error {
+		e.Attempts++
+		e.NextAttempt = next
+		e.LastError = lastErr
+		return nil
+	})
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	return err
+}
+
+func (m *Memory) DeadEmail(ctx context.Context, id int64, lastErr string) error {
+	err := m.email(id, func(e *models.OutboxEmail) error {
+		e.Attempts++
+		e.State = models.EmailDead
+		e.LastError = lastErr
+		return nil
+	})
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	return err
+}
+
+func (m *Memory) RequeueEmail(ctx context.Context, id int64) error {
+	return m.email(id, func(e *models.OutboxEmail) error {
+		e.Attempts = 0
+		e.State = models.EmailPending
+		e.NextAttempt = models.Time()
+		return nil
+	})
+}
+
+func (m *Memory) DeleteEmail(ctx context.Context, id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, e := range m.d.outbox {
+		if e.ID == id {
+			m.d.outbox = append(m.d.outbox[:i:i], m.d.outbox[i+1:]...)
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (m *Memory) NextEmailTS(ctx context.Context) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var ts int64
+	var ok bool
+	for _, e := range m.d.outbox {
+		if e.State == models.EmailPending && (!ok || e.NextAttempt < ts) {
+			ts = e.NextAttempt
+			ok = true
+		}
+	}
+	if !ok {
+		return 0, sql.ErrNoRows
+	}
+	return ts, nil
+}
+
+func (m *Memory) TakeRateLimitToken(ctx context.Context, key string, burst float64, interval, now int64) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	b, ok := m.d.buckets[key]
+	if !ok {
+		b = memoryBucket{tokens: burst, updated: now}
+	}
+	tokens := bucketTokens(b.tokens, b.updated, burst, interval, now)
+	if tokens < 1 {
+		return retryAfter(tokens, interval), nil
+	}
+	m.d.buckets[key] = memoryBucket{tokens: tokens - 1, updated: now}
+	return 0, nil
+}
+
+func (m *Memory) TakeRateLimitTokens(ctx context.Context, buckets []RateLimitBucket, now int64) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	tokens := make([]float64, len(buckets))
+	var retry int64
+	for i, b := range buckets {
+		tokens[i] = b.Burst
+		if v, ok := m.d.buckets[b.Key]; ok {
+			tokens[i] = bucketTokens(v.tokens, v.updated, b.Burst, b.Interval, now)
+		}
+		if r := retryAfter(tokens[i], b.Interval); r > retry {
+			retry = r
+		}
+	}
+	if retry > 0 {
+		return retry, nil
+	}
+	for i, b := range buckets {
+		m.d.buckets[b.Key] = memoryBucket{tokens: tokens[i] - 1, updated: now}
+	}
+	return 0, nil
+}
+
+func (m *Memory) ReapRateLimits(ctx context.Context, before int64, limit int) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var n int64
+	for k, b := range m.d.buckets {
+		if n >= int64(limit) {
+			break
+		}
+		if b.updated < before {
+			delete(m.d.buckets, k)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *Memory) AuditLog(ctx context.Context, q models.AuditQuery) ([]models.AuditEntry, error) {
+	if q.Medium != "" {
+		q.Address = m.canonical.Address(q.Medium, q.Address)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var o []models.AuditEntry
+	for _, e := range m.d.audit {
+		if len(o) >= q.Limit {
+			break
+		}
+		if e.ID <= q.AfterID || e.TS < q.Since || (q.Until != 0 && e.TS >= q.Until) {
+			continue
+		}
+		if !matchAudit(q.Medium, e.Medium) || !matchAudit(q.Address, e.Address) ||
+			!matchAudit(q.MatrixID, e.MatrixID) || !matchAudit(q.Peer, e.Peer) ||
+			!matchAudit(q.Action, e.Action) {
+			continue
+		}
+		o = append(o, e)
+	}
+	return o, nil
+}
+
+// matchAudit returns true if the audit entry field v matches the query field
+// want, empty query fields match everything.
+func matchAudit(want, v string) bool {
+	return want == "" || want == v
+}
+
+func (m *Memory) ExportSubject(ctx context.Context, s models.Subject) (*models.SubjectExport, error) {
+	s = canonicalSubject(m.canonical, s)
+	if err := validSubject(s); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	o := newSubjectExport(s)
+	local, global := m.d.subjectAssociations(s)
+	for _, a := range local {
+		o.LocalAssociations = append(o.LocalAssociations, models.SubjectAssociation(*a))
+	}
+	for _, a := range global {
+		o.GlobalAssociations = append(o.GlobalAssociations, models.SubjectAssociation(*a))
+	}
+	addresses := []models.Subject{{Medium: s.Medium, Address: s.Address}}
+	if s.MatrixID != "" {
+		addresses = subjectAddresses(append(local, global...))
+	}
+	in := make(map[models.Subject]bool)
+	for _, a := range addresses {
+		in[a] = true
+		for _, t := range m.d.inviteTokens {
+			if t.Medium != a.Medium || t.Address != a.Address {
+				continue
+			}
+			o.InviteTokens = append(o.InviteTokens, models.SubjectInvite{
+				Medium:     t.Medium,
+				Address:    t.Address,
+				RoomID:     t.RoomID,
+				Sender:     t.Sender,
+				ReceivedTS: t.receivedTS,
+				SentTS:     t.sentTS.Int64,
+			})
+		}
+		for id := int64(1); id <= m.d.sessionID; id++ {
+			v, ok := m.d.sessions[id]
+			if !ok || v.Medium != a.Medium || v.Address != a.Address {
+				continue
+			}
+			o.ValidationSessions = append(o.ValidationSessions, models.SubjectSession{
+				ID:        v.ID,
+				Medium:    v.Medium,
+				Address:   v.Address,
+				Validated: v.Validated == 1,
+				Mtime:     v.Mtime,
+			})
+		}
+	}
+	for _, e := range m.d.subjectEmails(m.canonical, addresses) {
+		o.Emails = append(o.Emails, models.SubjectEmail{
+			ID:      e.ID,
+			From:    e.From,
+			To:      append([]string(nil), e.To...),
+			State:   e.State,
+			Created: e.Created,
+			Message: e.Message,
+		})
+	}
+	for _, e := range m.d.audit {
+		if (s.MatrixID != "" && e.MatrixID == s.MatrixID) ||
+			in[models.Subject{Medium: e.Medium, Address: e.Address}] {
+			o.Audit = append(o.Audit, e)
+		}
+	}
+	return o, nil
+}
+
+// subjectAssociations returns snapshots of the local and global associations
+// of s, see ExportSubject.
+func (d *memoryData) subjectAssociations(s models.Subject) (local, global []*auditAssociation) {
+	match := func(a models.Association) bool {
+		if s.MatrixID != "" {
+			return a.MatrixID == s.MatrixID
+		}
+		return a.Medium == s.Medium && a.Address == s.Address
+	}
+	for _, a := range d.local {
+		if match(a) {
+			local = append(local, newAuditAssociation(&a))
+		}
+	}
+	for _, a := range d.global {
+		if match(a.Association) {
+			global = append(global, a.snapshot())
+		}
+	}
+	return local, global
+}
+
+func (m *Memory) EraseSubject(ctx context.Context, s models.Subject) (*models.SubjectErasure, error) {
+	s = canonicalSubject(m.canonical, s)
+	if err := validSubject(s); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	d := m.d.clone()
+	o := &models.SubjectErasure{Subject: s, Erased: []models.Subject{}}
+	addresses := []models.Subject{{Medium: s.Medium, Address: s.Address}}
+	if s.MatrixID != "" {
+		local, global := d.subjectAssociations(s)
+		addresses = nil
+		for _, a := range subjectAddresses(append(local, global...)) {
+			if d.boundToOthers(a, s.MatrixID) {
+				o.Skipped = append(o.Skipped, a)
+				continue
+			}
+			addresses = append(addresses, a)
+		}
+	}
+	for _, a := range addresses {
+		if err := d.eraseAddress(ctx, m.canonical, a, o); err != nil {
+			return nil, err
+		}
+	}
+	if s.MatrixID != "" {
+		for i := range d.audit {
+			e := &d.audit[i]
+			actor := e.Actor.Kind == models.ActorAccount && e.Actor.ID == s.MatrixID
+			if e.MatrixID != s.MatrixID && !actor {
+				continue
+			}
+			if e.MatrixID == s.MatrixID {
+				e.MatrixID = ""
+			}
+			if actor {
+				e.Actor.ID = ""
+			}
+			e.Actor.IP = ""
+			e.Before, e.After = nil, nil
+			o.Audit++
+		}
+	}
+	m.d = d
+	return o, nil
+}
+
+// subjectEmails returns the emails in the outbox to any of the email addresses
+// like the sql stores do, see ExportSubject.
+func (d *memoryData) subjectEmails(c *models.Canonicalizer, addresses []models.Subject) []models.OutboxEmail {
+	to := make(map[string]bool)
+	for _, a := range addresses {
+		if a.Medium == "email" {
+			to[a.Address] = true
+		}
+	}
+	var o []models.OutboxEmail
+	for _, e := range d.outbox {
+		if len(to) > 0 && emailTo(c, e, to) {
+			o = append(o, e)
+		}
+	}
+	return o
+}
+
+// boundToOthers returns true if the address a is bound to a matrix id other
+// than mxid.
+func (d *memoryData) boundToOthers(a models.Subject, mxid string) bool {
+	if l := d.getLocal(a.Medium, a.Address); l != nil && l.MatrixID != "" && l.MatrixID != mxid {
+		return true
+	}
+	for _, g := range d.global {
+		if g.Medium == a.Medium && g.Address == a.Address && g.MatrixID != mxid {
+			return true
+		}
+	}
+	return false
+}
+
+// eraseAddress erases the data of the address a like the sql stores do, see
+// EraseSubject.
+func (d *memoryData) eraseAddress(ctx context.Context, c *models.Canonicalizer, a models.Subject, o *models.SubjectErasure) error {
+	local := d.getLocal(a.Medium, a.Address)
+	e := &auditErasure{}
+	for _, g := range d.global {
+		if g.Medium == a.Medium && g.Address == a.Address {
+			e.GlobalAssociations++
+		}
+	}
+	e.LocalAssociation = (local != nil && local.MatrixID != "") || e.GlobalAssociations > 0
+	if e.LocalAssociation {
+		d.replaceLocal(models.Association{
+			Medium:    a.Medium,
+			Address:   a.Address,
+			Timestamp: models.Time(),
+		})
+	}
+	if err := d.removeGlobal(ctx, a.Medium, a.Address); err != nil {
+		return err
+	}
+	tokens := d.inviteTokens[:0]
+	for _, t := range d.inviteTokens {
+		if t.Medium == a.Medium && t.Address == a.Address {
+			e.InviteTokens++
+			continue
+		}
+		tokens = append(tokens, t)
+	}
+	d.inviteTokens = tokens
+	for id, v := range d.sessions {
+		if v.Medium != a.Medium || v.Address != a.Address {
+			continue
+		}
+		for tid, t := range d.tokenAuths {
+			if t.session == id {
+				delete(d.tokenAuths, tid)
+			}
+		}
+		delete(d.sessions, id)
+		e.ValidationSessions++
+	}
+	emails := d.subjectEmails(c, []models.Subject{a})
+	if len(emails) > 0 {
+		erased := make(map[int64]bool)
+		for _, m := range emails {
+			erased[m.ID] = true
+		}
+		outbox := d.outbox[:0]
+		for _, m := range d.outbox {
+			if !erased[m.ID] {
+				outbox = append(outbox, m)
+			}
+		}
+		d.outbox = outbox
+		e.Emails = int64(len(emails))
+	}
+	for i := range d.audit {
+		v := &d.audit[i]
+		if v.Medium != a.Medium || v.Address != a.Address {
+			continue
+		}
+		v.Address, v.MatrixID, v.Actor.IP = "", "", ""
+		v.Before, v.After = nil, nil
+		e.Audit++
+	}
+	if !e.erased() {
+		return nil
+	}
+	entry, err := newAuditEntry(ctx, models.AuditErase, e, nil)
+	if err != nil {
+		return err
+	}
+	entry.Medium = a.Medium
+	d.addAudit(entry)
+	addErased(o, a, e)
+	return nil
+}
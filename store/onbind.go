@@ -0,0 +1,74 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/gernest/sydent-go/models"
+)
+
+// AddOnbindNotification queues payload for delivery to the homeserver
+// destination and returns the id of the queued notification.
+func AddOnbindNotification(ctx context.Context, db models.Query, q Driver, destination, payload string) (int64, error) {
+	var id int64
+	err := db.QueryRowContext(ctx, q.AddOnbindNotification(),
+		destination, payload, models.Time(),
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// GetDueOnbindNotifications returns at most limit notifications which are due
+// for delivery at now.
+func GetDueOnbindNotifications(ctx context.Context, db models.Query, q Driver, now int64, limit int) ([]models.OnbindNotification, error) {
+	rows, err := db.QueryContext(ctx, q.GetDueOnbindNotifications(), now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var o []models.OnbindNotification
+	for rows.Next() {
+		var n models.OnbindNotification
+		err = rows.Scan(
+			&n.ID,
+			&n.Destination,
+			&n.Payload,
+			&n.Attempts,
+			&n.NextAttempt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		o = append(o, n)
+	}
+	return o, rows.Err()
+}
+
+// NextOnbindNotificationTS returns the time in milliseconds when the next
+// notification is due. sql.ErrNoRows is returned when the queue is empty.
+func NextOnbindNotificationTS(ctx context.Context, db models.Query, q Driver) (int64, error) {
+	var ts sql.NullInt64
+	err := db.QueryRowContext(ctx, q.NextOnbindNotificationTS()).Scan(&ts)
+	if err != nil {
+		return 0, err
+	}
+	if !ts.Valid {
+		return 0, sql.ErrNoRows
+	}
+	return ts.Int64, nil
+}
+
+// RetryOnbindNotification records a failed delivery attempt of the
+// notification id and schedules the next attempt at next.
+func RetryOnbindNotification(ctx context.Context, db models.Query, q Driver, id, next int64, lastErr string) error {
+	_, err := db.ExecContext(ctx, q.RetryOnbindNotification(), id, next, lastErr)
+	return err
+}
+
+// DeleteOnbindNotification removes the notification id from the queue.
+func DeleteOnbindNotification(ctx context.Context, db models.Query, q Driver, id int64) error {
+	_, err := db.ExecContext(ctx, q.DeleteOnbindNotification(), id)
+	return err
+}
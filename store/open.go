@@ -0,0 +1,82 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+
+	"github.com/gernest/sydent-go/config"
+	"github.com/gernest/sydent-go/embed"
+	"github.com/gernest/sydent-go/models"
+	"github.com/gernest/sydent-go/store/query"
+	"github.com/gernest/sydent-go/store/schema"
+)
+
+// Open returns the Store configured by c and applies pending migrations. It
+// fails with *schema.AheadError when the database schema is newer than the
+// binary. The returned io.Closer releases the database connections.
+//
+// Addresses are stored and looked up in the canonical form of canonical, it
+// can be nil to apply no provider rules.
+func Open(ctx context.Context, c config.DB, canonical *models.Canonicalizer, m Metric) (Store, io.Closer, error) {
+	if c.Driver == config.Memory {
+		return NewMemory().Canonical(canonical), nopCloser{}, nil
+	}
+	driver, err := NewDriver(c.Driver)
+	if err != nil {
+		return nil, nil, err
+	}
+	db, err := openSQL(c)
+	if err != nil {
+		return nil, nil, err
+	}
+	s := NewStore(query.New(db), driver, m).Canonical(canonical)
+	err = schema.IdentityUp(ctx, embed.New(), driver.Name(), s.DB())
+	if err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+	return s, db, nil
+}
+
+// OpenMigrator returns a schema.Migrator for the database configured by c
+// without applying any migration.
+func OpenMigrator(c config.DB) (*schema.Migrator, io.Closer, error) {
+	if c.Driver == config.Memory {
+		return nil, nil, errors.New("the memory driver has no schema to migrate")
+	}
+	driver, err := NewDriver(c.Driver)
+	if err != nil {
+		return nil, nil, err
+	}
+	db, err := openSQL(c)
+	if err != nil {
+		return nil, nil, err
+	}
+	m, err := schema.NewMigrator(embed.New(), driver.Name(), query.New(db))
+	if err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+	return m, db, nil
+}
+
+func openSQL(c config.DB) (*sql.DB, error) {
+	db, err := sql.Open(c.Driver, c.Conn)
+	if err != nil {
+		return nil, err
+	}
+	if c.Driver == config.SQLite {
+		// sqlite allows a single writer, sharing one connection avoids busy
+		// errors and keeps :memory: databases alive between queries.
+		db.SetMaxOpenConns(1)
+	}
+	return db, nil
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error {
+	return nil
+}
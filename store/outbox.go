@@ -0,0 +1,142 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/gernest/sydent-go/models"
+)
+
+// AddEmail queues the rendered message for delivery to the recipients to and
+// returns the id of the queued email. domain is the domain of the recipients,
+// it is used to limit concurrent deliveries per domain.
+func AddEmail(ctx context.Context, db models.Query, q Driver, from string, to []string, domain, message string) (int64, error) {
+	recipients, err := json.Marshal(to)
+	if err != nil {
+		return 0, err
+	}
+	var id int64
+	err = db.QueryRowContext(ctx, q.AddEmail(),
+		from, string(recipients), domain, message, models.Time(),
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// GetDueEmails returns at most limit pending emails which are due for delivery
+// at now.
+func GetDueEmails(ctx context.Context, db models.Query, q Driver, now int64, limit int) ([]models.OutboxEmail, error) {
+	return queryEmails(ctx, db, q.GetDueEmails(), now, limit)
+}
+
+// ListEmails returns at most limit emails in state ordered by id.
+func ListEmails(ctx context.Context, db models.Query, q Driver, state string, limit int) ([]models.OutboxEmail, error) {
+	return queryEmails(ctx, db, q.ListEmails(), state, limit)
+}
+
+func queryEmails(ctx context.Context, db models.Query, stmt string, args ...interface{}) ([]models.OutboxEmail, error) {
+	rows, err := db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var o []models.OutboxEmail
+	for rows.Next() {
+		var e models.OutboxEmail
+		var recipients string
+		var lastError sql.NullString
+		err = rows.Scan(
+			&e.ID,
+			&e.From,
+			&recipients,
+			&e.Domain,
+			&e.Message,
+			&e.State,
+			&e.Attempts,
+			&e.NextAttempt,
+			&e.Created,
+			&lastError,
+		)
+		if err != nil {
+			return nil, err
+		}
+		err = json.Unmarshal([]byte(recipients), &e.To)
+		if err != nil {
+			return nil, err
+		}
+		e.LastError = lastError.String
+		o = append(o, e)
+	}
+	return o, rows.Err()
+}
+
+// ClaimEmail marks the email id which was due at due as being sent until the
+// time in milliseconds until. sql.ErrNoRows is returned when the email was
+// claimed by someone else.
+func ClaimEmail(ctx context.Context, db models.Query, q Driver, id, due, until int64) error {
+	n, err := execCount(ctx, db, q.ClaimEmail(), id, due, until)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// RetryEmail records a failed delivery attempt and schedules the next one at
+// next.
+func RetryEmail(ctx context.Context, db models.Query, q Driver, id, next int64, lastErr string) error {
+	_, err := db.ExecContext(ctx, q.RetryEmail(), id, next, lastErr)
+	return err
+}
+
+// DeadEmail records a failed delivery attempt and moves the email to the dead
+// letter state, it is not sent again until it is requeued.
+func DeadEmail(ctx context.Context, db models.Query, q Driver, id int64, lastErr string) error {
+	_, err := db.ExecContext(ctx, q.DeadEmail(), id, lastErr)
+	return err
+}
+
+// RequeueEmail resets the attempts of the email id and makes it due now.
+// sql.ErrNoRows is returned when there is no such email.
+func RequeueEmail(ctx context.Context, db models.Query, q Driver, id int64) error {
+	n, err := execCount(ctx, db, q.RequeueEmail(), id, models.Time())
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// DeleteEmail removes the email id from the outbox. sql.ErrNoRows is returned
+// when there is no such email.
+func DeleteEmail(ctx context.Context, db models.Query, q Driver, id int64) error {
+	n, err := execCount(ctx, db, q.DeleteEmail(), id)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// NextEmailTS returns the time in milliseconds when the next pending email is
+// due. sql.ErrNoRows is returned when nothing is pending.
+func NextEmailTS(ctx context.Context, db models.Query, q Driver) (int64, error) {
+	var ts sql.NullInt64
+	err := db.QueryRowContext(ctx, q.NextEmailTS()).Scan(&ts)
+	if err != nil {
+		return 0, err
+	}
+	if !ts.Valid {
+		return 0, sql.ErrNoRows
+	}
+	return ts.Int64, nil
+}
@@ -2,14 +2,14 @@ package store
 
 import (
 	"context"
+	"database/sql"
 
 	"github.com/gernest/sydent-go/models"
-	"github.com/gernest/sydent-go/store/query"
 )
 
-func GetPeerByName(ctx context.Context, db models.Query, name string) (*models.Peer, error) {
+func GetPeerByName(ctx context.Context, db models.Query, q Driver, name string) (*models.Peer, error) {
 	var peer models.Peer
-	rows, err := db.QueryContext(ctx, query.GetPeerByName, name)
+	rows, err := db.QueryContext(ctx, q.GetPeerByName(), name)
 	if err != nil {
 		return nil, err
 	}
@@ -33,17 +33,19 @@ func GetPeerByName(ctx context.Context, db models.Query, name string) (*models.P
 	if rows.Err() != nil {
 		return nil, rows.Err()
 	}
+	if peer.Name == "" {
+		return nil, sql.ErrNoRows
+	}
 	return &peer, nil
 }
 
-func GetAllPeers(ctx context.Context, db models.Query) ([]models.Peer, error) {
-	rows, err := db.QueryContext(ctx, query.GetAllPeers)
+// GetAllPeers returns all active peers with their public keys.
+func GetAllPeers(ctx context.Context, db models.Query, q Driver) ([]models.Peer, error) {
+	rows, err := db.QueryContext(ctx, q.GetAllPeers())
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var cache models.Peer
-	cache.PublicKeys = make(map[string]string)
 	var peers []models.Peer
 	for rows.Next() {
 		var p models.Peer
@@ -52,35 +54,174 @@ func GetAllPeers(ctx context.Context, db models.Query) ([]models.Peer, error) {
 			&p.Name,
 			&p.Port,
 			&p.LastSentVersion,
-			&key, &alg,
+			&alg, &key,
 		)
-		if p.Name != cache.Name {
-			if len(cache.PublicKeys) > 0 {
-				p.PublicKeys = make(map[string]string)
-				for k, v := range cache.PublicKeys {
-					p.PublicKeys[k] = v
-				}
-				peers = append(peers, p)
-				cache.PublicKeys = make(map[string]string)
-			}
-			cache.Name = p.Name
-			cache.Port = p.Port
-			cache.LastSentVersion = p.LastSentVersion
+		if err != nil {
+			return nil, err
+		}
+		// rows are ordered by name, so keys of the same peer are adjacent.
+		if n := len(peers); n == 0 || peers[n-1].Name != p.Name {
+			p.Active = 1
+			p.PublicKeys = make(map[string]string)
+			peers = append(peers, p)
 		}
-		cache.PublicKeys[alg] = key
+		peers[len(peers)-1].PublicKeys[alg] = key
 	}
 	if rows.Err() != nil {
 		return nil, rows.Err()
 	}
-	if len(cache.PublicKeys) > 0 {
-		peers = append(peers, cache)
-	}
 	return peers, nil
 }
 
-func SetLastSentVersionAndPokeSucceeded(ctx context.Context, db models.Query, peerName, lastSentVersion, lastPokeSucceeded string) error {
+// SetLastSentVersionAndPokeSucceeded records that local associations up to
+// lastSentVersion were replicated to peerName at lastPokeSucceeded.
+func SetLastSentVersionAndPokeSucceeded(ctx context.Context, db models.Query, q Driver, peerName string, lastSentVersion, lastPokeSucceeded int64) error {
 	_, err := db.ExecContext(ctx,
-		query.SetLastSentVersionAndPokeSucceeded,
+		q.SetLastSentVersionAndPokeSucceeded(),
 		lastSentVersion, lastPokeSucceeded, peerName)
 	return err
 }
+
+// ListPeers returns all peers, including inactive peers and peers without
+// public keys.
+func ListPeers(ctx context.Context, db models.Query, q Driver) ([]models.Peer, error) {
+	rows, err := db.QueryContext(ctx, q.ListPeers())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var peers []models.Peer
+	for rows.Next() {
+		var p models.Peer
+		var alg, key sql.NullString
+		err = rows.Scan(
+			&p.Name,
+			&p.Port,
+			&p.LastSentVersion,
+			&p.LastPokeSucceededAt,
+			&p.Active,
+			&alg, &key,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if n := len(peers); n == 0 || peers[n-1].Name != p.Name {
+			p.PublicKeys = make(map[string]string)
+			peers = append(peers, p)
+		}
+		if alg.Valid {
+			peers[len(peers)-1].PublicKeys[alg.String] = key.String
+		}
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return peers, nil
+}
+
+// AddPeer adds an inactive peer, the port of an existing peer is updated.
+func AddPeer(ctx context.Context, db models.Query, q Driver, name string, port sql.NullInt64) error {
+	return changePeer(ctx, db, q, models.AuditPeerAdd, name, func(tx models.Query) error {
+		_, err := tx.ExecContext(ctx, q.AddPeer(), name, port)
+		return err
+	})
+}
+
+// changePeer calls fn in a transaction and records the peer name before and
+// after fn in the audit log.
+func changePeer(ctx context.Context, db models.Query, q Driver, action, name string, fn func(models.Query) error) error {
+	tx, err := begin(ctx, db)
+	if err != nil {
+		return err
+	}
+	before, err := peerSnapshot(ctx, tx, q, name)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	err = fn(tx)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	after, err := peerSnapshot(ctx, tx, q, name)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	err = auditPeerChange(ctx, tx, q, action, name, before, after)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// RemovePeer deletes the peer and its public keys. sql.ErrNoRows is returned
+// when the peer doesn't exist.
+func RemovePeer(ctx context.Context, db models.Query, q Driver, name string) error {
+	return changePeer(ctx, db, q, models.AuditPeerRemove, name, func(tx models.Query) error {
+		_, err := tx.ExecContext(ctx, q.DeletePeerKeys(), name)
+		if err != nil {
+			return err
+		}
+		return execOne(ctx, tx, q.DeletePeer(), name)
+	})
+}
+
+// SetPeerKey sets the public key of the peer for the algorithm alg.
+// sql.ErrNoRows is returned when the peer doesn't exist.
+func SetPeerKey(ctx context.Context, db models.Query, q Driver, name, alg, key string) error {
+	return changePeer(ctx, db, q, models.AuditPeerKey, name, func(tx models.Query) error {
+		var count int64
+		err := tx.QueryRowContext(ctx, q.CountPeers(), name).Scan(&count)
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return sql.ErrNoRows
+		}
+		_, err = tx.ExecContext(ctx, q.SetPeerKey(), name, alg, key)
+		return err
+	})
+}
+
+// SetPeerActive enables or disables replication with the peer. sql.ErrNoRows
+// is returned when the peer doesn't exist.
+func SetPeerActive(ctx context.Context, db models.Query, q Driver, name string, active bool) error {
+	var v int64
+	if active {
+		v = 1
+	}
+	return changePeer(ctx, db, q, models.AuditPeerActive, name, func(tx models.Query) error {
+		return execOne(ctx, tx, q.SetPeerActive(), v, name)
+	})
+}
+
+// CountAssociationsAfterID returns the number of local associations with id
+// greater than afterID.
+func CountAssociationsAfterID(ctx context.Context, db models.Query, q Driver, afterID int64) (int64, error) {
+	var n int64
+	err := db.QueryRowContext(ctx, q.CountAssociationsAfterID(), afterID).Scan(&n)
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// execOne executes a statement that is expected to change rows, sql.ErrNoRows
+// is returned when nothing was changed.
+func execOne(ctx context.Context, db models.Query, stmt string, args ...interface{}) error {
+	r, err := db.ExecContext(ctx, stmt, args...)
+	if err != nil {
+		return err
+	}
+	n, err := r.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package store
+
+import (
+	"context"
+	"math"
+
+	"github.com/gernest/sydent-go/models"
+)
+
+// TakeRateLimitToken takes a token from the bucket key, which holds at most
+// burst tokens and gains one every interval milliseconds. Buckets start full.
+// It returns 0 when a token was taken, otherwise the number of milliseconds
+// until the next token is available.
+func TakeRateLimitToken(ctx context.Context, db models.Query, q Driver, key string, burst float64, interval, now int64) (int64, error) {
+	n, err := execCount(ctx, db, q.TakeRateLimitToken(), key, burst, now, float64(interval))
+	if err != nil {
+		return 0, err
+	}
+	if n > 0 {
+		return 0, nil
+	}
+	var tokens float64
+	var updated int64
+	err = db.QueryRowContext(ctx, q.GetRateLimit(), key).Scan(&tokens, &updated)
+	if err != nil {
+		return 0, err
+	}
+	return retryAfter(bucketTokens(tokens, updated, burst, interval, now), interval), nil
+}
+
+// RateLimitBucket is a bucket of TakeRateLimitTokens, it holds at most Burst
+// tokens and gains one every Interval milliseconds.
+type RateLimitBucket struct {
+	Key      string
+	Burst    float64
+	Interval int64
+}
+
+// TakeRateLimitTokens takes a token from each of buckets in one transaction,
+// either from all of them or from none. It returns 0 when the tokens were
+// taken, otherwise the largest number of milliseconds until a bucket without
+// a token has one.
+func TakeRateLimitTokens(ctx context.Context, db models.Query, q Driver, buckets []RateLimitBucket, now int64) (int64, error) {
+	tx, err := begin(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+	var retry int64
+	for _, b := range buckets {
+		r, err := TakeRateLimitToken(ctx, tx, q, b.Key, b.Burst, b.Interval, now)
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+		if r > retry {
+			retry = r
+		}
+	}
+	if retry > 0 {
+		return retry, tx.Rollback()
+	}
+	return 0, tx.Commit()
+}
+
+// ReapRateLimits deletes at most limit buckets which were last updated before
+// the unix time in milliseconds before.
+func ReapRateLimits(ctx context.Context, db models.Query, q Driver, before int64, limit int) (int64, error) {
+	return execCount(ctx, db, q.ReapRateLimits(), before, limit)
+}
+
+// bucketTokens returns the tokens of a bucket at now, it had tokens at
+// updated.
+func bucketTokens(tokens float64, updated int64, burst float64, interval, now int64) float64 {
+	if now > updated {
+		tokens += float64(now-updated) / float64(interval)
+	}
+	return math.Min(burst, tokens)
+}
+
+// retryAfter returns the milliseconds until a bucket with tokens has one token.
+func retryAfter(tokens float64, interval int64) int64 {
+	if tokens >= 1 {
+		return 0
+	}
+	return int64(math.Ceil((1 - tokens) * float64(interval)))
+}
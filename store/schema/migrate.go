@@ -0,0 +1,329 @@
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gernest/sydent-go/embed"
+	"github.com/gernest/sydent-go/models"
+	"github.com/gernest/sydent-go/store/drivers/postgres"
+	"github.com/gernest/sydent-go/store/drivers/sqlite3"
+)
+
+const createMigrationsTable = `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version bigint primary key,
+    name varchar(256) not null,
+    applied_ts bigint not null
+);`
+
+const dropMigrationsTable = `DROP TABLE IF EXISTS schema_migrations;`
+
+const selectMigrations = `SELECT version, name, applied_ts FROM schema_migrations ORDER BY version`
+
+// migration files are named <version>_<name>.<up|down>.sql
+var migrationFile = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is a single numbered change to the schema.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status is the state of a migration in the database. Migrations which were
+// applied by a newer binary have no Up and Down.
+type Status struct {
+	Migration
+	Applied bool
+
+	// AppliedAt is the unix time in milliseconds when the migration was
+	// applied.
+	AppliedAt int64
+}
+
+// AheadError is returned when the database has migrations applied which are
+// not known to this binary.
+type AheadError struct {
+	Database int64
+	Latest   int64
+}
+
+func (e *AheadError) Error() string {
+	return fmt.Sprintf(
+		"database schema version %d is ahead of the latest known version %d, use a newer binary or roll back with the binary which applied it",
+		e.Database, e.Latest,
+	)
+}
+
+// Load reads the migrations of driver from fs sorted by version.
+func Load(fs embed.Embed, driver string) ([]Migration, error) {
+	dir := path.Join("/schemas", driver, "migrations")
+	d, err := fs.Open(dir)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for driver %q", driver)
+	}
+	defer d.Close()
+	files, err := d.Readdir(-1)
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[int64]*Migration)
+	for _, info := range files {
+		p := migrationFile.FindStringSubmatch(info.Name())
+		if p == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(p[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		if version == 0 {
+			return nil, fmt.Errorf("%s: version 0 is reserved for the empty schema", info.Name())
+		}
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: p[2]}
+			byVersion[version] = m
+		}
+		if m.Name != p[2] {
+			return nil, fmt.Errorf("%s: version %d is already used by %q", info.Name(), version, m.Name)
+		}
+		b, err := readFile(fs, path.Join(dir, info.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if p[3] == "up" {
+			m.Up = string(b)
+		} else {
+			m.Down = string(b)
+		}
+	}
+	o := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s must have both up and down files", m.Version, m.Name)
+		}
+		o = append(o, *m)
+	}
+	sort.Slice(o, func(i, j int) bool {
+		return o[i].Version < o[j].Version
+	})
+	return o, nil
+}
+
+func readFile(fs embed.Embed, name string) ([]byte, error) {
+	f, err := fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ioutil.ReadAll(f)
+}
+
+// Migrator applies and rolls back migrations. Applied versions are recorded in
+// the schema_migrations table, every migration runs in its own transaction.
+type Migrator struct {
+	db         models.SQL
+	param      func(int) string
+	migrations []Migration
+}
+
+// NewMigrator returns a Migrator for the migrations of driver.
+func NewMigrator(fs embed.Embed, driver string, db models.SQL) (*Migrator, error) {
+	migrations, err := Load(fs, driver)
+	if err != nil {
+		return nil, err
+	}
+	param := sqlite3.Param
+	if driver == "postgres" {
+		param = postgres.Param
+	}
+	return &Migrator{db: db, param: param, migrations: migrations}, nil
+}
+
+// Latest returns the version of the newest migration known to the binary.
+func (m *Migrator) Latest() int64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Version returns the newest migration applied to the database, 0 means no
+// migration was applied.
+func (m *Migrator) Version(ctx context.Context) (int64, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return version(applied), nil
+}
+
+func version(applied []Status) int64 {
+	if len(applied) == 0 {
+		return 0
+	}
+	return applied[len(applied)-1].Version
+}
+
+func (m *Migrator) applied(ctx context.Context) ([]Status, error) {
+	_, err := m.db.ExecContext(ctx, createMigrationsTable)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := m.db.QueryContext(ctx, selectMigrations)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var o []Status
+	for rows.Next() {
+		s := Status{Applied: true}
+		err = rows.Scan(&s.Version, &s.Name, &s.AppliedAt)
+		if err != nil {
+			return nil, err
+		}
+		o = append(o, s)
+	}
+	return o, rows.Err()
+}
+
+// Status returns all known migrations together with applied migrations which
+// are not known to the binary, sorted by version.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[int64]Status)
+	for _, s := range applied {
+		byVersion[s.Version] = s
+	}
+	for _, mg := range m.migrations {
+		s := byVersion[mg.Version]
+		s.Migration = mg
+		byVersion[mg.Version] = s
+	}
+	o := make([]Status, 0, len(byVersion))
+	for _, s := range byVersion {
+		o = append(o, s)
+	}
+	sort.Slice(o, func(i, j int) bool {
+		return o[i].Version < o[j].Version
+	})
+	return o, nil
+}
+
+// Check returns *AheadError when the database has migrations which are newer
+// than the binary.
+func (m *Migrator) Check(ctx context.Context) error {
+	v, err := m.Version(ctx)
+	if err != nil {
+		return err
+	}
+	if v > m.Latest() {
+		return &AheadError{Database: v, Latest: m.Latest()}
+	}
+	return nil
+}
+
+// Up applies all pending migrations.
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.To(ctx, m.Latest())
+}
+
+// Down rolls back the newest applied migration. It does nothing when no
+// migration was applied.
+func (m *Migrator) Down(ctx context.Context) error {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return err
+	}
+	if len(applied) < 2 {
+		return m.To(ctx, 0)
+	}
+	return m.To(ctx, applied[len(applied)-2].Version)
+}
+
+// To migrates the database up or down to target. Migrations newer than target
+// are rolled back in reverse order, pending migrations up to target are
+// applied in order.
+func (m *Migrator) To(ctx context.Context, target int64) error {
+	if target < 0 || target > m.Latest() {
+		return fmt.Errorf("unknown schema version %d, latest is %d", target, m.Latest())
+	}
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return err
+	}
+	if v := version(applied); v > m.Latest() {
+		return &AheadError{Database: v, Latest: m.Latest()}
+	}
+	done := make(map[int64]bool)
+	for _, s := range applied {
+		done[s.Version] = true
+	}
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		mg := m.migrations[i]
+		if mg.Version > target && done[mg.Version] {
+			if err := m.down(ctx, mg); err != nil {
+				return err
+			}
+		}
+	}
+	for _, mg := range m.migrations {
+		if mg.Version <= target && !done[mg.Version] {
+			if err := m.up(ctx, mg); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) up(ctx context.Context, mg Migration) error {
+	return m.tx(ctx, func(tx models.Tx) error {
+		_, err := tx.ExecContext(ctx, mg.Up)
+		if err != nil {
+			return fmt.Errorf("migration %d_%s up: %v", mg.Version, mg.Name, err)
+		}
+		_, err = tx.ExecContext(ctx, fmt.Sprintf(
+			"INSERT INTO schema_migrations (version, name, applied_ts) VALUES (%s, %s, %s)",
+			m.param(1), m.param(2), m.param(3),
+		), mg.Version, mg.Name, time.Now().UnixNano()/int64(time.Millisecond))
+		return err
+	})
+}
+
+func (m *Migrator) down(ctx context.Context, mg Migration) error {
+	return m.tx(ctx, func(tx models.Tx) error {
+		_, err := tx.ExecContext(ctx, mg.Down)
+		if err != nil {
+			return fmt.Errorf("migration %d_%s down: %v", mg.Version, mg.Name, err)
+		}
+		_, err = tx.ExecContext(ctx, fmt.Sprintf(
+			"DELETE FROM schema_migrations WHERE version = %s", m.param(1),
+		), mg.Version)
+		return err
+	})
+}
+
+func (m *Migrator) tx(ctx context.Context, fn func(models.Tx) error) error {
+	tx, err := m.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,132 @@
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gernest/sydent-go/embed"
+	"github.com/gernest/sydent-go/store/query"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestLoad(t *testing.T) {
+	for _, driver := range []string{"postgres", "sqlite3"} {
+		m, err := Load(embed.New(), driver)
+		if err != nil {
+			t.Fatalf("%s: %v", driver, err)
+		}
+		if len(m) == 0 {
+			t.Fatalf("%s: expected migrations", driver)
+		}
+		for i, mg := range m {
+			if mg.Version != int64(i+1) {
+				t.Errorf("%s: expected version %d got %d", driver, i+1, mg.Version)
+			}
+		}
+	}
+	_, err := Load(embed.New(), "mysql")
+	if err == nil {
+		t.Error("expected an error for unknown driver")
+	}
+}
+
+func TestMigrator(t *testing.T) {
+	dir, err := ioutil.TempDir("", "matrixid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "migrate.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	ctx := context.Background()
+	q := query.New(db)
+	m, err := NewMigrator(embed.New(), "sqlite3", q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	version := func(expect int64) {
+		t.Helper()
+		v, err := m.Version(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != expect {
+			t.Fatalf("expected schema version %d got %d", expect, v)
+		}
+	}
+	version(0)
+	status, err := m.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range status {
+		if s.Applied {
+			t.Errorf("expected %d to be pending", s.Version)
+		}
+	}
+	if err := m.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	version(m.Latest())
+	if _, err := q.ExecContext(ctx, "SELECT count(*) FROM accounts"); err != nil {
+		t.Fatal(err)
+	}
+
+	// applying twice is a no op
+	if err := m.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.To(ctx, m.Latest()+1); err == nil {
+		t.Error("expected an error for unknown version")
+	}
+	if err := m.To(ctx, 0); err != nil {
+		t.Fatal(err)
+	}
+	version(0)
+	if _, err := q.ExecContext(ctx, "SELECT count(*) FROM accounts"); err == nil {
+		t.Error("expected tables to be dropped")
+	}
+	if err := m.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Down(ctx); err != nil {
+		t.Fatal(err)
+	}
+	version(m.Latest() - 1)
+	if err := m.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// a newer binary applied a migration
+	ahead := m.Latest() + 1
+	_, err = q.ExecContext(ctx,
+		"INSERT INTO schema_migrations (version, name, applied_ts) VALUES (?, ?, ?)",
+		ahead, "future", 1,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = m.Check(ctx)
+	if e, ok := err.(*AheadError); !ok || e.Database != ahead {
+		t.Fatalf("expected *AheadError got %v", err)
+	}
+	if _, ok := m.Up(ctx).(*AheadError); !ok {
+		t.Error("expected up to refuse a schema which is ahead")
+	}
+	status, err = m.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := status[len(status)-1]
+	if last.Version != ahead || !last.Applied || last.Up != "" {
+		t.Errorf("expected unknown applied migration got %#v", last)
+	}
+}
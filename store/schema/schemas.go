@@ -2,34 +2,31 @@ package schema
 
 import (
 	"context"
-	"io/ioutil"
 
 	"github.com/gernest/sydent-go/embed"
 	"github.com/gernest/sydent-go/models"
 )
 
-const homeServerUpSQL = "/schemas/full_home_server_schema.sql"
-const identityUpSQL = "/schemas/full_identity_schema.sql"
-const identityDownSQL = "/schemas/full_identity_schema_down.sql"
-
-func IdentityUp(ctx context.Context, fs embed.Embed, db models.Query) error {
-	return execFile(ctx, fs, identityUpSQL, db)
+// IdentityUp applies all pending migrations of the identity service tables.
+// Each database driver has its own migrations in /schemas/<driver>/migrations.
+func IdentityUp(ctx context.Context, fs embed.Embed, driver string, db models.SQL) error {
+	m, err := NewMigrator(fs, driver, db)
+	if err != nil {
+		return err
+	}
+	return m.Up(ctx)
 }
 
-func execFile(ctx context.Context, fs embed.Embed, name string, db models.Query) error {
-	f, err := fs.Open(name)
+// IdentityDown rolls back all applied migrations, this drops the identity
+// service tables.
+func IdentityDown(ctx context.Context, fs embed.Embed, driver string, db models.SQL) error {
+	m, err := NewMigrator(fs, driver, db)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
-	b, err := ioutil.ReadAll(f)
-	if err != nil {
+	if err := m.To(ctx, 0); err != nil {
 		return err
 	}
-	_, err = db.ExecContext(ctx, string(b))
+	_, err = db.ExecContext(ctx, dropMigrationsTable)
 	return err
 }
-
-func IdentityDown(ctx context.Context, fs embed.Embed, db models.Query) error {
-	return execFile(ctx, fs, identityDownSQL, db)
-}
@@ -0,0 +1,492 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+
+	"github.com/gernest/sydent-go/models"
+)
+
+var errNoSubject = errors.New("store: subject has neither an address nor a matrix id")
+
+// canonicalSubject returns s with its address in the canonical form of c.
+func canonicalSubject(c *models.Canonicalizer, s models.Subject) models.Subject {
+	if s.Medium != "" {
+		s.Address = c.Address(s.Medium, s.Address)
+	}
+	return s
+}
+
+func validSubject(s models.Subject) error {
+	if s.MatrixID == "" && (s.Medium == "" || s.Address == "") {
+		return errNoSubject
+	}
+	return nil
+}
+
+func newSubjectExport(s models.Subject) *models.SubjectExport {
+	return &models.SubjectExport{
+		Subject:            s,
+		LocalAssociations:  []models.SubjectAssociation{},
+		GlobalAssociations: []models.SubjectAssociation{},
+		InviteTokens:       []models.SubjectInvite{},
+		ValidationSessions: []models.SubjectSession{},
+		Emails:             []models.SubjectEmail{},
+		Audit:              []models.AuditEntry{},
+	}
+}
+
+// subjectAddresses returns the sorted addresses of the associations as without
+// duplicates.
+func subjectAddresses(as []*auditAssociation) []models.Subject {
+	seen := make(map[models.Subject]bool)
+	var o []models.Subject
+	for _, a := range as {
+		k := models.Subject{Medium: a.Medium, Address: a.Address}
+		if !seen[k] {
+			seen[k] = true
+			o = append(o, k)
+		}
+	}
+	sort.Slice(o, func(i, j int) bool {
+		if o[i].Medium != o[j].Medium {
+			return o[i].Medium < o[j].Medium
+		}
+		return o[i].Address < o[j].Address
+	})
+	return o
+}
+
+// subjectAuditQueries returns the audit log queries of the entries about s,
+// which has the addresses.
+func subjectAuditQueries(s models.Subject, addresses []models.Subject) []models.AuditQuery {
+	var o []models.AuditQuery
+	if s.MatrixID != "" {
+		o = append(o, models.AuditQuery{MatrixID: s.MatrixID})
+	}
+	for _, a := range addresses {
+		o = append(o, models.AuditQuery{Medium: a.Medium, Address: a.Address})
+	}
+	return o
+}
+
+// ExportSubject returns the associations, invite tokens, validation sessions,
+// queued emails and audit log entries of s. The recipients of emails are
+// matched in the canonical form of c.
+//
+// The associations of a matrix id are the ones bound to it, the other data is
+// the data of their addresses. The associations of an address include removed
+// ones and the ones bound to other matrix ids.
+func ExportSubject(ctx context.Context, db models.Query, q Driver, c *models.Canonicalizer, s models.Subject) (*models.SubjectExport, error) {
+	if err := validSubject(s); err != nil {
+		return nil, err
+	}
+	o := newSubjectExport(s)
+	var local, global []*auditAssociation
+	var err error
+	if s.MatrixID != "" {
+		local, err = localAssociationsForMxid(ctx, db, q, s.MatrixID)
+		if err != nil {
+			return nil, err
+		}
+		global, err = globalAssociationsForMxid(ctx, db, q, s.MatrixID)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		as, err := getLocalAssociation(ctx, db, q, s.Medium, s.Address)
+		if err != nil {
+			return nil, err
+		}
+		if as != nil {
+			local = append(local, newAuditAssociation(as))
+		}
+		global, err = globalAssociationsFor(ctx, db, q, s.Medium, s.Address)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, a := range local {
+		o.LocalAssociations = append(o.LocalAssociations, models.SubjectAssociation(*a))
+	}
+	for _, a := range global {
+		o.GlobalAssociations = append(o.GlobalAssociations, models.SubjectAssociation(*a))
+	}
+	addresses := []models.Subject{{Medium: s.Medium, Address: s.Address}}
+	if s.MatrixID != "" {
+		addresses = subjectAddresses(append(local, global...))
+	}
+	for _, a := range addresses {
+		tokens, err := GetTokens(ctx, db, q, a.Medium, a.Address)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range tokens {
+			o.InviteTokens = append(o.InviteTokens, subjectInvite(t))
+		}
+		sessions, err := validationSessionsFor(ctx, db, q, a.Medium, a.Address)
+		if err != nil {
+			return nil, err
+		}
+		o.ValidationSessions = append(o.ValidationSessions, sessions...)
+	}
+	emails, err := subjectEmails(ctx, db, q, c, addresses)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range emails {
+		o.Emails = append(o.Emails, models.SubjectEmail{
+			ID:      e.ID,
+			From:    e.From,
+			To:      e.To,
+			State:   e.State,
+			Created: e.Created,
+			Message: e.Message,
+		})
+	}
+	seen := make(map[int64]bool)
+	for _, aq := range subjectAuditQueries(s, addresses) {
+		for {
+			aq.Limit = batch
+			entries, err := AuditLog(ctx, db, q, aq)
+			if err != nil {
+				return nil, err
+			}
+			for _, e := range entries {
+				if !seen[e.ID] {
+					seen[e.ID] = true
+					o.Audit = append(o.Audit, e)
+				}
+				aq.AfterID = e.ID
+			}
+			if len(entries) < batch {
+				break
+			}
+		}
+	}
+	sort.Slice(o.Audit, func(i, j int) bool {
+		return o.Audit[i].ID < o.Audit[j].ID
+	})
+	return o, nil
+}
+
+func subjectInvite(t models.InviteToken) models.SubjectInvite {
+	i := models.SubjectInvite{
+		Medium:  t.Medium,
+		Address: t.Address,
+		RoomID:  t.RoomID,
+		Sender:  t.Sender,
+	}
+	if !t.ReceivedAt.IsZero() {
+		i.ReceivedTS = models.MS(&t.ReceivedAt)
+	}
+	if !t.SentAt.IsZero() {
+		i.SentTS = models.MS(&t.SentAt)
+	}
+	return i
+}
+
+// auditErasure is what was erased of an address, recorded in the audit log.
+// The address itself is left out.
+type auditErasure struct {
+	LocalAssociation   bool  `json:"local_association"`
+	GlobalAssociations int64 `json:"global_associations"`
+	InviteTokens       int64 `json:"invite_tokens"`
+	ValidationSessions int64 `json:"validation_sessions"`
+	Emails             int64 `json:"emails"`
+	Audit              int64 `json:"audit"`
+}
+
+// EraseSubject erases the associations, invite tokens, validation sessions,
+// queued emails and audit log entries of s in one transaction. The recipients
+// of emails are matched in the canonical form of c.
+//
+// The local association of each erased address is replaced with a removal, so
+// peers remove the address when it is replicated to them. This happens even
+// when the association was replicated from a peer, in which case a removal is
+// created. The removal is the only row left with the address. Global
+// associations, invite tokens, validation sessions and emails are deleted.
+//
+// Audit log entries about the subject are redacted: the address, matrix id,
+// client ip and snapshots are blanked, and so is the actor of the entries made
+// by an erased matrix id. Each erased address is recorded in the audit log by
+// medium only, with the number of rows which were erased.
+//
+// A matrix id is erased by erasing the addresses bound to it. Addresses which
+// are bound to other matrix ids too are skipped and reported.
+func EraseSubject(ctx context.Context, db models.Query, q Driver, c *models.Canonicalizer, s models.Subject) (*models.SubjectErasure, error) {
+	if err := validSubject(s); err != nil {
+		return nil, err
+	}
+	tx, err := begin(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	o := &models.SubjectErasure{Subject: s, Erased: []models.Subject{}}
+	addresses := []models.Subject{{Medium: s.Medium, Address: s.Address}}
+	if s.MatrixID != "" {
+		addresses, o.Skipped, err = erasableAddresses(ctx, tx, q, s.MatrixID)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	for _, a := range addresses {
+		err = eraseAddress(ctx, tx, q, c, a, o)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	if s.MatrixID != "" {
+		n, err := execCount(ctx, tx, q.RedactAuditMxid(), s.MatrixID, models.ActorAccount)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		o.Audit += n
+	}
+	return o, tx.Commit()
+}
+
+// erasableAddresses returns the addresses bound to mxid, the ones which are
+// bound to other matrix ids too are returned as skipped.
+func erasableAddresses(ctx context.Context, db models.Query, q Driver, mxid string) (erasable, skipped []models.Subject, err error) {
+	local, err := localAssociationsForMxid(ctx, db, q, mxid)
+	if err != nil {
+		return nil, nil, err
+	}
+	global, err := globalAssociationsForMxid(ctx, db, q, mxid)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, a := range subjectAddresses(append(local, global...)) {
+		l, err := getLocalAssociation(ctx, db, q, a.Medium, a.Address)
+		if err != nil {
+			return nil, nil, err
+		}
+		as, err := globalAssociationsFor(ctx, db, q, a.Medium, a.Address)
+		if err != nil {
+			return nil, nil, err
+		}
+		other := l != nil && l.MatrixID != "" && l.MatrixID != mxid
+		for _, v := range as {
+			other = other || v.MatrixID != mxid
+		}
+		if other {
+			skipped = append(skipped, a)
+			continue
+		}
+		erasable = append(erasable, a)
+	}
+	return erasable, skipped, nil
+}
+
+// eraseAddress erases the data of the address a and adds it to o.
+func eraseAddress(ctx context.Context, tx models.Query, q Driver, c *models.Canonicalizer, a models.Subject, o *models.SubjectErasure) error {
+	local, err := getLocalAssociation(ctx, tx, q, a.Medium, a.Address)
+	if err != nil {
+		return err
+	}
+	global, err := globalAssociationsFor(ctx, tx, q, a.Medium, a.Address)
+	if err != nil {
+		return err
+	}
+	e := &auditErasure{
+		LocalAssociation:   (local != nil && local.MatrixID != "") || len(global) > 0,
+		GlobalAssociations: int64(len(global)),
+	}
+	if e.LocalAssociation {
+		err = replaceLocalAssociation(ctx, tx, q, a.Medium, a.Address,
+			nil, models.Time(), nil, nil,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	if len(global) > 0 {
+		err = GlobalRemoveAssociation(ctx, tx, q, a.Medium, a.Address)
+		if err != nil {
+			return err
+		}
+	}
+	e.InviteTokens, err = execCount(ctx, tx, q.DeleteInviteTokensFor(), a.Medium, a.Address)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, q.DeleteTokenAuthsFor(), a.Medium, a.Address)
+	if err != nil {
+		return err
+	}
+	e.ValidationSessions, err = execCount(ctx, tx, q.DeleteValidationSessionsFor(), a.Medium, a.Address)
+	if err != nil {
+		return err
+	}
+	emails, err := subjectEmails(ctx, tx, q, c, []models.Subject{a})
+	if err != nil {
+		return err
+	}
+	for _, m := range emails {
+		err = DeleteEmail(ctx, tx, q, m.ID)
+		if err != nil {
+			return err
+		}
+	}
+	e.Emails = int64(len(emails))
+	e.Audit, err = execCount(ctx, tx, q.RedactAuditAddress(), a.Medium, a.Address)
+	if err != nil {
+		return err
+	}
+	if !e.erased() {
+		return nil
+	}
+	err = auditChange(ctx, tx, q, models.AuditErase, a.Medium, "", "", e, nil)
+	if err != nil {
+		return err
+	}
+	addErased(o, a, e)
+	return nil
+}
+
+// erased returns true if any data was erased.
+func (e *auditErasure) erased() bool {
+	return e.LocalAssociation || e.GlobalAssociations > 0 || e.InviteTokens > 0 ||
+		e.ValidationSessions > 0 || e.Emails > 0 || e.Audit > 0
+}
+
+// addErased adds the address a, of which e was erased, to o.
+func addErased(o *models.SubjectErasure, a models.Subject, e *auditErasure) {
+	o.Erased = append(o.Erased, a)
+	if e.LocalAssociation {
+		o.LocalAssociations++
+	}
+	o.GlobalAssociations += e.GlobalAssociations
+	o.InviteTokens += e.InviteTokens
+	o.ValidationSessions += e.ValidationSessions
+	o.Emails += e.Emails
+	o.Audit += e.Audit
+}
+
+// localAssociationsForMxid returns snapshots of the local associations bound to
+// mxid.
+func localAssociationsForMxid(ctx context.Context, db models.Query, q Driver, mxid string) ([]*auditAssociation, error) {
+	rows, err := db.QueryContext(ctx, q.LocalAssociationsForMxid(), mxid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var o []*auditAssociation
+	for rows.Next() {
+		var a auditAssociation
+		var notBefore, notAfter sql.NullInt64
+		err = rows.Scan(
+			&a.ID,
+			&a.Medium,
+			&a.Address,
+			&a.MatrixID,
+			&a.Timestamp,
+			&notBefore,
+			&notAfter,
+		)
+		if err != nil {
+			return nil, err
+		}
+		a.NotBefore = notBefore.Int64
+		a.NotAfter = notAfter.Int64
+		o = append(o, &a)
+	}
+	return o, rows.Err()
+}
+
+// globalAssociationsForMxid returns snapshots of the global associations bound
+// to mxid.
+func globalAssociationsForMxid(ctx context.Context, db models.Query, q Driver, mxid string) ([]*auditAssociation, error) {
+	rows, err := db.QueryContext(ctx, q.GlobalAssociationsForMxid(), mxid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var o []*auditAssociation
+	for rows.Next() {
+		var a auditAssociation
+		err = rows.Scan(
+			&a.ID,
+			&a.Medium,
+			&a.Address,
+			&a.MatrixID,
+			&a.Timestamp,
+			&a.NotBefore,
+			&a.NotAfter,
+			&a.OriginServer,
+			&a.OriginID,
+		)
+		if err != nil {
+			return nil, err
+		}
+		o = append(o, &a)
+	}
+	return o, rows.Err()
+}
+
+// subjectEmails returns the emails in the outbox to any of the email addresses,
+// recipients are compared in the canonical form of c.
+func subjectEmails(ctx context.Context, db models.Query, q Driver, c *models.Canonicalizer, addresses []models.Subject) ([]models.OutboxEmail, error) {
+	to := make(map[string]bool)
+	for _, a := range addresses {
+		if a.Medium == "email" {
+			to[a.Address] = true
+		}
+	}
+	if len(to) == 0 {
+		return nil, nil
+	}
+	var o []models.OutboxEmail
+	var afterID int64
+	for {
+		emails, err := queryEmails(ctx, db, q.EmailsAfter(), afterID, batch)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range emails {
+			afterID = e.ID
+			if emailTo(c, e, to) {
+				o = append(o, e)
+			}
+		}
+		if len(emails) < batch {
+			return o, nil
+		}
+	}
+}
+
+// emailTo returns true if a recipient of e is in to.
+func emailTo(c *models.Canonicalizer, e models.OutboxEmail, to map[string]bool) bool {
+	for _, r := range e.To {
+		if to[c.Address("email", r)] {
+			return true
+		}
+	}
+	return false
+}
+
+func validationSessionsFor(ctx context.Context, db models.Query, q Driver, medium, address string) ([]models.SubjectSession, error) {
+	rows, err := db.QueryContext(ctx, q.ValidationSessionsFor(), medium, address)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var o []models.SubjectSession
+	for rows.Next() {
+		var s models.SubjectSession
+		var validated sql.NullInt64
+		err = rows.Scan(&s.ID, &s.Medium, &s.Address, &validated, &s.Mtime)
+		if err != nil {
+			return nil, err
+		}
+		s.Validated = validated.Int64 == 1
+		o = append(o, s)
+	}
+	return o, rows.Err()
+}
@@ -0,0 +1,233 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/gernest/sydent-go/models"
+)
+
+// SydentResult is the outcome of importing one table of a sydent database.
+type SydentResult struct {
+	Table string
+
+	// Imported is the number of rows copied by this run.
+	Imported int64
+
+	// Skipped is the number of rows which were not copied because a row with
+	// the same id or unique key already exists.
+	Skipped int64
+
+	// LastID is the highest id of the table which was processed, rows up to it
+	// are skipped by later runs.
+	LastID int64
+
+	// Missing is true when the table does not exist in the sydent database.
+	Missing bool
+}
+
+// sydentTable describes how to copy a table of the sydent sqlite database. The
+// first column must be the id, kinds has one byte per column, i for integer
+// and s for text columns.
+type sydentTable struct {
+	name    string
+	columns string
+	kinds   string
+	insert  func(Driver) string
+}
+
+// the order matters, peers and sessions are referenced by foreign keys.
+var sydentTables = []sydentTable{
+	{
+		name:    "invite_tokens",
+		columns: "id, medium, address, room_id, sender, token, received_ts, sent_ts",
+		kinds:   "isssssii",
+		insert:  Driver.ImportInviteToken,
+	},
+	{
+		name:    "ephemeral_public_keys",
+		columns: "id, public_key, verify_count, persistence_ts",
+		kinds:   "isii",
+		insert:  Driver.ImportEphemeralPublicKey,
+	},
+	{
+		name:    "peers",
+		columns: "id, name, port, lastSentVersion, lastPokeSucceededAt, active",
+		kinds:   "isiiii",
+		insert:  Driver.ImportPeer,
+	},
+	{
+		name:    "peer_pubkeys",
+		columns: "id, peername, alg, key",
+		kinds:   "isss",
+		insert:  Driver.ImportPeerPubkey,
+	},
+	{
+		name:    "local_threepid_associations",
+		columns: "id, medium, address, mxid, ts, notBefore, notAfter",
+		kinds:   "isssiii",
+		insert:  Driver.ImportLocalAssociation,
+	},
+	{
+		name:    "global_threepid_associations",
+		columns: "id, medium, address, mxid, ts, notBefore, notAfter, originServer, originId, sgAssoc",
+		kinds:   "isssiiisis",
+		insert:  Driver.ImportGlobalAssociation,
+	},
+	{
+		name:    "threepid_validation_sessions",
+		columns: "id, medium, address, clientSecret, validated, mtime",
+		kinds:   "isssii",
+		insert:  Driver.ImportValidationSession,
+	},
+	{
+		name:    "threepid_token_auths",
+		columns: "id, validationSession, token, sendAttemptNumber",
+		kinds:   "iisi",
+		insert:  Driver.ImportTokenAuth,
+	},
+}
+
+const sydentTableExists = `SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = ?`
+
+const sydentSelect = `SELECT %s FROM %s WHERE id > ? ORDER BY id LIMIT ?`
+
+// ImportSydent copies the associations, validation sessions, invite tokens,
+// ephemeral keys and peers of the sydent sqlite database src into m. Ids,
+// originServer and originId are kept as they are so replication with peers
+// carries on where sydent stopped.
+//
+// Rows are copied in transactions of batch rows, each recording how far the
+// table was copied. Running the import again resumes from there and picks up
+// rows added to src since, rows which already exist in m are skipped. progress
+// is called after every batch.
+func ImportSydent(ctx context.Context, src *sql.DB, m *Matrix, batch int, progress func(SydentResult)) ([]SydentResult, error) {
+	pepper, err := GetLookupPepper(ctx, m.DB(), m.Driver())
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	var o []SydentResult
+	for _, t := range sydentTables {
+		r, err := importSydentTable(ctx, src, m.DB(), m.Driver(), t, pepper, batch, progress)
+		if err != nil {
+			return o, fmt.Errorf("importing %s: %v", t.name, err)
+		}
+		o = append(o, r)
+	}
+	return o, nil
+}
+
+func importSydentTable(ctx context.Context, src *sql.DB, db models.SQL, q Driver, t sydentTable, pepper string, batch int, progress func(SydentResult)) (SydentResult, error) {
+	r := SydentResult{Table: t.name}
+	var n int
+	err := src.QueryRowContext(ctx, sydentTableExists, t.name).Scan(&n)
+	if err != nil {
+		return r, err
+	}
+	if n == 0 {
+		r.Missing = true
+		return r, nil
+	}
+	err = db.QueryRowContext(ctx, q.GetImportCheckpoint(), t.name).Scan(&r.LastID)
+	if err != nil && err != sql.ErrNoRows {
+		return r, err
+	}
+	for {
+		rows, err := readSydentRows(ctx, src, t, r.LastID, batch)
+		if err != nil {
+			return r, err
+		}
+		if len(rows) == 0 {
+			break
+		}
+		tx, err := begin(ctx, db)
+		if err != nil {
+			return r, err
+		}
+		var imported int64
+		for _, row := range rows {
+			switch t.name {
+			case "invite_tokens":
+				sydentMS(row[6], row[7])
+			case "global_threepid_associations":
+				row = append(row, sydentLookupHash(row, pepper))
+			}
+			res, err := tx.ExecContext(ctx, t.insert(q), row...)
+			if err != nil {
+				tx.Rollback()
+				return r, err
+			}
+			affected, err := res.RowsAffected()
+			if err != nil {
+				tx.Rollback()
+				return r, err
+			}
+			imported += affected
+		}
+		lastID := rows[len(rows)-1][0].(*sql.NullInt64).Int64
+		_, err = tx.ExecContext(ctx, q.SetImportCheckpoint(), t.name, lastID)
+		if err != nil {
+			tx.Rollback()
+			return r, err
+		}
+		if err := tx.Commit(); err != nil {
+			return r, err
+		}
+		r.Imported += imported
+		r.Skipped += int64(len(rows)) - imported
+		r.LastID = lastID
+		if progress != nil {
+			progress(r)
+		}
+	}
+	_, err = db.ExecContext(ctx, fmt.Sprintf(q.ResetSequence(), t.name))
+	return r, err
+}
+
+func readSydentRows(ctx context.Context, src *sql.DB, t sydentTable, afterID int64, limit int) ([][]interface{}, error) {
+	rows, err := src.QueryContext(ctx, fmt.Sprintf(sydentSelect, t.columns, t.name), afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var o [][]interface{}
+	for rows.Next() {
+		row := make([]interface{}, len(t.kinds))
+		for i, k := range t.kinds {
+			if k == 'i' {
+				row[i] = &sql.NullInt64{}
+			} else {
+				row[i] = &sql.NullString{}
+			}
+		}
+		if err := rows.Scan(row...); err != nil {
+			return nil, err
+		}
+		o = append(o, row)
+	}
+	return o, rows.Err()
+}
+
+// sydentMS converts timestamps which sydent stored in seconds to milliseconds.
+func sydentMS(columns ...interface{}) {
+	for _, c := range columns {
+		if ts := c.(*sql.NullInt64); ts.Valid && ts.Int64 < 100000000000 {
+			ts.Int64 *= 1000
+		}
+	}
+}
+
+// sydentLookupHash returns the lookup hash of a global association row, sydent
+// hashes with its own pepper so the hash is computed again with ours.
+func sydentLookupHash(row []interface{}, pepper string) sql.NullString {
+	if pepper == "" {
+		return sql.NullString{}
+	}
+	medium := row[1].(*sql.NullString).String
+	address := row[2].(*sql.NullString).String
+	return sql.NullString{
+		String: models.LookupHash(medium, address, pepper),
+		Valid:  true,
+	}
+}
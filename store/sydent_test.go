@@ -0,0 +1,171 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gernest/sydent-go/embed"
+	"github.com/gernest/sydent-go/models"
+	"github.com/gernest/sydent-go/store/query"
+	"github.com/gernest/sydent-go/store/schema"
+)
+
+// a subset of the schema created by sydent, threepid_token_auths is left out
+// on purpose.
+const sydentSchema = `
+CREATE TABLE invite_tokens (id integer primary key, medium varchar(16) not null, address varchar(256) not null, room_id varchar(256) not null, sender varchar(256) not null, token varchar(256) not null, received_ts bigint, sent_ts bigint);
+CREATE TABLE ephemeral_public_keys(id integer primary key, public_key varchar(256) not null, verify_count int default 0, persistence_ts integer);
+CREATE TABLE peers (id integer primary key, name varchar(255) not null, port integer default null, lastSentVersion integer, lastPokeSucceededAt integer, active integer not null default 0);
+CREATE TABLE peer_pubkeys (id integer primary key, peername varchar(255) not null, alg varchar(16) not null, key text not null);
+CREATE TABLE local_threepid_associations (id integer primary key, medium varchar(16) not null, address varchar(256) not null, lookup_hash varchar, mxid varchar(256), ts integer, notBefore bigint, notAfter bigint);
+CREATE TABLE global_threepid_associations (id integer primary key, medium varchar(16) not null, address varchar(256) not null, lookup_hash varchar, mxid varchar(256) not null, ts integer not null, notBefore bigint not null, notAfter integer not null, originServer varchar(255) not null, originId integer not null, sgAssoc text not null);
+CREATE TABLE threepid_validation_sessions (id integer primary key, medium varchar(16) not null, address varchar(256) not null, clientSecret varchar(32) not null, validated int default 0, mtime bigint not null);
+INSERT INTO invite_tokens VALUES (1, 'email', 'alice@example.com', '!room:example.com', '@bob:example.com', 'token', 10, NULL);
+INSERT INTO ephemeral_public_keys VALUES (4, 'key', 0, 10);
+INSERT INTO peers VALUES (1, 'peer.example.com', 1001, 7, 20, 1);
+INSERT INTO peer_pubkeys VALUES (1, 'peer.example.com', 'ed25519', 'pubkey');
+INSERT INTO local_threepid_associations VALUES (3, 'email', 'alice@example.com', 'hash', '@alice:example.com', 10, 0, 100000000000000);
+INSERT INTO local_threepid_associations VALUES (7, 'email', 'gone@example.com', 'hash', NULL, 10, NULL, NULL);
+INSERT INTO global_threepid_associations VALUES (2, 'email', 'carol@example.com', 'hash', '@carol:peer.example.com', 10, 0, 100000000000000, 'peer.example.com', 42, '{"signed":true}');
+INSERT INTO threepid_validation_sessions VALUES (9, 'email', 'alice@example.com', 'secret', 1, 10);
+`
+
+func TestImportSydent(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sydent-go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ctx := context.Background()
+	src, err := sql.Open("sqlite3", filepath.Join(dir, "sydent.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+	if _, err := src.ExecContext(ctx, sydentSchema); err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "identity.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	q := query.New(db)
+	if err := schema.IdentityUp(ctx, embed.New(), "sqlite3", q); err != nil {
+		t.Fatal(err)
+	}
+	driver, err := NewDriver("sqlite3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewStore(q, driver, Metric{})
+	if err := m.RotateLookupPepper(ctx, "pepper"); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ImportSydent(ctx, src, m, 1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	imported := make(map[string]SydentResult)
+	for _, r := range result {
+		imported[r.Table] = r
+	}
+	if r := imported["local_threepid_associations"]; r.Imported != 2 || r.LastID != 7 {
+		t.Errorf("expected 2 local associations up to id 7 got %#v", r)
+	}
+	if !imported["threepid_token_auths"].Missing {
+		t.Error("expected threepid_token_auths to be missing")
+	}
+
+	// ids, originServer and originId are kept
+	ass, err := m.GetAssociationsAfterID(ctx, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ass) != 2 || ass[0].ID != 3 || ass[1].ID != 7 || ass[1].MatrixID != "" {
+		t.Errorf("unexpected local associations %#v", ass)
+	}
+	id, err := m.GlobalLastIDFromServer(ctx, "peer.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != 42 {
+		t.Errorf("expected origin id 42 got %d", id)
+	}
+	sg, err := m.SignedAssociationStringForThreepid(ctx, "email", "carol@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sg != `{"signed":true}` {
+		t.Errorf("expected the signed association to be kept got %s", sg)
+	}
+	hashes, err := m.GlobalGetMxidsByHash(ctx, []string{
+		models.LookupHash("email", "carol@example.com", "pepper"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hashes) != 1 {
+		t.Errorf("expected lookup hash computed with our pepper got %v", hashes)
+	}
+	peer, err := m.GetPeerByName(ctx, "peer.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if peer.LastSentVersion.Int64 != 7 || peer.PublicKeys["ed25519"] != "pubkey" {
+		t.Errorf("unexpected peer %#v", peer)
+	}
+	sess, err := m.GetSessionByID(ctx, 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sess.Address != "alice@example.com" {
+		t.Errorf("unexpected session %#v", sess)
+	}
+
+	// new local associations must not reuse imported ids
+	err = m.LocalAddOrUpdateAssociation(ctx, &models.Association{
+		Medium:   "email",
+		Address:  "dave@example.com",
+		MatrixID: "@dave:example.com",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ass, err = m.GetAssociationsAfterID(ctx, 7, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ass) != 1 || ass[0].ID <= 7 {
+		t.Errorf("expected a new id after 7 got %#v", ass)
+	}
+
+	// running again only copies new rows
+	_, err = src.ExecContext(ctx, `INSERT INTO local_threepid_associations VALUES (8, 'email', 'eve@example.com', 'hash', '@eve:example.com', 10, 0, 100000000000000)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err = ImportSydent(ctx, src, m, 100, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range result {
+		switch r.Table {
+		case "local_threepid_associations":
+			// id 8 was taken by dave
+			if r.Imported != 0 || r.Skipped != 1 || r.LastID != 8 {
+				t.Errorf("expected id 8 to be skipped got %#v", r)
+			}
+		default:
+			if r.Imported != 0 || r.Skipped != 0 {
+				t.Errorf("expected nothing to be copied again got %#v", r)
+			}
+		}
+	}
+}
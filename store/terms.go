@@ -0,0 +1,46 @@
+package store
+
+import (
+	"context"
+
+	"github.com/gernest/sydent-go/models"
+)
+
+// AddAcceptedTerms records that userID accepted the policy documents in terms.
+// Documents which were already accepted are ignored.
+func AddAcceptedTerms(ctx context.Context, db models.Query, q Driver, userID string, terms []models.AcceptedTerms) error {
+	tx, err := begin(ctx, db)
+	if err != nil {
+		return err
+	}
+	now := models.Time()
+	for _, t := range terms {
+		_, err = tx.ExecContext(ctx, q.AddAcceptedTerms(),
+			userID, t.Policy, t.Version, t.URL, now,
+		)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetAcceptedTerms returns all policy documents which userID has accepted.
+func GetAcceptedTerms(ctx context.Context, db models.Query, q Driver, userID string) ([]models.AcceptedTerms, error) {
+	rows, err := db.QueryContext(ctx, q.GetAcceptedTerms(), userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var terms []models.AcceptedTerms
+	for rows.Next() {
+		var t models.AcceptedTerms
+		err = rows.Scan(&t.Policy, &t.Version, &t.URL)
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, t)
+	}
+	return terms, rows.Err()
+}
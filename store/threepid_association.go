@@ -2,21 +2,68 @@ package store
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"time"
 
 	"github.com/gernest/sydent-go/models"
-	"github.com/gernest/sydent-go/store/query"
 )
 
-func LocalAddOrUpdateAssociation(ctx context.Context, db models.Query, as *models.Association) error {
-	_, err := db.ExecContext(ctx, query.LocalAddOrUpdateAssociation,
-		as.Medium, as.Address, as.MatrixID, as.Timestamp, as.NotBefore, as.NotAfter)
-	return err
+// LocalAddOrUpdateAssociation stores as, replacing any existing association for
+// the same medium and address.
+func LocalAddOrUpdateAssociation(ctx context.Context, db models.Query, q Driver, as *models.Association) error {
+	tx, err := begin(ctx, db)
+	if err != nil {
+		return err
+	}
+	before, err := getLocalAssociation(ctx, tx, q, as.Medium, as.Address)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	err = replaceLocalAssociation(ctx, tx, q, as.Medium, as.Address,
+		as.MatrixID, as.Timestamp, as.NotBefore, as.NotAfter,
+	)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	err = auditChange(ctx, tx, q, models.AuditBind, as.Medium, as.Address, as.MatrixID,
+		newAuditAssociation(before), newAuditAssociation(as),
+	)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// replaceLocalAssociation deletes the association for medium and address and
+// inserts a new one, this ensures the association is assigned a new id.
+func replaceLocalAssociation(ctx context.Context, db models.Query, q Driver, medium, address string, args ...interface{}) error {
+	tx, err := begin(ctx, db)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, q.LocalDeleteAssociation(), medium, address)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	_, err = tx.ExecContext(ctx, q.LocalAddAssociation(),
+		append([]interface{}{medium, address}, args...)...,
+	)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
 }
 
-func GetAssociationsAfterID(ctx context.Context, db models.Query, afterID int64, limit int64) ([]models.Association, error) {
-	rows, err := db.QueryContext(ctx, query.GetAssociationsAfterId, afterID, limit)
+// GetAssociationsAfterID returns at most limit local associations with id
+// greater than afterID. Removed associations have an empty MatrixID.
+func GetAssociationsAfterID(ctx context.Context, db models.Query, q Driver, afterID int64, limit int64) ([]models.Association, error) {
+	rows, err := db.QueryContext(ctx, q.GetAssociationsAfterId(), afterID, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -24,67 +71,94 @@ func GetAssociationsAfterID(ctx context.Context, db models.Query, afterID int64,
 	var ass []models.Association
 	for rows.Next() {
 		var as models.Association
+		var mxid sql.NullString
+		var notBefore, notAfter sql.NullInt64
 		err := rows.Scan(
 			&as.ID,
 			&as.Medium,
 			&as.Address,
-			&as.MatrixID,
+			&mxid,
 			&as.Timestamp,
-			&as.NotBefore,
-			&as.NotAfter,
+			&notBefore,
+			&notAfter,
 		)
 		if err != nil {
 			return nil, err
 		}
+		as.MatrixID = mxid.String
+		as.NotBefore = notBefore.Int64
+		as.NotAfter = notAfter.Int64
 		ass = append(ass, as)
 	}
-	return ass, nil
+	return ass, rows.Err()
 }
 
-func LocalRemoveAssociation(ctx context.Context, db models.Query, as *models.Association) error {
-	var count int64
-	err := db.QueryRowContext(ctx,
-		query.GetLocal3pid, as.Medium, as.Address, as.MatrixID).Scan(&count)
+// LocalRemoveAssociation marks the association as removed if it is bound to
+// as.MatrixID.
+func LocalRemoveAssociation(ctx context.Context, db models.Query, q Driver, as *models.Association) error {
+	tx, err := begin(ctx, db)
 	if err != nil {
 		return err
 	}
-	if count > 0 {
-		now := time.Now()
-		ts := models.MS(&now)
-		_, err = db.ExecContext(ctx, query.LocalRemoveAssociation, as.Medium, as.Address, ts)
+	before, err := getLocalAssociation(ctx, tx, q, as.Medium, as.Address)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if before == nil || before.MatrixID == "" || before.MatrixID != as.MatrixID {
+		tx.Rollback()
+		return nil
+	}
+	removed := &models.Association{
+		Medium:    as.Medium,
+		Address:   as.Address,
+		Timestamp: models.Time(),
+	}
+	err = replaceLocalAssociation(ctx, tx, q, as.Medium, as.Address,
+		nil, removed.Timestamp, nil, nil,
+	)
+	if err != nil {
+		tx.Rollback()
 		return err
 	}
-	return nil
+	err = auditChange(ctx, tx, q, models.AuditUnbind, as.Medium, as.Address, before.MatrixID,
+		newAuditAssociation(before), newAuditAssociation(removed),
+	)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
 }
 
-func SignedAssociationStringForThreepid(ctx context.Context, db models.Query, medium, address string) (string, error) {
+func SignedAssociationStringForThreepid(ctx context.Context, db models.Query, q Driver, medium, address string) (string, error) {
 	now := time.Now()
 	ts := models.MS(&now)
 	var signed string
-	err := db.QueryRowContext(ctx, query.SignedAssociationStringForThreepid, medium, address, ts, ts).Scan(&signed)
+	err := db.QueryRowContext(ctx, q.SignedAssociationStringForThreepid(), medium, address, ts, ts).Scan(&signed)
 	if err != nil {
 		return "", err
 	}
 	return signed, nil
 }
 
-func GlobalGetMxid(ctx context.Context, db models.Query, medium, address string) (string, error) {
+func GlobalGetMxid(ctx context.Context, db models.Query, q Driver, medium, address string) (string, error) {
 	now := time.Now()
 	ts := models.MS(&now)
 	var signed string
-	err := db.QueryRowContext(ctx, query.GlobalGetMxid, medium, address, ts, ts).Scan(&signed)
+	err := db.QueryRowContext(ctx, q.GlobalGetMxid(), medium, address, ts, ts).Scan(&signed)
 	if err != nil {
 		return "", err
 	}
 	return signed, nil
 }
 
-func GlobalGetMxids(ctx context.Context, db models.Query, ids [][]string) ([]models.Association, error) {
-	tx, err := db.(models.SQL).BeginTx(ctx, nil)
+func GlobalGetMxids(ctx context.Context, db models.Query, q Driver, ids [][]string) ([]models.Association, error) {
+	tx, err := begin(ctx, db)
 	if err != nil {
 		return nil, err
 	}
-	_, err = tx.ExecContext(ctx, query.CreateTMPMxid)
+	_, err = tx.ExecContext(ctx, q.CreateTMPMxid())
 	if err != nil {
 		tx.Rollback()
 		return nil, err
@@ -95,9 +169,9 @@ func GlobalGetMxids(ctx context.Context, db models.Query, ids [][]string) ([]mod
 		var x string
 		var a []interface{}
 		if len(ids) > icap+500 {
-			x, a = mxidsInsertSQL(ids[icap : icap+500])
+			x, a = mxidsInsertSQL(q, ids[icap:icap+500])
 		} else {
-			x, a = mxidsInsertSQL(ids[icap:])
+			x, a = mxidsInsertSQL(q, ids[icap:])
 		}
 		_, err = tx.ExecContext(ctx, x, a...)
 		if err != nil {
@@ -108,7 +182,7 @@ func GlobalGetMxids(ctx context.Context, db models.Query, ids [][]string) ([]mod
 	}
 	now := time.Now()
 	ts := models.MS(&now)
-	rows, err := tx.QueryContext(ctx, query.GlobalGetMxids, ts, ts)
+	rows, err := tx.QueryContext(ctx, q.GlobalGetMxids(), ts, ts)
 	if err != nil {
 		tx.Rollback()
 		return nil, err
@@ -135,22 +209,22 @@ func GlobalGetMxids(ctx context.Context, db models.Query, ids [][]string) ([]mod
 	return results, nil
 }
 
-func mxidsInsertSQL(ids [][]string) (string, []interface{}) {
+func mxidsInsertSQL(q Driver, ids [][]string) (string, []interface{}) {
 	s := `
 INSERT INTO tmp_getmxids (medium, address) VALUES `
-	v, args := genValues(ids)
+	v, args := genValues(q.Param, ids)
 	return s + v + ";", args
 }
 
-func genValues(args [][]string) (string, []interface{}) {
+func genValues(param func(int) string, args [][]string) (string, []interface{}) {
 	s := ""
 	var r []interface{}
 	p := 0
 	for i, x := range args {
 		if i == 0 {
-			s = fmt.Sprintf("($%d,$%d)", p+1, p+2)
+			s = fmt.Sprintf("(%s,%s)", param(p+1), param(p+2))
 		} else {
-			s += fmt.Sprintf(",($%d,$%d)", p+1, p+2)
+			s += fmt.Sprintf(",(%s,%s)", param(p+1), param(p+2))
 		}
 		for _, v := range x {
 			r = append(r, v)
@@ -160,22 +234,81 @@ func genValues(args [][]string) (string, []interface{}) {
 	return s, r
 }
 
-func GlobalAddAssociation(ctx context.Context, db models.Query, as *models.Association, originServer string, originID int64, rawSgnAssoc string) error {
-	_, err := db.ExecContext(ctx, query.GlobalAddAssociation, as.Medium, as.Address, as.MatrixID,
-		as.Timestamp, as.NotBefore, as.NotAfter, originServer, originID, rawSgnAssoc)
-	return err
+// GlobalAddAssociation stores an association replicated from originServer,
+// associations which were already replicated are ignored.
+func GlobalAddAssociation(ctx context.Context, db models.Query, q Driver, as *models.Association, originServer string, originID int64, rawSgnAssoc string) error {
+	tx, err := begin(ctx, db)
+	if err != nil {
+		return err
+	}
+	hash, err := lookupHash(ctx, tx, q, as.Medium, as.Address)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	r, err := tx.ExecContext(ctx, q.GlobalAddAssociation(), as.Medium, as.Address, as.MatrixID,
+		as.Timestamp, as.NotBefore, as.NotAfter, originServer, originID, rawSgnAssoc, hash)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	n, err := r.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if n > 0 {
+		after := newAuditAssociation(as)
+		after.ID = 0
+		after.OriginServer = originServer
+		after.OriginID = originID
+		err = auditChange(ctx, tx, q, models.AuditGlobalAdd, as.Medium, as.Address, as.MatrixID, nil, after)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
 }
 
-func GlobalLastIDFromServer(ctx context.Context, db models.Query, originServer string) (int64, error) {
-	var originID int64
-	err := db.QueryRowContext(ctx, query.GlobalLastIDFromServer, originServer).Scan(&originID)
+// GlobalLastIDFromServer returns the highest origin id of associations
+// replicated from originServer. sql.ErrNoRows is returned when there are none.
+func GlobalLastIDFromServer(ctx context.Context, db models.Query, q Driver, originServer string) (int64, error) {
+	var originID sql.NullInt64
+	var count int64
+	err := db.QueryRowContext(ctx, q.GlobalLastIDFromServer(), originServer).Scan(&originID, &count)
 	if err != nil {
 		return 0, err
 	}
-	return originID, nil
+	if count == 0 || !originID.Valid {
+		return 0, sql.ErrNoRows
+	}
+	return originID.Int64, nil
 }
 
-func GlobalRemoveAssociation(ctx context.Context, db models.Query, medium, address string) error {
-	_, err := db.ExecContext(ctx, query.GlobalRemoveAssociation, medium, address)
-	return err
+// GlobalRemoveAssociation deletes the global associations of medium and
+// address, each deleted association is recorded in the audit log.
+func GlobalRemoveAssociation(ctx context.Context, db models.Query, q Driver, medium, address string) error {
+	tx, err := begin(ctx, db)
+	if err != nil {
+		return err
+	}
+	before, err := globalAssociationsFor(ctx, tx, q, medium, address)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	_, err = tx.ExecContext(ctx, q.GlobalRemoveAssociation(), medium, address)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	for _, a := range before {
+		err = auditChange(ctx, tx, q, models.AuditGlobalRemove, medium, address, a.MatrixID, a, nil)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
 }
@@ -6,12 +6,11 @@ import (
 
 	"github.com/gernest/sydent-go/config"
 	"github.com/gernest/sydent-go/models"
-	"github.com/gernest/sydent-go/store/query"
 )
 
-func GetOrCreateTokenSession(ctx context.Context, db models.Query, medium, address, clientSecret string) (*models.ValidationSession, error) {
+func GetOrCreateTokenSession(ctx context.Context, db models.Query, q Driver, medium, address, clientSecret string) (*models.ValidationSession, error) {
 	var v models.ValidationSession
-	err := db.QueryRowContext(ctx, query.GetTokenSession, medium, address, clientSecret).Scan(
+	err := db.QueryRowContext(ctx, q.GetTokenSession(), medium, address, clientSecret).Scan(
 		&v.ID,
 		&v.Medium,
 		&v.Address,
@@ -23,12 +22,12 @@ func GetOrCreateTokenSession(ctx context.Context, db models.Query, medium, addre
 	)
 	if err == sql.ErrNoRows {
 		mtime := models.Time()
-		sid, err := AddValidationSession(ctx, db, medium, address, clientSecret, mtime)
+		sid, err := AddValidationSession(ctx, db, q, medium, address, clientSecret, mtime)
 		if err != nil {
 			return nil, err
 		}
 		tokenString := models.GenerateToken(medium)
-		_, err = db.ExecContext(ctx, query.CreateTokenSession, sid, tokenString, -1)
+		_, err = db.ExecContext(ctx, q.CreateTokenSession(), sid, tokenString, -1)
 		if err != nil {
 			return nil, err
 		}
@@ -44,33 +43,33 @@ func GetOrCreateTokenSession(ctx context.Context, db models.Query, medium, addre
 	return &v, nil
 }
 
-func AddValidationSession(ctx context.Context, db models.Query, medium, address, clientSecret string, mtime int64) (int64, error) {
+func AddValidationSession(ctx context.Context, db models.Query, q Driver, medium, address, clientSecret string, mtime int64) (int64, error) {
 	var id int64
-	err := db.QueryRowContext(ctx, query.AddValidationSession, medium, address, clientSecret, mtime).Scan(&id)
+	err := db.QueryRowContext(ctx, q.AddValidationSession(), medium, address, clientSecret, mtime).Scan(&id)
 	if err != nil {
 		return 0, err
 	}
 	return id, nil
 }
 
-func SetSendAttemptNumber(ctx context.Context, db models.Query, sid int64, attemptNo int64) error {
-	_, err := db.ExecContext(ctx, query.SetSendAttemptNumber, attemptNo, sid)
+func SetSendAttemptNumber(ctx context.Context, db models.Query, q Driver, sid int64, attemptNo int64) error {
+	_, err := db.ExecContext(ctx, q.SetSendAttemptNumber(), attemptNo, sid)
 	return err
 }
 
-func SetValidated(ctx context.Context, db models.Query, sid string, validated int) error {
-	_, err := db.ExecContext(ctx, query.SetValidated, validated, sid)
+func SetValidated(ctx context.Context, db models.Query, q Driver, sid string, validated int) error {
+	_, err := db.ExecContext(ctx, q.SetValidated(), validated, sid)
 	return err
 }
 
-func SetMtime(ctx context.Context, db models.Query, sid int64, mtime int64) error {
-	_, err := db.ExecContext(ctx, query.SetMtime, mtime, sid)
+func SetMtime(ctx context.Context, db models.Query, q Driver, sid int64, mtime int64) error {
+	_, err := db.ExecContext(ctx, q.SetMtime(), mtime, sid)
 	return err
 }
 
-func GetSessionByID(ctx context.Context, db models.Query, sid int64) (*models.ValidationSession, error) {
+func GetSessionByID(ctx context.Context, db models.Query, q Driver, sid int64) (*models.ValidationSession, error) {
 	var v models.ValidationSession
-	err := db.QueryRowContext(ctx, query.GetSessionByID, sid).Scan(
+	err := db.QueryRowContext(ctx, q.GetSessionByID(), sid).Scan(
 		&v.ID,
 		&v.Medium,
 		&v.Address,
@@ -84,9 +83,9 @@ func GetSessionByID(ctx context.Context, db models.Query, sid int64) (*models.Va
 	return &v, err
 }
 
-func GetTokenSessionByID(ctx context.Context, db models.Query, sid int64) (*models.TokenSession, error) {
+func GetTokenSessionByID(ctx context.Context, db models.Query, q Driver, sid int64) (*models.TokenSession, error) {
 	var v models.TokenSession
-	err := db.QueryRowContext(ctx, query.GetTokenSessionByID, sid).Scan(
+	err := db.QueryRowContext(ctx, q.GetTokenSessionByID(), sid).Scan(
 		&v.ID,
 		&v.Medium,
 		&v.Address,
@@ -103,11 +102,17 @@ func GetTokenSessionByID(ctx context.Context, db models.Query, sid int64) (*mode
 }
 
 // GetValidatedSession returns a validated session with ma matching clientSecret
-func GetValidatedSession(ctx context.Context, db models.Query, sid int64, clientSecret string) (*models.ValidationSession, error) {
-	sess, err := GetSessionByID(ctx, db, sid)
+func GetValidatedSession(ctx context.Context, db models.Query, q Driver, sid int64, clientSecret string) (*models.ValidationSession, error) {
+	sess, err := GetSessionByID(ctx, db, q, sid)
 	if err != nil {
 		return nil, err
 	}
+	return validatedSession(sess, clientSecret)
+}
+
+// validatedSession returns sess if it is validated, not expired and owned by
+// clientSecret.
+func validatedSession(sess *models.ValidationSession, clientSecret string) (*models.ValidationSession, error) {
 	if sess.ClientSecret != clientSecret {
 		return nil, models.ErrIncorrectClientSecret
 	}